@@ -0,0 +1,794 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-course/exercicios/notas"
+	"go-course/modulo12-http/autenticacao"
+	"go-course/modulo12-http/autorizacao"
+	"go-course/modulo12-http/openapi"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+API REST DO SISTEMA DE NOTAS
+
+Expõe um notas.SistemaSeguro via net/http (o mesmo servidor de
+modulo12-http/01_servidor_basico.go), com as rotas despachadas pelo
+package modulo12-http/roteador.
+
+ROTAS:
+    GET    /api/alunos?pagina=1&por_pagina=20    lista paginada
+    POST   /api/alunos                           cadastra {"nome","notas","frequencia","recuperacao"}
+    GET    /api/alunos/{nome}                    aluno + média e situação (com ETag)
+    PUT    /api/alunos/{nome}                    substitui notas, frequência e recuperação
+    DELETE /api/alunos/{nome}                    remove
+    POST   /api/alunos/{nome}/notas              inclui notas {"notas":[7, 8]}
+    PUT    /api/alunos/{nome}/notas/{n}          corrige a n-ésima nota {"nota": 7.5}
+    DELETE /api/alunos/{nome}/notas/{n}          apaga a n-ésima nota
+    GET    /api/aprovados?pagina=1               aprovados, paginado
+    GET    /api/estatisticas                     resumo da turma
+
+Cada rota é documentada (roteador.Documentacao): srv.OpenAPI serve o
+documento OpenAPI 3 de todas elas, inclusive as montadas com Grupo.
+
+AUTENTICAÇÃO:
+Opcoes.Escrita protege as rotas que alteram dados (POST, PUT,
+DELETE); Opcoes.Leitura embrulha os GETs, que ficam abertos mas
+identificam quem manda um token (para a política de autorização):
+
+    auth := autenticacao.NovoServico(usuarios, tokens)
+    srv := api.NovoServidor(sistema, api.Opcoes{
+        Escrita: []roteador.Middleware{auth.Exigir()},
+        Leitura: []roteador.Middleware{auth.Identificar()},
+    })
+    auth.Rotas(srv.Grupo("/api/auth"))
+
+A auditoria registra o usuário autenticado (autenticacao.UsuarioDe).
+Sem autenticação configurada, vale o cabeçalho X-Autor.
+
+AUTORIZAÇÃO (opcional, srv.UsarPolitica):
+Cada rota é uma ação sobre um recurso, conferida com
+modulo12-http/autorizacao:
+
+    ler      aluno, estatisticas     GETs
+    criar    aluno                   POST /api/alunos
+    alterar  aluno                   PUT /api/alunos/{nome}
+    remover  aluno                   DELETE /api/alunos/{nome}
+    criar, alterar, remover  nota    /api/alunos/{nome}/notas...
+
+O dono de um aluno (e das suas notas) é quem o cadastrou
+(SistemaSeguro.Dono); regras "somente_dono" da política usam isso. A
+conferência do dono acontece junto da alteração, sob a mesma trava.
+
+CONCORRÊNCIA OTIMISTA (If-Match):
+Cada aluno tem um ETag (hash do seu estado). Um cliente que envia
+If-Match com o ETag lido antes só altera se ninguém mudou o aluno
+nesse meio tempo; senão recebe 412 Precondition Failed e relê.
+If-Match usa comparação forte: W/"..." nunca confere (If-None-Match,
+nos GETs, aceita os dois).
+
+ERROS:
+    400 JSON malformado, campo desconhecido, paginação inválida
+    401 alteração sem token válido (com autenticação)
+    403 a política não permite a ação ao usuário
+    404 aluno ou nota inexistente
+    405 método não suportado (com cabeçalho Allow)
+    409 aluno já cadastrado
+    412 If-Match não confere
+    413 corpo acima de 1 MB
+    415 Content-Type diferente de application/json
+    422 nota ou nome inválido
+
+Corpo de erro: application/problem+json (modulo12-http/problema)
+
+    {"type": "about:blank", "title": "Unprocessable Entity", "status": 422,
+     "detail": "nota 11.00: nota fora do intervalo 0 a 10",
+     "instance": "/api/alunos", "erros": [{"campo": "notas", ...}]}
+
+Erros inesperados (500) chegam ao cliente só como "erro interno" e o
+request_id; a causa vai para o log.
+*/
+
+// Limites da API
+const (
+	TamanhoMaximoCorpo = 1 << 20 // 1 MB
+	PorPaginaPadrao    = 20
+	PorPaginaMaximo    = 100
+)
+
+// AutorPadrao é registrado na auditoria quando a requisição não é
+// autenticada nem informa o cabeçalho X-Autor
+const AutorPadrao = "api"
+
+// Servidor é o http.Handler da API
+type Servidor struct {
+	sistema   *notas.SistemaSeguro
+	rotas     *roteador.Roteador
+	politica  *autorizacao.Politica
+	problemas *problema.Registro
+
+	// escrita serializa "conferir If-Match e alterar": sem ela, duas
+	// requisições com o mesmo ETag poderiam passar pela conferência
+	escrita sync.Mutex
+}
+
+// Opcoes ajusta NovoServidor; o valor zero deixa todas as rotas abertas
+type Opcoes struct {
+	// Escrita embrulha as rotas que alteram dados
+	// (ex.: autenticacao.Servico.Exigir)
+	Escrita []roteador.Middleware
+	// Leitura embrulha os GETs (ex.: autenticacao.Servico.Identificar,
+	// que põe o usuário no context sem exigir token)
+	Leitura []roteador.Middleware
+}
+
+// NovoServidor cria a API sobre um sistema seguro
+func NovoServidor(sistema *notas.SistemaSeguro, opcoes Opcoes) *Servidor {
+	s := &Servidor{sistema: sistema, rotas: roteador.Novo(), problemas: novosProblemas()}
+	s.rotas.NaoEncontrado = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responderErro(w, http.StatusNotFound, "rota não encontrada")
+	})
+	s.rotas.MetodoNaoPermitido = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responderErro(w, http.StatusMethodNotAllowed, "método "+r.Method+" não permitido")
+	})
+
+	// Cada rota declara a ação e o recurso, para a autorização (UsarPolitica),
+	// e a documentação, para o OpenAPI (Servidor.OpenAPI)
+	aluno := autorizacao.Tipo("aluno", "nome")
+	rota := func(g *roteador.GrupoRotas, metodo, padrao, acao string, recurso autorizacao.FuncaoRecurso, h http.HandlerFunc) *roteador.Registro {
+		return g.Grupo("", s.autorizar(acao, recurso)).HandleFunc(metodo, padrao, h)
+	}
+	errosAlteracao := []int{400, 401, 403, 404, 412, 413, 415, 422}
+
+	api := s.rotas.Grupo("/api")
+	leitura := api.Grupo("", opcoes.Leitura...)
+	rota(leitura, "GET", "/alunos", "ler", aluno, s.listarAlunos).Documentar(roteador.Documentacao{
+		Resumo:    "Lista os alunos",
+		Descricao: "Paginada por ?pagina e ?por_pagina; ?aprovados=true|false filtra pela situação. Links de navegação no cabeçalho Link.",
+		Tags:      []string{"alunos"},
+		Resposta:  Pagina{},
+		Erros:     []int{400, 403},
+	})
+	rota(leitura, "GET", "/alunos/{nome}", "ler", aluno, s.obterAluno).Documentar(roteador.Documentacao{
+		Resumo:    "Aluno com média e situação",
+		Descricao: "Responde com ETag; If-None-Match com o mesmo ETag recebe 304.",
+		Tags:      []string{"alunos"},
+		Resposta:  AlunoJSON{},
+		Erros:     []int{403, 404},
+	})
+	rota(leitura, "GET", "/aprovados", "ler", aluno, s.listarAprovados).Documentar(roteador.Documentacao{
+		Resumo:   "Lista os aprovados",
+		Tags:     []string{"alunos"},
+		Resposta: Pagina{},
+		Erros:    []int{400, 403},
+	})
+	rota(leitura, "GET", "/estatisticas", "ler", autorizacao.Tipo("estatisticas", ""), s.estatisticas).Documentar(roteador.Documentacao{
+		Resumo:   "Resumo da turma",
+		Tags:     []string{"turma"},
+		Resposta: Estatisticas{},
+		Erros:    []int{403},
+	})
+
+	// Nas rotas de um aluno existente, a autorização depende do dono e
+	// fica no handler (conferirDono), com s.escrita travado: num
+	// middleware, o aluno poderia ser removido e recriado por outro
+	// entre a conferência e a alteração
+	escrita := api.Grupo("", opcoes.Escrita...)
+	rota(escrita, "POST", "/alunos", "criar", aluno, s.criarAluno).Documentar(roteador.Documentacao{
+		Resumo:     "Cadastra um aluno",
+		Tags:       []string{"alunos"},
+		Requisicao: entradaAluno{},
+		Resposta:   AlunoJSON{},
+		Status:     http.StatusCreated,
+		Erros:      []int{400, 401, 403, 409, 413, 415, 422},
+	})
+	escrita.HandleFunc("PUT", "/alunos/{nome}", s.substituirAluno).Documentar(roteador.Documentacao{
+		Resumo:     "Substitui notas, frequência e recuperação",
+		Descricao:  "Com If-Match, só altera se o ETag ainda conferir.",
+		Tags:       []string{"alunos"},
+		Requisicao: entradaAluno{},
+		Resposta:   AlunoJSON{},
+		Erros:      errosAlteracao,
+	})
+	escrita.HandleFunc("DELETE", "/alunos/{nome}", s.removerAluno).Documentar(roteador.Documentacao{
+		Resumo: "Remove o aluno",
+		Tags:   []string{"alunos"},
+		Status: http.StatusNoContent,
+		Erros:  []int{401, 403, 404, 412},
+	})
+	escrita.HandleFunc("POST", "/alunos/{nome}/notas", s.adicionarNotas).Documentar(roteador.Documentacao{
+		Resumo:     "Inclui notas no fim da lista",
+		Tags:       []string{"notas"},
+		Requisicao: entradaNotas{},
+		Resposta:   AlunoJSON{},
+		Status:     http.StatusCreated,
+		Erros:      errosAlteracao,
+	})
+	escrita.HandleFunc("PUT", "/alunos/{nome}/notas/{n}", s.alterarNota).Documentar(roteador.Documentacao{
+		Resumo:     "Corrige a n-ésima nota (1, 2, 3...)",
+		Tags:       []string{"notas"},
+		Requisicao: entradaNota{},
+		Resposta:   AlunoJSON{},
+		Erros:      errosAlteracao,
+	})
+	escrita.HandleFunc("DELETE", "/alunos/{nome}/notas/{n}", s.removerNota).Documentar(roteador.Documentacao{
+		Resumo:   "Apaga a n-ésima nota (1, 2, 3...)",
+		Tags:     []string{"notas"},
+		Resposta: AlunoJSON{},
+		Erros:    []int{401, 403, 404, 412},
+	})
+	return s
+}
+
+// Rotas retorna a tabela de rotas da API
+func (s *Servidor) Rotas() []roteador.Rota {
+	return s.rotas.Rotas()
+}
+
+// PadraoDe retorna o padrão da rota que atenderia r ("" se nenhuma),
+// para middlewares que embrulham a API por fora (ex.: métricas por rota)
+func (s *Servidor) PadraoDe(r *http.Request) string {
+	return s.rotas.PadraoDe(r)
+}
+
+// UsarPolitica liga a autorização por papéis em todas as rotas. Chame
+// antes de o servidor começar a atender.
+func (s *Servidor) UsarPolitica(p *autorizacao.Politica) {
+	s.politica = p
+}
+
+// OpenAPI serve o documento OpenAPI 3 das rotas da API, inclusive as
+// montadas com Grupo (veja modulo12-http/openapi)
+func (s *Servidor) OpenAPI(info openapi.Info) http.Handler {
+	return openapi.Handler(s.rotas, info)
+}
+
+// Grupo dá acesso ao roteador da API para montar outras rotas ao
+// lado das de alunos (ex.: as de autenticacao em "/api/auth")
+func (s *Servidor) Grupo(prefixo string, middlewares ...roteador.Middleware) *roteador.GrupoRotas {
+	return s.rotas.Grupo(prefixo, middlewares...)
+}
+
+// AlunoJSON é a representação de um aluno nas respostas
+type AlunoJSON struct {
+	Nome        string    `json:"nome"`
+	Notas       []float64 `json:"notas"`
+	Frequencia  *float64  `json:"frequencia,omitempty"`
+	Recuperacao *float64  `json:"recuperacao,omitempty"`
+	Media       float64   `json:"media"`
+	Conceito    string    `json:"conceito"`
+	Aprovado    bool      `json:"aprovado"`
+}
+
+// Pagina é a resposta das listagens
+type Pagina struct {
+	Itens        []AlunoJSON `json:"itens"`
+	Pagina       int         `json:"pagina"`
+	PorPagina    int         `json:"por_pagina"`
+	Total        int         `json:"total"`
+	TotalPaginas int         `json:"total_paginas"`
+}
+
+// Estatisticas é a resposta de /api/estatisticas
+type Estatisticas struct {
+	Total      int                      `json:"total"`
+	Aprovados  int                      `json:"aprovados"`
+	Reprovados int                      `json:"reprovados"`
+	MediaGeral float64                  `json:"media_geral"`
+	Quartis    notas.Quartis            `json:"quartis"`
+	Conceitos  []notas.ContagemConceito `json:"conceitos"`
+}
+
+// entradaAluno é o corpo de POST e PUT em /api/alunos
+type entradaAluno struct {
+	Nome        string    `json:"nome"`
+	Notas       []float64 `json:"notas"`
+	Frequencia  *float64  `json:"frequencia"`
+	Recuperacao *float64  `json:"recuperacao"`
+}
+
+// entradaNotas é o corpo de POST /api/alunos/{nome}/notas
+type entradaNotas struct {
+	Notas []float64 `json:"notas"`
+}
+
+// entradaNota é o corpo de PUT /api/alunos/{nome}/notas/{n}
+type entradaNota struct {
+	Nota *float64 `json:"nota"`
+}
+
+// ServeHTTP implementa http.Handler
+func (s *Servidor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.rotas.ServeHTTP(w, r)
+}
+
+// indiceNota lê {n} (1, 2, 3...) e o converte para índice 0-based
+func indiceNota(w http.ResponseWriter, r *http.Request) (int, bool) {
+	n, err := strconv.Atoi(roteador.Parametro(r, "n"))
+	if err != nil || n < 1 {
+		responderErro(w, http.StatusNotFound, "nota deve ser identificada por 1, 2, 3...")
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// ========================================
+// HANDLERS
+// ========================================
+
+func (s *Servidor) listarAlunos(w http.ResponseWriter, r *http.Request) {
+	// Lista e avaliações saem da mesma cópia: uma remoção no meio não
+	// deixa um aluno sem média na resposta
+	copia := s.sistema.Copia()
+	alunos := copia.Alunos
+	if aprovados := r.URL.Query().Get("aprovados"); aprovados != "" {
+		filtro, err := strconv.ParseBool(aprovados)
+		if err != nil {
+			responderErro(w, http.StatusBadRequest, "aprovados deve ser true ou false")
+			return
+		}
+		alunos = filtrarAprovados(copia, filtro)
+	}
+	responderPagina(w, r, copia, alunos)
+}
+
+func (s *Servidor) listarAprovados(w http.ResponseWriter, r *http.Request) {
+	copia := s.sistema.Copia()
+	responderPagina(w, r, copia, filtrarAprovados(copia, true))
+}
+
+func filtrarAprovados(copia *notas.SistemaNotas, aprovado bool) []notas.Aluno {
+	var filtrados []notas.Aluno
+	for _, a := range copia.Alunos {
+		if copia.Avaliar(a).Aprovado == aprovado {
+			filtrados = append(filtrados, a)
+		}
+	}
+	return filtrados
+}
+
+func (s *Servidor) criarAluno(w http.ResponseWriter, r *http.Request) {
+	var entrada entradaAluno
+	if !lerJSON(w, r, &entrada) {
+		return
+	}
+
+	s.escrita.Lock()
+	defer s.escrita.Unlock()
+	err := s.sistema.Cadastrar(autor(r), notas.Aluno{
+		Nome:        entrada.Nome,
+		Notas:       entrada.Notas,
+		Frequencia:  entrada.Frequencia,
+		Recuperacao: entrada.Recuperacao,
+	})
+	if err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/alunos/"+url.PathEscape(strings.TrimSpace(entrada.Nome)))
+	s.responderAluno(w, r, http.StatusCreated, entrada.Nome)
+}
+
+func (s *Servidor) obterAluno(w http.ResponseWriter, r *http.Request) {
+	aluno, resultado, err := s.sistema.BuscarAvaliado(roteador.Parametro(r, "nome"))
+	if err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+	etag := etagAluno(aluno)
+	w.Header().Set("ETag", etag)
+	if correspondeFraco(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	responderJSON(w, http.StatusOK, paraJSON(aluno, resultado))
+}
+
+func (s *Servidor) substituirAluno(w http.ResponseWriter, r *http.Request) {
+	nome := roteador.Parametro(r, "nome")
+	var entrada entradaAluno
+	if !lerJSON(w, r, &entrada) {
+		return
+	}
+	if entrada.Nome != "" && !strings.EqualFold(strings.TrimSpace(entrada.Nome), nome) {
+		responderErro(w, http.StatusUnprocessableEntity, "o nome do corpo difere do nome da URL")
+		return
+	}
+
+	s.escrita.Lock()
+	defer s.escrita.Unlock()
+	if !s.conferirDono(w, r, "alterar", "aluno", nome) || !s.conferirIfMatch(w, r, nome) {
+		return
+	}
+	err := s.sistema.Substituir(autor(r), notas.Aluno{
+		Nome:        nome,
+		Notas:       entrada.Notas,
+		Frequencia:  entrada.Frequencia,
+		Recuperacao: entrada.Recuperacao,
+	})
+	if err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+	s.responderAluno(w, r, http.StatusOK, nome)
+}
+
+func (s *Servidor) removerAluno(w http.ResponseWriter, r *http.Request) {
+	nome := roteador.Parametro(r, "nome")
+	s.escrita.Lock()
+	defer s.escrita.Unlock()
+	if !s.conferirDono(w, r, "remover", "aluno", nome) || !s.conferirIfMatch(w, r, nome) {
+		return
+	}
+	if err := s.sistema.RemoverAluno(autor(r), nome); err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Servidor) adicionarNotas(w http.ResponseWriter, r *http.Request) {
+	nome := roteador.Parametro(r, "nome")
+	var entrada entradaNotas
+	if !lerJSON(w, r, &entrada) {
+		return
+	}
+	if len(entrada.Notas) == 0 {
+		responderErro(w, http.StatusUnprocessableEntity, "informe ao menos uma nota em \"notas\"")
+		return
+	}
+
+	s.escrita.Lock()
+	defer s.escrita.Unlock()
+	if !s.conferirDono(w, r, "criar", "nota", nome) || !s.conferirIfMatch(w, r, nome) {
+		return
+	}
+	if err := s.sistema.AdicionarNotas(autor(r), nome, entrada.Notas...); err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+	s.responderAluno(w, r, http.StatusCreated, nome)
+}
+
+func (s *Servidor) alterarNota(w http.ResponseWriter, r *http.Request) {
+	nome := roteador.Parametro(r, "nome")
+	indice, ok := indiceNota(w, r)
+	if !ok {
+		return
+	}
+	var entrada entradaNota
+	if !lerJSON(w, r, &entrada) {
+		return
+	}
+	if entrada.Nota == nil {
+		responderErro(w, http.StatusUnprocessableEntity, "campo \"nota\" é obrigatório")
+		return
+	}
+
+	s.escrita.Lock()
+	defer s.escrita.Unlock()
+	if !s.conferirDono(w, r, "alterar", "nota", nome) || !s.conferirIfMatch(w, r, nome) {
+		return
+	}
+	if err := s.sistema.AlterarNota(autor(r), nome, indice, *entrada.Nota); err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+	s.responderAluno(w, r, http.StatusOK, nome)
+}
+
+func (s *Servidor) removerNota(w http.ResponseWriter, r *http.Request) {
+	nome := roteador.Parametro(r, "nome")
+	indice, ok := indiceNota(w, r)
+	if !ok {
+		return
+	}
+	s.escrita.Lock()
+	defer s.escrita.Unlock()
+	if !s.conferirDono(w, r, "remover", "nota", nome) || !s.conferirIfMatch(w, r, nome) {
+		return
+	}
+	if err := s.sistema.RemoverNota(autor(r), nome, indice); err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+	s.responderAluno(w, r, http.StatusOK, nome)
+}
+
+func (s *Servidor) estatisticas(w http.ResponseWriter, r *http.Request) {
+	rel := s.sistema.Relatorio()
+	responderJSON(w, http.StatusOK, Estatisticas{
+		Total:      rel.Total,
+		Aprovados:  rel.Aprovados,
+		Reprovados: rel.Total - rel.Aprovados,
+		MediaGeral: rel.MediaGeral,
+		Quartis:    rel.Quartis,
+		Conceitos:  rel.Distribuicao,
+	})
+}
+
+// ========================================
+// AUXILIARES
+// ========================================
+
+// autorizar é o middleware de autorização de uma rota; sem política,
+// deixa tudo passar
+func (s *Servidor) autorizar(acao string, recurso autorizacao.FuncaoRecurso) roteador.Middleware {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.politica != nil {
+				if rec, ok := recurso(r); ok {
+					if err := s.politica.Autorizar(r.Context(), acao, rec); err != nil {
+						s.responderErroSistema(w, r, err)
+						return
+					}
+				}
+			}
+			proximo.ServeHTTP(w, r)
+		})
+	}
+}
+
+// conferirDono autoriza acao sobre o tipo ("aluno" ou "nota") do aluno
+// nome, cujo dono é quem o cadastrou; chamar com s.escrita travado, para
+// a conferência ver o mesmo estado que a alteração. Aluno inexistente é
+// conferido como "criar" sem dono: quem pode criar segue e recebe 404.
+func (s *Servidor) conferirDono(w http.ResponseWriter, r *http.Request, acao, tipo, nome string) bool {
+	if s.politica == nil {
+		return true
+	}
+	rec := autorizacao.Recurso{Tipo: tipo, ID: nome}
+	dono, err := s.sistema.Dono(nome)
+	switch {
+	case errors.Is(err, notas.ErrAlunoNaoEncontrado):
+		acao = "criar"
+	case err != nil:
+		s.responderErroSistema(w, r, err)
+		return false
+	default:
+		rec.Dono = dono
+	}
+	if err := s.politica.Autorizar(r.Context(), acao, rec); err != nil {
+		s.responderErroSistema(w, r, err)
+		return false
+	}
+	return true
+}
+
+// autor identifica quem fez a alteração, para a auditoria. O usuário
+// autenticado tem precedência: com login, X-Autor é ignorado (qualquer
+// cliente poderia escrever ali o nome de outra pessoa).
+func autor(r *http.Request) string {
+	if u, ok := autenticacao.UsuarioDe(r.Context()); ok {
+		return u.Username
+	}
+	if a := strings.TrimSpace(r.Header.Get("X-Autor")); a != "" {
+		return a
+	}
+	return AutorPadrao
+}
+
+// conferirIfMatch responde 412 se o If-Match não bater com o aluno
+// atual; chamar com s.escrita travado
+func (s *Servidor) conferirIfMatch(w http.ResponseWriter, r *http.Request, nome string) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+	aluno, err := s.sistema.BuscarAluno(nome)
+	if err != nil {
+		// If-Match em recurso inexistente também é 412 (RFC 9110)
+		responderErro(w, http.StatusPreconditionFailed, "aluno não existe mais")
+		return false
+	}
+	etag := etagAluno(aluno)
+	if !correspondeForte(ifMatch, etag) {
+		w.Header().Set("ETag", etag)
+		responderErro(w, http.StatusPreconditionFailed, "o aluno foi alterado por outra requisição; leia novamente")
+		return false
+	}
+	return true
+}
+
+// etagAluno é um hash forte do estado do aluno
+func etagAluno(a notas.Aluno) string {
+	dados, _ := json.Marshal(a)
+	soma := sha256.Sum256(dados)
+	return `"` + hex.EncodeToString(soma[:8]) + `"`
+}
+
+// correspondeForte confere If-Match ("*" ou lista separada por
+// vírgulas) com comparação forte (RFC 9110, 13.1.1): um validador fraco
+// W/"..." nunca autoriza uma alteração
+func correspondeForte(cabecalho, etag string) bool {
+	for _, candidato := range strings.Split(cabecalho, ",") {
+		candidato = strings.TrimSpace(candidato)
+		if candidato == "*" || (candidato == etag && !strings.HasPrefix(etag, "W/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// correspondeFraco confere If-None-Match com comparação fraca: o W/ é
+// ignorado dos dois lados (um proxy com gzip pode ter enfraquecido o
+// ETag)
+func correspondeFraco(cabecalho, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidato := range strings.Split(cabecalho, ",") {
+		candidato = strings.TrimSpace(candidato)
+		if candidato == "*" || strings.TrimPrefix(candidato, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Servidor) responderAluno(w http.ResponseWriter, r *http.Request, status int, nome string) {
+	aluno, resultado, err := s.sistema.BuscarAvaliado(nome)
+	if err != nil {
+		s.responderErroSistema(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etagAluno(aluno))
+	responderJSON(w, status, paraJSON(aluno, resultado))
+}
+
+func paraJSON(a notas.Aluno, r notas.Resultado) AlunoJSON {
+	if a.Notas == nil {
+		a.Notas = []float64{} // [] em vez de null no JSON
+	}
+	return AlunoJSON{
+		Nome:        a.Nome,
+		Notas:       a.Notas,
+		Frequencia:  a.Frequencia,
+		Recuperacao: a.Recuperacao,
+		Media:       r.Media,
+		Conceito:    notas.Conceito(r.Media),
+		Aprovado:    r.Aprovado,
+	}
+}
+
+// responderPagina pagina alunos (tirados de copia) com as avaliações
+// da mesma copia
+func responderPagina(w http.ResponseWriter, r *http.Request, copia *notas.SistemaNotas, alunos []notas.Aluno) {
+	pagina, porPagina, err := lerPaginacao(r.URL.Query())
+	if err != nil {
+		responderErro(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p := Pagina{
+		Itens:        []AlunoJSON{},
+		Pagina:       pagina,
+		PorPagina:    porPagina,
+		Total:        len(alunos),
+		TotalPaginas: (len(alunos) + porPagina - 1) / porPagina,
+	}
+	inicio := (pagina - 1) * porPagina
+	for i := inicio; i < len(alunos) && i < inicio+porPagina; i++ {
+		p.Itens = append(p.Itens, paraJSON(alunos[i], copia.Avaliar(alunos[i])))
+	}
+
+	// Link (RFC 8288) para clientes que preferem navegar pelos cabeçalhos
+	var links []string
+	link := func(n int, rel string) {
+		q := r.URL.Query()
+		q.Set("pagina", strconv.Itoa(n))
+		q.Set("por_pagina", strconv.Itoa(porPagina))
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, r.URL.Path, q.Encode(), rel))
+	}
+	if pagina > 1 {
+		link(pagina-1, "prev")
+	}
+	if pagina < p.TotalPaginas {
+		link(pagina+1, "next")
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	responderJSON(w, http.StatusOK, p)
+}
+
+func lerPaginacao(q url.Values) (pagina, porPagina int, err error) {
+	pagina, porPagina = 1, PorPaginaPadrao
+	if v := q.Get("pagina"); v != "" {
+		if pagina, err = strconv.Atoi(v); err != nil || pagina < 1 {
+			return 0, 0, fmt.Errorf("pagina inválida: %q", v)
+		}
+	}
+	if v := q.Get("por_pagina"); v != "" {
+		if porPagina, err = strconv.Atoi(v); err != nil || porPagina < 1 || porPagina > PorPaginaMaximo {
+			return 0, 0, fmt.Errorf("por_pagina deve estar entre 1 e %d", PorPaginaMaximo)
+		}
+	}
+	// (pagina-1)*porPagina não pode estourar int
+	if pagina > math.MaxInt/porPagina {
+		return 0, 0, fmt.Errorf("pagina inválida: %d", pagina)
+	}
+	return pagina, porPagina, nil
+}
+
+// lerJSON decodifica o corpo com validação; em caso de erro já responde
+func lerJSON(w http.ResponseWriter, r *http.Request, destino any) bool {
+	if tipo, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); tipo != "application/json" {
+		responderErro(w, http.StatusUnsupportedMediaType, "Content-Type deve ser application/json")
+		return false
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, TamanhoMaximoCorpo))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(destino)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("o corpo deve conter um único objeto JSON")
+	}
+	if err == nil {
+		return true
+	}
+
+	var (
+		sintaxe     *json.SyntaxError
+		tipo        *json.UnmarshalTypeError
+		muitoGrande *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &muitoGrande):
+		responderErro(w, http.StatusRequestEntityTooLarge, "corpo acima de 1 MB")
+	case errors.As(err, &sintaxe):
+		responderErro(w, http.StatusBadRequest, fmt.Sprintf("JSON malformado na posição %d", sintaxe.Offset))
+	case errors.As(err, &tipo):
+		responderErro(w, http.StatusBadRequest, fmt.Sprintf("campo %q deve ser %s", tipo.Field, tipo.Type))
+	case errors.Is(err, io.EOF):
+		responderErro(w, http.StatusBadRequest, "corpo vazio")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		responderErro(w, http.StatusBadRequest, "campo desconhecido "+strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		responderErro(w, http.StatusBadRequest, err.Error())
+	}
+	return false
+}
+
+// novosProblemas traduz os erros de notas e da autorização em status
+// HTTP; as mensagens dos sentinels de notas podem ir para o cliente
+func novosProblemas() *problema.Registro {
+	reg := problema.Padrao()
+	reg.Sentinela(notas.ErrAlunoNaoEncontrado, problema.Regra{Status: http.StatusNotFound, Publica: true})
+	reg.Sentinela(notas.ErrIndiceNota, problema.Regra{Status: http.StatusNotFound, Publica: true})
+	reg.Sentinela(notas.ErrAlunoDuplicado, problema.Regra{Status: http.StatusConflict, Publica: true})
+	reg.Sentinela(notas.ErrNomeVazio, problema.Regra{Status: http.StatusUnprocessableEntity, Publica: true, Campo: "nome"})
+	reg.Sentinela(notas.ErrNotaInvalida, problema.Regra{Status: http.StatusUnprocessableEntity, Publica: true, Campo: "notas"})
+	reg.Sentinela(notas.ErrFrequenciaInvalida, problema.Regra{Status: http.StatusUnprocessableEntity, Publica: true, Campo: "frequencia"})
+
+	// 401 para anônimo (falta se autenticar), 403 para usuário autenticado
+	problema.RegistrarTipo(reg, autorizacao.ProblemaAcesso)
+	return reg
+}
+
+// responderErroSistema responde err como problem+json; 500 vai para o log
+func (s *Servidor) responderErroSistema(w http.ResponseWriter, r *http.Request, err error) {
+	s.problemas.Responder(w, r, err)
+}
+
+func responderErro(w http.ResponseWriter, status int, mensagem string) {
+	problema.Escrever(w, problema.Novo(status, mensagem))
+}
+
+func responderJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
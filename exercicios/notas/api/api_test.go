@@ -0,0 +1,509 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-course/exercicios/notas"
+	"go-course/modulo12-http/autenticacao"
+	"go-course/modulo12-http/autorizacao"
+	"go-course/modulo12-http/openapi"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func novoServidorTeste(t *testing.T) (*Servidor, *notas.SistemaSeguro) {
+	t.Helper()
+	sistema, err := notas.NovoSistemaSeguro(&notas.AuditoriaMemoria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NovoServidor(sistema, Opcoes{}), sistema
+}
+
+// requisitar executa uma requisição JSON e devolve a resposta gravada
+func requisitar(h http.Handler, metodo, caminho, corpo string, cabecalhos ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(metodo, caminho, strings.NewReader(corpo))
+	if corpo != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(cabecalhos); i += 2 {
+		req.Header.Set(cabecalhos[i], cabecalhos[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCRUD(t *testing.T) {
+	srv, sistema := novoServidorTeste(t)
+
+	rec := requisitar(srv, "POST", "/api/alunos", `{"nome":"Ana Costa","notas":[7,8],"frequencia":90}`, "X-Autor", "prof.ana")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s", rec.Code, rec.Body)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/api/alunos/Ana%20Costa" {
+		t.Errorf("Location = %q", loc)
+	}
+	var aluno AlunoJSON
+	json.NewDecoder(rec.Body).Decode(&aluno)
+	if aluno.Media != 7.5 || !aluno.Aprovado || aluno.Conceito != "C" {
+		t.Errorf("aluno criado = %+v", aluno)
+	}
+
+	rec = requisitar(srv, "POST", "/api/alunos/Ana%20Costa/notas", `{"notas":[10]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST notas = %d %s", rec.Code, rec.Body)
+	}
+	rec = requisitar(srv, "PUT", "/api/alunos/ana%20costa/notas/1", `{"nota":4}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"notas":[4,8,10]`) {
+		t.Fatalf("PUT nota = %d %s", rec.Code, rec.Body)
+	}
+	rec = requisitar(srv, "DELETE", "/api/alunos/Ana%20Costa/notas/3", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"notas":[4,8]`) {
+		t.Fatalf("DELETE nota = %d %s", rec.Code, rec.Body)
+	}
+	rec = requisitar(srv, "PUT", "/api/alunos/Ana%20Costa", `{"notas":[9,9],"recuperacao":null}`)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "frequencia") {
+		t.Fatalf("PUT aluno = %d %s", rec.Code, rec.Body)
+	}
+
+	historico, _ := sistema.HistoricoAluno("Ana Costa")
+	if historico[0].Autor != "prof.ana" || historico[len(historico)-1].Autor != AutorPadrao {
+		t.Errorf("autores na auditoria: %v", historico)
+	}
+
+	rec = requisitar(srv, "DELETE", "/api/alunos/Ana%20Costa", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", rec.Code)
+	}
+	if rec = requisitar(srv, "GET", "/api/alunos/Ana%20Costa", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET após DELETE = %d", rec.Code)
+	}
+}
+
+func TestErros(t *testing.T) {
+	srv, sistema := novoServidorTeste(t)
+	sistema.AdicionarAluno("prof", "Ana", 7)
+
+	tests := []struct {
+		name        string
+		metodo      string
+		caminho     string
+		corpo       string
+		contentType string
+		status      int
+	}{
+		{"JSON malformado", "POST", "/api/alunos", `{"nome":`, "application/json", 400},
+		{"campo desconhecido", "POST", "/api/alunos", `{"nome":"Bia","nota":[7]}`, "application/json", 400},
+		{"tipo errado", "POST", "/api/alunos", `{"nome":"Bia","notas":"7"}`, "application/json", 400},
+		{"dois objetos", "POST", "/api/alunos", `{"nome":"Bia"}{"nome":"Caio"}`, "application/json", 400},
+		{"sem content-type", "POST", "/api/alunos", `{"nome":"Bia"}`, "text/plain", 415},
+		{"nota inválida", "POST", "/api/alunos", `{"nome":"Bia","notas":[11]}`, "application/json", 422},
+		{"frequência inválida", "POST", "/api/alunos", `{"nome":"Bia","frequencia":150}`, "application/json", 422},
+		{"nome vazio", "POST", "/api/alunos", `{"nome":"  "}`, "application/json", 422},
+		{"duplicado", "POST", "/api/alunos", `{"nome":"ana"}`, "application/json", 409},
+		{"aluno inexistente", "GET", "/api/alunos/Zé", "", "", 404},
+		{"nota inexistente", "PUT", "/api/alunos/Ana/notas/5", `{"nota":5}`, "application/json", 404},
+		{"nota zero", "DELETE", "/api/alunos/Ana/notas/0", "", "", 404},
+		{"rota inexistente", "GET", "/api/professores", "", "", 404},
+		{"método não permitido", "PATCH", "/api/alunos/Ana", "", "", 405},
+		{"paginação inválida", "GET", "/api/alunos?por_pagina=1000", "", "", 400},
+		{"página que estoura int", "GET", "/api/alunos?pagina=9223372036854775807&por_pagina=50", "", "", 400},
+		{"corpo grande", "POST", "/api/alunos", `{"nome":"` + strings.Repeat("a", TamanhoMaximoCorpo) + `"}`, "application/json", 413},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.metodo, tt.caminho, strings.NewReader(tt.corpo))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d; esperado %d (%s)", rec.Code, tt.status, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != problema.TipoContent {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var corpo problema.Problema
+			if err := json.NewDecoder(rec.Body).Decode(&corpo); err != nil ||
+				corpo.Status != tt.status || corpo.Titulo == "" || corpo.Detalhe == "" {
+				t.Errorf("corpo de erro inválido: %+v %v", corpo, err)
+			}
+		})
+	}
+
+	// Validação: o campo inválido vai em "erros"
+	rec := requisitar(srv, "POST", "/api/alunos", `{"nome":"Bia","notas":[7,11]}`)
+	var p problema.Problema
+	json.NewDecoder(rec.Body).Decode(&p)
+	if len(p.Erros) != 1 || p.Erros[0].Campo != "notas" || p.Instancia != "/api/alunos" {
+		t.Errorf("problema de validação = %+v", p)
+	}
+
+	rec = requisitar(srv, "PATCH", "/api/alunos/Ana", "")
+	if allow := rec.Header().Get("Allow"); allow != "GET, HEAD, PUT, DELETE, OPTIONS" {
+		t.Errorf("Allow = %q", allow)
+	}
+}
+
+func TestPaginacao(t *testing.T) {
+	srv, sistema := novoServidorTeste(t)
+	for i := 1; i <= 25; i++ {
+		sistema.AdicionarAluno("prof", fmt.Sprintf("Aluno %02d", i), float64(i%11))
+	}
+
+	rec := requisitar(srv, "GET", "/api/alunos?pagina=2&por_pagina=10", "")
+	var p Pagina
+	json.NewDecoder(rec.Body).Decode(&p)
+	if p.Total != 25 || p.TotalPaginas != 3 || len(p.Itens) != 10 || p.Itens[0].Nome != "Aluno 11" {
+		t.Errorf("página 2 = total %d, páginas %d, itens %d, primeiro %v", p.Total, p.TotalPaginas, len(p.Itens), p.Itens)
+	}
+	link := rec.Header().Get("Link")
+	if !strings.Contains(link, `pagina=1&por_pagina=10>; rel="prev"`) || !strings.Contains(link, `pagina=3&por_pagina=10>; rel="next"`) {
+		t.Errorf("Link = %q", link)
+	}
+
+	// Página além do fim: lista vazia, não erro
+	rec = requisitar(srv, "GET", "/api/alunos?pagina=9", "")
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"itens":[]`) {
+		t.Errorf("página 9 = %d %s", rec.Code, rec.Body)
+	}
+
+	// Aprovados: notas 7..10 de i%11
+	rec = requisitar(srv, "GET", "/api/aprovados?por_pagina=100", "")
+	json.NewDecoder(rec.Body).Decode(&p)
+	for _, a := range p.Itens {
+		if !a.Aprovado {
+			t.Errorf("%s na lista de aprovados com média %.1f", a.Nome, a.Media)
+		}
+	}
+	if p.Total != 8 {
+		t.Errorf("aprovados = %d; esperado 8", p.Total)
+	}
+
+	rec = requisitar(srv, "GET", "/api/estatisticas", "")
+	var e Estatisticas
+	json.NewDecoder(rec.Body).Decode(&e)
+	if e.Total != 25 || e.Aprovados != 8 || e.Reprovados != 17 || len(e.Conceitos) != 5 {
+		t.Errorf("estatísticas = %+v", e)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	srv, sistema := novoServidorTeste(t)
+	sistema.AdicionarAluno("prof", "Ana", 7)
+
+	rec := requisitar(srv, "GET", "/api/alunos/Ana", "")
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET sem ETag")
+	}
+	if rec = requisitar(srv, "GET", "/api/alunos/Ana", "", "If-None-Match", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match igual = %d; esperado 304", rec.Code)
+	}
+	if rec = requisitar(srv, "GET", "/api/alunos/Ana", "", "If-None-Match", "W/"+etag); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match fraco = %d; esperado 304 (comparação fraca)", rec.Code)
+	}
+	// If-Match usa comparação forte: o mesmo ETag marcado como fraco não serve
+	if rec = requisitar(srv, "PUT", "/api/alunos/Ana/notas/1", `{"nota":8}`, "If-Match", "W/"+etag); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("If-Match fraco = %d; esperado 412", rec.Code)
+	}
+
+	// Primeira escrita com o ETag lido passa e gera um novo ETag
+	rec = requisitar(srv, "PUT", "/api/alunos/Ana/notas/1", `{"nota":8}`, "If-Match", etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Fatalf("PUT com If-Match = %d, ETag %s", rec.Code, rec.Header().Get("ETag"))
+	}
+	// Segunda escrita com o ETag antigo perde a corrida
+	rec = requisitar(srv, "PUT", "/api/alunos/Ana/notas/1", `{"nota":9}`, "If-Match", etag)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT com ETag antigo = %d; esperado 412", rec.Code)
+	}
+	if a, _ := sistema.BuscarAluno("Ana"); a.Notas[0] != 8 {
+		t.Errorf("nota = %v; a escrita com ETag antigo não deveria aplicar", a.Notas[0])
+	}
+	if rec = requisitar(srv, "DELETE", "/api/alunos/Ana", "", "If-Match", "*"); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE com If-Match * = %d", rec.Code)
+	}
+}
+
+func TestIfMatch_Concorrente(t *testing.T) {
+	srv, sistema := novoServidorTeste(t)
+	sistema.AdicionarAluno("prof", "Ana", 10)
+	etag := requisitar(srv, "GET", "/api/alunos/Ana", "").Header().Get("ETag")
+
+	// Várias requisições com o mesmo ETag: exatamente uma pode vencer
+	// (nenhuma grava 10 de novo, então o ETag sempre muda)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	vencedores := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(nota int) {
+			defer wg.Done()
+			rec := requisitar(srv, "PUT", "/api/alunos/Ana/notas/1", fmt.Sprintf(`{"nota":%d}`, nota%10), "If-Match", etag)
+			if rec.Code == http.StatusOK {
+				mu.Lock()
+				vencedores++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if vencedores != 1 {
+		t.Errorf("%d requisições venceram com o mesmo ETag; esperado 1", vencedores)
+	}
+}
+
+func TestAutenticacao(t *testing.T) {
+	sistema, err := notas.NovoSistemaSeguro(&notas.AuditoriaMemoria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	usuarios := autenticacao.NovosUsuarios()
+	usuarios.Iteracoes = 1000
+	tokens, _ := autenticacao.NovosTokens([]byte("0123456789abcdef0123456789abcdef"), autenticacao.OpcoesTokens{})
+	auth := autenticacao.NovoServico(usuarios, tokens)
+	srv := NovoServidor(sistema, Opcoes{
+		Escrita: []roteador.Middleware{auth.Exigir()},
+		Leitura: []roteador.Middleware{auth.Identificar()},
+	})
+	auth.Rotas(srv.Grupo("/api/auth"))
+
+	usuarios.Registrar("prof.ana", "ana@escola.br", "senha-secreta")
+	rec := requisitar(srv, "POST", "/api/auth/entrar", `{"username":"prof.ana","senha":"senha-secreta"}`)
+	var par autenticacao.Par
+	json.NewDecoder(rec.Body).Decode(&par)
+
+	// Escrita sem token: 401; leitura continua aberta
+	corpo := `{"nome":"Bia","notas":[8]}`
+	if rec := requisitar(srv, "POST", "/api/alunos", corpo, "X-Autor", "diretor"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("POST sem token = %d", rec.Code)
+	}
+	if rec := requisitar(srv, "GET", "/api/alunos", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET sem token = %d", rec.Code)
+	}
+
+	// Com token, a auditoria registra o usuário e ignora X-Autor
+	rec = requisitar(srv, "POST", "/api/alunos", corpo, "Authorization", "Bearer "+par.Acesso, "X-Autor", "diretor")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST com token = %d %s", rec.Code, rec.Body)
+	}
+	historico, _ := sistema.HistoricoAluno("Bia")
+	if len(historico) == 0 || historico[0].Autor != "prof.ana" {
+		t.Errorf("auditoria = %v; esperado autor prof.ana", historico)
+	}
+}
+
+func TestAutenticacao_LeituraComPolitica(t *testing.T) {
+	// Sem "anonimo" na política, só quem manda token lê: o usuário tem
+	// de chegar ao context também nos GETs
+	sistema, err := notas.NovoSistemaSeguro(&notas.AuditoriaMemoria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	politica, err := autorizacao.LerPolitica(strings.NewReader(`{
+	  "papeis": {"leitor": {"permissoes": [{"acoes": ["ler"], "recursos": ["*"]}]}},
+	  "padrao": ["leitor"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	usuarios := autenticacao.NovosUsuarios()
+	usuarios.Iteracoes = 1000
+	tokens, _ := autenticacao.NovosTokens([]byte("0123456789abcdef0123456789abcdef"), autenticacao.OpcoesTokens{})
+	auth := autenticacao.NovoServico(usuarios, tokens)
+	srv := NovoServidor(sistema, Opcoes{
+		Escrita: []roteador.Middleware{auth.Exigir()},
+		Leitura: []roteador.Middleware{auth.Identificar()},
+	})
+	srv.UsarPolitica(politica)
+	usuarios.Registrar("bia", "bia@escola.br", "senha-secreta")
+	par, err := tokens.Emitir("bia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testes := []struct {
+		nome, autorizacao string
+		status            int
+	}{
+		{"sem token", "", http.StatusUnauthorized},
+		{"token inválido", "Bearer abc.def.ghi", http.StatusUnauthorized},
+		{"com token", "Bearer " + par.Acesso, http.StatusOK},
+	}
+	for _, tt := range testes {
+		for _, caminho := range []string{"/api/alunos", "/api/estatisticas"} {
+			rec := requisitar(srv, "GET", caminho, "", "Authorization", tt.autorizacao)
+			if rec.Code != tt.status {
+				t.Errorf("%s: GET %s = %d; esperado %d (%s)", tt.nome, caminho, rec.Code, tt.status, rec.Body)
+			}
+		}
+	}
+}
+
+func TestAutenticacao_AutoCadastroSemPapel(t *testing.T) {
+	// A política do exemplo dá coordenador a "carla"; quem se cadastra
+	// com esse nome pela rota aberta continua só leitor
+	politica, err := autorizacao.LerArquivoPolitica("../../../modulo12-http/politica_notas.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sistema, err := notas.NovoSistemaSeguro(&notas.AuditoriaMemoria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	usuarios := autenticacao.NovosUsuarios()
+	usuarios.Iteracoes = 1000
+	tokens, _ := autenticacao.NovosTokens([]byte("0123456789abcdef0123456789abcdef"), autenticacao.OpcoesTokens{})
+	auth := autenticacao.NovoServico(usuarios, tokens)
+	srv := NovoServidor(sistema, Opcoes{
+		Escrita: []roteador.Middleware{auth.Exigir()},
+		Leitura: []roteador.Middleware{auth.Identificar()},
+	})
+	srv.UsarPolitica(politica)
+	auth.Rotas(srv.Grupo("/api/auth"))
+	sistema.Cadastrar("prof.ana", notas.Aluno{Nome: "Bia", Notas: []float64{5}})
+
+	rec := requisitar(srv, "POST", "/api/auth/registrar", `{"username":"carla","email":"carla@x.com","senha":"senha-secreta"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("registrar = %d %s", rec.Code, rec.Body)
+	}
+	rec = requisitar(srv, "POST", "/api/auth/entrar", `{"username":"carla","senha":"senha-secreta"}`)
+	var par autenticacao.Par
+	json.NewDecoder(rec.Body).Decode(&par)
+	bearer := "Bearer " + par.Acesso
+
+	if rec := requisitar(srv, "GET", "/api/alunos", "", "Authorization", bearer); rec.Code != http.StatusOK {
+		t.Errorf("leitura = %d", rec.Code)
+	}
+	if rec := requisitar(srv, "PUT", "/api/alunos/Bia/notas/1", `{"nota":10}`, "Authorization", bearer); rec.Code != http.StatusForbidden {
+		t.Errorf("carla autocadastrada alterando nota = %d; esperado 403", rec.Code)
+	}
+	if rec := requisitar(srv, "POST", "/api/alunos", `{"nome":"Caio"}`, "Authorization", bearer); rec.Code != http.StatusForbidden {
+		t.Errorf("carla autocadastrada criando aluno = %d; esperado 403", rec.Code)
+	}
+}
+
+func TestAutorizacao(t *testing.T) {
+	sistema, err := notas.NovoSistemaSeguro(&notas.AuditoriaMemoria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	politica, err := autorizacao.LerPolitica(strings.NewReader(`{
+	  "papeis": {
+	    "leitor":    {"permissoes": [{"acoes": ["ler"], "recursos": ["*"]}]},
+	    "professor": {"herda": ["leitor"], "permissoes": [
+	                   {"acoes": ["criar"], "recursos": ["aluno"]},
+	                   {"acoes": ["*"], "recursos": ["aluno", "nota"], "somente_dono": true}]},
+	    "admin":     {"permissoes": [{"acoes": ["*"], "recursos": ["*"]}]}
+	  },
+	  "anonimo": ["leitor"],
+	  "padrao": ["leitor"],
+	  "papel_admin": "admin",
+	  "usuarios": {"prof.ana": ["professor"], "prof.rui": ["professor"]}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NovoServidor(sistema, Opcoes{})
+	srv.UsarPolitica(politica)
+
+	// Sem autenticacao.Exigir na frente, o usuário vem direto no context
+	como := func(username string, admin bool, metodo, caminho, corpo string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(metodo, caminho, strings.NewReader(corpo))
+		if corpo != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if username != "" {
+			req = req.WithContext(autenticacao.ComUsuario(req.Context(), autenticacao.Usuario{Username: username, Admin: admin}))
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testes := []struct {
+		nome, usuario          string
+		admin                  bool
+		metodo, caminho, corpo string
+		status                 int
+	}{
+		{"anônimo lê", "", false, "GET", "/api/alunos", "", http.StatusOK},
+		{"anônimo não cria", "", false, "POST", "/api/alunos", `{"nome":"Bia"}`, http.StatusUnauthorized},
+		{"aluno comum não cria", "bia", false, "POST", "/api/alunos", `{"nome":"Bia"}`, http.StatusForbidden},
+		{"professor cria", "prof.ana", false, "POST", "/api/alunos", `{"nome":"Bia","notas":[7]}`, http.StatusCreated},
+		{"outro professor não altera", "prof.rui", false, "POST", "/api/alunos/Bia/notas", `{"notas":[10]}`, http.StatusForbidden},
+		{"dono altera", "prof.ana", false, "PUT", "/api/alunos/bia/notas/1", `{"nota":8}`, http.StatusOK},
+		{"inexistente é 404, não 403", "prof.rui", false, "DELETE", "/api/alunos/Caio", "", http.StatusNotFound},
+		{"outro professor não remove", "prof.rui", false, "DELETE", "/api/alunos/Bia", "", http.StatusForbidden},
+		{"admin remove", "diretor", true, "DELETE", "/api/alunos/Bia", "", http.StatusNoContent},
+		{"outro professor recria", "prof.rui", false, "POST", "/api/alunos", `{"nome":"Bia","notas":[5]}`, http.StatusCreated},
+		{"novo dono altera", "prof.rui", false, "PUT", "/api/alunos/Bia/notas/1", `{"nota":6}`, http.StatusOK},
+		{"antigo dono não altera", "prof.ana", false, "PUT", "/api/alunos/Bia/notas/1", `{"nota":9}`, http.StatusForbidden},
+		{"inexistente sem poder criar é 403", "bia", false, "DELETE", "/api/alunos/Caio", "", http.StatusForbidden},
+	}
+	for _, tt := range testes {
+		rec := como(tt.usuario, tt.admin, tt.metodo, tt.caminho, tt.corpo)
+		if rec.Code != tt.status {
+			t.Errorf("%s: %s %s = %d; esperado %d (%s)", tt.nome, tt.metodo, tt.caminho, rec.Code, tt.status, rec.Body)
+		}
+		if tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: 401 sem WWW-Authenticate", tt.nome)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	sistema, err := notas.NovoSistemaSeguro(&notas.AuditoriaMemoria{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, _ := autenticacao.NovosTokens([]byte("0123456789abcdef0123456789abcdef"), autenticacao.OpcoesTokens{})
+	auth := autenticacao.NovoServico(autenticacao.NovosUsuarios(), tokens)
+	srv := NovoServidor(sistema, Opcoes{})
+	auth.Rotas(srv.Grupo("/api/auth"))
+	srv.Grupo("").Get("/openapi.json", srv.OpenAPI(openapi.Info{Titulo: "API de notas", Versao: "1.0"}).ServeHTTP)
+
+	rec := requisitar(srv, "GET", "/openapi.json", "")
+	var doc openapi.Documento
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("%d: %v", rec.Code, err)
+	}
+	testes := []struct {
+		caminho, metodo string
+		resumo          bool
+		corpo           bool
+		status          string
+	}{
+		{"/api/alunos", "get", true, false, "200"},
+		{"/api/alunos", "post", true, true, "201"},
+		{"/api/alunos/{nome}", "delete", true, false, "204"},
+		{"/api/alunos/{nome}/notas/{n}", "put", true, true, "200"},
+		{"/api/auth/registrar", "post", true, true, "201"},
+		{"/api/auth/eu", "get", true, false, "200"},
+		{"/openapi.json", "get", false, false, "200"},
+	}
+	for _, tt := range testes {
+		op := doc.Caminhos[tt.caminho][tt.metodo]
+		if op == nil {
+			t.Errorf("%s %s não documentada", tt.metodo, tt.caminho)
+			continue
+		}
+		if (op.Resumo != "") != tt.resumo || (op.Corpo != nil) != tt.corpo || op.Respostas[tt.status] == nil {
+			t.Errorf("%s %s: resumo %q, corpo %v, respostas %v", tt.metodo, tt.caminho, op.Resumo, op.Corpo != nil, op.Respostas)
+		}
+	}
+	for _, nome := range []string{"AlunoJSON", "Pagina", "Estatisticas", "Usuario", "Par"} {
+		if doc.Componentes.Esquemas[nome] == nil {
+			t.Errorf("esquema %s ausente", nome)
+		}
+	}
+}
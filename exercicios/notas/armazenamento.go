@@ -0,0 +1,232 @@
+package notas
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/*
+ARMAZENAMENTO EM DISCO
+
+Salva e carrega o SistemaNotas entre execuções.
+
+FORMATOS:
+- JSON (padrão): {"alunos":[{"nome":"Ana","notas":[7,8.5]}]}
+- CSV: uma linha por aluno → nome,frequencia,recuperacao,nota1,nota2,...
+
+ESCRITA ATÔMICA:
+O arquivo é escrito em um temporário no mesmo diretório e depois
+renomeado. Se o programa cair no meio da escrita, o arquivo antigo
+continua intacto (os.Rename é atômico no mesmo sistema de arquivos).
+*/
+
+// Armazenamento persiste um SistemaNotas
+type Armazenamento interface {
+	Carregar() (*SistemaNotas, error)
+	Salvar(s *SistemaNotas) error
+}
+
+// ArquivoJSON guarda o sistema em um arquivo JSON
+type ArquivoJSON struct {
+	Caminho string
+}
+
+// ArquivoCSV guarda o sistema em um arquivo CSV
+type ArquivoCSV struct {
+	Caminho string
+}
+
+// NovoArmazenamento escolhe o formato pela extensão (.csv ou JSON)
+func NovoArmazenamento(caminho string) Armazenamento {
+	if strings.EqualFold(filepath.Ext(caminho), ".csv") {
+		return ArquivoCSV{Caminho: caminho}
+	}
+	return ArquivoJSON{Caminho: caminho}
+}
+
+// Carregar lê o arquivo JSON; se ele não existir, retorna um sistema vazio
+func (a ArquivoJSON) Carregar() (*SistemaNotas, error) {
+	arquivo, err := os.Open(a.Caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return &SistemaNotas{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("carregar %s: %w", a.Caminho, err)
+	}
+	defer arquivo.Close()
+
+	var s SistemaNotas
+	if err := json.NewDecoder(arquivo).Decode(&s); err != nil {
+		return nil, fmt.Errorf("carregar %s: %w", a.Caminho, err)
+	}
+	return &s, nil
+}
+
+// Salvar grava o sistema em JSON de forma atômica
+func (a ArquivoJSON) Salvar(s *SistemaNotas) error {
+	return escreverAtomico(a.Caminho, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	})
+}
+
+// Carregar lê o arquivo CSV; se ele não existir, retorna um sistema vazio
+func (a ArquivoCSV) Carregar() (*SistemaNotas, error) {
+	arquivo, err := os.Open(a.Caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return &SistemaNotas{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("carregar %s: %w", a.Caminho, err)
+	}
+	defer arquivo.Close()
+
+	r := csv.NewReader(bufio.NewReader(arquivo))
+	r.FieldsPerRecord = -1 // cada aluno pode ter um número diferente de notas
+
+	s := &SistemaNotas{}
+	// Sem cabeçalho, a coluna 0 é o nome e as demais são notas
+	colFrequencia, colRecuperacao := -1, -1
+	for linha := 1; ; linha++ {
+		registro, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("carregar %s: %w", a.Caminho, err)
+		}
+		if linha == 1 && strings.EqualFold(registro[0], "nome") {
+			for i, coluna := range registro {
+				switch strings.ToLower(coluna) {
+				case "frequencia":
+					colFrequencia = i
+				case "recuperacao":
+					colRecuperacao = i
+				}
+			}
+			continue
+		}
+
+		aluno := Aluno{Nome: registro[0]}
+		for i, campo := range registro[1:] {
+			if campo == "" {
+				continue
+			}
+			valor, err := strconv.ParseFloat(campo, 64)
+			if err != nil {
+				return nil, fmt.Errorf("carregar %s (linha %d): %w", a.Caminho, linha, err)
+			}
+			switch i + 1 {
+			case colFrequencia:
+				aluno.Frequencia = &valor
+			case colRecuperacao:
+				aluno.Recuperacao = &valor
+			default:
+				aluno.Notas = append(aluno.Notas, valor)
+			}
+		}
+		if err := s.AdicionarAluno(aluno.Nome, aluno.Notas...); err != nil {
+			return nil, fmt.Errorf("carregar %s (linha %d): %w", a.Caminho, linha, err)
+		}
+		if aluno.Frequencia != nil {
+			if err := s.DefinirFrequencia(aluno.Nome, *aluno.Frequencia); err != nil {
+				return nil, fmt.Errorf("carregar %s (linha %d): %w", a.Caminho, linha, err)
+			}
+		}
+		if aluno.Recuperacao != nil {
+			if err := s.DefinirRecuperacao(aluno.Nome, *aluno.Recuperacao); err != nil {
+				return nil, fmt.Errorf("carregar %s (linha %d): %w", a.Caminho, linha, err)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Salvar grava o sistema em CSV de forma atômica:
+// nome,frequencia,recuperacao,nota1,nota2,...
+func (a ArquivoCSV) Salvar(s *SistemaNotas) error {
+	return escreverAtomico(a.Caminho, func(w io.Writer) error {
+		maxNotas := 0
+		for _, aluno := range s.Alunos {
+			if len(aluno.Notas) > maxNotas {
+				maxNotas = len(aluno.Notas)
+			}
+		}
+
+		cw := csv.NewWriter(w)
+		cabecalho := []string{"nome", "frequencia", "recuperacao"}
+		for i := 1; i <= maxNotas; i++ {
+			cabecalho = append(cabecalho, fmt.Sprintf("nota%d", i))
+		}
+		cw.Write(cabecalho)
+
+		for _, aluno := range s.Alunos {
+			registro := []string{aluno.Nome, formatarOpcional(aluno.Frequencia), formatarOpcional(aluno.Recuperacao)}
+			for _, nota := range aluno.Notas {
+				registro = append(registro, strconv.FormatFloat(nota, 'f', -1, 64))
+			}
+			cw.Write(registro)
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+func formatarOpcional(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// escreverAtomico escreve em um arquivo temporário e o renomeia no final
+func escreverAtomico(caminho string, escrever func(io.Writer) error) (err error) {
+	dir := filepath.Dir(caminho)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(caminho)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("salvar %s: %w", caminho, err)
+	}
+	// Em caso de erro, apagar o temporário e manter o arquivo original
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(tmp)
+	if err = escrever(w); err != nil {
+		return fmt.Errorf("salvar %s: %w", caminho, err)
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("salvar %s: %w", caminho, err)
+	}
+	// Garantir que os dados chegaram ao disco antes do rename
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("salvar %s: %w", caminho, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("salvar %s: %w", caminho, err)
+	}
+	// CreateTemp usa permissão 0600; manter a do arquivo original se existir
+	modo := os.FileMode(0o644)
+	if info, errStat := os.Stat(caminho); errStat == nil {
+		modo = info.Mode().Perm()
+	}
+	if err = os.Chmod(tmp.Name(), modo); err != nil {
+		return fmt.Errorf("salvar %s: %w", caminho, err)
+	}
+	if err = os.Rename(tmp.Name(), caminho); err != nil {
+		return fmt.Errorf("salvar %s: %w", caminho, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package notas
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func sistemaExemplo(t *testing.T) *SistemaNotas {
+	t.Helper()
+	s := &SistemaNotas{}
+	if err := s.AdicionarAluno("Ana Costa", 7, 8.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AdicionarAluno("Pedro, o \"Grande\"", 5.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AdicionarAluno("Maria"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DefinirFrequencia("Ana Costa", 87.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DefinirRecuperacao("Pedro, o \"Grande\"", 7); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestArmazenamento_IdaEVolta(t *testing.T) {
+	for _, nome := range []string{"turma.json", "turma.csv"} {
+		t.Run(nome, func(t *testing.T) {
+			caminho := filepath.Join(t.TempDir(), nome)
+			a := NovoArmazenamento(caminho)
+			original := sistemaExemplo(t)
+
+			if err := a.Salvar(original); err != nil {
+				t.Fatalf("Salvar: %v", err)
+			}
+			carregado, err := a.Carregar()
+			if err != nil {
+				t.Fatalf("Carregar: %v", err)
+			}
+
+			if len(carregado.Alunos) != len(original.Alunos) {
+				t.Fatalf("carregados %d alunos; esperado %d", len(carregado.Alunos), len(original.Alunos))
+			}
+			for i := range original.Alunos {
+				o, c := original.Alunos[i], carregado.Alunos[i]
+				if o.Nome != c.Nome || len(o.Notas) != len(c.Notas) ||
+					(len(o.Notas) > 0 && !reflect.DeepEqual(o.Notas, c.Notas)) {
+					t.Errorf("aluno %d = %+v; esperado %+v", i, c, o)
+				}
+				if !reflect.DeepEqual(o.Frequencia, c.Frequencia) || !reflect.DeepEqual(o.Recuperacao, c.Recuperacao) {
+					t.Errorf("aluno %d: frequência/recuperação não preservadas", i)
+				}
+			}
+		})
+	}
+}
+
+func TestArmazenamento_ArquivoInexistente(t *testing.T) {
+	s, err := ArquivoJSON{Caminho: filepath.Join(t.TempDir(), "nao-existe.json")}.Carregar()
+	if err != nil {
+		t.Fatalf("Carregar: %v", err)
+	}
+	if len(s.Alunos) != 0 {
+		t.Errorf("esperado sistema vazio, obtido %d alunos", len(s.Alunos))
+	}
+}
+
+func TestArmazenamento_FalhaPreservaOriginal(t *testing.T) {
+	dir := t.TempDir()
+	caminho := filepath.Join(dir, "turma.json")
+	if err := os.WriteFile(caminho, []byte(`{"alunos":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	falha := errors.New("disco cheio")
+	err := escreverAtomico(caminho, func(w io.Writer) error {
+		w.Write([]byte("lixo"))
+		return falha
+	})
+	if !errors.Is(err, falha) {
+		t.Fatalf("erro = %v; esperado %v", err, falha)
+	}
+
+	dados, _ := os.ReadFile(caminho)
+	if string(dados) != `{"alunos":[]}` {
+		t.Errorf("arquivo original alterado: %s", dados)
+	}
+	entradas, _ := os.ReadDir(dir)
+	if len(entradas) != 1 {
+		t.Errorf("temporário não removido: %d arquivos no diretório", len(entradas))
+	}
+}
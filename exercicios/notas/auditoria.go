@@ -0,0 +1,363 @@
+package notas
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+LOG DE AUDITORIA
+
+Cada alteração do SistemaSeguro vira um RegistroAuditoria, gravado
+ANTES de ser aplicado (write-ahead) em um log só de acréscimo:
+
+    [{"seq":1,"quando":"2024-03-01T10:00:00Z","autor":"prof.ana","acao":"cadastro","aluno":"Bruno"},
+     {"seq":2,"quando":"2024-03-01T10:00:00Z","autor":"prof.ana","acao":"nota","aluno":"Bruno","indice":0,"novo":7}]
+    {"seq":3,"quando":"2024-03-08T09:12:44Z","autor":"coord","acao":"nota","aluno":"Bruno","indice":0,"anterior":7,"novo":7.5}
+
+Como o log descreve todas as mudanças desde o sistema vazio,
+reaplicar os registros até um instante reconstrói o estado daquele
+momento (event sourcing).
+
+IMPLEMENTAÇÕES:
+- AuditoriaMemoria: para testes e servidores sem persistência
+- AuditoriaArquivo: JSON Lines com O_APPEND e fsync a cada gravação
+
+Os registros de uma mesma alteração (cadastro + notas iniciais) vão
+numa linha só, como array JSON. Se o processo cair no meio da escrita,
+sobra uma última linha sem \n: AbrirAuditoria a corta (a alteração
+nunca foi confirmada) para a próxima gravação não grudar nela, e uma
+gravação que falha desfaz o que escreveu. Uma linha inválida no meio do
+arquivo continua sendo erro.
+*/
+
+// Ações registradas na auditoria
+const (
+	AcaoCadastro    = "cadastro"    // aluno criado (sem notas)
+	AcaoNota        = "nota"        // nota incluída, alterada ou removida
+	AcaoFrequencia  = "frequencia"  // frequência definida ou apagada
+	AcaoRecuperacao = "recuperacao" // recuperação definida ou apagada
+	AcaoRemocao     = "remocao"     // aluno removido
+)
+
+// ErrAuditoriaInconsistente indica um registro que não pode ser reaplicado
+var ErrAuditoriaInconsistente = errors.New("log de auditoria inconsistente")
+
+// RegistroAuditoria é uma alteração: quem, quando, valor anterior e novo.
+// Anterior nil = valor não existia; Novo nil = valor apagado.
+type RegistroAuditoria struct {
+	Seq      int64     `json:"seq"`
+	Quando   time.Time `json:"quando"`
+	Autor    string    `json:"autor"`
+	Acao     string    `json:"acao"`
+	Aluno    string    `json:"aluno"`
+	Indice   int       `json:"indice,omitempty"` // posição da nota (AcaoNota)
+	Anterior *float64  `json:"anterior,omitempty"`
+	Novo     *float64  `json:"novo,omitempty"`
+}
+
+// String descreve o registro em uma linha
+func (r RegistroAuditoria) String() string {
+	quando := r.Quando.Format("2006-01-02 15:04:05")
+	switch r.Acao {
+	case AcaoNota:
+		return fmt.Sprintf("#%d %s %s: nota %d de %s %s → %s",
+			r.Seq, quando, r.Autor, r.Indice+1, r.Aluno, valorAuditoria(r.Anterior), valorAuditoria(r.Novo))
+	case AcaoFrequencia, AcaoRecuperacao:
+		return fmt.Sprintf("#%d %s %s: %s de %s %s → %s",
+			r.Seq, quando, r.Autor, r.Acao, r.Aluno, valorAuditoria(r.Anterior), valorAuditoria(r.Novo))
+	default:
+		return fmt.Sprintf("#%d %s %s: %s de %s", r.Seq, quando, r.Autor, r.Acao, r.Aluno)
+	}
+}
+
+func valorAuditoria(v *float64) string {
+	if v == nil {
+		return "(vazio)"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+// Auditoria é um log de alterações só de acréscimo
+type Auditoria interface {
+	// Registrar grava os registros juntos: ou todos ou nenhum
+	Registrar(registros ...RegistroAuditoria) error
+	// Registros retorna o log completo, na ordem de gravação
+	Registros() ([]RegistroAuditoria, error)
+}
+
+// AuditoriaMemoria guarda o log em memória
+type AuditoriaMemoria struct {
+	mu        sync.RWMutex
+	registros []RegistroAuditoria
+}
+
+// Registrar implementa Auditoria
+func (a *AuditoriaMemoria) Registrar(registros ...RegistroAuditoria) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, r := range registros {
+		a.registros = append(a.registros, copiarRegistro(r))
+	}
+	return nil
+}
+
+// Registros implementa Auditoria (retorna uma cópia)
+func (a *AuditoriaMemoria) Registros() ([]RegistroAuditoria, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	copia := make([]RegistroAuditoria, len(a.registros))
+	for i, r := range a.registros {
+		copia[i] = copiarRegistro(r)
+	}
+	return copia, nil
+}
+
+// AuditoriaArquivo grava o log em um arquivo JSON Lines. O arquivo tem
+// um único escritor (este processo): a abertura e as gravações que
+// falham cortam o fim do arquivo, o que perderia dados de outro escritor.
+type AuditoriaArquivo struct {
+	mu      sync.Mutex
+	caminho string
+	arquivo *os.File
+	falha   error // corte que falhou: o fim do arquivo não é confiável
+}
+
+// AbrirAuditoria abre (ou cria) um log de auditoria em arquivo e corta
+// a linha incompleta deixada por uma queda no meio de uma gravação
+func AbrirAuditoria(caminho string) (*AuditoriaArquivo, error) {
+	// O_APPEND: toda escrita vai para o fim do arquivo
+	arquivo, err := os.OpenFile(caminho, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("abrir auditoria %s: %w", caminho, err)
+	}
+	a := &AuditoriaArquivo{caminho: caminho, arquivo: arquivo}
+	if err := a.repararFim(); err != nil {
+		arquivo.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// repararFim corta o que vier depois do último \n: é uma gravação
+// interrompida, que nunca foi confirmada a quem a pediu
+func (a *AuditoriaArquivo) repararFim() error {
+	info, err := a.arquivo.Stat()
+	if err != nil {
+		return fmt.Errorf("abrir auditoria %s: %w", a.caminho, err)
+	}
+	fim := info.Size()
+	bloco := make([]byte, 4096)
+	for inicio := fim; inicio > 0; {
+		n := int64(len(bloco))
+		if inicio < n {
+			n = inicio
+		}
+		inicio -= n
+		if _, err := a.arquivo.ReadAt(bloco[:n], inicio); err != nil {
+			return fmt.Errorf("abrir auditoria %s: %w", a.caminho, err)
+		}
+		if i := bytes.LastIndexByte(bloco[:n], '\n'); i >= 0 {
+			return a.cortarIncompleta(inicio+int64(i)+1, fim)
+		}
+	}
+	return a.cortarIncompleta(0, fim)
+}
+
+// cortarIncompleta descarta a linha incompleta entre tamanho e fim, se houver
+func (a *AuditoriaArquivo) cortarIncompleta(tamanho, fim int64) error {
+	if tamanho == fim {
+		return nil
+	}
+	log.Printf("auditoria %s: %d bytes sem \\n no fim descartados: escrita interrompida", a.caminho, fim-tamanho)
+	return a.cortar(tamanho)
+}
+
+// cortar volta o arquivo a tamanho bytes; chamar com a.mu travado ou
+// antes de a auditoria ser usada
+func (a *AuditoriaArquivo) cortar(tamanho int64) error {
+	if err := a.arquivo.Truncate(tamanho); err != nil {
+		return fmt.Errorf("cortar auditoria %s: %w", a.caminho, err)
+	}
+	if err := a.arquivo.Sync(); err != nil {
+		return fmt.Errorf("cortar auditoria %s: %w", a.caminho, err)
+	}
+	return nil
+}
+
+// Registrar implementa Auditoria: uma única escrita seguida de fsync.
+// Vários registros vão numa linha só (array), para que uma escrita
+// interrompida perca a alteração inteira e não só parte dela. Se a
+// escrita ou o fsync falham, o arquivo volta ao tamanho anterior: a
+// alteração recusada não fica no log nem gruda na próxima linha. Se
+// nem o corte funciona, as gravações seguintes são recusadas.
+func (a *AuditoriaArquivo) Registrar(registros ...RegistroAuditoria) error {
+	if len(registros) == 0 {
+		return nil
+	}
+	var linha []byte
+	var err error
+	if len(registros) == 1 {
+		linha, err = json.Marshal(registros[0])
+	} else {
+		linha, err = json.Marshal(registros)
+	}
+	if err != nil {
+		return fmt.Errorf("registrar auditoria: %w", err)
+	}
+	linha = append(linha, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.falha != nil {
+		return fmt.Errorf("registrar auditoria em %s: %w", a.caminho, a.falha)
+	}
+	info, err := a.arquivo.Stat()
+	if err != nil {
+		return fmt.Errorf("registrar auditoria em %s: %w", a.caminho, err)
+	}
+	if _, err = a.arquivo.Write(linha); err == nil {
+		err = a.arquivo.Sync()
+	}
+	if err != nil {
+		if errCorte := a.cortar(info.Size()); errCorte != nil {
+			a.falha = errCorte
+			err = errors.Join(err, errCorte)
+		}
+		return fmt.Errorf("registrar auditoria em %s: %w", a.caminho, err)
+	}
+	return nil
+}
+
+// Registros implementa Auditoria lendo o arquivo do início.
+// Uma linha inválida vira *erros.ErroProcessamento com o número da
+// linha; a última, se não terminar em \n, é uma gravação em andamento
+// e fica de fora (o arquivo não é alterado).
+func (a *AuditoriaArquivo) Registros() ([]RegistroAuditoria, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	arquivo, err := os.Open(a.caminho)
+	if err != nil {
+		return nil, fmt.Errorf("ler auditoria: %w", err)
+	}
+	defer arquivo.Close()
+
+	var registros []RegistroAuditoria
+	leitor := bufio.NewReader(arquivo)
+	for linha := 1; ; linha++ {
+		dados, err := leitor.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ler auditoria %s: %w", a.caminho, err)
+		}
+		if len(bytes.TrimSpace(dados)) == 0 {
+			continue
+		}
+		lidos, err := decodificarLinha(dados)
+		if err != nil {
+			return nil, &erros.ErroProcessamento{Arquivo: a.caminho, Linha: linha, Erro: err}
+		}
+		registros = append(registros, lidos...)
+	}
+	return registros, nil
+}
+
+// decodificarLinha lê um registro ou um array de registros (alteração
+// com vários registros)
+func decodificarLinha(dados []byte) ([]RegistroAuditoria, error) {
+	if dados = bytes.TrimSpace(dados); dados[0] == '[' {
+		var registros []RegistroAuditoria
+		if err := json.Unmarshal(dados, &registros); err != nil {
+			return nil, err
+		}
+		return registros, nil
+	}
+	var r RegistroAuditoria
+	if err := json.Unmarshal(dados, &r); err != nil {
+		return nil, err
+	}
+	return []RegistroAuditoria{r}, nil
+}
+
+// Close fecha o arquivo do log
+func (a *AuditoriaArquivo) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.arquivo.Close()
+}
+
+// aplicarRegistro reproduz um registro no sistema. É usado tanto pelas
+// alterações ao vivo quanto pela reconstrução, então os dois caminhos
+// nunca divergem.
+func aplicarRegistro(s *SistemaNotas, r RegistroAuditoria) error {
+	inconsistente := func(motivo string) error {
+		return fmt.Errorf("%w: registro #%d (%s de %q): %s", ErrAuditoriaInconsistente, r.Seq, r.Acao, r.Aluno, motivo)
+	}
+
+	if r.Acao == AcaoCadastro {
+		if err := s.AdicionarAluno(r.Aluno); err != nil {
+			return inconsistente(err.Error())
+		}
+		return nil
+	}
+
+	i := s.indice(r.Aluno)
+	if i < 0 {
+		return inconsistente("aluno não cadastrado")
+	}
+	aluno := &s.Alunos[i]
+
+	switch r.Acao {
+	case AcaoNota:
+		switch {
+		case r.Indice < 0 || r.Indice > len(aluno.Notas):
+			return inconsistente(fmt.Sprintf("nota %d inexistente", r.Indice+1))
+		case r.Anterior == nil && r.Novo != nil: // inclusão no fim
+			if r.Indice != len(aluno.Notas) {
+				return inconsistente("inclusão fora do fim da lista")
+			}
+			aluno.Notas = append(aluno.Notas, *r.Novo)
+		case r.Indice == len(aluno.Notas):
+			return inconsistente(fmt.Sprintf("nota %d inexistente", r.Indice+1))
+		case r.Novo == nil: // remoção
+			aluno.Notas = append(aluno.Notas[:r.Indice], aluno.Notas[r.Indice+1:]...)
+		default: // alteração
+			aluno.Notas[r.Indice] = *r.Novo
+		}
+	case AcaoFrequencia:
+		aluno.Frequencia = copiarValor(r.Novo)
+	case AcaoRecuperacao:
+		aluno.Recuperacao = copiarValor(r.Novo)
+	case AcaoRemocao:
+		s.Alunos = append(s.Alunos[:i], s.Alunos[i+1:]...)
+	default:
+		return inconsistente("ação desconhecida")
+	}
+	return nil
+}
+
+func copiarRegistro(r RegistroAuditoria) RegistroAuditoria {
+	r.Anterior = copiarValor(r.Anterior)
+	r.Novo = copiarValor(r.Novo)
+	return r
+}
+
+func copiarValor(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,344 @@
+package notas
+
+import (
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go-course/modulo08-packages/locale"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+/*
+BOLETINS EM HTML
+
+Gera um boletim imprimível por aluno e uma página de resumo da turma.
+Cada arquivo é autocontido: o CSS vai dentro de <style> e o logo
+vira uma data URI, então o HTML pode ser enviado por e-mail ou impresso
+sem depender de outros arquivos.
+
+MODELOS (embutidos com embed, em modelos/):
+- cabecalho.html  define "cabecalho" e "rodape"
+- boletim.html    página de um aluno
+- turma.html      resumo da turma
+- estilo.css      CSS padrão
+
+PERSONALIZAÇÃO:
+Com OpcoesBoletim.DirModelos, qualquer arquivo .html do diretório
+substitui o modelo embutido de mesmo nome (o caso comum é trocar
+só o cabecalho.html), estilo.css é acrescentado ao CSS padrão e
+logo.png/logo.jpg/logo.svg vira o logo da escola.
+
+LOTE:
+    g, _ := notas.NovoGeradorBoletim(notas.OpcoesBoletim{
+        Cabecalho: notas.Cabecalho{Escola: "Escola Estadual Go", Periodo: "2024.1"},
+    })
+    arquivos, err := g.GerarLote(sistema, "boletins/")
+
+Os boletins são gerados em paralelo por um pool de workers
+(veja modulo05-goroutines/05_padroes_concorrencia.go).
+*/
+
+//go:embed modelos
+var modelosEmbutidos embed.FS
+
+// Cabecalho identifica a escola no topo de cada página
+type Cabecalho struct {
+	Escola    string
+	Subtitulo string // ex.: "Ensino Médio - 3º ano B"
+	Periodo   string
+}
+
+// OpcoesBoletim configura o GeradorBoletim.
+// O valor zero usa os modelos embutidos e um worker por CPU.
+type OpcoesBoletim struct {
+	Cabecalho     Cabecalho
+	DirModelos    string    // modelos personalizados (opcional)
+	Trabalhadores int       // boletins gerados em paralelo (0 = runtime.NumCPU())
+	Data          time.Time // data impressa no rodapé (zero = agora)
+}
+
+// GeradorBoletim gera boletins e resumos de turma em HTML.
+// Pode ser usado por várias goroutines ao mesmo tempo.
+type GeradorBoletim struct {
+	modelos       *template.Template
+	css           template.CSS
+	logo          template.URL
+	cabecalho     Cabecalho
+	trabalhadores int
+	data          time.Time
+}
+
+// dadosPagina é o que os modelos recebem
+type dadosPagina struct {
+	Cabecalho Cabecalho
+	CSS       template.CSS
+	Logo      template.URL
+	Gerado    string
+
+	// Boletim
+	Aluno       Aluno
+	Resultado   Resultado
+	Conceito    string
+	Posicao     PosicaoRanking
+	TotalAlunos int
+
+	// Resumo da turma
+	Relatorio Relatorio
+}
+
+// NovoGeradorBoletim carrega os modelos embutidos e, se houver, os personalizados
+func NovoGeradorBoletim(opcoes OpcoesBoletim) (*GeradorBoletim, error) {
+	g := &GeradorBoletim{
+		cabecalho:     opcoes.Cabecalho,
+		trabalhadores: opcoes.Trabalhadores,
+		data:          opcoes.Data,
+	}
+	if g.trabalhadores <= 0 {
+		g.trabalhadores = runtime.NumCPU()
+	}
+
+	modelos, err := template.New("").Funcs(funcoesModelo).ParseFS(modelosEmbutidos, "modelos/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("modelos embutidos: %w", err)
+	}
+	css, err := modelosEmbutidos.ReadFile("modelos/estilo.css")
+	if err != nil {
+		return nil, fmt.Errorf("modelos embutidos: %w", err)
+	}
+
+	if opcoes.DirModelos != "" {
+		dir := os.DirFS(opcoes.DirModelos)
+		if err := sobreporModelos(modelos, dir); err != nil {
+			return nil, fmt.Errorf("modelos de %s: %w", opcoes.DirModelos, err)
+		}
+		extra, err := fs.ReadFile(dir, "estilo.css")
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("modelos de %s: %w", opcoes.DirModelos, err)
+		}
+		css = append(append(css, '\n'), extra...)
+		if g.logo, err = carregarLogo(dir); err != nil {
+			return nil, fmt.Errorf("modelos de %s: %w", opcoes.DirModelos, err)
+		}
+	}
+
+	for _, nome := range []string{"boletim.html", "turma.html", "cabecalho", "rodape"} {
+		if modelos.Lookup(nome) == nil {
+			return nil, fmt.Errorf("modelo %q não definido", nome)
+		}
+	}
+	g.modelos = modelos
+	g.css = template.CSS(css)
+	return g, nil
+}
+
+// sobreporModelos troca os modelos embutidos pelos .html do diretório.
+// Como o arquivo pode redefinir "cabecalho" e "rodape", ele é lido
+// com Parse no conjunto existente.
+func sobreporModelos(modelos *template.Template, dir fs.FS) error {
+	arquivos, err := fs.Glob(dir, "*.html")
+	if err != nil {
+		return err
+	}
+	for _, nome := range arquivos {
+		conteudo, err := fs.ReadFile(dir, nome)
+		if err != nil {
+			return err
+		}
+		if _, err := modelos.New(nome).Parse(string(conteudo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// carregarLogo converte logo.png/jpg/svg em data URI (vazio se não houver)
+func carregarLogo(dir fs.FS) (template.URL, error) {
+	for _, nome := range []string{"logo.png", "logo.jpg", "logo.jpeg", "logo.svg"} {
+		dados, err := fs.ReadFile(dir, nome)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		tipo := mime.TypeByExtension(filepath.Ext(nome))
+		if i := strings.IndexByte(tipo, ';'); i >= 0 {
+			tipo = tipo[:i] // "image/svg+xml; charset=utf-8" em alguns sistemas
+		}
+		// A data URI vem de um arquivo do próprio usuário, por isso é confiável
+		return template.URL("data:" + tipo + ";base64," + base64.StdEncoding.EncodeToString(dados)), nil
+	}
+	return "", nil
+}
+
+func (g *GeradorBoletim) pagina() dadosPagina {
+	data := g.data
+	if data.IsZero() {
+		data = time.Now()
+	}
+	return dadosPagina{
+		Cabecalho: g.cabecalho,
+		CSS:       g.css,
+		Logo:      g.logo,
+		Gerado:    locale.PtBR.DataHora(data),
+	}
+}
+
+// Boletim escreve o boletim de um aluno do sistema
+func (g *GeradorBoletim) Boletim(w io.Writer, s *SistemaNotas, nome string) error {
+	aluno, err := s.BuscarAluno(nome)
+	if err != nil {
+		return fmt.Errorf("boletim: %w", err)
+	}
+	ranking := s.Ranking()
+	return g.boletim(w, s, *aluno, ranking)
+}
+
+func (g *GeradorBoletim) boletim(w io.Writer, s *SistemaNotas, aluno Aluno, ranking []PosicaoRanking) error {
+	dados := g.pagina()
+	dados.Aluno = aluno
+	dados.Resultado = s.Avaliar(aluno)
+	dados.Conceito = Conceito(dados.Resultado.Media)
+	dados.TotalAlunos = len(ranking)
+	for _, p := range ranking {
+		if strings.EqualFold(p.Aluno, aluno.Nome) {
+			dados.Posicao = p
+			break
+		}
+	}
+	if err := g.modelos.ExecuteTemplate(w, "boletim.html", dados); err != nil {
+		return fmt.Errorf("boletim de %s: %w", aluno.Nome, err)
+	}
+	return nil
+}
+
+// ResumoTurma escreve a página de resumo da turma
+func (g *GeradorBoletim) ResumoTurma(w io.Writer, s *SistemaNotas) error {
+	dados := g.pagina()
+	dados.Relatorio = s.Relatorio()
+	if err := g.modelos.ExecuteTemplate(w, "turma.html", dados); err != nil {
+		return fmt.Errorf("resumo da turma: %w", err)
+	}
+	return nil
+}
+
+// GerarLote escreve turma.html e um boletim-<nome>.html por aluno em dir,
+// em paralelo. Retorna os arquivos gerados; falhas individuais não
+// interrompem o lote e voltam juntas no erro.
+func (g *GeradorBoletim) GerarLote(s *SistemaNotas, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("gerar boletins: %w", err)
+	}
+
+	// Calculado uma vez e compartilhado (só leitura) entre os workers
+	ranking := s.Ranking()
+	nomes := nomesArquivos(s.Alunos)
+
+	type resultado struct {
+		indice  int
+		caminho string
+		err     error
+	}
+
+	tarefas := make(chan int)
+	resultados := make(chan resultado)
+	var wg sync.WaitGroup
+	for w := 0; w < g.trabalhadores; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tarefas {
+				caminho := filepath.Join(dir, nomes[i])
+				err := escreverAtomico(caminho, func(w io.Writer) error {
+					return g.boletim(w, s, s.Alunos[i], ranking)
+				})
+				resultados <- resultado{indice: i, caminho: caminho, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for i := range s.Alunos {
+			tarefas <- i
+		}
+		close(tarefas)
+		wg.Wait()
+		close(resultados)
+	}()
+
+	// Manter a ordem de cadastro na lista de arquivos
+	gerados := make([]string, len(s.Alunos))
+	var falhas []error
+	for r := range resultados {
+		if r.err != nil {
+			falhas = append(falhas, r.err)
+			continue
+		}
+		gerados[r.indice] = r.caminho
+	}
+
+	resumo := filepath.Join(dir, "turma.html")
+	if err := escreverAtomico(resumo, func(w io.Writer) error { return g.ResumoTurma(w, s) }); err != nil {
+		falhas = append(falhas, err)
+	} else {
+		gerados = append(gerados, resumo)
+	}
+
+	arquivos := gerados[:0]
+	for _, caminho := range gerados {
+		if caminho != "" {
+			arquivos = append(arquivos, caminho)
+		}
+	}
+	return arquivos, errors.Join(falhas...)
+}
+
+// nomesArquivos gera "boletim-<nome>.html" sem acentos nem espaços,
+// numerando nomes repetidos ("ana-2"). O número é conferido contra os
+// nomes já gerados: "Ana", "Ana!" e "Ana 2" viram ana, ana-2 e ana-2-2.
+func nomesArquivos(alunos []Aluno) []string {
+	usados := make(map[string]bool, len(alunos))
+	nomes := make([]string, len(alunos))
+	for i, a := range alunos {
+		base := "boletim-" + slug(a.Nome)
+		nome := base
+		for n := 2; usados[nome]; n++ {
+			nome = fmt.Sprintf("%s-%d", base, n)
+		}
+		usados[nome] = true
+		nomes[i] = nome + ".html"
+	}
+	return nomes
+}
+
+// slug reduz um nome a letras minúsculas sem acento, números e hífens
+func slug(nome string) string {
+	var b strings.Builder
+	hifen := false
+	for _, r := range normalizar(nome) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			hifen = false
+		case !hifen && b.Len() > 0:
+			b.WriteByte('-')
+			hifen = true
+		}
+	}
+	s := strings.TrimSuffix(b.String(), "-")
+	if s == "" {
+		return "aluno"
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+package notas
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func geradorTeste(t *testing.T, opcoes OpcoesBoletim) *GeradorBoletim {
+	t.Helper()
+	opcoes.Data = time.Date(2024, 6, 28, 14, 30, 0, 0, time.UTC)
+	g, err := NovoGeradorBoletim(opcoes)
+	if err != nil {
+		t.Fatalf("NovoGeradorBoletim: %v", err)
+	}
+	return g
+}
+
+func TestBoletim(t *testing.T) {
+	s := &SistemaNotas{}
+	s.AdicionarAluno("Ana <script>", 9, 10)
+	s.AdicionarAluno("Bruno", 5, 6)
+	s.DefinirFrequencia("Bruno", 80)
+
+	g := geradorTeste(t, OpcoesBoletim{Cabecalho: Cabecalho{Escola: "Escola Estadual Go", Periodo: "2024.1"}})
+
+	var buf bytes.Buffer
+	if err := g.Boletim(&buf, s, "Bruno"); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, trecho := range []string{
+		"Escola Estadual Go", "Período: 2024.1", "Boletim de Bruno",
+		"Avaliação 2", "5,50", "80,00%", "2º de 2", "Reprovado",
+		"@page",      // CSS embutido na página
+		"28/06/2024", // data do rodapé
+	} {
+		if !strings.Contains(html, trecho) {
+			t.Errorf("boletim sem %q", trecho)
+		}
+	}
+
+	buf.Reset()
+	if err := g.Boletim(&buf, s, "ana <script>"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "Ana <script>") {
+		t.Error("nome do aluno deveria ser escapado")
+	}
+
+	if err := g.Boletim(&buf, s, "Carla"); err == nil {
+		t.Error("boletim de aluno inexistente deveria falhar")
+	}
+}
+
+func TestBoletim_ModelosPersonalizados(t *testing.T) {
+	dir := t.TempDir()
+	arquivos := map[string]string{
+		"cabecalho.html": `{{define "cabecalho"}}<div class="topo"><img src="{{.Logo}}">{{.Cabecalho.Escola}} - personalizado</div>{{end}}`,
+		"estilo.css":     `.topo { color: purple; }`,
+		"logo.svg":       `<svg xmlns="http://www.w3.org/2000/svg"/>`,
+	}
+	for nome, conteudo := range arquivos {
+		if err := os.WriteFile(filepath.Join(dir, nome), []byte(conteudo), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := geradorTeste(t, OpcoesBoletim{Cabecalho: Cabecalho{Escola: "Colégio X"}, DirModelos: dir})
+	s := &SistemaNotas{}
+	s.AdicionarAluno("Ana", 8)
+
+	var buf bytes.Buffer
+	if err := g.ResumoTurma(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, trecho := range []string{"Colégio X - personalizado", `src="data:image/svg`, "color: purple", "@page", "Resumo da turma"} {
+		if !strings.Contains(html, trecho) {
+			t.Errorf("resumo sem %q:\n%s", trecho, html)
+		}
+	}
+
+	// Modelo com erro de sintaxe é rejeitado na criação
+	os.WriteFile(filepath.Join(dir, "boletim.html"), []byte("{{if}}"), 0o644)
+	if _, err := NovoGeradorBoletim(OpcoesBoletim{DirModelos: dir}); err == nil {
+		t.Error("modelo inválido deveria falhar")
+	}
+}
+
+func TestGerarLote(t *testing.T) {
+	s := &SistemaNotas{}
+	for i := 0; i < 30; i++ {
+		s.AdicionarAluno(fmt.Sprintf("Aluno %02d", i), float64(i%11))
+	}
+	s.AdicionarAluno("João Ávila", 7)
+	s.AdicionarAluno("Joao   Avila", 8) // mesmo nome de arquivo
+
+	dir := filepath.Join(t.TempDir(), "boletins")
+	g := geradorTeste(t, OpcoesBoletim{Trabalhadores: 4})
+	arquivos, err := g.GerarLote(s, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arquivos) != len(s.Alunos)+1 {
+		t.Fatalf("%d arquivos; esperado %d", len(arquivos), len(s.Alunos)+1)
+	}
+	if filepath.Base(arquivos[0]) != "boletim-aluno-00.html" || filepath.Base(arquivos[len(arquivos)-1]) != "turma.html" {
+		t.Errorf("ordem dos arquivos: primeiro %s, último %s", arquivos[0], arquivos[len(arquivos)-1])
+	}
+	for _, nome := range []string{"boletim-joao-avila.html", "boletim-joao-avila-2.html"} {
+		conteudo, err := os.ReadFile(filepath.Join(dir, nome))
+		if err != nil {
+			t.Fatalf("arquivo %s: %v", nome, err)
+		}
+		if !strings.Contains(string(conteudo), "Boletim de Jo") {
+			t.Errorf("%s com conteúdo inesperado", nome)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Ana Costa":        "ana-costa",
+		"  José da Silva ": "jose-da-silva",
+		"Maria (2º ano)":   "maria-2-ano",
+		"!!!":              "aluno",
+	}
+	for nome, want := range tests {
+		if got := slug(nome); got != want {
+			t.Errorf("slug(%q) = %q; esperado %q", nome, got, want)
+		}
+	}
+}
+
+func TestNomesArquivos(t *testing.T) {
+	alunos := []Aluno{{Nome: "Ana"}, {Nome: "Ana!"}, {Nome: "Ana 2"}, {Nome: "ana"}, {Nome: "Bruno"}}
+	esperado := []string{
+		"boletim-ana.html", "boletim-ana-2.html", "boletim-ana-2-2.html", "boletim-ana-3.html", "boletim-bruno.html",
+	}
+	nomes := nomesArquivos(alunos)
+	for i := range esperado {
+		if nomes[i] != esperado[i] {
+			t.Errorf("nomesArquivos[%d] (%q) = %q; esperado %q", i, alunos[i].Nome, nomes[i], esperado[i])
+		}
+	}
+}
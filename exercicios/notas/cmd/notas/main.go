@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go-course/exercicios/notas"
+	"go-course/modulo08-packages/locale"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+CLI DO SISTEMA DE NOTAS
+
+Mantém o diário de classe entre execuções, salvando em disco.
+
+USO:
+    notas [-arquivo turma.json] [-politica politica.json] <comando> [argumentos]
+
+COMANDOS:
+    add "Ana" 7 8.5                 cadastra a aluna (ou inclui notas se já existir)
+    frequencia "Ana" 80             registra a frequência (%)
+    recuperacao "Ana" 7             registra a nota de recuperação
+    list [--aprovados] [--explicar] lista os alunos
+    melhor                          mostra o(s) aluno(s) com maior média
+    stats                           estatísticas da turma
+    remove "Ana"                    remove o aluno
+    importar planilha.csv           importa alunos de um CSV
+    relatorio --formato html        ranking, conceitos, quartis e avaliações
+    boletins --saida boletins/      um boletim HTML por aluno + resumo da turma
+
+O arquivo padrão é notas.json (ou a variável NOTAS_ARQUIVO).
+Use extensão .csv para salvar em CSV.
+Sem -politica, vale a regra padrão: média simples >= 7.0.
+*/
+
+const uso = `Uso: notas [-arquivo caminho] [-politica caminho] <comando> [argumentos]
+
+Comandos:
+  add <nome> [notas...]             cadastra aluno ou inclui notas
+  frequencia <nome> <porcentagem>   registra frequência
+  recuperacao <nome> <nota>         registra nota de recuperação
+  list [--aprovados] [--explicar]   lista alunos
+  melhor                            aluno(s) com maior média
+  stats                             estatísticas da turma
+  remove <nome>                     remove aluno
+  importar <arquivo.csv> [--delimitador ";"] [--duplicados ignorar|substituir|falhar]
+  relatorio [--formato md|html|json] [--saida arquivo]
+  boletins [--saida dir] [--modelos dir] [--escola nome] [--periodo p]
+`
+
+func main() {
+	if err := executar(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Erro:", err)
+		os.Exit(1)
+	}
+}
+
+func executar(args []string) error {
+	padrao := os.Getenv("NOTAS_ARQUIVO")
+	if padrao == "" {
+		padrao = "notas.json"
+	}
+
+	global := flag.NewFlagSet("notas", flag.ContinueOnError)
+	caminho := global.String("arquivo", padrao, "arquivo de dados (.json ou .csv)")
+	caminhoPolitica := global.String("politica", "", "arquivo JSON com a política de aprovação")
+	global.Usage = func() { fmt.Fprint(os.Stderr, uso) }
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return errors.New("nenhum comando informado")
+	}
+
+	armazenamento := notas.NovoArmazenamento(*caminho)
+	sistema, err := armazenamento.Carregar()
+	if err != nil {
+		return err
+	}
+	if *caminhoPolitica != "" {
+		if sistema.Politica, err = notas.CarregarPolitica(*caminhoPolitica); err != nil {
+			return err
+		}
+	}
+
+	comando, resto := global.Arg(0), global.Args()[1:]
+	switch comando {
+	case "add":
+		if err := comandoAdd(sistema, resto); err != nil {
+			return err
+		}
+		return armazenamento.Salvar(sistema)
+	case "frequencia", "recuperacao":
+		if len(resto) != 2 {
+			return fmt.Errorf("uso: notas %s <nome> <valor>", comando)
+		}
+		valor, err := lerNumero(resto[1])
+		if err != nil {
+			return err
+		}
+		rotulo := "Frequência"
+		if comando == "frequencia" {
+			err = sistema.DefinirFrequencia(resto[0], valor)
+		} else {
+			rotulo = "Recuperação"
+			err = sistema.DefinirRecuperacao(resto[0], valor)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ %s de %s registrada\n", rotulo, resto[0])
+		return armazenamento.Salvar(sistema)
+	case "remove":
+		if len(resto) != 1 {
+			return errors.New("uso: notas remove <nome>")
+		}
+		if err := sistema.RemoverAluno(resto[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Aluno %s removido\n", resto[0])
+		return armazenamento.Salvar(sistema)
+	case "list":
+		return comandoList(sistema, resto)
+	case "importar":
+		if err := comandoImportar(sistema, resto); err != nil {
+			return err
+		}
+		return armazenamento.Salvar(sistema)
+	case "melhor":
+		ranking := sistema.Ranking()
+		if len(ranking) == 0 {
+			fmt.Println("Nenhum aluno cadastrado")
+			return nil
+		}
+		// Empatados no primeiro lugar aparecem todos
+		for _, p := range ranking {
+			if p.Posicao != 1 {
+				break
+			}
+			aluno, err := sistema.BuscarAluno(p.Aluno)
+			if err != nil {
+				return err
+			}
+			exibir(sistema, *aluno, false)
+		}
+		return nil
+	case "relatorio":
+		return comandoRelatorio(sistema, resto)
+	case "boletins":
+		return comandoBoletins(sistema, resto)
+	case "stats":
+		comandoStats(sistema)
+		return nil
+	default:
+		global.Usage()
+		return fmt.Errorf("comando desconhecido: %s", comando)
+	}
+}
+
+func comandoAdd(sistema *notas.SistemaNotas, args []string) error {
+	if len(args) == 0 {
+		return errors.New("uso: notas add <nome> [notas...]")
+	}
+
+	nome := args[0]
+	valores := make([]float64, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		nota, err := lerNumero(arg)
+		if err != nil {
+			return err
+		}
+		valores = append(valores, nota)
+	}
+
+	err := sistema.AdicionarAluno(nome, valores...)
+	if errors.Is(err, notas.ErrAlunoDuplicado) {
+		if err := sistema.AdicionarNotas(nome, valores...); err != nil {
+			return err
+		}
+		fmt.Printf("✓ %d nota(s) incluída(s) para %s\n", len(valores), nome)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Aluno %s adicionado\n", nome)
+	return nil
+}
+
+func comandoList(sistema *notas.SistemaNotas, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	apenasAprovados := fs.Bool("aprovados", false, "listar apenas aprovados")
+	explicar := fs.Bool("explicar", false, "explicar a decisão de cada aluno")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	alunos := sistema.Alunos
+	if *apenasAprovados {
+		alunos = sistema.Aprovados()
+	}
+	if len(alunos) == 0 {
+		fmt.Println("Nenhum aluno encontrado")
+		return nil
+	}
+	for _, aluno := range alunos {
+		exibir(sistema, aluno, *explicar)
+	}
+	return nil
+}
+
+func comandoImportar(sistema *notas.SistemaNotas, args []string) error {
+	fs := flag.NewFlagSet("importar", flag.ContinueOnError)
+	delimitador := fs.String("delimitador", "", "delimitador do CSV (padrão: detectar)")
+	duplicados := fs.String("duplicados", "ignorar", "alunos já cadastrados: ignorar, substituir ou falhar")
+	// Permitir o arquivo antes das flags: importar planilha.csv --duplicados substituir
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		args = append(args[1:], args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: notas importar <arquivo.csv> [--delimitador ;] [--duplicados modo]")
+	}
+
+	var opcoes notas.OpcoesImportacao
+	switch *duplicados {
+	case "ignorar":
+		opcoes.Duplicados = notas.IgnorarDuplicados
+	case "substituir":
+		opcoes.Duplicados = notas.SubstituirDuplicados
+	case "falhar":
+		opcoes.Duplicados = notas.FalharDuplicados
+	default:
+		return fmt.Errorf("modo de duplicados desconhecido: %s", *duplicados)
+	}
+	if *delimitador != "" {
+		d := []rune(*delimitador)
+		if len(d) != 1 {
+			return fmt.Errorf("delimitador deve ter um caractere: %q", *delimitador)
+		}
+		opcoes.Delimitador = d[0]
+	}
+
+	resumo, err := sistema.ImportarArquivoCSV(fs.Arg(0), opcoes)
+	if err != nil {
+		return err
+	}
+	for _, e := range resumo.Erros {
+		fmt.Println("  ⚠️", e)
+	}
+	fmt.Println("✓", resumo)
+	return nil
+}
+
+func comandoRelatorio(sistema *notas.SistemaNotas, args []string) (err error) {
+	fs := flag.NewFlagSet("relatorio", flag.ContinueOnError)
+	formato := fs.String("formato", notas.FormatoMarkdown, "md, html ou json")
+	saida := fs.String("saida", "", "arquivo de saída (padrão: terminal)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *saida != "" {
+		arquivo, err := os.Create(*saida)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if errClose := arquivo.Close(); err == nil {
+				err = errClose
+			}
+		}()
+		w = arquivo
+	}
+
+	if err := sistema.Relatorio().Exportar(w, *formato); err != nil {
+		return err
+	}
+	if *saida != "" {
+		fmt.Printf("✓ Relatório salvo em %s\n", *saida)
+	}
+	return nil
+}
+
+func comandoBoletins(sistema *notas.SistemaNotas, args []string) error {
+	fs := flag.NewFlagSet("boletins", flag.ContinueOnError)
+	saida := fs.String("saida", "boletins", "diretório dos arquivos HTML")
+	modelos := fs.String("modelos", "", "diretório com modelos, estilo.css e logo personalizados")
+	escola := fs.String("escola", "Boletim Escolar", "nome da escola no cabeçalho")
+	periodo := fs.String("periodo", "", "período letivo no cabeçalho")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	gerador, err := notas.NovoGeradorBoletim(notas.OpcoesBoletim{
+		Cabecalho:  notas.Cabecalho{Escola: *escola, Periodo: *periodo},
+		DirModelos: *modelos,
+	})
+	if err != nil {
+		return err
+	}
+	arquivos, err := gerador.GerarLote(sistema, *saida)
+	fmt.Printf("✓ %d arquivo(s) gerado(s) em %s\n", len(arquivos), *saida)
+	return err
+}
+
+func comandoStats(sistema *notas.SistemaNotas) {
+	total := len(sistema.Alunos)
+	aprovados := len(sistema.Aprovados())
+
+	fmt.Printf("Total de alunos: %d\n", total)
+	if total == 0 {
+		return
+	}
+	fmt.Printf("Média geral: %s\n", locale.Numero(sistema.MediaGeral(), 2))
+	fmt.Printf("Aprovados: %d (%s)\n", aprovados,
+		locale.PtBR.FormatarPorcentagem(float64(aprovados)/float64(total), 1))
+	fmt.Printf("Reprovados: %d (%s)\n", total-aprovados,
+		locale.PtBR.FormatarPorcentagem(float64(total-aprovados)/float64(total), 1))
+}
+
+func exibir(sistema *notas.SistemaNotas, a notas.Aluno, explicar bool) {
+	r := sistema.Avaliar(a)
+	status := "❌ Reprovado"
+	if r.Aprovado {
+		status = "✓ Aprovado"
+	}
+	fmt.Printf("  %s - Média: %s - %s\n", a.Nome, locale.Numero(r.Media, 2), status)
+	if explicar {
+		for _, linha := range strings.Split(r.Explicar(), "\n") {
+			fmt.Printf("      %s\n", linha)
+		}
+	}
+}
+
+// lerNumero aceita "8,5" e "8.5"
+func lerNumero(texto string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.Replace(texto, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("número inválido %q", texto)
+	}
+	return v, nil
+}
@@ -0,0 +1,808 @@
+package notas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+ESCOLA: VÁRIAS TURMAS, DISCIPLINAS E PERÍODOS
+
+SistemaNotas é uma lista única de alunos. Escola modela o cenário real:
+
+    Periodo     2024.1, 2024.2...
+    Disciplina  MAT101 - Cálculo I (60h)
+    Turma       oferta de uma disciplina em um período (MAT101-2024.1-A)
+    Avaliacao   prova/trabalho de uma turma, com peso
+    Estudante   matrícula + nome
+    Matricula   estudante inscrito em uma turma (notas, frequência, recuperação)
+
+INTEGRIDADE REFERENCIAL:
+- Não dá para criar turma de disciplina/período inexistente,
+  lançar nota de avaliação inexistente ou para estudante não matriculado
+- Não dá para remover algo em uso (ErrEmUso): cancele as matrículas antes
+- CarregarEscola valida as referências do arquivo (Validar)
+
+MÉDIA DE UMA TURMA:
+Sem Politica, vale a média ponderada pelos pesos das avaliações
+(avaliação sem nota conta como zero) com mínimo MediaAprovacao.
+Com Politica, as notas são passadas na ordem das avaliações.
+
+CONSULTAS:
+    escola.ReprovadosNoPeriodo("2024.1", 2) // reprovados em 2+ disciplinas
+    escola.Historico("2024001")             // histórico escolar
+    escola.SistemaDaTurma("MAT101-A")       // reaproveita Relatorio e CLI
+*/
+
+// Erros do modelo da escola
+var (
+	ErrIDVazio                 = errors.New("identificador vazio")
+	ErrIDDuplicado             = errors.New("identificador já cadastrado")
+	ErrPeriodoNaoEncontrado    = errors.New("período não encontrado")
+	ErrDisciplinaNaoEncontrada = errors.New("disciplina não encontrada")
+	ErrTurmaNaoEncontrada      = errors.New("turma não encontrada")
+	ErrAvaliacaoNaoEncontrada  = errors.New("avaliação não encontrada")
+	ErrEstudanteNaoEncontrado  = errors.New("estudante não encontrado")
+	ErrMatriculaNaoEncontrada  = errors.New("estudante não matriculado na turma")
+	ErrMatriculaDuplicada      = errors.New("estudante já matriculado na turma")
+	ErrDisciplinaJaCursada     = errors.New("estudante já matriculado na disciplina neste período")
+	ErrEmUso                   = errors.New("registro em uso")
+	ErrPesoInvalido            = errors.New("peso da avaliação deve ser positivo")
+	ErrReferenciaInvalida      = errors.New("referência inválida")
+)
+
+// Periodo é um período letivo (ex.: "2024.1")
+type Periodo struct {
+	ID   string `json:"id"`
+	Nome string `json:"nome"`
+}
+
+// Disciplina é uma matéria do currículo
+type Disciplina struct {
+	Codigo       string `json:"codigo"`
+	Nome         string `json:"nome"`
+	CargaHoraria int    `json:"carga_horaria"` // em horas
+}
+
+// Turma é a oferta de uma disciplina em um período
+type Turma struct {
+	ID         string `json:"id"`
+	Disciplina string `json:"disciplina"` // Disciplina.Codigo
+	Periodo    string `json:"periodo"`    // Periodo.ID
+	Professor  string `json:"professor,omitempty"`
+}
+
+// Avaliacao é uma prova ou trabalho de uma turma
+type Avaliacao struct {
+	ID    string  `json:"id"`
+	Turma string  `json:"turma"` // Turma.ID
+	Nome  string  `json:"nome"`
+	Peso  float64 `json:"peso"`
+}
+
+// Estudante é identificado pela matrícula institucional
+type Estudante struct {
+	Matricula string `json:"matricula"`
+	Nome      string `json:"nome"`
+}
+
+// Matricula inscreve um estudante em uma turma
+type Matricula struct {
+	Estudante   string             `json:"estudante"`       // Estudante.Matricula
+	Turma       string             `json:"turma"`           // Turma.ID
+	Notas       map[string]float64 `json:"notas,omitempty"` // Avaliacao.ID → nota
+	Frequencia  *float64           `json:"frequencia,omitempty"`
+	Recuperacao *float64           `json:"recuperacao,omitempty"`
+}
+
+// Escola reúne períodos, disciplinas, turmas e matrículas.
+// Politica define o critério de aprovação (nil = média ponderada pelos pesos).
+type Escola struct {
+	Periodos    []Periodo    `json:"periodos"`
+	Disciplinas []Disciplina `json:"disciplinas"`
+	Estudantes  []Estudante  `json:"estudantes"`
+	Turmas      []Turma      `json:"turmas"`
+	Avaliacoes  []Avaliacao  `json:"avaliacoes"`
+	Matriculas  []Matricula  `json:"matriculas"`
+	Politica    Politica     `json:"-"`
+}
+
+// ========================================
+// CADASTROS
+// ========================================
+
+// AdicionarPeriodo cadastra um período letivo
+func (e *Escola) AdicionarPeriodo(id, nome string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("adicionar período: %w", ErrIDVazio)
+	}
+	if e.periodo(id) >= 0 {
+		return fmt.Errorf("adicionar período %q: %w", id, ErrIDDuplicado)
+	}
+	e.Periodos = append(e.Periodos, Periodo{ID: id, Nome: nome})
+	return nil
+}
+
+// AdicionarDisciplina cadastra uma disciplina
+func (e *Escola) AdicionarDisciplina(codigo, nome string, cargaHoraria int) error {
+	codigo = strings.TrimSpace(codigo)
+	if codigo == "" {
+		return fmt.Errorf("adicionar disciplina: %w", ErrIDVazio)
+	}
+	if e.disciplina(codigo) >= 0 {
+		return fmt.Errorf("adicionar disciplina %q: %w", codigo, ErrIDDuplicado)
+	}
+	if cargaHoraria < 0 {
+		return fmt.Errorf("adicionar disciplina %q: carga horária negativa", codigo)
+	}
+	e.Disciplinas = append(e.Disciplinas, Disciplina{Codigo: codigo, Nome: nome, CargaHoraria: cargaHoraria})
+	return nil
+}
+
+// AdicionarEstudante cadastra um estudante
+func (e *Escola) AdicionarEstudante(matricula, nome string) error {
+	matricula = strings.TrimSpace(matricula)
+	if matricula == "" {
+		return fmt.Errorf("adicionar estudante: %w", ErrIDVazio)
+	}
+	if strings.TrimSpace(nome) == "" {
+		return fmt.Errorf("adicionar estudante %q: %w", matricula, ErrNomeVazio)
+	}
+	if e.estudante(matricula) >= 0 {
+		return fmt.Errorf("adicionar estudante %q: %w", matricula, ErrIDDuplicado)
+	}
+	e.Estudantes = append(e.Estudantes, Estudante{Matricula: matricula, Nome: strings.TrimSpace(nome)})
+	return nil
+}
+
+// AdicionarTurma abre uma turma de uma disciplina em um período
+func (e *Escola) AdicionarTurma(t Turma) error {
+	t.ID = strings.TrimSpace(t.ID)
+	if t.ID == "" {
+		return fmt.Errorf("adicionar turma: %w", ErrIDVazio)
+	}
+	if e.turma(t.ID) >= 0 {
+		return fmt.Errorf("adicionar turma %q: %w", t.ID, ErrIDDuplicado)
+	}
+	if e.disciplina(t.Disciplina) < 0 {
+		return fmt.Errorf("adicionar turma %q: disciplina %q: %w", t.ID, t.Disciplina, ErrDisciplinaNaoEncontrada)
+	}
+	if e.periodo(t.Periodo) < 0 {
+		return fmt.Errorf("adicionar turma %q: período %q: %w", t.ID, t.Periodo, ErrPeriodoNaoEncontrado)
+	}
+	e.Turmas = append(e.Turmas, t)
+	return nil
+}
+
+// AdicionarAvaliacao cria uma avaliação em uma turma
+func (e *Escola) AdicionarAvaliacao(a Avaliacao) error {
+	a.ID = strings.TrimSpace(a.ID)
+	if a.ID == "" {
+		return fmt.Errorf("adicionar avaliação: %w", ErrIDVazio)
+	}
+	if e.avaliacao(a.ID) >= 0 {
+		return fmt.Errorf("adicionar avaliação %q: %w", a.ID, ErrIDDuplicado)
+	}
+	if e.turma(a.Turma) < 0 {
+		return fmt.Errorf("adicionar avaliação %q: turma %q: %w", a.ID, a.Turma, ErrTurmaNaoEncontrada)
+	}
+	if !(a.Peso > 0) {
+		return fmt.Errorf("adicionar avaliação %q: %w", a.ID, ErrPesoInvalido)
+	}
+	e.Avaliacoes = append(e.Avaliacoes, a)
+	return nil
+}
+
+// Matricular inscreve um estudante em uma turma.
+// Um estudante não pode estar em duas turmas da mesma disciplina no mesmo período.
+func (e *Escola) Matricular(estudante, turma string) error {
+	if e.estudante(estudante) < 0 {
+		return fmt.Errorf("matricular %q: %w", estudante, ErrEstudanteNaoEncontrado)
+	}
+	i := e.turma(turma)
+	if i < 0 {
+		return fmt.Errorf("matricular %q em %q: %w", estudante, turma, ErrTurmaNaoEncontrada)
+	}
+	if e.matricula(estudante, turma) >= 0 {
+		return fmt.Errorf("matricular %q em %q: %w", estudante, turma, ErrMatriculaDuplicada)
+	}
+	nova := e.Turmas[i]
+	for _, m := range e.Matriculas {
+		if m.Estudante != estudante {
+			continue
+		}
+		j := e.turma(m.Turma)
+		if j < 0 {
+			return fmt.Errorf("matricular %q: matrícula em %q: %w", estudante, m.Turma, ErrTurmaNaoEncontrada)
+		}
+		t := e.Turmas[j]
+		if t.Disciplina == nova.Disciplina && t.Periodo == nova.Periodo {
+			return fmt.Errorf("matricular %q em %q (já está em %q): %w", estudante, turma, t.ID, ErrDisciplinaJaCursada)
+		}
+	}
+	e.Matriculas = append(e.Matriculas, Matricula{Estudante: estudante, Turma: turma})
+	return nil
+}
+
+// LancarNota registra a nota de um estudante em uma avaliação
+func (e *Escola) LancarNota(estudante, avaliacao string, nota float64) error {
+	i := e.avaliacao(avaliacao)
+	if i < 0 {
+		return fmt.Errorf("lançar nota em %q: %w", avaliacao, ErrAvaliacaoNaoEncontrada)
+	}
+	if err := validarNotas([]float64{nota}); err != nil {
+		return fmt.Errorf("lançar nota de %q em %q: %w", estudante, avaliacao, err)
+	}
+	m := e.matricula(estudante, e.Avaliacoes[i].Turma)
+	if m < 0 {
+		return fmt.Errorf("lançar nota de %q em %q: %w", estudante, avaliacao, ErrMatriculaNaoEncontrada)
+	}
+	if e.Matriculas[m].Notas == nil {
+		e.Matriculas[m].Notas = make(map[string]float64)
+	}
+	e.Matriculas[m].Notas[avaliacao] = nota
+	return nil
+}
+
+// DefinirFrequencia registra a frequência (0 a 100%) de um estudante em uma turma
+func (e *Escola) DefinirFrequencia(estudante, turma string, porcentagem float64) error {
+	if err := validarFrequencia(porcentagem); err != nil {
+		return err
+	}
+	m := e.matricula(estudante, turma)
+	if m < 0 {
+		return fmt.Errorf("definir frequência de %q em %q: %w", estudante, turma, ErrMatriculaNaoEncontrada)
+	}
+	e.Matriculas[m].Frequencia = &porcentagem
+	return nil
+}
+
+// DefinirRecuperacao registra a nota de recuperação de um estudante em uma turma
+func (e *Escola) DefinirRecuperacao(estudante, turma string, nota float64) error {
+	if err := validarNotas([]float64{nota}); err != nil {
+		return err
+	}
+	m := e.matricula(estudante, turma)
+	if m < 0 {
+		return fmt.Errorf("definir recuperação de %q em %q: %w", estudante, turma, ErrMatriculaNaoEncontrada)
+	}
+	e.Matriculas[m].Recuperacao = &nota
+	return nil
+}
+
+// ========================================
+// REMOÇÕES (respeitando as referências)
+// ========================================
+
+// CancelarMatricula remove a matrícula e as notas do estudante na turma
+func (e *Escola) CancelarMatricula(estudante, turma string) error {
+	m := e.matricula(estudante, turma)
+	if m < 0 {
+		return fmt.Errorf("cancelar matrícula de %q em %q: %w", estudante, turma, ErrMatriculaNaoEncontrada)
+	}
+	e.Matriculas = append(e.Matriculas[:m], e.Matriculas[m+1:]...)
+	return nil
+}
+
+// RemoverAvaliacao remove uma avaliação que ainda não tem notas
+func (e *Escola) RemoverAvaliacao(id string) error {
+	i := e.avaliacao(id)
+	if i < 0 {
+		return fmt.Errorf("remover avaliação %q: %w", id, ErrAvaliacaoNaoEncontrada)
+	}
+	for _, m := range e.Matriculas {
+		if _, ok := m.Notas[id]; ok {
+			return fmt.Errorf("remover avaliação %q: há notas lançadas: %w", id, ErrEmUso)
+		}
+	}
+	e.Avaliacoes = append(e.Avaliacoes[:i], e.Avaliacoes[i+1:]...)
+	return nil
+}
+
+// RemoverTurma remove uma turma sem matrículas (e suas avaliações)
+func (e *Escola) RemoverTurma(id string) error {
+	i := e.turma(id)
+	if i < 0 {
+		return fmt.Errorf("remover turma %q: %w", id, ErrTurmaNaoEncontrada)
+	}
+	for _, m := range e.Matriculas {
+		if m.Turma == id {
+			return fmt.Errorf("remover turma %q: há estudantes matriculados: %w", id, ErrEmUso)
+		}
+	}
+	avaliacoes := e.Avaliacoes[:0]
+	for _, a := range e.Avaliacoes {
+		if a.Turma != id {
+			avaliacoes = append(avaliacoes, a)
+		}
+	}
+	e.Avaliacoes = avaliacoes
+	e.Turmas = append(e.Turmas[:i], e.Turmas[i+1:]...)
+	return nil
+}
+
+// RemoverEstudante remove um estudante sem matrículas
+func (e *Escola) RemoverEstudante(matricula string) error {
+	i := e.estudante(matricula)
+	if i < 0 {
+		return fmt.Errorf("remover estudante %q: %w", matricula, ErrEstudanteNaoEncontrado)
+	}
+	for _, m := range e.Matriculas {
+		if m.Estudante == matricula {
+			return fmt.Errorf("remover estudante %q: matriculado em %q: %w", matricula, m.Turma, ErrEmUso)
+		}
+	}
+	e.Estudantes = append(e.Estudantes[:i], e.Estudantes[i+1:]...)
+	return nil
+}
+
+// RemoverDisciplina remove uma disciplina sem turmas
+func (e *Escola) RemoverDisciplina(codigo string) error {
+	i := e.disciplina(codigo)
+	if i < 0 {
+		return fmt.Errorf("remover disciplina %q: %w", codigo, ErrDisciplinaNaoEncontrada)
+	}
+	for _, t := range e.Turmas {
+		if t.Disciplina == codigo {
+			return fmt.Errorf("remover disciplina %q: turma %q: %w", codigo, t.ID, ErrEmUso)
+		}
+	}
+	e.Disciplinas = append(e.Disciplinas[:i], e.Disciplinas[i+1:]...)
+	return nil
+}
+
+// RemoverPeriodo remove um período sem turmas
+func (e *Escola) RemoverPeriodo(id string) error {
+	i := e.periodo(id)
+	if i < 0 {
+		return fmt.Errorf("remover período %q: %w", id, ErrPeriodoNaoEncontrado)
+	}
+	for _, t := range e.Turmas {
+		if t.Periodo == id {
+			return fmt.Errorf("remover período %q: turma %q: %w", id, t.ID, ErrEmUso)
+		}
+	}
+	e.Periodos = append(e.Periodos[:i], e.Periodos[i+1:]...)
+	return nil
+}
+
+// ========================================
+// AVALIAÇÃO E CONSULTAS
+// ========================================
+
+// AvaliacoesDaTurma retorna as avaliações da turma, na ordem de cadastro
+func (e *Escola) AvaliacoesDaTurma(turma string) []Avaliacao {
+	var avaliacoes []Avaliacao
+	for _, a := range e.Avaliacoes {
+		if a.Turma == turma {
+			avaliacoes = append(avaliacoes, a)
+		}
+	}
+	return avaliacoes
+}
+
+// SituacaoNaTurma avalia um estudante em uma turma
+func (e *Escola) SituacaoNaTurma(estudante, turma string) (Resultado, error) {
+	m := e.matricula(estudante, turma)
+	if m < 0 {
+		return Resultado{}, fmt.Errorf("situação de %q em %q: %w", estudante, turma, ErrMatriculaNaoEncontrada)
+	}
+	aluno, pesos := e.alunoDaMatricula(e.Matriculas[m])
+	return e.politicaDaTurma(pesos).Avaliar(aluno), nil
+}
+
+// alunoDaMatricula monta um Aluno com as notas na ordem das avaliações
+// (avaliação sem nota conta como zero) e os pesos correspondentes
+func (e *Escola) alunoDaMatricula(m Matricula) (Aluno, []float64) {
+	aluno := Aluno{Frequencia: m.Frequencia, Recuperacao: m.Recuperacao}
+	if i := e.estudante(m.Estudante); i >= 0 {
+		aluno.Nome = e.Estudantes[i].Nome
+	}
+	var pesos []float64
+	for _, a := range e.AvaliacoesDaTurma(m.Turma) {
+		aluno.Notas = append(aluno.Notas, m.Notas[a.ID])
+		pesos = append(pesos, a.Peso)
+	}
+	return aluno, pesos
+}
+
+func (e *Escola) politicaDaTurma(pesos []float64) Politica {
+	if e.Politica != nil {
+		return e.Politica
+	}
+	if len(pesos) == 0 {
+		return PoliticaPadrao
+	}
+	return MediaPonderada{Pesos: pesos, Minima: MediaAprovacao}
+}
+
+// SistemaDaTurma converte uma turma em SistemaNotas, para usar
+// Relatorio, Ranking e a CLI. As notas seguem a ordem das avaliações.
+// A política do sistema é a mesma usada por SituacaoNaTurma.
+//
+// O SistemaNotas identifica alunos pelo nome; estudantes homônimos na
+// turma ganham a matrícula entre parênteses ("Ana (001)").
+func (e *Escola) SistemaDaTurma(turma string) (*SistemaNotas, error) {
+	if e.turma(turma) < 0 {
+		return nil, fmt.Errorf("sistema da turma %q: %w", turma, ErrTurmaNaoEncontrada)
+	}
+	s := &SistemaNotas{}
+	var pesos []float64
+	var matriculas []string
+	homonimos := make(map[string]int)
+	for _, m := range e.Matriculas {
+		if m.Turma != turma {
+			continue
+		}
+		var aluno Aluno
+		aluno, pesos = e.alunoDaMatricula(m)
+		s.Alunos = append(s.Alunos, aluno)
+		matriculas = append(matriculas, m.Estudante)
+		homonimos[strings.ToLower(strings.TrimSpace(aluno.Nome))]++
+	}
+	for i := range s.Alunos {
+		if homonimos[strings.ToLower(strings.TrimSpace(s.Alunos[i].Nome))] > 1 {
+			s.Alunos[i].Nome = fmt.Sprintf("%s (%s)", strings.TrimSpace(s.Alunos[i].Nome), matriculas[i])
+		}
+	}
+	if pesos == nil {
+		for _, a := range e.AvaliacoesDaTurma(turma) {
+			pesos = append(pesos, a.Peso)
+		}
+	}
+	s.Politica = e.politicaDaTurma(pesos)
+	return s, nil
+}
+
+// Reprovacoes lista as disciplinas em que um estudante foi reprovado
+type Reprovacoes struct {
+	Estudante   Estudante `json:"estudante"`
+	Disciplinas []string  `json:"disciplinas"` // códigos
+}
+
+// ReprovadosNoPeriodo lista os estudantes reprovados em pelo menos
+// minimo disciplinas do período, na ordem de cadastro dos estudantes
+func (e *Escola) ReprovadosNoPeriodo(periodo string, minimo int) ([]Reprovacoes, error) {
+	if e.periodo(periodo) < 0 {
+		return nil, fmt.Errorf("reprovados em %q: %w", periodo, ErrPeriodoNaoEncontrado)
+	}
+
+	porEstudante := make(map[string][]string)
+	for _, m := range e.Matriculas {
+		i := e.turma(m.Turma)
+		if i < 0 {
+			return nil, fmt.Errorf("reprovados em %q: matrícula de %q em %q: %w", periodo, m.Estudante, m.Turma, ErrTurmaNaoEncontrada)
+		}
+		t := e.Turmas[i]
+		if t.Periodo != periodo {
+			continue
+		}
+		r, err := e.SituacaoNaTurma(m.Estudante, m.Turma)
+		if err != nil {
+			return nil, err
+		}
+		if !r.Aprovado {
+			porEstudante[m.Estudante] = append(porEstudante[m.Estudante], t.Disciplina)
+		}
+	}
+
+	var reprovados []Reprovacoes
+	for _, est := range e.Estudantes {
+		if disciplinas := porEstudante[est.Matricula]; len(disciplinas) >= minimo && len(disciplinas) > 0 {
+			reprovados = append(reprovados, Reprovacoes{Estudante: est, Disciplinas: disciplinas})
+		}
+	}
+	return reprovados, nil
+}
+
+// ========================================
+// HISTÓRICO ESCOLAR
+// ========================================
+
+// LinhaHistorico é uma disciplina cursada
+type LinhaHistorico struct {
+	Disciplina Disciplina `json:"disciplina"`
+	Turma      string     `json:"turma"`
+	Media      float64    `json:"media"`
+	Frequencia *float64   `json:"frequencia,omitempty"`
+	Aprovado   bool       `json:"aprovado"`
+	Pendencias []string   `json:"pendencias,omitempty"`
+}
+
+// PeriodoHistorico agrupa as disciplinas de um período
+type PeriodoHistorico struct {
+	Periodo     Periodo          `json:"periodo"`
+	Disciplinas []LinhaHistorico `json:"disciplinas"`
+	Media       float64          `json:"media"` // ponderada pela carga horária
+}
+
+// Historico é o histórico escolar de um estudante em todos os períodos
+type Historico struct {
+	Estudante     Estudante          `json:"estudante"`
+	Periodos      []PeriodoHistorico `json:"periodos"`
+	Coeficiente   float64            `json:"coeficiente"`    // média geral ponderada pela carga horária
+	CargaCursada  int                `json:"carga_cursada"`  // horas
+	CargaAprovada int                `json:"carga_aprovada"` // horas em disciplinas aprovadas
+}
+
+// Historico monta o histórico escolar, com os períodos na ordem de cadastro
+func (e *Escola) Historico(matricula string) (Historico, error) {
+	i := e.estudante(matricula)
+	if i < 0 {
+		return Historico{}, fmt.Errorf("histórico de %q: %w", matricula, ErrEstudanteNaoEncontrado)
+	}
+	h := Historico{Estudante: e.Estudantes[i]}
+
+	somaGeral, pesoGeral := 0.0, 0.0
+	for _, p := range e.Periodos {
+		ph := PeriodoHistorico{Periodo: p}
+		soma, peso := 0.0, 0.0
+		for _, m := range e.Matriculas {
+			if m.Estudante != matricula {
+				continue
+			}
+			j := e.turma(m.Turma)
+			if j < 0 {
+				return Historico{}, fmt.Errorf("histórico de %q: turma %q: %w", matricula, m.Turma, ErrTurmaNaoEncontrada)
+			}
+			t := e.Turmas[j]
+			if t.Periodo != p.ID {
+				continue
+			}
+			k := e.disciplina(t.Disciplina)
+			if k < 0 {
+				return Historico{}, fmt.Errorf("histórico de %q: turma %q: disciplina %q: %w", matricula, t.ID, t.Disciplina, ErrDisciplinaNaoEncontrada)
+			}
+			d := e.Disciplinas[k]
+			r, err := e.SituacaoNaTurma(matricula, t.ID)
+			if err != nil {
+				return Historico{}, err
+			}
+			ph.Disciplinas = append(ph.Disciplinas, LinhaHistorico{
+				Disciplina: d,
+				Turma:      t.ID,
+				Media:      r.Media,
+				Frequencia: m.Frequencia,
+				Aprovado:   r.Aprovado,
+				Pendencias: r.Pendencias,
+			})
+
+			// Disciplina sem carga horária pesa 1 para não sumir da média
+			ch := float64(d.CargaHoraria)
+			if ch == 0 {
+				ch = 1
+			}
+			soma += r.Media * ch
+			peso += ch
+			h.CargaCursada += d.CargaHoraria
+			if r.Aprovado {
+				h.CargaAprovada += d.CargaHoraria
+			}
+		}
+		if len(ph.Disciplinas) == 0 {
+			continue
+		}
+		ph.Media = soma / peso
+		somaGeral += soma
+		pesoGeral += peso
+		h.Periodos = append(h.Periodos, ph)
+	}
+	if pesoGeral > 0 {
+		h.Coeficiente = somaGeral / pesoGeral
+	}
+	return h, nil
+}
+
+// String formata o histórico como texto
+func (h Historico) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "HISTÓRICO ESCOLAR - %s (%s)\n", h.Estudante.Nome, h.Estudante.Matricula)
+	for _, p := range h.Periodos {
+		titulo := p.Periodo.ID
+		if p.Periodo.Nome != "" {
+			titulo += " - " + p.Periodo.Nome
+		}
+		fmt.Fprintf(&b, "\n%s\n", titulo)
+		for _, d := range p.Disciplinas {
+			freq := "-"
+			if d.Frequencia != nil {
+				freq = fmt.Sprintf("%.0f%%", *d.Frequencia)
+			}
+			fmt.Fprintf(&b, "  %-8s %-30s %3dh  média %5.2f  freq %4s  %s\n",
+				d.Disciplina.Codigo, d.Disciplina.Nome, d.Disciplina.CargaHoraria, d.Media, freq, situacao(d.Aprovado))
+		}
+		fmt.Fprintf(&b, "  Média do período: %.2f\n", p.Media)
+	}
+	fmt.Fprintf(&b, "\nCoeficiente de rendimento: %.2f\n", h.Coeficiente)
+	fmt.Fprintf(&b, "Carga horária: %dh cursadas, %dh aprovadas\n", h.CargaCursada, h.CargaAprovada)
+	return b.String()
+}
+
+// ========================================
+// PERSISTÊNCIA E INTEGRIDADE
+// ========================================
+
+// Validar confere todas as referências e as regras de Matricular (útil
+// após editar o JSON à mão)
+func (e *Escola) Validar() error {
+	var problemas []error
+	invalida := func(formato string, args ...any) {
+		problemas = append(problemas, fmt.Errorf("%w: "+formato, append([]any{ErrReferenciaInvalida}, args...)...))
+	}
+
+	duplicados := func(tipo string, ids []string) {
+		vistos := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if vistos[id] {
+				invalida("%s %q duplicado", tipo, id)
+			}
+			vistos[id] = true
+		}
+	}
+	var ids []string
+	for _, p := range e.Periodos {
+		ids = append(ids, p.ID)
+	}
+	duplicados("período", ids)
+	ids = ids[:0]
+	for _, d := range e.Disciplinas {
+		ids = append(ids, d.Codigo)
+	}
+	duplicados("disciplina", ids)
+	ids = ids[:0]
+	for _, s := range e.Estudantes {
+		ids = append(ids, s.Matricula)
+	}
+	duplicados("estudante", ids)
+	ids = ids[:0]
+	for _, t := range e.Turmas {
+		ids = append(ids, t.ID)
+	}
+	duplicados("turma", ids)
+	ids = ids[:0]
+	for _, a := range e.Avaliacoes {
+		ids = append(ids, a.ID)
+	}
+	duplicados("avaliação", ids)
+
+	for _, t := range e.Turmas {
+		if e.disciplina(t.Disciplina) < 0 {
+			invalida("turma %q: disciplina %q inexistente", t.ID, t.Disciplina)
+		}
+		if e.periodo(t.Periodo) < 0 {
+			invalida("turma %q: período %q inexistente", t.ID, t.Periodo)
+		}
+	}
+	for _, a := range e.Avaliacoes {
+		if e.turma(a.Turma) < 0 {
+			invalida("avaliação %q: turma %q inexistente", a.ID, a.Turma)
+		}
+		if !(a.Peso > 0) {
+			invalida("avaliação %q: peso %v", a.ID, a.Peso)
+		}
+	}
+	vistas := make(map[[2]string]bool)
+	// estudante, disciplina e período → turma: a regra de Matricular
+	cursadas := make(map[[3]string]string)
+	for _, m := range e.Matriculas {
+		chave := [2]string{m.Estudante, m.Turma}
+		if vistas[chave] {
+			invalida("matrícula de %q em %q duplicada", m.Estudante, m.Turma)
+		}
+		vistas[chave] = true
+		if e.estudante(m.Estudante) < 0 {
+			invalida("matrícula em %q: estudante %q inexistente", m.Turma, m.Estudante)
+		}
+		if i := e.turma(m.Turma); i < 0 {
+			invalida("matrícula de %q: turma %q inexistente", m.Estudante, m.Turma)
+		} else {
+			t := e.Turmas[i]
+			chave := [3]string{m.Estudante, t.Disciplina, t.Periodo}
+			if outra, ok := cursadas[chave]; ok && outra != m.Turma {
+				problemas = append(problemas, fmt.Errorf("%w: %q em %q e %q", ErrDisciplinaJaCursada, m.Estudante, outra, m.Turma))
+			} else {
+				cursadas[chave] = m.Turma
+			}
+		}
+		for id, nota := range m.Notas {
+			i := e.avaliacao(id)
+			if i < 0 || e.Avaliacoes[i].Turma != m.Turma {
+				invalida("nota de %q: avaliação %q não pertence à turma %q", m.Estudante, id, m.Turma)
+			}
+			if err := validarNotas([]float64{nota}); err != nil {
+				invalida("nota de %q em %q: %v", m.Estudante, id, err)
+			}
+		}
+	}
+	return errors.Join(problemas...)
+}
+
+// CarregarEscola lê uma escola de um arquivo JSON e valida as referências.
+// Se o arquivo não existir, retorna uma escola vazia.
+func CarregarEscola(caminho string) (*Escola, error) {
+	arquivo, err := os.Open(caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Escola{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("carregar %s: %w", caminho, err)
+	}
+	defer arquivo.Close()
+
+	var e Escola
+	if err := json.NewDecoder(arquivo).Decode(&e); err != nil {
+		return nil, fmt.Errorf("carregar %s: %w", caminho, err)
+	}
+	if err := e.Validar(); err != nil {
+		return nil, fmt.Errorf("carregar %s: %w", caminho, err)
+	}
+	return &e, nil
+}
+
+// Salvar grava a escola em JSON de forma atômica
+func (e *Escola) Salvar(caminho string) error {
+	return escreverAtomico(caminho, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	})
+}
+
+func (e *Escola) periodo(id string) int {
+	for i, p := range e.Periodos {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Escola) disciplina(codigo string) int {
+	for i, d := range e.Disciplinas {
+		if d.Codigo == codigo {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Escola) estudante(matricula string) int {
+	for i, s := range e.Estudantes {
+		if s.Matricula == matricula {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Escola) turma(id string) int {
+	for i, t := range e.Turmas {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Escola) avaliacao(id string) int {
+	for i, a := range e.Avaliacoes {
+		if a.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Escola) matricula(estudante, turma string) int {
+	for i, m := range e.Matriculas {
+		if m.Estudante == estudante && m.Turma == turma {
+			return i
+		}
+	}
+	return -1
+}
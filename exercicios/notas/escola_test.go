@@ -0,0 +1,257 @@
+package notas
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// novaEscola monta dois períodos com três disciplinas
+func novaEscola(t *testing.T) *Escola {
+	t.Helper()
+	e := &Escola{}
+	deveriaFuncionar := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deveriaFuncionar(e.AdicionarPeriodo("2024.1", "1º semestre"))
+	deveriaFuncionar(e.AdicionarPeriodo("2024.2", "2º semestre"))
+	deveriaFuncionar(e.AdicionarDisciplina("MAT", "Cálculo", 60))
+	deveriaFuncionar(e.AdicionarDisciplina("FIS", "Física", 60))
+	deveriaFuncionar(e.AdicionarDisciplina("PRG", "Programação", 30))
+	deveriaFuncionar(e.AdicionarEstudante("001", "Ana"))
+	deveriaFuncionar(e.AdicionarEstudante("002", "Bruno"))
+
+	for _, t1 := range []Turma{
+		{ID: "MAT-1", Disciplina: "MAT", Periodo: "2024.1"},
+		{ID: "FIS-1", Disciplina: "FIS", Periodo: "2024.1"},
+		{ID: "PRG-1", Disciplina: "PRG", Periodo: "2024.1"},
+		{ID: "MAT-2", Disciplina: "MAT", Periodo: "2024.2"},
+	} {
+		deveriaFuncionar(e.AdicionarTurma(t1))
+		deveriaFuncionar(e.AdicionarAvaliacao(Avaliacao{ID: t1.ID + "-P1", Turma: t1.ID, Nome: "P1", Peso: 1}))
+		deveriaFuncionar(e.AdicionarAvaliacao(Avaliacao{ID: t1.ID + "-P2", Turma: t1.ID, Nome: "P2", Peso: 3}))
+	}
+
+	notas := map[string]map[string][2]float64{
+		"001": {"MAT-1": {5, 6}, "FIS-1": {8, 9}, "PRG-1": {10, 10}},
+		"002": {"MAT-1": {4, 5}, "FIS-1": {3, 6}, "PRG-1": {9, 6}, "MAT-2": {8, 7}},
+	}
+	for estudante, turmas := range notas {
+		for turma, n := range turmas {
+			deveriaFuncionar(e.Matricular(estudante, turma))
+			deveriaFuncionar(e.LancarNota(estudante, turma+"-P1", n[0]))
+			deveriaFuncionar(e.LancarNota(estudante, turma+"-P2", n[1]))
+		}
+	}
+	return e
+}
+
+func TestEscola_IntegridadeReferencial(t *testing.T) {
+	e := novaEscola(t)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"turma sem disciplina", e.AdicionarTurma(Turma{ID: "X", Disciplina: "QUI", Periodo: "2024.1"}), ErrDisciplinaNaoEncontrada},
+		{"turma sem período", e.AdicionarTurma(Turma{ID: "X", Disciplina: "MAT", Periodo: "2030.1"}), ErrPeriodoNaoEncontrado},
+		{"turma duplicada", e.AdicionarTurma(Turma{ID: "MAT-1", Disciplina: "MAT", Periodo: "2024.1"}), ErrIDDuplicado},
+		{"avaliação sem peso", e.AdicionarAvaliacao(Avaliacao{ID: "Z", Turma: "MAT-1"}), ErrPesoInvalido},
+		{"matrícula duplicada", e.Matricular("001", "MAT-1"), ErrMatriculaDuplicada},
+		{"estudante inexistente", e.Matricular("999", "MAT-1"), ErrEstudanteNaoEncontrado},
+		{"nota sem matrícula", e.LancarNota("001", "MAT-2-P1", 7), ErrMatriculaNaoEncontrada},
+		{"nota inválida", e.LancarNota("001", "MAT-1-P1", 11), ErrNotaInvalida},
+		{"avaliação inexistente", e.LancarNota("001", "NADA", 7), ErrAvaliacaoNaoEncontrada},
+		{"remover disciplina em uso", e.RemoverDisciplina("MAT"), ErrEmUso},
+		{"remover estudante matriculado", e.RemoverEstudante("001"), ErrEmUso},
+		{"remover avaliação com notas", e.RemoverAvaliacao("MAT-1-P1"), ErrEmUso},
+		{"frequência inválida", e.DefinirFrequencia("001", "MAT-1", 120), ErrFrequenciaInvalida},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: erro = %v; esperado %v", tt.name, tt.err, tt.want)
+		}
+	}
+
+	// Segunda turma da mesma disciplina no mesmo período
+	e.AdicionarTurma(Turma{ID: "MAT-1B", Disciplina: "MAT", Periodo: "2024.1"})
+	if err := e.Matricular("001", "MAT-1B"); !errors.Is(err, ErrDisciplinaJaCursada) {
+		t.Errorf("matrícula em turma paralela: erro = %v", err)
+	}
+
+	// Cancelar a matrícula libera o estudante
+	if err := e.CancelarMatricula("001", "PRG-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.SituacaoNaTurma("001", "PRG-1"); !errors.Is(err, ErrMatriculaNaoEncontrada) {
+		t.Errorf("situação após cancelar: %v", err)
+	}
+}
+
+func TestEscola_MediaPonderadaPelasAvaliacoes(t *testing.T) {
+	e := novaEscola(t)
+
+	r, err := e.SituacaoNaTurma("001", "FIS-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.Media-8.75) > 1e-9 || !r.Aprovado { // (8·1 + 9·3) / 4
+		t.Errorf("FIS-1 de Ana = %.2f aprovado=%v; esperado 8.75 aprovado", r.Media, r.Aprovado)
+	}
+
+	// A política da escola substitui a média ponderada
+	e.Politica = MediaSimples{Minima: 5}
+	r, _ = e.SituacaoNaTurma("001", "MAT-1")
+	if r.Media != 5.5 || !r.Aprovado {
+		t.Errorf("com MediaSimples: %+v", r)
+	}
+}
+
+func TestEscola_ReprovadosNoPeriodo(t *testing.T) {
+	e := novaEscola(t)
+
+	// Ana: MAT 5.75 (reprovada). Bruno: MAT 4.75, FIS 5.25, PRG 6.75 (três reprovações)
+	reprovados, err := e.ReprovadosNoPeriodo("2024.1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reprovados) != 1 || reprovados[0].Estudante.Matricula != "002" {
+		t.Fatalf("reprovados em 2+ = %+v; esperado só Bruno", reprovados)
+	}
+	if len(reprovados[0].Disciplinas) != 3 {
+		t.Errorf("disciplinas de Bruno = %v; esperado 3", reprovados[0].Disciplinas)
+	}
+
+	if todos, _ := e.ReprovadosNoPeriodo("2024.1", 1); len(todos) != 2 {
+		t.Errorf("reprovados em 1+ = %d; esperado 2", len(todos))
+	}
+	if _, err := e.ReprovadosNoPeriodo("1999.1", 1); !errors.Is(err, ErrPeriodoNaoEncontrado) {
+		t.Errorf("período inexistente: %v", err)
+	}
+}
+
+func TestEscola_Historico(t *testing.T) {
+	e := novaEscola(t)
+
+	h, err := e.Historico("002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Periodos) != 2 || len(h.Periodos[0].Disciplinas) != 3 || len(h.Periodos[1].Disciplinas) != 1 {
+		t.Fatalf("histórico = %+v", h)
+	}
+	// 2024.1: (4.75·60 + 5.25·60 + 6.75·30) / 150 = 5.35
+	if math.Abs(h.Periodos[0].Media-5.35) > 1e-9 {
+		t.Errorf("média 2024.1 = %.4f; esperado 5.35", h.Periodos[0].Media)
+	}
+	// Geral: (802.5 + 7.25·60) / 210
+	if math.Abs(h.Coeficiente-(802.5+435)/210) > 1e-9 {
+		t.Errorf("coeficiente = %.4f", h.Coeficiente)
+	}
+	if h.CargaCursada != 210 || h.CargaAprovada != 60 {
+		t.Errorf("carga = %d/%d; esperado 210/60", h.CargaCursada, h.CargaAprovada)
+	}
+	if texto := h.String(); !strings.Contains(texto, "Bruno (002)") || !strings.Contains(texto, "2024.2 - 2º semestre") {
+		t.Errorf("String():\n%s", texto)
+	}
+
+	if _, err := e.Historico("999"); !errors.Is(err, ErrEstudanteNaoEncontrado) {
+		t.Errorf("estudante inexistente: %v", err)
+	}
+}
+
+func TestEscola_ReferenciasQuebradas(t *testing.T) {
+	// Uma Escola carregada de fora pode apontar para o que não existe:
+	// erro do package, não panic
+	e := novaEscola(t)
+	e.Matriculas = append(e.Matriculas, Matricula{Estudante: "001", Turma: "QUI-1"})
+	if err := e.Matricular("001", "MAT-2"); !errors.Is(err, ErrTurmaNaoEncontrada) {
+		t.Errorf("Matricular: %v", err)
+	}
+	if _, err := e.ReprovadosNoPeriodo("2024.1", 1); !errors.Is(err, ErrTurmaNaoEncontrada) {
+		t.Errorf("ReprovadosNoPeriodo: %v", err)
+	}
+	if _, err := e.Historico("001"); !errors.Is(err, ErrTurmaNaoEncontrada) {
+		t.Errorf("Historico: %v", err)
+	}
+
+	e = novaEscola(t)
+	e.Turmas[0].Disciplina = "QUI"
+	if _, err := e.Historico("001"); !errors.Is(err, ErrDisciplinaNaoEncontrada) {
+		t.Errorf("Historico com disciplina inexistente: %v", err)
+	}
+}
+
+func TestEscola_SistemaDaTurma(t *testing.T) {
+	e := novaEscola(t)
+
+	s, err := e.SistemaDaTurma("MAT-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := s.Relatorio()
+	if r.Total != 2 || r.Ranking[0].Aluno != "Ana" || math.Abs(r.Ranking[0].Media-5.75) > 1e-9 {
+		t.Errorf("relatório da turma = %+v", r.Ranking)
+	}
+
+	// Homônimos não se fundem: cada um leva a matrícula no nome
+	e.AdicionarEstudante("003", "ana")
+	e.Matricular("003", "MAT-1")
+	e.LancarNota("003", "MAT-1-P1", 10)
+	s, _ = e.SistemaDaTurma("MAT-1")
+	var nomes []string
+	for _, a := range s.Alunos {
+		nomes = append(nomes, a.Nome)
+	}
+	sort.Strings(nomes) // a ordem das matrículas de novaEscola varia
+	if got := strings.Join(nomes, ","); got != "Ana (001),Bruno,ana (003)" {
+		t.Errorf("alunos = %q", got)
+	}
+	if _, err := s.BuscarAluno("ana (003)"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEscola_SalvarCarregar(t *testing.T) {
+	e := novaEscola(t)
+	e.DefinirFrequencia("001", "MAT-1", 80)
+
+	caminho := filepath.Join(t.TempDir(), "escola.json")
+	if err := e.Salvar(caminho); err != nil {
+		t.Fatal(err)
+	}
+	carregada, err := CarregarEscola(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h1, _ := e.Historico("001")
+	h2, _ := carregada.Historico("001")
+	if h1.String() != h2.String() {
+		t.Errorf("histórico mudou ao recarregar:\n%s\n%s", h1, h2)
+	}
+
+	// Duas turmas da mesma disciplina no período, que Matricular recusa
+	carregada.AdicionarTurma(Turma{ID: "MAT-1B", Disciplina: "MAT", Periodo: "2024.1"})
+	carregada.Matriculas = append(carregada.Matriculas, Matricula{Estudante: "001", Turma: "MAT-1B"})
+	if err := carregada.Validar(); !errors.Is(err, ErrDisciplinaJaCursada) {
+		t.Errorf("Validar com a disciplina duas vezes no período: %v", err)
+	}
+	carregada.Matriculas = carregada.Matriculas[:len(carregada.Matriculas)-1]
+	if err := carregada.Validar(); err != nil {
+		t.Errorf("Validar depois de desfazer: %v", err)
+	}
+
+	// Referência quebrada é detectada ao carregar
+	carregada.Matriculas[0].Turma = "FANTASMA"
+	if err := carregada.Validar(); !errors.Is(err, ErrReferenciaInvalida) {
+		t.Errorf("Validar com turma inexistente: %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package notas
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+IMPORTAÇÃO DE PLANILHAS (CSV)
+
+Professores exportam notas do Excel/LibreOffice em formatos variados:
+
+    Aluno;Prova 1;Prova 2;Frequência      ← ";" e vírgula decimal
+    Ana Costa;8,5;7;90
+
+    nome,nota1,nota2                      ← "," e ponto decimal
+    "Costa, Ana",8.5,7
+
+O importador:
+- Detecta o delimitador (";" ou ",") pela linha de cabeçalho
+- Mapeia colunas pelo nome do cabeçalho (aluno, nota, prova, p1...)
+- Aceita vírgula decimal ("8,5")
+- Remove o BOM do UTF-8 (comum em arquivos do Excel)
+- NÃO para no primeiro erro: cada linha ruim vira um
+  *erros.ErroProcessamento com arquivo e linha, e o resumo final
+  informa quantas linhas foram importadas, ignoradas e com falha.
+*/
+
+// Campos de destino de uma coluna da planilha
+const (
+	CampoNome        = "nome"
+	CampoNota        = "nota"
+	CampoFrequencia  = "frequencia"
+	CampoRecuperacao = "recuperacao"
+	CampoIgnorar     = "ignorar"
+)
+
+// ModoDuplicados define o que fazer com alunos já cadastrados
+type ModoDuplicados int
+
+const (
+	IgnorarDuplicados    ModoDuplicados = iota // mantém o cadastro atual (linha ignorada)
+	SubstituirDuplicados                       // troca notas, frequência e recuperação
+	FalharDuplicados                           // conta a linha como erro
+)
+
+// OpcoesImportacao ajusta a leitura do CSV.
+// O valor zero detecta o delimitador e ignora alunos já cadastrados.
+type OpcoesImportacao struct {
+	Delimitador rune              // 0 = detectar entre ';' e ','
+	Colunas     map[string]string // cabeçalho → Campo*, sobrepõe a detecção automática
+	Duplicados  ModoDuplicados
+}
+
+// ResumoImportacao é o balanço de uma importação
+type ResumoImportacao struct {
+	Arquivo    string
+	Importados int
+	Ignorados  int
+	Falhas     int
+	Erros      []error // um *erros.ErroProcessamento por linha com falha
+}
+
+// String resume a importação em uma linha
+func (r ResumoImportacao) String() string {
+	return fmt.Sprintf("%s: %d importado(s), %d ignorado(s), %d com erro",
+		r.Arquivo, r.Importados, r.Ignorados, r.Falhas)
+}
+
+// Err junta os erros de linha em um único erro (nil se não houve falhas)
+func (r ResumoImportacao) Err() error {
+	return errors.Join(r.Erros...)
+}
+
+// ImportarArquivoCSV abre o arquivo e chama ImportarCSV
+func (s *SistemaNotas) ImportarArquivoCSV(caminho string, opcoes OpcoesImportacao) (ResumoImportacao, error) {
+	arquivo, err := os.Open(caminho)
+	if err != nil {
+		return ResumoImportacao{Arquivo: caminho}, fmt.Errorf("importar %s: %w", caminho, err)
+	}
+	defer arquivo.Close()
+	return s.ImportarCSV(arquivo, caminho, opcoes)
+}
+
+// ImportarCSV lê alunos de um CSV. Linhas inválidas não interrompem a
+// importação: ficam em ResumoImportacao.Erros. O erro retornado é
+// reservado para falhas que impedem a leitura (ex.: cabeçalho sem nome).
+func (s *SistemaNotas) ImportarCSV(r io.Reader, arquivo string, opcoes OpcoesImportacao) (ResumoImportacao, error) {
+	resumo := ResumoImportacao{Arquivo: arquivo}
+	falhaArquivo := func(linha int, err error) (ResumoImportacao, error) {
+		return resumo, &erros.ErroProcessamento{Arquivo: arquivo, Linha: linha, Erro: err}
+	}
+
+	br := bufio.NewReader(r)
+	// BOM do UTF-8 (EF BB BF), gravado pelo Excel em "CSV UTF-8"
+	if inicio, _ := br.Peek(3); bytes.Equal(inicio, []byte("\xEF\xBB\xBF")) {
+		br.Discard(3)
+	}
+
+	delimitador := opcoes.Delimitador
+	if delimitador == 0 {
+		primeira, _ := br.Peek(4096)
+		delimitador = detectarDelimitador(primeira)
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = delimitador
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	cabecalho, err := cr.Read()
+	if err == io.EOF {
+		return falhaArquivo(1, fmt.Errorf("arquivo vazio: %w", erros.ErrFormatoInvalido))
+	}
+	if err != nil {
+		return falhaArquivo(1, err)
+	}
+	campos, err := mapearColunas(cabecalho, opcoes.Colunas)
+	if err != nil {
+		return falhaArquivo(1, err)
+	}
+
+	for {
+		registro, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		linha, _ := cr.FieldPos(0)
+
+		var erroLinha error
+		if err != nil {
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				linha = pe.Line
+			}
+			erroLinha = err
+		} else {
+			var importado bool
+			importado, erroLinha = s.importarRegistro(registro, cabecalho, campos, opcoes.Duplicados)
+			if erroLinha == nil && !importado {
+				resumo.Ignorados++
+				continue
+			}
+		}
+
+		if erroLinha != nil {
+			resumo.Falhas++
+			resumo.Erros = append(resumo.Erros, &erros.ErroProcessamento{Arquivo: arquivo, Linha: linha, Erro: erroLinha})
+			continue
+		}
+		resumo.Importados++
+	}
+	return resumo, nil
+}
+
+// importarRegistro grava uma linha no sistema; retorna false se ela foi ignorada
+func (s *SistemaNotas) importarRegistro(registro, cabecalho, campos []string, modo ModoDuplicados) (bool, error) {
+	vazia := true
+	for _, valor := range registro {
+		if strings.TrimSpace(valor) != "" {
+			vazia = false
+			break
+		}
+	}
+	if vazia {
+		return false, nil
+	}
+
+	var aluno Aluno
+	for i, valor := range registro {
+		if i >= len(campos) {
+			return false, fmt.Errorf("%d coluna(s) a mais que o cabeçalho: %w", len(registro)-len(campos), erros.ErrFormatoInvalido)
+		}
+		if !utf8.ValidString(valor) {
+			return false, fmt.Errorf("coluna %q: texto não está em UTF-8: %w", cabecalho[i], erros.ErrFormatoInvalido)
+		}
+		valor = strings.TrimSpace(valor)
+		if valor == "" || campos[i] == CampoIgnorar {
+			continue
+		}
+
+		if campos[i] == CampoNome {
+			aluno.Nome = valor
+			continue
+		}
+		numero, err := lerDecimal(valor)
+		if err != nil {
+			return false, fmt.Errorf("coluna %q: %w", cabecalho[i], err)
+		}
+		switch campos[i] {
+		case CampoNota:
+			aluno.Notas = append(aluno.Notas, numero)
+		case CampoFrequencia:
+			aluno.Frequencia = &numero
+		case CampoRecuperacao:
+			aluno.Recuperacao = &numero
+		}
+	}
+
+	if aluno.Nome == "" {
+		return false, ErrNomeVazio
+	}
+	if err := validarAluno(aluno); err != nil {
+		return false, err
+	}
+
+	i := s.indice(aluno.Nome)
+	switch {
+	case i < 0:
+		s.Alunos = append(s.Alunos, aluno)
+	case modo == SubstituirDuplicados:
+		s.Alunos[i] = aluno
+	case modo == FalharDuplicados:
+		return false, fmt.Errorf("%q: %w", aluno.Nome, ErrAlunoDuplicado)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func validarAluno(a Aluno) error {
+	if err := validarNotas(a.Notas); err != nil {
+		return err
+	}
+	if a.Recuperacao != nil {
+		if err := validarNotas([]float64{*a.Recuperacao}); err != nil {
+			return fmt.Errorf("recuperação: %w", err)
+		}
+	}
+	if a.Frequencia != nil {
+		return validarFrequencia(*a.Frequencia)
+	}
+	return nil
+}
+
+// lerDecimal aceita "8,5", "8.5" e "90%"
+func lerDecimal(texto string) (float64, error) {
+	limpo := strings.TrimSuffix(strings.TrimSpace(texto), "%")
+	limpo = strings.Replace(limpo, ",", ".", 1)
+	v, err := strconv.ParseFloat(limpo, 64)
+	if err != nil {
+		return 0, fmt.Errorf("número inválido %q: %w", texto, erros.ErrFormatoInvalido)
+	}
+	return v, nil
+}
+
+// detectarDelimitador escolhe ';' ou ',' pela contagem na primeira linha
+func detectarDelimitador(inicio []byte) rune {
+	if i := bytes.IndexByte(inicio, '\n'); i >= 0 {
+		inicio = inicio[:i]
+	}
+	if bytes.Count(inicio, []byte(";")) > bytes.Count(inicio, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+// Nomes de cabeçalho reconhecidos automaticamente (já normalizados)
+var aliasColunas = map[string]string{
+	"nome":        CampoNome,
+	"aluno":       CampoNome,
+	"aluna":       CampoNome,
+	"estudante":   CampoNome,
+	"name":        CampoNome,
+	"frequencia":  CampoFrequencia,
+	"freq":        CampoFrequencia,
+	"presenca":    CampoFrequencia,
+	"recuperacao": CampoRecuperacao,
+	"rec":         CampoRecuperacao,
+}
+
+// Prefixos de cabeçalho que indicam uma nota: "Nota 1", "Prova 2", "Trabalho"
+var prefixosNota = []string{"nota", "prova", "trabalho", "teste", "avaliacao"}
+
+// Siglas seguidas de número que indicam uma nota: "P1", "AV 3", "N2"
+var siglasNota = []string{"p", "av", "n"}
+
+// ehColunaNota reconhece cabeçalhos (normalizados) de notas
+func ehColunaNota(chave string) bool {
+	for _, prefixo := range prefixosNota {
+		if strings.HasPrefix(chave, prefixo) {
+			return true
+		}
+	}
+	for _, sigla := range siglasNota {
+		resto := strings.TrimSpace(strings.TrimPrefix(chave, sigla))
+		if strings.HasPrefix(chave, sigla) && resto != "" && isNumeroInteiro(resto) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumeroInteiro(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// mapearColunas define o campo de destino de cada coluna do cabeçalho
+func mapearColunas(cabecalho []string, personalizado map[string]string) ([]string, error) {
+	manual := make(map[string]string, len(personalizado))
+	for coluna, campo := range personalizado {
+		manual[normalizar(coluna)] = campo
+	}
+
+	campos := make([]string, len(cabecalho))
+	temNome := false
+	for i, coluna := range cabecalho {
+		chave := normalizar(coluna)
+		campo, ok := manual[chave]
+		if !ok {
+			campo, ok = aliasColunas[chave]
+		}
+		if !ok {
+			campo = CampoIgnorar
+			if ehColunaNota(chave) {
+				campo = CampoNota
+			}
+		}
+
+		switch campo {
+		case CampoNome:
+			if temNome {
+				return nil, fmt.Errorf("mais de uma coluna de nome (%q): %w", coluna, erros.ErrFormatoInvalido)
+			}
+			temNome = true
+		case CampoNota, CampoFrequencia, CampoRecuperacao, CampoIgnorar:
+		default:
+			return nil, fmt.Errorf("coluna %q mapeada para campo desconhecido %q: %w", coluna, campo, erros.ErrFormatoInvalido)
+		}
+		campos[i] = campo
+	}
+
+	if !temNome {
+		return nil, fmt.Errorf("cabeçalho sem coluna de nome (%s): %w", strings.Join(cabecalho, ", "), erros.ErrFormatoInvalido)
+	}
+	return campos, nil
+}
+
+// normalizar deixa o cabeçalho em minúsculas e sem acentos: "Frequência" → "frequencia"
+func normalizar(s string) string {
+	substituir := strings.NewReplacer(
+		"á", "a", "à", "a", "â", "a", "ã", "a",
+		"é", "e", "ê", "e", "í", "i",
+		"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ç", "c",
+	)
+	return strings.TrimSpace(substituir.Replace(strings.ToLower(s)))
+}
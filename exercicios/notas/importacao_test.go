@@ -0,0 +1,108 @@
+package notas
+
+import (
+	"errors"
+	"go-course/modulo07-erros/erros"
+	"strings"
+	"testing"
+)
+
+func TestImportarCSV_PlanilhaBrasileira(t *testing.T) {
+	csv := "\xEF\xBB\xBFAluno;Prova 1;P2;Frequência;Observação\n" +
+		"Ana Costa;8,5;7;90%;ótima\n" +
+		"Bruno;6;;75;\n" +
+		";;;;\n" +
+		"Carla;11;7;80;\n" +
+		"Davi;abc;7;80;\n" +
+		";8;7;80;\n"
+
+	s := &SistemaNotas{}
+	resumo, err := s.ImportarCSV(strings.NewReader(csv), "turma.csv", OpcoesImportacao{})
+	if err != nil {
+		t.Fatalf("ImportarCSV: %v", err)
+	}
+
+	if resumo.Importados != 2 || resumo.Ignorados != 1 || resumo.Falhas != 3 {
+		t.Fatalf("resumo = %v; esperado 2 importados, 1 ignorado, 3 com erro", resumo)
+	}
+
+	ana, err := s.BuscarAluno("ana costa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ana.Notas) != 2 || ana.Notas[0] != 8.5 || ana.Frequencia == nil || *ana.Frequencia != 90 {
+		t.Errorf("Ana importada como %+v", *ana)
+	}
+	if bruno, _ := s.BuscarAluno("Bruno"); len(bruno.Notas) != 1 {
+		t.Errorf("Bruno: nota vazia deveria ser ignorada, obtido %v", bruno.Notas)
+	}
+
+	linhasEsperadas := []int{5, 6, 7}
+	for i, e := range resumo.Erros {
+		var pe *erros.ErroProcessamento
+		if !errors.As(e, &pe) {
+			t.Fatalf("erro %d não é *ErroProcessamento: %v", i, e)
+		}
+		if pe.Arquivo != "turma.csv" || pe.Linha != linhasEsperadas[i] {
+			t.Errorf("erro %d em %s:%d; esperado turma.csv:%d", i, pe.Arquivo, pe.Linha, linhasEsperadas[i])
+		}
+	}
+	if !errors.Is(resumo.Erros[0], ErrNotaInvalida) {
+		t.Errorf("nota 11: erro = %v; esperado ErrNotaInvalida", resumo.Erros[0])
+	}
+	if !errors.Is(resumo.Erros[1], erros.ErrFormatoInvalido) {
+		t.Errorf("nota abc: erro = %v; esperado ErrFormatoInvalido", resumo.Erros[1])
+	}
+	if !errors.Is(resumo.Err(), ErrNomeVazio) {
+		t.Errorf("Err() deveria conter ErrNomeVazio: %v", resumo.Err())
+	}
+}
+
+func TestImportarCSV_VirgulaEDuplicados(t *testing.T) {
+	csv := "nome,nota1,nota2,recuperacao\n" +
+		"\"Costa, Ana\",\"8,5\",7.5,\n" +
+		"Bruno,5,5,7\n" +
+		"Bruno,5,5,7,9\n"
+
+	tests := []struct {
+		name       string
+		modo       ModoDuplicados
+		importados int
+		ignorados  int
+		falhas     int
+	}{
+		{"ignorar", IgnorarDuplicados, 1, 1, 1},
+		{"substituir", SubstituirDuplicados, 2, 0, 1},
+		{"falhar", FalharDuplicados, 1, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SistemaNotas{}
+			s.AdicionarAluno("Bruno", 10)
+
+			resumo, err := s.ImportarCSV(strings.NewReader(csv), "x.csv", OpcoesImportacao{Duplicados: tt.modo})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resumo.Importados != tt.importados || resumo.Ignorados != tt.ignorados || resumo.Falhas != tt.falhas {
+				t.Errorf("resumo = %v %v", resumo, resumo.Erros)
+			}
+		})
+	}
+}
+
+func TestImportarCSV_CabecalhoInvalido(t *testing.T) {
+	s := &SistemaNotas{}
+	_, err := s.ImportarCSV(strings.NewReader("coluna;p1\nAna;8\n"), "x.csv", OpcoesImportacao{})
+	if !errors.Is(err, erros.ErrFormatoInvalido) {
+		t.Errorf("erro = %v; esperado ErrFormatoInvalido", err)
+	}
+
+	// Mapeamento manual resolve o cabeçalho desconhecido
+	resumo, err := s.ImportarCSV(strings.NewReader("coluna;p1\nAna;8\n"), "x.csv",
+		OpcoesImportacao{Colunas: map[string]string{"Coluna": CampoNome}})
+	if err != nil || resumo.Importados != 1 {
+		t.Errorf("com mapeamento: %v, %v", resumo, err)
+	}
+}
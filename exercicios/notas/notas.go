@@ -0,0 +1,232 @@
+package notas
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+/*
+PACKAGE NOTAS
+
+Versão reutilizável do exercício 4 (exercicio04_notas.go).
+
+Diferenças em relação ao exercício:
+- Métodos retornam erros em vez de imprimir
+- Struct tags para persistência em JSON
+- Operações de busca e remoção por nome
+*/
+
+// MediaAprovacao é a média mínima para aprovação
+const MediaAprovacao = 7.0
+
+// Limites de uma nota válida
+const (
+	NotaMinima = 0.0
+	NotaMaxima = 10.0
+)
+
+// Erros do sistema de notas
+var (
+	ErrNomeVazio          = errors.New("nome do aluno não pode ser vazio")
+	ErrNotaInvalida       = errors.New("nota fora do intervalo 0 a 10")
+	ErrAlunoNaoEncontrado = errors.New("aluno não encontrado")
+	ErrAlunoDuplicado     = errors.New("aluno já cadastrado")
+	ErrFrequenciaInvalida = errors.New("frequência fora do intervalo 0 a 100")
+)
+
+// Aluno representa um aluno e suas notas.
+// Frequencia (em %) e Recuperacao são opcionais (nil = não registrado).
+type Aluno struct {
+	Nome        string    `json:"nome"`
+	Notas       []float64 `json:"notas"`
+	Frequencia  *float64  `json:"frequencia,omitempty"`
+	Recuperacao *float64  `json:"recuperacao,omitempty"`
+}
+
+// Media calcula a média simples das notas
+func (a Aluno) Media() float64 {
+	if len(a.Notas) == 0 {
+		return 0
+	}
+
+	soma := 0.0
+	for _, nota := range a.Notas {
+		soma += nota
+	}
+	return soma / float64(len(a.Notas))
+}
+
+// Aprovado informa se o aluno passa pela PoliticaPadrao (média >= MediaAprovacao)
+func (a Aluno) Aprovado() bool {
+	return PoliticaPadrao.Avaliar(a).Aprovado
+}
+
+// SistemaNotas gerencia a lista de alunos.
+// Politica define o critério de aprovação (nil = PoliticaPadrao).
+type SistemaNotas struct {
+	Alunos   []Aluno  `json:"alunos"`
+	Politica Politica `json:"-"`
+}
+
+// AdicionarAluno cadastra um novo aluno
+func (s *SistemaNotas) AdicionarAluno(nome string, notas ...float64) error {
+	nome = strings.TrimSpace(nome)
+	if nome == "" {
+		return ErrNomeVazio
+	}
+	if err := validarNotas(notas); err != nil {
+		return err
+	}
+	if s.indice(nome) >= 0 {
+		return fmt.Errorf("adicionar %q: %w", nome, ErrAlunoDuplicado)
+	}
+
+	s.Alunos = append(s.Alunos, Aluno{
+		Nome:  nome,
+		Notas: append([]float64(nil), notas...),
+	})
+	return nil
+}
+
+// AdicionarNotas inclui novas notas para um aluno existente
+func (s *SistemaNotas) AdicionarNotas(nome string, notas ...float64) error {
+	if err := validarNotas(notas); err != nil {
+		return err
+	}
+	i := s.indice(nome)
+	if i < 0 {
+		return fmt.Errorf("adicionar notas para %q: %w", nome, ErrAlunoNaoEncontrado)
+	}
+	s.Alunos[i].Notas = append(s.Alunos[i].Notas, notas...)
+	return nil
+}
+
+// DefinirFrequencia registra a frequência (0 a 100%) de um aluno
+func (s *SistemaNotas) DefinirFrequencia(nome string, porcentagem float64) error {
+	if err := validarFrequencia(porcentagem); err != nil {
+		return err
+	}
+	i := s.indice(nome)
+	if i < 0 {
+		return fmt.Errorf("definir frequência de %q: %w", nome, ErrAlunoNaoEncontrado)
+	}
+	s.Alunos[i].Frequencia = &porcentagem
+	return nil
+}
+
+// DefinirRecuperacao registra a nota de recuperação de um aluno
+func (s *SistemaNotas) DefinirRecuperacao(nome string, nota float64) error {
+	if err := validarNotas([]float64{nota}); err != nil {
+		return err
+	}
+	i := s.indice(nome)
+	if i < 0 {
+		return fmt.Errorf("definir recuperação de %q: %w", nome, ErrAlunoNaoEncontrado)
+	}
+	s.Alunos[i].Recuperacao = &nota
+	return nil
+}
+
+// RemoverAluno remove o aluno com o nome informado
+func (s *SistemaNotas) RemoverAluno(nome string) error {
+	i := s.indice(nome)
+	if i < 0 {
+		return fmt.Errorf("remover %q: %w", nome, ErrAlunoNaoEncontrado)
+	}
+	s.Alunos = append(s.Alunos[:i], s.Alunos[i+1:]...)
+	return nil
+}
+
+// BuscarAluno retorna o aluno com o nome informado (sem diferenciar maiúsculas)
+func (s *SistemaNotas) BuscarAluno(nome string) (*Aluno, error) {
+	i := s.indice(nome)
+	if i < 0 {
+		return nil, fmt.Errorf("buscar %q: %w", nome, ErrAlunoNaoEncontrado)
+	}
+	return &s.Alunos[i], nil
+}
+
+// Avaliar aplica a política do sistema a um aluno
+func (s *SistemaNotas) Avaliar(a Aluno) Resultado {
+	if s.Politica == nil {
+		return PoliticaPadrao.Avaliar(a)
+	}
+	return s.Politica.Avaliar(a)
+}
+
+// Resultados avalia todos os alunos, na ordem de cadastro
+func (s *SistemaNotas) Resultados() []Resultado {
+	resultados := make([]Resultado, len(s.Alunos))
+	for i, aluno := range s.Alunos {
+		resultados[i] = s.Avaliar(aluno)
+	}
+	return resultados
+}
+
+// Aprovados retorna os alunos aprovados pela política do sistema
+func (s *SistemaNotas) Aprovados() []Aluno {
+	var aprovados []Aluno
+	for _, aluno := range s.Alunos {
+		if s.Avaliar(aluno).Aprovado {
+			aprovados = append(aprovados, aluno)
+		}
+	}
+	return aprovados
+}
+
+// MelhorAluno retorna o aluno com maior média pela política (nil se não houver alunos)
+func (s *SistemaNotas) MelhorAluno() *Aluno {
+	if len(s.Alunos) == 0 {
+		return nil
+	}
+
+	melhor := 0
+	maiorMedia := s.Avaliar(s.Alunos[0]).Media
+	for i := 1; i < len(s.Alunos); i++ {
+		if media := s.Avaliar(s.Alunos[i]).Media; media > maiorMedia {
+			melhor, maiorMedia = i, media
+		}
+	}
+	return &s.Alunos[melhor]
+}
+
+// MediaGeral calcula a média das médias da turma (pela política)
+func (s *SistemaNotas) MediaGeral() float64 {
+	if len(s.Alunos) == 0 {
+		return 0
+	}
+
+	soma := 0.0
+	for _, aluno := range s.Alunos {
+		soma += s.Avaliar(aluno).Media
+	}
+	return soma / float64(len(s.Alunos))
+}
+
+func (s *SistemaNotas) indice(nome string) int {
+	nome = strings.TrimSpace(nome)
+	for i, aluno := range s.Alunos {
+		if strings.EqualFold(aluno.Nome, nome) {
+			return i
+		}
+	}
+	return -1
+}
+
+func validarFrequencia(porcentagem float64) error {
+	if math.IsNaN(porcentagem) || porcentagem < 0 || porcentagem > 100 {
+		return fmt.Errorf("%.1f%%: %w", porcentagem, ErrFrequenciaInvalida)
+	}
+	return nil
+}
+
+func validarNotas(notas []float64) error {
+	for _, nota := range notas {
+		if math.IsNaN(nota) || nota < NotaMinima || nota > NotaMaxima {
+			return fmt.Errorf("nota %.2f: %w", nota, ErrNotaInvalida)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package notas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+/*
+POLÍTICAS DE APROVAÇÃO
+
+Cada escola tem suas regras. Em vez de fixar "média >= 7.0",
+o critério é uma interface:
+
+    type Politica interface {
+        Avaliar(a Aluno) Resultado
+    }
+
+POLÍTICAS PRONTAS (combináveis como decorators):
+- MediaSimples:     média aritmética das notas
+- MediaPonderada:   peso por avaliação (ex.: 2, 3, 5)
+- ComRecuperacao:   nota de recuperação substitui a menor nota
+- ComFrequencia:    reprova abaixo da frequência mínima
+- ComArredondamento: arredonda a média em passos (ex.: 0.5)
+
+EXEMPLO:
+    p := ComFrequencia{
+        Base:   ComArredondamento{
+            Base:  ComRecuperacao{Base: MediaPonderada{Pesos: []float64{2, 3, 5}, Minima: 6}},
+            Passo: 0.5,
+        },
+        Minima: 75,
+    }
+    r := p.Avaliar(aluno)
+    fmt.Println(r.Explicar())
+
+Ou a partir de um arquivo JSON (veja ConfigPolitica).
+*/
+
+// ErrPoliticaInvalida indica uma configuração de política inconsistente
+var ErrPoliticaInvalida = errors.New("política de aprovação inválida")
+
+// Politica decide se um aluno foi aprovado
+type Politica interface {
+	Avaliar(a Aluno) Resultado
+}
+
+// Resultado é a decisão de uma política, com a explicação de cada etapa
+type Resultado struct {
+	Aluno       string   `json:"aluno"`
+	Media       float64  `json:"media"`
+	MediaMinima float64  `json:"media_minima"`
+	Aprovado    bool     `json:"aprovado"`
+	Pendencias  []string `json:"pendencias,omitempty"` // motivos que impedem a aprovação
+	Etapas      []string `json:"etapas"`               // como a média foi calculada
+}
+
+// concluir recalcula Aprovado após cada etapa
+func (r *Resultado) concluir() {
+	r.Aprovado = r.Media >= r.MediaMinima && len(r.Pendencias) == 0
+}
+
+// Explicar descreve em texto por que o aluno foi aprovado ou reprovado
+func (r Resultado) Explicar() string {
+	var b strings.Builder
+	for _, etapa := range r.Etapas {
+		b.WriteString("- " + etapa + "\n")
+	}
+
+	if r.Aprovado {
+		fmt.Fprintf(&b, "Aprovado: média %.2f >= %.2f", r.Media, r.MediaMinima)
+		return b.String()
+	}
+
+	motivos := append([]string(nil), r.Pendencias...)
+	if r.Media < r.MediaMinima {
+		motivos = append([]string{fmt.Sprintf("média %.2f < %.2f", r.Media, r.MediaMinima)}, motivos...)
+	}
+	b.WriteString("Reprovado: " + strings.Join(motivos, "; "))
+	return b.String()
+}
+
+// PoliticaPadrao reproduz a regra original: média simples >= 7.0
+var PoliticaPadrao Politica = MediaSimples{Minima: MediaAprovacao}
+
+// ========================================
+// POLÍTICAS BASE
+// ========================================
+
+// MediaSimples aprova quem tiver média aritmética >= Minima
+type MediaSimples struct {
+	Minima float64
+}
+
+// Avaliar implementa Politica
+func (p MediaSimples) Avaliar(a Aluno) Resultado {
+	r := Resultado{Aluno: a.Nome, Media: a.Media(), MediaMinima: p.Minima}
+	r.Etapas = append(r.Etapas, fmt.Sprintf("média simples de %d nota(s): %.2f", len(a.Notas), r.Media))
+	r.concluir()
+	return r
+}
+
+// MediaPonderada usa um peso por avaliação, na ordem das notas.
+// Avaliações sem nota contam como zero; notas além dos pesos são ignoradas.
+type MediaPonderada struct {
+	Pesos  []float64
+	Minima float64
+}
+
+// Avaliar implementa Politica
+func (p MediaPonderada) Avaliar(a Aluno) Resultado {
+	r := Resultado{Aluno: a.Nome, MediaMinima: p.Minima}
+
+	somaPesos, soma := 0.0, 0.0
+	for i, peso := range p.Pesos {
+		somaPesos += peso
+		if i < len(a.Notas) {
+			soma += a.Notas[i] * peso
+		}
+	}
+	if somaPesos > 0 {
+		r.Media = soma / somaPesos
+	}
+
+	r.Etapas = append(r.Etapas, fmt.Sprintf("média ponderada (pesos %v): %.2f", p.Pesos, r.Media))
+	if faltam := len(p.Pesos) - len(a.Notas); faltam > 0 {
+		r.Etapas = append(r.Etapas, fmt.Sprintf("%d avaliação(ões) sem nota contada(s) como zero", faltam))
+	}
+	if sobram := len(a.Notas) - len(p.Pesos); sobram > 0 {
+		r.Etapas = append(r.Etapas, fmt.Sprintf("%d nota(s) além das avaliações previstas ignorada(s)", sobram))
+	}
+	r.concluir()
+	return r
+}
+
+// ========================================
+// DECORATORS
+// ========================================
+
+// ComRecuperacao substitui a menor nota pela nota de recuperação, se for maior
+type ComRecuperacao struct {
+	Base Politica
+}
+
+// Avaliar implementa Politica
+func (p ComRecuperacao) Avaliar(a Aluno) Resultado {
+	if a.Recuperacao == nil || len(a.Notas) == 0 {
+		return p.Base.Avaliar(a)
+	}
+
+	menor := 0
+	for i, nota := range a.Notas {
+		if nota < a.Notas[menor] {
+			menor = i
+		}
+	}
+
+	rec := *a.Recuperacao
+	if rec <= a.Notas[menor] {
+		r := p.Base.Avaliar(a)
+		r.Etapas = append(r.Etapas, fmt.Sprintf("recuperação %.2f não supera a menor nota %.2f", rec, a.Notas[menor]))
+		return r
+	}
+
+	// Copiar as notas para não alterar o aluno original
+	ajustado := a
+	ajustado.Notas = append([]float64(nil), a.Notas...)
+	ajustado.Notas[menor] = rec
+
+	r := p.Base.Avaliar(ajustado)
+	etapa := fmt.Sprintf("recuperação %.2f substituiu a nota %d (%.2f)", rec, menor+1, a.Notas[menor])
+	r.Etapas = append([]string{etapa}, r.Etapas...)
+	return r
+}
+
+// ComFrequencia reprova quem tiver frequência (%) abaixo de Minima.
+// Alunos sem frequência registrada não são afetados.
+type ComFrequencia struct {
+	Base   Politica
+	Minima float64
+}
+
+// Avaliar implementa Politica
+func (p ComFrequencia) Avaliar(a Aluno) Resultado {
+	r := p.Base.Avaliar(a)
+	switch {
+	case a.Frequencia == nil:
+		r.Etapas = append(r.Etapas, "frequência não registrada (regra ignorada)")
+	case *a.Frequencia < p.Minima:
+		r.Pendencias = append(r.Pendencias,
+			fmt.Sprintf("frequência %.1f%% abaixo do mínimo de %.1f%%", *a.Frequencia, p.Minima))
+	default:
+		r.Etapas = append(r.Etapas, fmt.Sprintf("frequência %.1f%% (mínimo %.1f%%)", *a.Frequencia, p.Minima))
+	}
+	r.concluir()
+	return r
+}
+
+// ComArredondamento arredonda a média em múltiplos de Passo (ex.: 0.5).
+// Com ParaCima, 6.6 vira 7.0; sem, vira 6.5.
+type ComArredondamento struct {
+	Base     Politica
+	Passo    float64
+	ParaCima bool
+}
+
+// Avaliar implementa Politica
+func (p ComArredondamento) Avaliar(a Aluno) Resultado {
+	r := p.Base.Avaliar(a)
+	if p.Passo <= 0 {
+		return r
+	}
+
+	original := r.Media
+	// Cortar o ruído de ponto flutuante: sem isso, 14.000000001 passos
+	// virariam 15 com ParaCima
+	passos := math.Round(original/p.Passo*1e9) / 1e9
+	if p.ParaCima {
+		passos = math.Ceil(passos)
+	} else {
+		passos = math.Round(passos)
+	}
+	r.Media = passos * p.Passo
+
+	if r.Media != original {
+		r.Etapas = append(r.Etapas, fmt.Sprintf("média %.2f arredondada para %.2f (passo %.2f)", original, r.Media, p.Passo))
+	}
+	r.concluir()
+	return r
+}
+
+// ========================================
+// CONFIGURAÇÃO EM JSON
+// ========================================
+
+// ConfigPolitica descreve uma política em JSON:
+//
+//	{
+//	  "media_minima": 6,
+//	  "pesos": [2, 3, 5],
+//	  "recuperacao": true,
+//	  "frequencia_minima": 75,
+//	  "arredondamento": {"passo": 0.5, "para_cima": true}
+//	}
+type ConfigPolitica struct {
+	MediaMinima      *float64  `json:"media_minima"`
+	Pesos            []float64 `json:"pesos,omitempty"`
+	Recuperacao      bool      `json:"recuperacao,omitempty"`
+	FrequenciaMinima float64   `json:"frequencia_minima,omitempty"`
+	Arredondamento   *struct {
+		Passo    float64 `json:"passo"`
+		ParaCima bool    `json:"para_cima"`
+	} `json:"arredondamento,omitempty"`
+}
+
+// Politica monta a política descrita pela configuração
+func (c ConfigPolitica) Politica() (Politica, error) {
+	minima := MediaAprovacao
+	if c.MediaMinima != nil {
+		minima = *c.MediaMinima
+	}
+	if minima < NotaMinima || minima > NotaMaxima {
+		return nil, fmt.Errorf("%w: media_minima %.2f fora de 0 a 10", ErrPoliticaInvalida, minima)
+	}
+
+	var p Politica = MediaSimples{Minima: minima}
+	if len(c.Pesos) > 0 {
+		soma := 0.0
+		for _, peso := range c.Pesos {
+			if peso < 0 {
+				return nil, fmt.Errorf("%w: peso negativo %.2f", ErrPoliticaInvalida, peso)
+			}
+			soma += peso
+		}
+		if soma == 0 {
+			return nil, fmt.Errorf("%w: soma dos pesos é zero", ErrPoliticaInvalida)
+		}
+		p = MediaPonderada{Pesos: c.Pesos, Minima: minima}
+	}
+
+	// A ordem importa: recuperação altera as notas antes da média,
+	// o arredondamento age sobre a média e a frequência por último
+	if c.Recuperacao {
+		p = ComRecuperacao{Base: p}
+	}
+	if c.Arredondamento != nil {
+		if c.Arredondamento.Passo <= 0 {
+			return nil, fmt.Errorf("%w: passo de arredondamento deve ser positivo", ErrPoliticaInvalida)
+		}
+		p = ComArredondamento{Base: p, Passo: c.Arredondamento.Passo, ParaCima: c.Arredondamento.ParaCima}
+	}
+	if c.FrequenciaMinima != 0 {
+		if c.FrequenciaMinima < 0 || c.FrequenciaMinima > 100 {
+			return nil, fmt.Errorf("%w: frequencia_minima %.1f fora de 0 a 100", ErrPoliticaInvalida, c.FrequenciaMinima)
+		}
+		p = ComFrequencia{Base: p, Minima: c.FrequenciaMinima}
+	}
+	return p, nil
+}
+
+// CarregarPolitica lê uma ConfigPolitica de um arquivo JSON
+func CarregarPolitica(caminho string) (Politica, error) {
+	dados, err := os.ReadFile(caminho)
+	if err != nil {
+		return nil, fmt.Errorf("carregar política %s: %w", caminho, err)
+	}
+
+	var cfg ConfigPolitica
+	dec := json.NewDecoder(bytes.NewReader(dados))
+	dec.DisallowUnknownFields() // erro de digitação no JSON não pode passar em silêncio
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("carregar política %s: %w", caminho, err)
+	}
+
+	p, err := cfg.Politica()
+	if err != nil {
+		return nil, fmt.Errorf("carregar política %s: %w", caminho, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,167 @@
+package notas
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestPoliticas(t *testing.T) {
+	tests := []struct {
+		name      string
+		politica  Politica
+		aluno     Aluno
+		media     float64
+		aprovado  bool
+		pendencia bool
+	}{
+		{
+			name:     "padrão",
+			politica: PoliticaPadrao,
+			aluno:    Aluno{Nome: "Ana", Notas: []float64{7, 7.5}},
+			media:    7.25,
+			aprovado: true,
+		},
+		{
+			name:     "ponderada",
+			politica: MediaPonderada{Pesos: []float64{1, 3}, Minima: 6},
+			aluno:    Aluno{Nome: "Bia", Notas: []float64{2, 8}},
+			media:    6.5,
+			aprovado: true,
+		},
+		{
+			name:     "ponderada com avaliação faltando",
+			politica: MediaPonderada{Pesos: []float64{1, 1}, Minima: 6},
+			aluno:    Aluno{Nome: "Caio", Notas: []float64{10}},
+			media:    5,
+		},
+		{
+			name:     "recuperação substitui a menor",
+			politica: ComRecuperacao{Base: MediaSimples{Minima: 7}},
+			aluno:    Aluno{Nome: "Davi", Notas: []float64{4, 8, 9}, Recuperacao: ptr(7)},
+			media:    8,
+			aprovado: true,
+		},
+		{
+			name:     "recuperação menor que a nota é ignorada",
+			politica: ComRecuperacao{Base: MediaSimples{Minima: 7}},
+			aluno:    Aluno{Nome: "Eva", Notas: []float64{6, 6}, Recuperacao: ptr(5)},
+			media:    6,
+		},
+		{
+			name:      "frequência insuficiente",
+			politica:  ComFrequencia{Base: MediaSimples{Minima: 7}, Minima: 75},
+			aluno:     Aluno{Nome: "Fábio", Notas: []float64{10}, Frequencia: ptr(60)},
+			media:     10,
+			pendencia: true,
+		},
+		{
+			name:     "arredondamento para o passo mais próximo",
+			politica: ComArredondamento{Base: MediaSimples{Minima: 7}, Passo: 0.5},
+			aluno:    Aluno{Nome: "Gil", Notas: []float64{6.8, 6.8}},
+			media:    7,
+			aprovado: true,
+		},
+		{
+			name:     "arredondamento para cima",
+			politica: ComArredondamento{Base: MediaSimples{Minima: 7}, Passo: 0.5, ParaCima: true},
+			aluno:    Aluno{Nome: "Hugo", Notas: []float64{6.6}},
+			media:    7,
+			aprovado: true,
+		},
+		{
+			name:     "arredondamento exato não sobe",
+			politica: ComArredondamento{Base: MediaSimples{Minima: 7}, Passo: 0.1, ParaCima: true},
+			aluno:    Aluno{Nome: "Iara", Notas: []float64{6.9, 7.1}},
+			media:    7,
+			aprovado: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.politica.Avaliar(tt.aluno)
+			if diff := r.Media - tt.media; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Media = %.4f; esperado %.4f", r.Media, tt.media)
+			}
+			if r.Aprovado != tt.aprovado {
+				t.Errorf("Aprovado = %t; esperado %t\n%s", r.Aprovado, tt.aprovado, r.Explicar())
+			}
+			if (len(r.Pendencias) > 0) != tt.pendencia {
+				t.Errorf("Pendencias = %v", r.Pendencias)
+			}
+			if r.Explicar() == "" {
+				t.Error("Explicar() vazio")
+			}
+		})
+	}
+}
+
+func TestCarregarPolitica(t *testing.T) {
+	dir := t.TempDir()
+	escrever := func(nome, conteudo string) string {
+		caminho := filepath.Join(dir, nome)
+		if err := os.WriteFile(caminho, []byte(conteudo), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return caminho
+	}
+
+	caminho := escrever("ok.json", `{
+		"media_minima": 6,
+		"pesos": [2, 3, 5],
+		"recuperacao": true,
+		"frequencia_minima": 75,
+		"arredondamento": {"passo": 0.5}
+	}`)
+	p, err := CarregarPolitica(caminho)
+	if err != nil {
+		t.Fatalf("CarregarPolitica: %v", err)
+	}
+
+	// (4*2 + 6*3 + 7*5) / 10 = 6.1 → recuperação 8 no lugar do 4 → 6.9 → 7.0
+	aluno := Aluno{Nome: "Ana", Notas: []float64{4, 6, 7}, Recuperacao: ptr(8), Frequencia: ptr(80)}
+	r := p.Avaliar(aluno)
+	if !r.Aprovado || r.Media != 7 {
+		t.Errorf("resultado = %+v\n%s", r, r.Explicar())
+	}
+
+	aluno.Frequencia = ptr(50)
+	if r := p.Avaliar(aluno); r.Aprovado {
+		t.Errorf("frequência 50%% deveria reprovar\n%s", r.Explicar())
+	}
+
+	invalidos := map[string]string{
+		"peso negativo":   `{"pesos": [1, -1]}`,
+		"pesos zerados":   `{"pesos": [0, 0]}`,
+		"média fora":      `{"media_minima": 11}`,
+		"passo zero":      `{"arredondamento": {"passo": 0}}`,
+		"campo estranho":  `{"media_minma": 6}`,
+		"frequência fora": `{"frequencia_minima": 120}`,
+	}
+	for nome, conteudo := range invalidos {
+		if _, err := CarregarPolitica(escrever("x.json", conteudo)); err == nil {
+			t.Errorf("%s: esperado erro", nome)
+		} else if nome != "campo estranho" && !errors.Is(err, ErrPoliticaInvalida) {
+			t.Errorf("%s: erro = %v; esperado ErrPoliticaInvalida", nome, err)
+		}
+	}
+}
+
+func TestSistemaNotas_UsaPolitica(t *testing.T) {
+	s := &SistemaNotas{}
+	s.AdicionarAluno("Ana", 6, 6)
+	s.AdicionarAluno("Bia", 8, 8)
+
+	if n := len(s.Aprovados()); n != 1 {
+		t.Fatalf("política padrão: %d aprovados; esperado 1", n)
+	}
+
+	s.Politica = MediaSimples{Minima: 5}
+	if n := len(s.Aprovados()); n != 2 {
+		t.Errorf("média mínima 5: %d aprovados; esperado 2", n)
+	}
+}
@@ -0,0 +1,496 @@
+package notas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-course/modulo08-packages/locale"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+/*
+RELATÓRIOS DA TURMA
+
+Relatorio reúne, a partir de uma única avaliação de cada aluno:
+- Ranking com empates (1, 2, 2, 4 - "ranking de competição")
+- Distribuição por conceito A-F (as faixas do switch de
+  modulo02-controle/03_switch.go, na escala 0 a 10)
+- Quartis (mínimo, Q1, mediana, Q3, máximo)
+- Outliers pela regra de Tukey: abaixo de Q1 - 1,5·IQR ou acima de Q3 + 1,5·IQR
+- Comparação entre avaliações (média, mínimo, máximo e desvio de
+  cada prova, e a variação em relação à anterior)
+
+EXPORTAÇÃO:
+    r := sistema.Relatorio()
+    r.Exportar(os.Stdout, notas.FormatoMarkdown) // ou FormatoHTML, FormatoJSON
+
+As médias seguem a política de aprovação do sistema; as estatísticas
+por avaliação usam as notas originais (sem recuperação).
+*/
+
+// ErrFormatoDesconhecido indica um formato de exportação não suportado
+var ErrFormatoDesconhecido = errors.New("formato de relatório desconhecido")
+
+// Formatos de exportação do relatório
+const (
+	FormatoMarkdown = "md"
+	FormatoHTML     = "html"
+	FormatoJSON     = "json"
+)
+
+// empate é a tolerância para considerar duas médias iguais
+// (7.5 calculado de formas diferentes pode virar 7.499999999)
+const empate = 1e-9
+
+// Faixa é um conceito com sua nota mínima
+type Faixa struct {
+	Conceito  string  `json:"conceito"`
+	Minima    float64 `json:"minima"`
+	Descricao string  `json:"descricao"`
+}
+
+// Faixas de conceito, da maior para a menor
+var Faixas = []Faixa{
+	{"A", 9, "Excelente"},
+	{"B", 8, "Ótimo"},
+	{"C", 7, "Bom"},
+	{"D", 6, "Regular"},
+	{"F", 0, "Reprovado"},
+}
+
+// Conceito converte uma média (0 a 10) na letra da faixa
+func Conceito(media float64) string {
+	for _, f := range Faixas {
+		if media+empate >= f.Minima {
+			return f.Conceito
+		}
+	}
+	return Faixas[len(Faixas)-1].Conceito
+}
+
+// PosicaoRanking é uma linha do ranking
+type PosicaoRanking struct {
+	Posicao  int     `json:"posicao"`
+	Empatado bool    `json:"empatado"`
+	Aluno    string  `json:"aluno"`
+	Media    float64 `json:"media"`
+	Conceito string  `json:"conceito"`
+	Aprovado bool    `json:"aprovado"`
+}
+
+// ContagemConceito é uma barra do histograma de conceitos
+type ContagemConceito struct {
+	Faixa
+	Quantidade  int     `json:"quantidade"`
+	Porcentagem float64 `json:"porcentagem"` // fração de 0 a 1
+}
+
+// Quartis resume a distribuição das médias
+type Quartis struct {
+	Minimo  float64 `json:"minimo"`
+	Q1      float64 `json:"q1"`
+	Mediana float64 `json:"mediana"`
+	Q3      float64 `json:"q3"`
+	Maximo  float64 `json:"maximo"`
+}
+
+// IQR é a amplitude interquartil (Q3 - Q1)
+func (q Quartis) IQR() float64 {
+	return q.Q3 - q.Q1
+}
+
+// Outlier é um aluno com média fora das cercas de Tukey
+type Outlier struct {
+	Aluno string  `json:"aluno"`
+	Media float64 `json:"media"`
+	Acima bool    `json:"acima"` // false = abaixo da cerca inferior
+}
+
+// EstatisticaAvaliacao compara o desempenho da turma em uma avaliação
+type EstatisticaAvaliacao struct {
+	Numero       int     `json:"numero"` // 1 = primeira nota
+	Alunos       int     `json:"alunos"` // quantos fizeram a avaliação
+	Media        float64 `json:"media"`
+	Minima       float64 `json:"minima"`
+	Maxima       float64 `json:"maxima"`
+	DesvioPadrao float64 `json:"desvio_padrao"`
+	Variacao     float64 `json:"variacao"` // média menos a média da avaliação anterior
+}
+
+// Relatorio é a análise completa da turma
+type Relatorio struct {
+	Total        int                    `json:"total"`
+	Aprovados    int                    `json:"aprovados"`
+	MediaGeral   float64                `json:"media_geral"`
+	Ranking      []PosicaoRanking       `json:"ranking"`
+	Distribuicao []ContagemConceito     `json:"distribuicao"`
+	Quartis      Quartis                `json:"quartis"`
+	Outliers     []Outlier              `json:"outliers"`
+	Avaliacoes   []EstatisticaAvaliacao `json:"avaliacoes"`
+}
+
+// Relatorio analisa a turma, avaliando cada aluno uma única vez
+func (s *SistemaNotas) Relatorio() Relatorio {
+	resultados := s.Resultados()
+	r := Relatorio{
+		Total:        len(resultados),
+		Ranking:      ranking(resultados),
+		Distribuicao: distribuicao(resultados),
+		Outliers:     []Outlier{},
+		Avaliacoes:   avaliacoes(s.Alunos),
+	}
+	if r.Total == 0 {
+		return r
+	}
+
+	medias := make([]float64, len(resultados))
+	soma := 0.0
+	for i, res := range resultados {
+		medias[i] = res.Media
+		soma += res.Media
+		if res.Aprovado {
+			r.Aprovados++
+		}
+	}
+	r.MediaGeral = soma / float64(r.Total)
+	r.Quartis = calcularQuartis(medias)
+
+	// Com poucos alunos os quartis não dizem nada sobre valores atípicos
+	if r.Total >= 4 {
+		iqr := r.Quartis.IQR()
+		inferior, superior := r.Quartis.Q1-1.5*iqr, r.Quartis.Q3+1.5*iqr
+		for _, p := range r.Ranking {
+			if p.Media < inferior-empate || p.Media > superior+empate {
+				r.Outliers = append(r.Outliers, Outlier{Aluno: p.Aluno, Media: p.Media, Acima: p.Media > superior})
+			}
+		}
+	}
+	return r
+}
+
+// Ranking ordena a turma pela média da política, com empates
+func (s *SistemaNotas) Ranking() []PosicaoRanking {
+	return ranking(s.Resultados())
+}
+
+func ranking(resultados []Resultado) []PosicaoRanking {
+	ordenados := append([]Resultado(nil), resultados...)
+	sort.SliceStable(ordenados, func(i, j int) bool {
+		if math.Abs(ordenados[i].Media-ordenados[j].Media) > empate {
+			return ordenados[i].Media > ordenados[j].Media
+		}
+		return strings.ToLower(ordenados[i].Aluno) < strings.ToLower(ordenados[j].Aluno)
+	})
+
+	posicoes := make([]PosicaoRanking, len(ordenados))
+	for i, res := range ordenados {
+		posicoes[i] = PosicaoRanking{
+			Posicao:  i + 1,
+			Aluno:    res.Aluno,
+			Media:    res.Media,
+			Conceito: Conceito(res.Media),
+			Aprovado: res.Aprovado,
+		}
+		// Empatados dividem a posição do primeiro do grupo: 1, 2, 2, 4
+		if i > 0 && math.Abs(res.Media-posicoes[i-1].Media) <= empate {
+			posicoes[i].Posicao = posicoes[i-1].Posicao
+			posicoes[i].Empatado = true
+			posicoes[i-1].Empatado = true
+		}
+	}
+	return posicoes
+}
+
+func distribuicao(resultados []Resultado) []ContagemConceito {
+	contagem := make([]ContagemConceito, len(Faixas))
+	indice := make(map[string]int, len(Faixas))
+	for i, f := range Faixas {
+		contagem[i].Faixa = f
+		indice[f.Conceito] = i
+	}
+	for _, res := range resultados {
+		contagem[indice[Conceito(res.Media)]].Quantidade++
+	}
+	if len(resultados) > 0 {
+		for i := range contagem {
+			contagem[i].Porcentagem = float64(contagem[i].Quantidade) / float64(len(resultados))
+		}
+	}
+	return contagem
+}
+
+// calcularQuartis usa interpolação linear entre as posições
+// (o mesmo método do Excel QUARTIL e do R type 7)
+func calcularQuartis(valores []float64) Quartis {
+	ordenados := append([]float64(nil), valores...)
+	sort.Float64s(ordenados)
+	return Quartis{
+		Minimo:  ordenados[0],
+		Q1:      percentil(ordenados, 0.25),
+		Mediana: percentil(ordenados, 0.50),
+		Q3:      percentil(ordenados, 0.75),
+		Maximo:  ordenados[len(ordenados)-1],
+	}
+}
+
+// percentil espera valores já ordenados e não vazios
+func percentil(ordenados []float64, p float64) float64 {
+	pos := p * float64(len(ordenados)-1)
+	abaixo := int(math.Floor(pos))
+	if abaixo+1 >= len(ordenados) {
+		return ordenados[abaixo]
+	}
+	fracao := pos - float64(abaixo)
+	return ordenados[abaixo] + fracao*(ordenados[abaixo+1]-ordenados[abaixo])
+}
+
+func avaliacoes(alunos []Aluno) []EstatisticaAvaliacao {
+	maxNotas := 0
+	for _, a := range alunos {
+		if len(a.Notas) > maxNotas {
+			maxNotas = len(a.Notas)
+		}
+	}
+
+	estatisticas := make([]EstatisticaAvaliacao, 0, maxNotas)
+	for i := 0; i < maxNotas; i++ {
+		e := EstatisticaAvaliacao{Numero: i + 1, Minima: math.Inf(1), Maxima: math.Inf(-1)}
+		soma := 0.0
+		for _, a := range alunos {
+			if i >= len(a.Notas) {
+				continue
+			}
+			nota := a.Notas[i]
+			e.Alunos++
+			soma += nota
+			e.Minima = math.Min(e.Minima, nota)
+			e.Maxima = math.Max(e.Maxima, nota)
+		}
+		e.Media = soma / float64(e.Alunos)
+
+		quadrados := 0.0
+		for _, a := range alunos {
+			if i < len(a.Notas) {
+				quadrados += (a.Notas[i] - e.Media) * (a.Notas[i] - e.Media)
+			}
+		}
+		e.DesvioPadrao = math.Sqrt(quadrados / float64(e.Alunos))
+
+		if i > 0 {
+			e.Variacao = e.Media - estatisticas[i-1].Media
+		}
+		estatisticas = append(estatisticas, e)
+	}
+	return estatisticas
+}
+
+// MaisDificil retorna a avaliação com menor média (nil se não houver avaliações)
+func (r Relatorio) MaisDificil() *EstatisticaAvaliacao {
+	var pior *EstatisticaAvaliacao
+	for i := range r.Avaliacoes {
+		if pior == nil || r.Avaliacoes[i].Media < pior.Media {
+			pior = &r.Avaliacoes[i]
+		}
+	}
+	return pior
+}
+
+// ========================================
+// EXPORTAÇÃO
+// ========================================
+
+// Exportar escreve o relatório no formato pedido ("md", "html" ou "json")
+func (r Relatorio) Exportar(w io.Writer, formato string) error {
+	switch strings.ToLower(formato) {
+	case FormatoMarkdown, "markdown":
+		return r.EscreverMarkdown(w)
+	case FormatoHTML:
+		return r.EscreverHTML(w)
+	case FormatoJSON:
+		return r.EscreverJSON(w)
+	default:
+		return fmt.Errorf("%w: %q (use md, html ou json)", ErrFormatoDesconhecido, formato)
+	}
+}
+
+// EscreverJSON exporta o relatório como JSON indentado
+func (r Relatorio) EscreverJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// EscreverMarkdown exporta o relatório como tabelas Markdown
+func (r Relatorio) EscreverMarkdown(w io.Writer) error {
+	var b strings.Builder
+	num := func(v float64) string { return locale.PtBR.FormatarNumero(v, 2) }
+	pct := func(v float64) string { return locale.PtBR.FormatarPorcentagem(v, 1) }
+
+	b.WriteString("# Relatório da Turma\n\n")
+	fmt.Fprintf(&b, "- Alunos: %d\n", r.Total)
+	if r.Total > 0 {
+		fmt.Fprintf(&b, "- Aprovados: %d (%s)\n", r.Aprovados, pct(float64(r.Aprovados)/float64(r.Total)))
+		fmt.Fprintf(&b, "- Média geral: %s\n", num(r.MediaGeral))
+	}
+
+	b.WriteString("\n## Ranking\n\n| # | Aluno | Média | Conceito | Situação |\n|---|---|---|---|---|\n")
+	for _, p := range r.Ranking {
+		posicao := fmt.Sprint(p.Posicao)
+		if p.Empatado {
+			posicao += " (empate)"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			posicao, escaparMarkdown(p.Aluno), num(p.Media), p.Conceito, situacao(p.Aprovado))
+	}
+
+	b.WriteString("\n## Distribuição por conceito\n\n| Conceito | Faixa | Alunos | % | |\n|---|---|---|---|---|\n")
+	for _, c := range r.Distribuicao {
+		fmt.Fprintf(&b, "| %s | %s | %d | %s | %s |\n",
+			c.Conceito, c.Descricao, c.Quantidade, pct(c.Porcentagem), strings.Repeat("█", c.Quantidade))
+	}
+
+	if r.Total > 0 {
+		q := r.Quartis
+		b.WriteString("\n## Quartis\n\n| Mínimo | Q1 | Mediana | Q3 | Máximo | IQR |\n|---|---|---|---|---|---|\n")
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			num(q.Minimo), num(q.Q1), num(q.Mediana), num(q.Q3), num(q.Maximo), num(q.IQR()))
+
+		b.WriteString("\n### Outliers\n\n")
+		if len(r.Outliers) == 0 {
+			b.WriteString("Nenhum.\n")
+		}
+		for _, o := range r.Outliers {
+			fmt.Fprintf(&b, "- %s: %s (%s)\n", escaparMarkdown(o.Aluno), num(o.Media), direcao(o.Acima))
+		}
+	}
+
+	if len(r.Avaliacoes) > 0 {
+		b.WriteString("\n## Avaliações\n\n| Avaliação | Alunos | Média | Mínima | Máxima | Desvio | Variação |\n|---|---|---|---|---|---|---|\n")
+		for _, e := range r.Avaliacoes {
+			fmt.Fprintf(&b, "| %d | %d | %s | %s | %s | %s | %s |\n",
+				e.Numero, e.Alunos, num(e.Media), num(e.Minima), num(e.Maxima), num(e.DesvioPadrao), variacao(e))
+		}
+		if d := r.MaisDificil(); d != nil && len(r.Avaliacoes) > 1 {
+			fmt.Fprintf(&b, "\nAvaliação mais difícil: %d (média %s)\n", d.Numero, num(d.Media))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// EscreverHTML exporta o relatório como página HTML (nomes são escapados)
+func (r Relatorio) EscreverHTML(w io.Writer) error {
+	return modeloHTML.Execute(w, r)
+}
+
+var modeloHTML = template.Must(template.New("relatorio").Funcs(funcoesModelo).Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>Relatório da Turma</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+td:nth-child(2) { text-align: left; }
+.barra { background: #4a90d9; height: 1em; }
+.reprovado { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>Relatório da Turma</h1>
+<p>Alunos: {{.Total}}{{if .Total}} · Aprovados: {{.Aprovados}} ({{pct (fracao .Aprovados .Total)}}) · Média geral: {{num .MediaGeral}}{{end}}</p>
+
+<h2>Ranking</h2>
+<table>
+<tr><th>#</th><th>Aluno</th><th>Média</th><th>Conceito</th><th>Situação</th></tr>
+{{- range .Ranking}}
+<tr{{if not .Aprovado}} class="reprovado"{{end}}><td>{{.Posicao}}{{if .Empatado}}*{{end}}</td><td>{{.Aluno}}</td><td>{{num .Media}}</td><td>{{.Conceito}}</td><td>{{situacao .Aprovado}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Distribuição por conceito</h2>
+<table>
+<tr><th>Conceito</th><th>Faixa</th><th>Alunos</th><th>%</th><th></th></tr>
+{{- range .Distribuicao}}
+<tr><td>{{.Conceito}}</td><td>{{.Descricao}}</td><td>{{.Quantidade}}</td><td>{{pct .Porcentagem}}</td><td style="width:200px"><div class="barra" style="width:{{largura .Porcentagem}}%"></div></td></tr>
+{{- end}}
+</table>
+{{if .Total}}
+<h2>Quartis</h2>
+<table>
+<tr><th>Mínimo</th><th>Q1</th><th>Mediana</th><th>Q3</th><th>Máximo</th><th>IQR</th></tr>
+<tr><td>{{num .Quartis.Minimo}}</td><td>{{num .Quartis.Q1}}</td><td>{{num .Quartis.Mediana}}</td><td>{{num .Quartis.Q3}}</td><td>{{num .Quartis.Maximo}}</td><td>{{num .Quartis.IQR}}</td></tr>
+</table>
+<h3>Outliers</h3>
+{{if .Outliers}}<ul>
+{{- range .Outliers}}
+<li>{{.Aluno}}: {{num .Media}} ({{direcao .Acima}})</li>
+{{- end}}
+</ul>{{else}}<p>Nenhum.</p>{{end}}
+{{end}}
+{{- if .Avaliacoes}}
+<h2>Avaliações</h2>
+<table>
+<tr><th>Avaliação</th><th>Alunos</th><th>Média</th><th>Mínima</th><th>Máxima</th><th>Desvio</th><th>Variação</th></tr>
+{{- range .Avaliacoes}}
+<tr><td>{{.Numero}}</td><td>{{.Alunos}}</td><td>{{num .Media}}</td><td>{{num .Minima}}</td><td>{{num .Maxima}}</td><td>{{num .DesvioPadrao}}</td><td>{{variacao .}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// funcoesModelo são as funções disponíveis nos templates HTML de
+// relatório e de boletim
+var funcoesModelo = template.FuncMap{
+	"num":      func(v float64) string { return locale.PtBR.FormatarNumero(v, 2) },
+	"pct":      func(v float64) string { return locale.PtBR.FormatarPorcentagem(v, 1) },
+	"situacao": situacao,
+	"direcao":  direcao,
+	"variacao": variacao,
+	"largura":  func(p float64) int { return int(math.Round(p * 100)) },
+	"inc":      func(i int) int { return i + 1 },
+	"fracao": func(a, b int) float64 {
+		if b == 0 {
+			return 0
+		}
+		return float64(a) / float64(b)
+	},
+}
+
+func situacao(aprovado bool) string {
+	if aprovado {
+		return "Aprovado"
+	}
+	return "Reprovado"
+}
+
+func direcao(acima bool) string {
+	if acima {
+		return "acima da turma"
+	}
+	return "abaixo da turma"
+}
+
+func variacao(e EstatisticaAvaliacao) string {
+	if e.Numero == 1 {
+		return "-"
+	}
+	sinal := "+"
+	if e.Variacao < -0.005 { // não mostrar "-0,00"
+		sinal = "-"
+	}
+	return sinal + locale.PtBR.FormatarNumero(math.Abs(e.Variacao), 2)
+}
+
+// escaparMarkdown evita que "|" no nome quebre a tabela
+func escaparMarkdown(texto string) string {
+	return strings.ReplaceAll(texto, "|", `\|`)
+}
@@ -0,0 +1,155 @@
+package notas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func turmaRelatorio() *SistemaNotas {
+	s := &SistemaNotas{}
+	s.AdicionarAluno("Ana", 9, 10)   // 9.5  A
+	s.AdicionarAluno("Bruno", 8, 7)  // 7.5  C
+	s.AdicionarAluno("Carla", 7, 8)  // 7.5  C
+	s.AdicionarAluno("Davi", 8, 8)   // 8.0  B
+	s.AdicionarAluno("Eva", 6.5, 6)  // 6.25 D
+	s.AdicionarAluno("Fábio", 0, 1)  // 0.5  F (outlier)
+	s.AdicionarAluno("Gil", 7, 8, 9) // 8.0  B
+	return s
+}
+
+func TestConceito(t *testing.T) {
+	tests := []struct {
+		media float64
+		want  string
+	}{
+		{10, "A"}, {9, "A"}, {8.99, "B"}, {8, "B"}, {7, "C"},
+		{6.999999999999, "C"}, // ruído de ponto flutuante
+		{6, "D"}, {5.9, "F"}, {0, "F"},
+	}
+	for _, tt := range tests {
+		if got := Conceito(tt.media); got != tt.want {
+			t.Errorf("Conceito(%v) = %s; esperado %s", tt.media, got, tt.want)
+		}
+	}
+}
+
+func TestRanking_Empates(t *testing.T) {
+	ranking := turmaRelatorio().Ranking()
+
+	esperado := []struct {
+		aluno    string
+		posicao  int
+		empatado bool
+	}{
+		{"Ana", 1, false},
+		{"Davi", 2, true}, {"Gil", 2, true},
+		{"Bruno", 4, true}, {"Carla", 4, true},
+		{"Eva", 6, false},
+		{"Fábio", 7, false},
+	}
+	if len(ranking) != len(esperado) {
+		t.Fatalf("ranking com %d alunos; esperado %d", len(ranking), len(esperado))
+	}
+	for i, e := range esperado {
+		p := ranking[i]
+		if p.Aluno != e.aluno || p.Posicao != e.posicao || p.Empatado != e.empatado {
+			t.Errorf("posição %d = %+v; esperado %+v", i, p, e)
+		}
+	}
+}
+
+func TestRelatorio(t *testing.T) {
+	r := turmaRelatorio().Relatorio()
+
+	if r.Total != 7 || r.Aprovados != 5 {
+		t.Errorf("total/aprovados = %d/%d; esperado 7/5", r.Total, r.Aprovados)
+	}
+
+	contagem := map[string]int{}
+	for _, c := range r.Distribuicao {
+		contagem[c.Conceito] = c.Quantidade
+	}
+	if contagem["A"] != 1 || contagem["B"] != 2 || contagem["C"] != 2 || contagem["D"] != 1 || contagem["F"] != 1 {
+		t.Errorf("distribuição = %v", contagem)
+	}
+
+	// Médias ordenadas: 0.5 6.25 7.5 7.5 8 8 9.5
+	q := r.Quartis
+	if q.Minimo != 0.5 || q.Q1 != 6.875 || q.Mediana != 7.5 || q.Q3 != 8 || q.Maximo != 9.5 {
+		t.Errorf("quartis = %+v", q)
+	}
+	if len(r.Outliers) != 1 || r.Outliers[0].Aluno != "Fábio" || r.Outliers[0].Acima {
+		t.Errorf("outliers = %+v; esperado só Fábio abaixo", r.Outliers)
+	}
+
+	if len(r.Avaliacoes) != 3 {
+		t.Fatalf("avaliações = %d; esperado 3", len(r.Avaliacoes))
+	}
+	p1, p2, p3 := r.Avaliacoes[0], r.Avaliacoes[1], r.Avaliacoes[2]
+	if p1.Alunos != 7 || math.Abs(p1.Media-45.5/7) > 1e-9 || p1.Minima != 0 || p1.Maxima != 9 {
+		t.Errorf("avaliação 1 = %+v", p1)
+	}
+	if math.Abs(p2.Variacao-(p2.Media-p1.Media)) > 1e-9 {
+		t.Errorf("variação da avaliação 2 = %v", p2.Variacao)
+	}
+	if p3.Alunos != 1 || p3.DesvioPadrao != 0 {
+		t.Errorf("avaliação 3 = %+v", p3)
+	}
+	if d := r.MaisDificil(); d == nil || d.Numero != 1 {
+		t.Errorf("mais difícil = %+v; esperado avaliação 1", d)
+	}
+}
+
+func TestRelatorio_TurmaVazia(t *testing.T) {
+	r := (&SistemaNotas{}).Relatorio()
+	for _, formato := range []string{FormatoMarkdown, FormatoHTML, FormatoJSON} {
+		var buf bytes.Buffer
+		if err := r.Exportar(&buf, formato); err != nil {
+			t.Errorf("Exportar(%s) com turma vazia: %v", formato, err)
+		}
+	}
+}
+
+func TestRelatorio_Exportar(t *testing.T) {
+	s := turmaRelatorio()
+	s.AdicionarAluno("<b>Hugo</b> | Jr", 5)
+	r := s.Relatorio()
+
+	var md bytes.Buffer
+	if err := r.Exportar(&md, "md"); err != nil {
+		t.Fatal(err)
+	}
+	for _, trecho := range []string{"| 2 (empate) | Davi | 8,00 | B | Aprovado |", `<b>Hugo</b> \| Jr`, "Avaliação mais difícil: 1"} {
+		if !strings.Contains(md.String(), trecho) {
+			t.Errorf("Markdown sem %q:\n%s", trecho, md.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := r.Exportar(&html, "html"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html.String(), "<b>Hugo") || !strings.Contains(html.String(), "&lt;b&gt;Hugo") {
+		t.Error("HTML deveria escapar o nome do aluno")
+	}
+
+	var js bytes.Buffer
+	if err := r.Exportar(&js, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var volta Relatorio
+	if err := json.Unmarshal(js.Bytes(), &volta); err != nil {
+		t.Fatalf("JSON inválido: %v", err)
+	}
+	if volta.Total != r.Total || len(volta.Ranking) != len(r.Ranking) || volta.Distribuicao[0].Conceito != "A" {
+		t.Errorf("JSON não preserva o relatório: %+v", volta)
+	}
+
+	if err := r.Exportar(&js, "pdf"); !errors.Is(err, ErrFormatoDesconhecido) {
+		t.Errorf("formato pdf: erro = %v; esperado ErrFormatoDesconhecido", err)
+	}
+}
@@ -0,0 +1,502 @@
+package notas
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+SISTEMA SEGURO PARA CONCORRÊNCIA
+
+SistemaNotas não tem sincronização: atrás de um servidor HTTP, duas
+requisições ao mesmo tempo corrompem o slice de alunos. SistemaSeguro
+protege o sistema com sync.RWMutex, como a ContaBancaria de
+modulo05-goroutines/04_waitgroup_mutex.go:
+
+- Leituras (Alunos, Relatorio...) usam RLock e podem rodar juntas
+- Alterações usam Lock e são exclusivas
+- Nada devolvido aponta para dentro do estado: alunos são cópias
+
+AUDITORIA:
+Toda alteração recebe o autor e é gravada na Auditoria antes de ser
+aplicada. Se a gravação falhar, o sistema não muda.
+
+    log, _ := notas.AbrirAuditoria("notas.log")
+    s, _ := notas.NovoSistemaSeguro(log)     // reconstrói o estado a partir do log
+    s.AlterarNota("coord", "Ana", 0, 7.5)
+    ontem, _ := s.EstadoEm(time.Now().Add(-24 * time.Hour))
+*/
+
+// ErrIndiceNota indica uma posição de nota inexistente
+var ErrIndiceNota = errors.New("nota inexistente")
+
+// SistemaSeguro é um SistemaNotas seguro para uso concorrente e auditado
+type SistemaSeguro struct {
+	mu        sync.RWMutex
+	sistema   SistemaNotas
+	auditoria Auditoria
+	seq       int64
+	ultimo    time.Time         // instante do último registro
+	donos     map[string]string // nome em minúsculas → autor do cadastro
+	agora     func() time.Time
+}
+
+// NovoSistemaSeguro cria o sistema reaplicando o log existente
+// (um log vazio resulta em um sistema vazio)
+func NovoSistemaSeguro(auditoria Auditoria) (*SistemaSeguro, error) {
+	registros, err := auditoria.Registros()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SistemaSeguro{auditoria: auditoria, donos: make(map[string]string), agora: time.Now}
+	for _, r := range registros {
+		if err := aplicar(&s.sistema, s.donos, r); err != nil {
+			return nil, err
+		}
+		s.seq, s.ultimo = r.Seq, r.Quando
+	}
+	return s, nil
+}
+
+// DefinirPolitica troca o critério de aprovação (nil = PoliticaPadrao)
+func (s *SistemaSeguro) DefinirPolitica(p Politica) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sistema.Politica = p
+}
+
+// ========================================
+// ALTERAÇÕES (Lock + auditoria)
+// ========================================
+
+// registrar grava os registros e só então os aplica; chamar com s.mu travado.
+// Os registros são aplicados primeiro a uma cópia do estado: um registro
+// que não se aplica é recusado antes de chegar ao log, e o log nunca
+// guarda uma alteração que a memória não tem.
+func (s *SistemaSeguro) registrar(autor string, registros []RegistroAuditoria) error {
+	if strings.TrimSpace(autor) == "" {
+		return errors.New("autor da alteração não pode ser vazio")
+	}
+	// O log precisa estar em ordem de tempo para EstadoEm; se o relógio
+	// do servidor voltar, repete o instante do último registro
+	agora := s.agora().UTC()
+	if agora.Before(s.ultimo) {
+		agora = s.ultimo
+	}
+	for i := range registros {
+		registros[i].Seq = s.seq + int64(i) + 1
+		registros[i].Quando = agora
+		registros[i].Autor = autor
+	}
+	sistema, donos := copiarSistema(&s.sistema), maps.Clone(s.donos)
+	for _, r := range registros {
+		if err := aplicar(sistema, donos, r); err != nil {
+			return err
+		}
+	}
+	if err := s.auditoria.Registrar(registros...); err != nil {
+		return err
+	}
+	s.sistema, s.donos = *sistema, donos
+	s.seq += int64(len(registros))
+	s.ultimo = agora
+	return nil
+}
+
+// aplicar reproduz o registro no sistema e acompanha o dono de cada aluno
+func aplicar(sistema *SistemaNotas, donos map[string]string, r RegistroAuditoria) error {
+	if err := aplicarRegistro(sistema, r); err != nil {
+		return err
+	}
+	switch r.Acao {
+	case AcaoCadastro:
+		donos[strings.ToLower(strings.TrimSpace(r.Aluno))] = r.Autor
+	case AcaoRemocao:
+		delete(donos, strings.ToLower(strings.TrimSpace(r.Aluno)))
+	}
+	return nil
+}
+
+// AdicionarAluno cadastra um aluno com as notas iniciais
+func (s *SistemaSeguro) AdicionarAluno(autor, nome string, notas ...float64) error {
+	nome = strings.TrimSpace(nome)
+	if nome == "" {
+		return ErrNomeVazio
+	}
+	if err := validarNotas(notas); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sistema.indice(nome) >= 0 {
+		return fmt.Errorf("adicionar %q: %w", nome, ErrAlunoDuplicado)
+	}
+	registros := []RegistroAuditoria{{Acao: AcaoCadastro, Aluno: nome}}
+	for i, nota := range notas {
+		registros = append(registros, RegistroAuditoria{Acao: AcaoNota, Aluno: nome, Indice: i, Novo: copiarValor(&nota)})
+	}
+	return s.registrar(autor, registros)
+}
+
+// AdicionarNotas inclui notas no fim da lista do aluno
+func (s *SistemaSeguro) AdicionarNotas(autor, nome string, notas ...float64) error {
+	if err := validarNotas(notas); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return fmt.Errorf("adicionar notas: %w", err)
+	}
+	var registros []RegistroAuditoria
+	for i, nota := range notas {
+		registros = append(registros, RegistroAuditoria{
+			Acao: AcaoNota, Aluno: aluno.Nome, Indice: len(aluno.Notas) + i, Novo: copiarValor(&nota),
+		})
+	}
+	return s.registrar(autor, registros)
+}
+
+// AlterarNota corrige a nota na posição indice (0 = primeira)
+func (s *SistemaSeguro) AlterarNota(autor, nome string, indice int, nota float64) error {
+	if err := validarNotas([]float64{nota}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return fmt.Errorf("alterar nota: %w", err)
+	}
+	if indice < 0 || indice >= len(aluno.Notas) {
+		return fmt.Errorf("alterar nota %d de %q: %w", indice+1, aluno.Nome, ErrIndiceNota)
+	}
+	anterior := aluno.Notas[indice]
+	return s.registrar(autor, []RegistroAuditoria{{
+		Acao: AcaoNota, Aluno: aluno.Nome, Indice: indice, Anterior: &anterior, Novo: &nota,
+	}})
+}
+
+// RemoverNota apaga a nota na posição indice; as seguintes sobem uma posição
+func (s *SistemaSeguro) RemoverNota(autor, nome string, indice int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return fmt.Errorf("remover nota: %w", err)
+	}
+	if indice < 0 || indice >= len(aluno.Notas) {
+		return fmt.Errorf("remover nota %d de %q: %w", indice+1, aluno.Nome, ErrIndiceNota)
+	}
+	anterior := aluno.Notas[indice]
+	return s.registrar(autor, []RegistroAuditoria{{
+		Acao: AcaoNota, Aluno: aluno.Nome, Indice: indice, Anterior: &anterior,
+	}})
+}
+
+// DefinirFrequencia registra a frequência (0 a 100%) de um aluno
+func (s *SistemaSeguro) DefinirFrequencia(autor, nome string, porcentagem float64) error {
+	if err := validarFrequencia(porcentagem); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return fmt.Errorf("definir frequência: %w", err)
+	}
+	return s.registrar(autor, []RegistroAuditoria{{
+		Acao: AcaoFrequencia, Aluno: aluno.Nome, Anterior: copiarValor(aluno.Frequencia), Novo: &porcentagem,
+	}})
+}
+
+// DefinirRecuperacao registra a nota de recuperação de um aluno
+func (s *SistemaSeguro) DefinirRecuperacao(autor, nome string, nota float64) error {
+	if err := validarNotas([]float64{nota}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return fmt.Errorf("definir recuperação: %w", err)
+	}
+	return s.registrar(autor, []RegistroAuditoria{{
+		Acao: AcaoRecuperacao, Aluno: aluno.Nome, Anterior: copiarValor(aluno.Recuperacao), Novo: &nota,
+	}})
+}
+
+// RemoverAluno remove o aluno. A auditoria guarda cada nota apagada,
+// para que os valores antigos continuem consultáveis.
+func (s *SistemaSeguro) RemoverAluno(autor, nome string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return fmt.Errorf("remover: %w", err)
+	}
+
+	var registros []RegistroAuditoria
+	// Da última para a primeira, para os índices continuarem válidos
+	for i := len(aluno.Notas) - 1; i >= 0; i-- {
+		registros = append(registros, RegistroAuditoria{
+			Acao: AcaoNota, Aluno: aluno.Nome, Indice: i, Anterior: copiarValor(&aluno.Notas[i]),
+		})
+	}
+	if aluno.Frequencia != nil {
+		registros = append(registros, RegistroAuditoria{Acao: AcaoFrequencia, Aluno: aluno.Nome, Anterior: copiarValor(aluno.Frequencia)})
+	}
+	if aluno.Recuperacao != nil {
+		registros = append(registros, RegistroAuditoria{Acao: AcaoRecuperacao, Aluno: aluno.Nome, Anterior: copiarValor(aluno.Recuperacao)})
+	}
+	registros = append(registros, RegistroAuditoria{Acao: AcaoRemocao, Aluno: aluno.Nome})
+	return s.registrar(autor, registros)
+}
+
+// Substituir troca notas, frequência e recuperação do aluno de uma vez
+// (nil apaga frequência/recuperação). Só as diferenças vão para a auditoria.
+func (s *SistemaSeguro) Substituir(autor string, novo Aluno) error {
+	if err := validarAluno(novo); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aluno, err := s.aluno(novo.Nome)
+	if err != nil {
+		return fmt.Errorf("substituir: %w", err)
+	}
+
+	var registros []RegistroAuditoria
+	nota := func(i int, anterior, valor *float64) {
+		registros = append(registros, RegistroAuditoria{Acao: AcaoNota, Aluno: aluno.Nome, Indice: i, Anterior: anterior, Novo: valor})
+	}
+	for i := range novo.Notas {
+		switch {
+		case i >= len(aluno.Notas):
+			nota(i, nil, copiarValor(&novo.Notas[i]))
+		case aluno.Notas[i] != novo.Notas[i]:
+			nota(i, copiarValor(&aluno.Notas[i]), copiarValor(&novo.Notas[i]))
+		}
+	}
+	// Notas que sobraram saem da última para a primeira
+	for i := len(aluno.Notas) - 1; i >= len(novo.Notas); i-- {
+		nota(i, copiarValor(&aluno.Notas[i]), nil)
+	}
+	if !mesmoValor(aluno.Frequencia, novo.Frequencia) {
+		registros = append(registros, RegistroAuditoria{
+			Acao: AcaoFrequencia, Aluno: aluno.Nome, Anterior: copiarValor(aluno.Frequencia), Novo: copiarValor(novo.Frequencia),
+		})
+	}
+	if !mesmoValor(aluno.Recuperacao, novo.Recuperacao) {
+		registros = append(registros, RegistroAuditoria{
+			Acao: AcaoRecuperacao, Aluno: aluno.Nome, Anterior: copiarValor(aluno.Recuperacao), Novo: copiarValor(novo.Recuperacao),
+		})
+	}
+	if len(registros) == 0 {
+		return nil
+	}
+	return s.registrar(autor, registros)
+}
+
+func mesmoValor(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Cadastrar cria um aluno completo (notas, frequência e recuperação)
+// em uma única gravação na auditoria
+func (s *SistemaSeguro) Cadastrar(autor string, a Aluno) error {
+	return s.Importar(autor, &SistemaNotas{Alunos: []Aluno{a}})
+}
+
+// Importar cadastra todos os alunos de um SistemaNotas (ex.: carregado
+// de notas.json) em uma única gravação na auditoria: ou todos ou nenhum
+func (s *SistemaSeguro) Importar(autor string, origem *SistemaNotas) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var registros []RegistroAuditoria
+	vistos := make(map[string]bool)
+	for _, a := range origem.Alunos {
+		nome := strings.TrimSpace(a.Nome)
+		if nome == "" {
+			return ErrNomeVazio
+		}
+		chave := strings.ToLower(nome)
+		if s.sistema.indice(nome) >= 0 || vistos[chave] {
+			return fmt.Errorf("adicionar %q: %w", nome, ErrAlunoDuplicado)
+		}
+		if err := validarAluno(a); err != nil {
+			return fmt.Errorf("adicionar %q: %w", nome, err)
+		}
+		vistos[chave] = true
+
+		registros = append(registros, RegistroAuditoria{Acao: AcaoCadastro, Aluno: nome})
+		for i := range a.Notas {
+			registros = append(registros, RegistroAuditoria{Acao: AcaoNota, Aluno: nome, Indice: i, Novo: copiarValor(&a.Notas[i])})
+		}
+		if a.Frequencia != nil {
+			registros = append(registros, RegistroAuditoria{Acao: AcaoFrequencia, Aluno: nome, Novo: copiarValor(a.Frequencia)})
+		}
+		if a.Recuperacao != nil {
+			registros = append(registros, RegistroAuditoria{Acao: AcaoRecuperacao, Aluno: nome, Novo: copiarValor(a.Recuperacao)})
+		}
+	}
+	if len(registros) == 0 {
+		return nil
+	}
+	return s.registrar(autor, registros)
+}
+
+// aluno busca o aluno no estado interno; chamar com s.mu travado
+func (s *SistemaSeguro) aluno(nome string) (*Aluno, error) {
+	i := s.sistema.indice(nome)
+	if i < 0 {
+		return nil, fmt.Errorf("%q: %w", nome, ErrAlunoNaoEncontrado)
+	}
+	return &s.sistema.Alunos[i], nil
+}
+
+// ========================================
+// LEITURAS (RLock, sempre cópias)
+// ========================================
+
+// Copia retorna um SistemaNotas independente com o estado atual,
+// para salvar em disco, gerar relatórios ou boletins
+func (s *SistemaSeguro) Copia() *SistemaNotas {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copiarSistema(&s.sistema)
+}
+
+// Alunos retorna uma cópia da lista de alunos
+func (s *SistemaSeguro) Alunos() []Aluno {
+	return s.Copia().Alunos
+}
+
+// BuscarAluno retorna uma cópia do aluno
+func (s *SistemaSeguro) BuscarAluno(nome string) (Aluno, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return Aluno{}, fmt.Errorf("buscar: %w", err)
+	}
+	return copiarAluno(*aluno), nil
+}
+
+// Dono retorna o autor do cadastro do aluno (quem o criou)
+func (s *SistemaSeguro) Dono(nome string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return "", fmt.Errorf("dono: %w", err)
+	}
+	return s.donos[strings.ToLower(aluno.Nome)], nil
+}
+
+// Avaliar aplica a política do sistema a um aluno
+func (s *SistemaSeguro) Avaliar(nome string) (Resultado, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return Resultado{}, fmt.Errorf("avaliar: %w", err)
+	}
+	return s.sistema.Avaliar(*aluno), nil
+}
+
+// BuscarAvaliado retorna uma cópia do aluno e a avaliação dele, lidas
+// sob a mesma trava: BuscarAluno seguido de Avaliar poderia ver uma
+// alteração (ou remoção) no meio
+func (s *SistemaSeguro) BuscarAvaliado(nome string) (Aluno, Resultado, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	aluno, err := s.aluno(nome)
+	if err != nil {
+		return Aluno{}, Resultado{}, fmt.Errorf("buscar: %w", err)
+	}
+	return copiarAluno(*aluno), s.sistema.Avaliar(*aluno), nil
+}
+
+// Relatorio analisa a turma (veja SistemaNotas.Relatorio)
+func (s *SistemaSeguro) Relatorio() Relatorio {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sistema.Relatorio()
+}
+
+// Auditoria retorna o log completo de alterações
+func (s *SistemaSeguro) Auditoria() ([]RegistroAuditoria, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.auditoria.Registros()
+}
+
+// HistoricoAluno retorna as alterações de um aluno, da mais antiga à mais recente
+func (s *SistemaSeguro) HistoricoAluno(nome string) ([]RegistroAuditoria, error) {
+	registros, err := s.Auditoria()
+	if err != nil {
+		return nil, err
+	}
+	var historico []RegistroAuditoria
+	for _, r := range registros {
+		if strings.EqualFold(r.Aluno, strings.TrimSpace(nome)) {
+			historico = append(historico, r)
+		}
+	}
+	return historico, nil
+}
+
+// EstadoEm reconstrói o sistema como estava no instante t,
+// reaplicando o log desde o início
+func (s *SistemaSeguro) EstadoEm(t time.Time) (*SistemaNotas, error) {
+	s.mu.RLock()
+	politica := s.sistema.Politica
+	registros, err := s.auditoria.Registros()
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	estado := &SistemaNotas{Politica: politica}
+	for _, r := range registros {
+		if r.Quando.After(t) {
+			break // o log está em ordem de gravação
+		}
+		if err := aplicarRegistro(estado, r); err != nil {
+			return nil, err
+		}
+	}
+	return estado, nil
+}
+
+func copiarSistema(s *SistemaNotas) *SistemaNotas {
+	copia := &SistemaNotas{Politica: s.Politica, Alunos: make([]Aluno, len(s.Alunos))}
+	for i, a := range s.Alunos {
+		copia.Alunos[i] = copiarAluno(a)
+	}
+	return copia
+}
+
+func copiarAluno(a Aluno) Aluno {
+	a.Notas = append([]float64(nil), a.Notas...)
+	a.Frequencia = copiarValor(a.Frequencia)
+	a.Recuperacao = copiarValor(a.Recuperacao)
+	return a
+}
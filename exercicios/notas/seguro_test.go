@@ -0,0 +1,401 @@
+package notas
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// relogio devolve instantes controlados pelo teste
+type relogio struct{ t time.Time }
+
+func (r *relogio) agora() time.Time { return r.t }
+
+func (r *relogio) avancar(d time.Duration) { r.t = r.t.Add(d) }
+
+func novoSeguroTeste(t *testing.T, auditoria Auditoria) (*SistemaSeguro, *relogio) {
+	t.Helper()
+	s, err := NovoSistemaSeguro(auditoria)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &relogio{t: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+	s.agora = r.agora
+	return s, r
+}
+
+func TestSistemaSeguro_AuditoriaEEstadoEm(t *testing.T) {
+	s, rel := novoSeguroTeste(t, &AuditoriaMemoria{})
+	inicio := rel.t
+
+	if err := s.AdicionarAluno("prof", "Ana", 6, 7); err != nil {
+		t.Fatal(err)
+	}
+	rel.avancar(24 * time.Hour)
+	depoisDoCadastro := rel.t
+	if err := s.AlterarNota("coord", "ana", 0, 8); err != nil {
+		t.Fatal(err)
+	}
+	rel.avancar(24 * time.Hour)
+	s.DefinirFrequencia("prof", "Ana", 90)
+	s.AdicionarAluno("prof", "Bruno", 5)
+	rel.avancar(24 * time.Hour)
+	s.RemoverAluno("coord", "Bruno")
+
+	historico, err := s.HistoricoAluno("Ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(historico) != 5 {
+		t.Fatalf("histórico de Ana com %d registros: %v", len(historico), historico)
+	}
+	alteracao := historico[3]
+	if alteracao.Autor != "coord" || *alteracao.Anterior != 6 || *alteracao.Novo != 8 || alteracao.Indice != 0 {
+		t.Errorf("registro da alteração = %v", alteracao)
+	}
+
+	// Estado de cada momento
+	antes, _ := s.EstadoEm(inicio.Add(-time.Second))
+	if len(antes.Alunos) != 0 {
+		t.Errorf("antes do cadastro: %+v", antes.Alunos)
+	}
+	dia1, _ := s.EstadoEm(depoisDoCadastro.Add(-time.Second))
+	if a, _ := dia1.BuscarAluno("Ana"); a == nil || a.Notas[0] != 6 {
+		t.Errorf("no dia 1 a primeira nota de Ana era 6: %+v", dia1.Alunos)
+	}
+	dia3, _ := s.EstadoEm(rel.t.Add(-time.Second))
+	if len(dia3.Alunos) != 2 {
+		t.Errorf("no dia 3 Bruno ainda existia: %+v", dia3.Alunos)
+	}
+	atual, _ := s.EstadoEm(rel.t)
+	if len(atual.Alunos) != 1 || atual.Alunos[0].Notas[0] != 8 || *atual.Alunos[0].Frequencia != 90 {
+		t.Errorf("estado atual = %+v", atual.Alunos)
+	}
+
+	// A remoção guardou as notas apagadas
+	bruno, _ := s.HistoricoAluno("Bruno")
+	if len(bruno) != 4 || bruno[2].Anterior == nil || *bruno[2].Anterior != 5 || bruno[3].Acao != AcaoRemocao {
+		t.Errorf("histórico de Bruno = %v", bruno)
+	}
+}
+
+func TestSistemaSeguro_CopiasIndependentes(t *testing.T) {
+	s, _ := novoSeguroTeste(t, &AuditoriaMemoria{})
+	s.AdicionarAluno("prof", "Ana", 7)
+
+	aluno, _ := s.BuscarAluno("Ana")
+	aluno.Notas[0] = 0
+	copia := s.Copia()
+	copia.Alunos[0].Notas[0] = 0
+
+	avaliado, resultado, err := s.BuscarAvaliado("ana")
+	if err != nil || resultado.Media != 7 {
+		t.Fatalf("BuscarAvaliado = %v, %v", resultado, err)
+	}
+	avaliado.Notas[0] = 0
+
+	if atual, _ := s.BuscarAluno("Ana"); atual.Notas[0] != 7 {
+		t.Errorf("alterar uma cópia mudou o sistema: %v", atual.Notas)
+	}
+	if _, _, err := s.BuscarAvaliado("Bruno"); !errors.Is(err, ErrAlunoNaoEncontrado) {
+		t.Errorf("BuscarAvaliado de inexistente: %v", err)
+	}
+}
+
+func TestSistemaSeguro_Dono(t *testing.T) {
+	auditoria := &AuditoriaMemoria{}
+	s, _ := novoSeguroTeste(t, auditoria)
+	s.AdicionarAluno("prof.ana", "Bia", 7)
+	if dono, err := s.Dono(" bia "); err != nil || dono != "prof.ana" {
+		t.Fatalf("Dono = %q, %v", dono, err)
+	}
+
+	// Removido e recriado por outro: o dono muda junto
+	s.RemoverAluno("diretor", "Bia")
+	if _, err := s.Dono("Bia"); !errors.Is(err, ErrAlunoNaoEncontrado) {
+		t.Errorf("Dono de removido: %v", err)
+	}
+	s.AdicionarAluno("prof.rui", "Bia", 5)
+	if dono, _ := s.Dono("Bia"); dono != "prof.rui" {
+		t.Errorf("Dono depois de recriar = %q", dono)
+	}
+
+	// Reconstruído da auditoria, o dono é o mesmo
+	reaberto, err := NovoSistemaSeguro(auditoria)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dono, _ := reaberto.Dono("Bia"); dono != "prof.rui" {
+		t.Errorf("Dono reconstruído = %q", dono)
+	}
+}
+
+func TestSistemaSeguro_Validacoes(t *testing.T) {
+	s, _ := novoSeguroTeste(t, &AuditoriaMemoria{})
+	s.AdicionarAluno("prof", "Ana", 7)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nota inválida", s.AlterarNota("prof", "Ana", 0, 11), ErrNotaInvalida},
+		{"índice inexistente", s.AlterarNota("prof", "Ana", 3, 5), ErrIndiceNota},
+		{"aluno inexistente", s.AdicionarNotas("prof", "Zé", 5), ErrAlunoNaoEncontrado},
+		{"duplicado", s.AdicionarAluno("prof", "ANA"), ErrAlunoDuplicado},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: erro = %v; esperado %v", tt.name, tt.err, tt.want)
+		}
+	}
+	if err := s.AdicionarNotas("", "Ana", 5); err == nil {
+		t.Error("alteração sem autor deveria falhar")
+	}
+
+	registros, _ := s.Auditoria()
+	if len(registros) != 2 {
+		t.Errorf("operações rejeitadas não devem ir para a auditoria: %v", registros)
+	}
+}
+
+// auditoriaQuebrada simula um disco cheio
+type auditoriaQuebrada struct{ AuditoriaMemoria }
+
+func (a *auditoriaQuebrada) Registrar(...RegistroAuditoria) error {
+	return errors.New("disco cheio")
+}
+
+func TestSistemaSeguro_FalhaNaAuditoriaNaoAltera(t *testing.T) {
+	s, _ := novoSeguroTeste(t, &auditoriaQuebrada{})
+	if err := s.AdicionarAluno("prof", "Ana", 7); err == nil {
+		t.Fatal("esperado erro da auditoria")
+	}
+	if len(s.Alunos()) != 0 {
+		t.Error("sistema mudou sem registro na auditoria")
+	}
+}
+
+func TestSistemaSeguro_RegistroInaplicavelNaoVaiAoLog(t *testing.T) {
+	auditoria := &AuditoriaMemoria{}
+	s, _ := novoSeguroTeste(t, auditoria)
+	s.AdicionarAluno("prof", "Ana", 7)
+
+	// O segundo registro falha ao ser aplicado: nenhum dos dois é gravado
+	novo := 5.0
+	s.mu.Lock()
+	err := s.registrar("prof", []RegistroAuditoria{
+		{Acao: AcaoNota, Aluno: "Ana", Indice: 1, Novo: &novo},
+		{Acao: AcaoNota, Aluno: "Bruno", Novo: &novo},
+	})
+	s.mu.Unlock()
+	if !errors.Is(err, ErrAuditoriaInconsistente) {
+		t.Fatalf("erro = %v; esperado ErrAuditoriaInconsistente", err)
+	}
+	if registros, _ := auditoria.Registros(); len(registros) != 2 {
+		t.Errorf("log com %d registros; esperado 2", len(registros))
+	}
+	if ana, _ := s.BuscarAluno("Ana"); len(ana.Notas) != 1 {
+		t.Errorf("memória mudou: %v", ana.Notas)
+	}
+	// A sequência não pulou
+	s.AdicionarNotas("prof", "Ana", 8)
+	if registros, _ := auditoria.Registros(); registros[len(registros)-1].Seq != 3 {
+		t.Errorf("registros = %v", registros)
+	}
+}
+
+func TestSistemaSeguro_Concorrencia(t *testing.T) {
+	s, _ := novoSeguroTeste(t, &AuditoriaMemoria{})
+	const alunos, notasPorAluno = 10, 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < alunos; i++ {
+		nome := fmt.Sprintf("Aluno %d", i)
+		if err := s.AdicionarAluno("prof", nome); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < notasPorAluno; j++ {
+				s.AdicionarNotas("prof", nome, float64(j%11))
+			}
+		}()
+		go func() { // leitores em paralelo com os escritores
+			defer wg.Done()
+			for j := 0; j < notasPorAluno; j++ {
+				s.Relatorio()
+				s.BuscarAluno(nome)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, a := range s.Alunos() {
+		if len(a.Notas) != notasPorAluno {
+			t.Errorf("%s com %d notas; esperado %d", a.Nome, len(a.Notas), notasPorAluno)
+		}
+	}
+	registros, _ := s.Auditoria()
+	for i, r := range registros {
+		if r.Seq != int64(i+1) {
+			t.Fatalf("registro %d com seq %d", i, r.Seq)
+		}
+	}
+}
+
+func TestAuditoriaArquivo_Reconstrucao(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "notas.log")
+	log, err := AbrirAuditoria(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := novoSeguroTeste(t, log)
+	inicial := &SistemaNotas{}
+	inicial.AdicionarAluno("Ana", 7, 8)
+	inicial.AdicionarAluno("Bruno", 5)
+	inicial.DefinirRecuperacao("Bruno", 6)
+	if err := s.Importar("migracao", inicial); err != nil {
+		t.Fatal(err)
+	}
+	s.RemoverNota("prof", "Ana", 0)
+	log.Close()
+
+	// Reabrir o arquivo reconstrói o mesmo estado
+	log, err = AbrirAuditoria(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	reaberto, err := NovoSistemaSeguro(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alunos := reaberto.Alunos()
+	if len(alunos) != 2 || len(alunos[0].Notas) != 1 || alunos[0].Notas[0] != 8 || *alunos[1].Recuperacao != 6 {
+		t.Errorf("estado reconstruído = %+v", alunos)
+	}
+	// A sequência continua de onde parou
+	reaberto.AdicionarNotas("prof", "Bruno", 9)
+	registros, _ := reaberto.Auditoria()
+	if ultimo := registros[len(registros)-1]; ultimo.Seq != int64(len(registros)) {
+		t.Errorf("seq do último registro = %d; esperado %d", ultimo.Seq, len(registros))
+	}
+
+	// Linha corrompida aponta o número da linha
+	os.WriteFile(caminho, []byte("{\"seq\":1}\n{quebrado\n"), 0o644)
+	_, err = log.Registros()
+	var pe *erros.ErroProcessamento
+	if !errors.As(err, &pe) || pe.Linha != 2 {
+		t.Errorf("erro = %v; esperado ErroProcessamento na linha 2", err)
+	}
+}
+
+func TestAuditoriaArquivo_EscritaInterrompida(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "notas.log")
+	log, err := AbrirAuditoria(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := novoSeguroTeste(t, log)
+	s.AdicionarAluno("prof", "Ana", 7, 8) // cadastro + 2 notas numa linha
+	s.AdicionarNotas("prof", "Ana", 9)
+
+	// Queda no meio da próxima gravação: a linha fica sem \n
+	arquivo, _ := os.OpenFile(caminho, os.O_WRONLY|os.O_APPEND, 0)
+	arquivo.WriteString(`[{"seq":5,"acao":"cadastro","aluno":"Bruno"},{"seq":6,"ac`)
+	arquivo.Close()
+
+	// Ler não altera o arquivo: o pedaço só fica de fora
+	antes, _ := os.ReadFile(caminho)
+	if registros, err := log.Registros(); err != nil || len(registros) != 4 {
+		t.Errorf("registros = %v, %v", registros, err)
+	}
+	if depois, _ := os.ReadFile(caminho); !bytes.Equal(antes, depois) {
+		t.Error("Registros alterou o arquivo")
+	}
+	log.Close()
+
+	// Reabrir corta o pedaço: a gravação seguinte começa numa linha nova
+	log, err = AbrirAuditoria(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	reaberto, err := NovoSistemaSeguro(log)
+	if err != nil {
+		t.Fatalf("reabrir depois da queda: %v", err)
+	}
+	alunos := reaberto.Alunos()
+	if len(alunos) != 1 || len(alunos[0].Notas) != 3 {
+		t.Errorf("estado = %+v; esperado só Ana com 3 notas", alunos)
+	}
+	if err := reaberto.AdicionarAluno("prof", "Bruno"); err != nil {
+		t.Fatal(err)
+	}
+	registros, err := log.Registros()
+	if err != nil || len(registros) != 5 || registros[4].Aluno != "Bruno" || registros[4].Seq != 5 {
+		t.Errorf("registros = %v, %v", registros, err)
+	}
+}
+
+func TestAuditoriaArquivo_FalhaNaGravacao(t *testing.T) {
+	// /dev/full recusa toda escrita e não pode ser cortado
+	log, err := AbrirAuditoria("/dev/full")
+	if err != nil {
+		t.Skipf("sem /dev/full: %v", err)
+	}
+	defer log.Close()
+	registro := RegistroAuditoria{Seq: 1, Acao: AcaoCadastro, Aluno: "Ana"}
+	if err := log.Registrar(registro); err == nil {
+		t.Fatal("gravação em /dev/full deveria falhar")
+	}
+	// Sem conseguir desfazer, o fim do arquivo não é confiável
+	if err := log.Registrar(registro); err == nil || !strings.Contains(err.Error(), "cortar auditoria") {
+		t.Errorf("segunda gravação: erro = %v; esperado a falha do corte", err)
+	}
+}
+
+func TestAplicarRegistro_Inconsistente(t *testing.T) {
+	s := &SistemaNotas{}
+	novo := 7.0
+	err := aplicarRegistro(s, RegistroAuditoria{Seq: 1, Acao: AcaoNota, Aluno: "Ana", Novo: &novo})
+	if !errors.Is(err, ErrAuditoriaInconsistente) {
+		t.Errorf("nota de aluno inexistente: erro = %v", err)
+	}
+}
+
+func TestSistemaSeguro_Substituir(t *testing.T) {
+	s, _ := novoSeguroTeste(t, &AuditoriaMemoria{})
+	s.AdicionarAluno("prof", "Ana", 5, 6, 7)
+	s.DefinirFrequencia("prof", "Ana", 80)
+
+	rec := 9.0
+	if err := s.Substituir("coord", Aluno{Nome: "ana", Notas: []float64{5, 8}, Recuperacao: &rec}); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := s.BuscarAluno("Ana")
+	if len(a.Notas) != 2 || a.Notas[1] != 8 || a.Frequencia != nil || *a.Recuperacao != 9 {
+		t.Errorf("aluno após Substituir = %+v", a)
+	}
+
+	// Alteração, remoção da nota 3, frequência apagada e recuperação definida
+	historico, _ := s.HistoricoAluno("Ana")
+	if novos := historico[5:]; len(novos) != 4 {
+		t.Errorf("registros do Substituir = %v", novos)
+	}
+
+	// Sem diferença, nada é registrado
+	s.Substituir("coord", a)
+	if depois, _ := s.HistoricoAluno("Ana"); len(depois) != len(historico) {
+		t.Errorf("Substituir sem mudanças gerou %d registro(s)", len(depois)-len(historico))
+	}
+}
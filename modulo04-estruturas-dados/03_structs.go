@@ -211,6 +211,7 @@ func (p Pessoa) fazerAniversario() {
 }
 
 // Struct para conta bancária
+// (float64 acumula erros de centavos; veja modulo08-packages/dinheiro)
 type ContaBancaria struct {
 	Titular string
 	Saldo   float64
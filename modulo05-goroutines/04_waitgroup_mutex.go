@@ -151,6 +151,7 @@ func exemploBancoComMutex() {
 }
 
 // Conta bancária thread-safe
+// (float64 acumula erros de centavos; veja modulo08-packages/dinheiro)
 type ContaBancaria struct {
 	mu    sync.RWMutex
 	saldo float64
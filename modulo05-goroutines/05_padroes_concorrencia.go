@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"go-course/modulo12-http/metricas"
 	"math/rand"
+	"os"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -41,9 +44,19 @@ func exemploWorkerPool() {
 	jobs := make(chan int, numJobs)
 	results := make(chan int, numJobs)
 
+	// Métricas do pool (em um servidor, expostas em /metrics; veja
+	// modulo12-http/metricas): o tamanho da fila é lido na coleta
+	reg := metricas.NovoRegistro()
+	reg.NovoMedidorFunc("pool_fila", "Jobs esperando um worker", func() float64 {
+		return float64(len(jobs))
+	})
+	processados := reg.NovoContador("pool_jobs_processados_total", "Jobs concluídos", "worker")
+	duracao := reg.NovoHistograma("pool_job_duracao_segundos", "Tempo de cada job",
+		metricas.LimitesLineares(0.05, 0.05, 6))
+
 	// Iniciar workers
 	for w := 1; w <= numWorkers; w++ {
-		go worker(w, jobs, results)
+		go worker(w, jobs, results, processados.Com(strconv.Itoa(w)), duracao)
 	}
 
 	// Enviar jobs
@@ -56,12 +69,18 @@ func exemploWorkerPool() {
 	for r := 1; r <= numJobs; r++ {
 		<-results
 	}
+
+	fmt.Println("\n  Métricas no formato do Prometheus:")
+	reg.Escrever(os.Stdout)
 }
 
-func worker(id int, jobs <-chan int, results chan<- int) {
+func worker(id int, jobs <-chan int, results chan<- int, processados *metricas.Contador, duracao *metricas.Histograma) {
 	for j := range jobs {
+		inicio := time.Now()
 		fmt.Printf("  Worker %d processando job %d\n", id, j)
 		time.Sleep(time.Duration(rand.Intn(300)) * time.Millisecond)
+		duracao.ObservarDesde(inicio)
+		processados.Incrementar()
 		results <- j * 2
 	}
 }
@@ -204,8 +223,13 @@ func exemploTimeout() {
 // PADRÃO 5: RATE LIMITING
 // ========================================
 func exemploRateLimiting() {
-	// Limitar a 1 requisição por 200ms
-	limiter := time.Tick(200 * time.Millisecond)
+	// Limitar a 1 requisição por 200ms. NewTicker + Stop em vez de
+	// time.Tick: o ticker é liberado quando a função termina.
+	// Para limitar por cliente e com rajadas, veja
+	// modulo12-http/limitador (token bucket, janela deslizante, GCRA).
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	limiter := ticker.C
 
 	requests := make(chan int, 5)
 	for i := 1; i <= 5; i++ {
@@ -227,6 +251,7 @@ RESUMO DOS PADRÕES:
    - Número fixo de workers
    - Jobs distribuídos entre workers
    - Uso: processar muitas tarefas com recursos limitados
+   - Observe: tamanho da fila (len do canal) e jobs processados
 
 2. FAN-OUT / FAN-IN
    - Fan-out: distribuir trabalho para múltiplos workers
@@ -245,7 +270,7 @@ RESUMO DOS PADRÕES:
 
 5. RATE LIMITING
    - Limitar taxa de execução
-   - Usar time.Tick
+   - time.NewTicker (lembre do Stop); por cliente: modulo12-http/limitador
    - Uso: controlar uso de APIs, recursos
 
 OUTROS PADRÕES IMPORTANTES:
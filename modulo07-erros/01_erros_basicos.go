@@ -137,6 +137,8 @@ type Usuario struct {
 	Idade int
 }
 
+// Validar para no primeiro problema. Para devolver todos de uma vez,
+// com as regras nas tags da struct, veja modulo15-avancado/validar
 func (u Usuario) Validar() error {
 	if u.Nome == "" {
 		return errors.New("nome é obrigatório")
@@ -88,6 +88,10 @@ func main() {
 // ERRO CUSTOMIZADO: VALIDAÇÃO
 // ========================================
 
+// ErroValidacao, ErroHTTP e ErroBanco também estão no package
+// modulo07-erros/erros; modulo12-http/problema os transforma em
+// respostas HTTP application/problem+json
+
 type ErroValidacao struct {
 	Campo    string
 	Valor    string
@@ -244,6 +248,9 @@ func exemploErroBanco() {
 // SENTINEL ERRORS (Erros Predefinidos)
 // ========================================
 
+// Os mesmos três estão no package modulo07-erros/erros, para uso fora
+// deste exemplo: modulo12-http/autorizacao retorna ErrNaoAutorizado
+// embrulhado, e aí só errors.Is o reconhece (o switch abaixo não)
 var (
 	ErrNaoEncontrado     = errors.New("recurso não encontrado")
 	ErrNaoAutorizado     = errors.New("não autorizado")
@@ -0,0 +1,95 @@
+package erros
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+PACKAGE ERROS
+
+Tipos de erro dos exemplos do módulo 07 em um package importável,
+para que outros módulos (notas, HTTP...) usem os mesmos tipos
+com errors.Is e errors.As.
+
+Origem de cada tipo:
+- ErroProcessamento e sentinels de arquivo: 04_error_wrapping.go
+- ErrNaoEncontrado, ErrNaoAutorizado, ErrParametroInvalido: 02_erros_customizados.go
+- ErroValidacao, ErroHTTP, ErroBanco: 02_erros_customizados.go (ErroBanco
+  ganhou Unwrap, para errors.Is enxergar o Detalhe)
+
+modulo12-http/problema converte todos eles em respostas HTTP.
+*/
+
+// Sentinels de processamento de arquivos
+var (
+	ErrArquivoNaoEncontrado = errors.New("arquivo não encontrado")
+	ErrPermissaoNegada      = errors.New("permissão negada")
+	ErrFormatoInvalido      = errors.New("formato inválido")
+)
+
+// Sentinels de acesso a recursos. ErrNaoAutorizado costuma vir
+// embrulhado (ex.: *autorizacao.ErroAcesso, em modulo12-http), então
+// compare com errors.Is, não com ==
+var (
+	ErrNaoEncontrado     = errors.New("recurso não encontrado")
+	ErrNaoAutorizado     = errors.New("não autorizado")
+	ErrParametroInvalido = errors.New("parâmetro inválido")
+)
+
+// ErroProcessamento indica falha em uma linha específica de um arquivo
+type ErroProcessamento struct {
+	Arquivo string
+	Linha   int
+	Erro    error
+}
+
+func (e *ErroProcessamento) Error() string {
+	return fmt.Sprintf("erro ao processar %s (linha %d): %v",
+		e.Arquivo, e.Linha, e.Erro)
+}
+
+func (e *ErroProcessamento) Unwrap() error {
+	return e.Erro
+}
+
+// ErroValidacao indica um valor inválido em um campo de entrada.
+// Vários podem ser juntados com errors.Join (um por campo).
+type ErroValidacao struct {
+	Campo    string
+	Valor    string
+	Mensagem string
+}
+
+func (e ErroValidacao) Error() string {
+	return fmt.Sprintf("validação falhou no campo '%s' (valor: '%s'): %s",
+		e.Campo, e.Valor, e.Mensagem)
+}
+
+// ErroHTTP indica uma resposta de erro de um serviço HTTP externo
+type ErroHTTP struct {
+	StatusCode int
+	Mensagem   string
+	URL        string
+}
+
+func (e ErroHTTP) Error() string {
+	return fmt.Sprintf("HTTP %d ao acessar %s: %s",
+		e.StatusCode, e.URL, e.Mensagem)
+}
+
+// ErroBanco indica falha em uma operação de banco de dados
+type ErroBanco struct {
+	Operacao string
+	Tabela   string
+	Detalhe  error
+}
+
+func (e ErroBanco) Error() string {
+	return fmt.Sprintf("erro ao %s na tabela '%s': %v",
+		e.Operacao, e.Tabela, e.Detalhe)
+}
+
+func (e ErroBanco) Unwrap() error {
+	return e.Detalhe
+}
@@ -0,0 +1,515 @@
+package dinheiro
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+/*
+PACKAGE DINHEIRO
+
+Valores monetários armazenados em CENTAVOS inteiros (int64),
+nunca em float64.
+
+POR QUE NÃO float64?
+    saldo := 0.0
+    for i := 0; i < 10; i++ {
+        saldo += 0.10
+    }
+    fmt.Println(saldo == 1.0) // false! (0.9999999999999999)
+
+Com centavos inteiros a soma é exata:
+    saldo := dinheiro.BRL(0)
+    for i := 0; i < 10; i++ {
+        saldo, _ = saldo.Somar(dinheiro.BRL(10))
+    }
+    fmt.Println(saldo) // R$ 1,00
+
+RECURSOS:
+- Aritmética segura (moedas diferentes e overflow viram erro)
+- Arredondamento bancário (meio para o par)
+- Alocação proporcional sem perder centavos
+- Parse e formatação no padrão brasileiro: R$ 1.234,56
+- JSON como string decimal: "1234.56"
+*/
+
+// Códigos de moeda (ISO 4217)
+const (
+	MoedaBRL = "BRL"
+	MoedaUSD = "USD"
+	MoedaEUR = "EUR"
+)
+
+// MoedaPadrao é usada quando nenhuma moeda é informada
+const MoedaPadrao = MoedaBRL
+
+// Símbolo de exibição de cada moeda conhecida
+var simbolos = map[string]string{
+	MoedaBRL: "R$",
+	MoedaUSD: "US$",
+	MoedaEUR: "€",
+}
+
+// Erros retornados pelo package
+var (
+	ErrMoedaDiferente   = errors.New("moedas diferentes")
+	ErrOverflow         = errors.New("valor fora do intervalo suportado")
+	ErrAlocacaoInvalida = errors.New("proporções de alocação inválidas")
+	ErrFormatoInvalido  = errors.New("formato de valor monetário inválido")
+)
+
+// Dinheiro representa um valor monetário em centavos
+type Dinheiro struct {
+	centavos int64
+	moeda    string
+}
+
+// Novo cria um valor a partir de centavos e código da moeda
+func Novo(centavos int64, moeda string) Dinheiro {
+	if moeda == "" {
+		moeda = MoedaPadrao
+	}
+	return Dinheiro{centavos: centavos, moeda: strings.ToUpper(moeda)}
+}
+
+// BRL cria um valor em reais a partir de centavos
+func BRL(centavos int64) Dinheiro {
+	return Novo(centavos, MoedaBRL)
+}
+
+// DeFloat converte um float64 para Dinheiro usando arredondamento bancário.
+// Útil apenas na fronteira com código legado que ainda usa float64.
+func DeFloat(valor float64, moeda string) (Dinheiro, error) {
+	c := math.RoundToEven(valor * 100)
+	// float64(math.MaxInt64) arredonda para 2^63, que já não cabe em int64
+	if math.IsNaN(c) || c >= 1<<63 || c < -1<<63 {
+		return Dinheiro{}, ErrOverflow
+	}
+	return Novo(int64(c), moeda), nil
+}
+
+// Centavos retorna o valor em centavos
+func (d Dinheiro) Centavos() int64 {
+	return d.centavos
+}
+
+// Moeda retorna o código da moeda
+func (d Dinheiro) Moeda() string {
+	if d.moeda == "" {
+		return MoedaPadrao
+	}
+	return d.moeda
+}
+
+// Float64 retorna o valor como float64 (apenas para exibição ou cálculos aproximados)
+func (d Dinheiro) Float64() float64 {
+	return float64(d.centavos) / 100
+}
+
+// ========================================
+// ARITMÉTICA
+// ========================================
+
+// Somar retorna d + o
+func (d Dinheiro) Somar(o Dinheiro) (Dinheiro, error) {
+	if d.Moeda() != o.Moeda() {
+		return Dinheiro{}, fmt.Errorf("somar %s com %s: %w", d.Moeda(), o.Moeda(), ErrMoedaDiferente)
+	}
+	r := d.centavos + o.centavos
+	// Overflow: operandos com mesmo sinal e resultado com sinal oposto
+	if (d.centavos > 0 && o.centavos > 0 && r < 0) || (d.centavos < 0 && o.centavos < 0 && r >= 0) {
+		return Dinheiro{}, ErrOverflow
+	}
+	return Novo(r, d.Moeda()), nil
+}
+
+// Subtrair retorna d - o
+func (d Dinheiro) Subtrair(o Dinheiro) (Dinheiro, error) {
+	neg, err := o.Negativo()
+	if err != nil {
+		return Dinheiro{}, err
+	}
+	return d.Somar(neg)
+}
+
+// Multiplicar retorna d * fator com arredondamento bancário.
+// A conta é exata (big.Rat): em float64, centavos acima de 2^53 perdem
+// precisão e MaxInt64 * 1 viraria 2^63, que não cabe em int64.
+func (d Dinheiro) Multiplicar(fator float64) (Dinheiro, error) {
+	produto := new(big.Rat).SetFloat64(fator)
+	if produto == nil {
+		return Dinheiro{}, ErrOverflow // NaN ou infinito
+	}
+	produto.Mul(produto, new(big.Rat).SetInt64(d.centavos))
+	c, ok := arredondarPar(produto)
+	if !ok {
+		return Dinheiro{}, ErrOverflow
+	}
+	return Novo(c, d.Moeda()), nil
+}
+
+// arredondarPar arredonda r para o inteiro mais próximo (no empate, o
+// par) e diz se o resultado cabe em int64
+func arredondarPar(r *big.Rat) (int64, bool) {
+	// QuoRem trunca em direção a zero: o resto tem o sinal de r
+	q, resto := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	dobro := resto.Lsh(resto.Abs(resto), 1)
+	if cmp := dobro.Cmp(r.Denom()); cmp > 0 || (cmp == 0 && q.Bit(0) == 1) {
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	}
+	return q.Int64(), q.IsInt64()
+}
+
+// Alocar divide o valor proporcionalmente aos pesos sem perder centavos.
+// Os centavos que sobram vão, um a um, para as partes com maior resto.
+//
+//	dinheiro.BRL(100).Alocar(1, 1, 1) // R$ 0,34  R$ 0,33  R$ 0,33
+func (d Dinheiro) Alocar(pesos ...int) ([]Dinheiro, error) {
+	if len(pesos) == 0 {
+		return nil, ErrAlocacaoInvalida
+	}
+	var total int64
+	for _, p := range pesos {
+		if p < 0 || int64(p) > math.MaxInt64-total {
+			return nil, ErrAlocacaoInvalida
+		}
+		total += int64(p)
+	}
+	if total == 0 {
+		return nil, ErrAlocacaoInvalida
+	}
+
+	// Trabalhar com o valor absoluto e reaplicar o sinal no final
+	valor := d.centavos
+	sinal := int64(1)
+	if valor == math.MinInt64 {
+		return nil, ErrOverflow
+	}
+	if valor < 0 {
+		valor, sinal = -valor, -1
+	}
+
+	partes := make([]int64, len(pesos))
+	restos := make([]int64, len(pesos))
+	var distribuido int64
+	for i, p := range pesos {
+		// valor*p pode passar de 64 bits: o produto fica em 128 bits
+		// (hi, lo) e o quociente cabe em int64 porque p <= total
+		hi, lo := bits.Mul64(uint64(valor), uint64(p))
+		q, r := bits.Div64(hi, lo, uint64(total))
+		partes[i], restos[i] = int64(q), int64(r)
+		distribuido += partes[i]
+	}
+
+	for sobra := valor - distribuido; sobra > 0; sobra-- {
+		maior := 0
+		for i := range restos {
+			if restos[i] > restos[maior] {
+				maior = i
+			}
+		}
+		partes[maior]++
+		restos[maior] = -1
+	}
+
+	resultado := make([]Dinheiro, len(pesos))
+	for i, p := range partes {
+		resultado[i] = Novo(p*sinal, d.Moeda())
+	}
+	return resultado, nil
+}
+
+// Dividir reparte o valor em n partes iguais (diferença máxima de 1 centavo)
+func (d Dinheiro) Dividir(n int) ([]Dinheiro, error) {
+	if n <= 0 {
+		return nil, ErrAlocacaoInvalida
+	}
+	pesos := make([]int, n)
+	for i := range pesos {
+		pesos[i] = 1
+	}
+	return d.Alocar(pesos...)
+}
+
+// Negativo retorna -d (-math.MinInt64 não cabe em int64: ErrOverflow)
+func (d Dinheiro) Negativo() (Dinheiro, error) {
+	if d.centavos == math.MinInt64 {
+		return Dinheiro{}, ErrOverflow
+	}
+	return Novo(-d.centavos, d.Moeda()), nil
+}
+
+// Abs retorna o valor absoluto
+func (d Dinheiro) Abs() (Dinheiro, error) {
+	if d.centavos < 0 {
+		return d.Negativo()
+	}
+	return d, nil
+}
+
+// ========================================
+// COMPARAÇÃO
+// ========================================
+
+// Comparar retorna -1, 0 ou 1 (d < o, d == o, d > o)
+func (d Dinheiro) Comparar(o Dinheiro) (int, error) {
+	if d.Moeda() != o.Moeda() {
+		return 0, fmt.Errorf("comparar %s com %s: %w", d.Moeda(), o.Moeda(), ErrMoedaDiferente)
+	}
+	switch {
+	case d.centavos < o.centavos:
+		return -1, nil
+	case d.centavos > o.centavos:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Igual informa se os valores e moedas são iguais
+func (d Dinheiro) Igual(o Dinheiro) bool {
+	return d.centavos == o.centavos && d.Moeda() == o.Moeda()
+}
+
+// EhZero informa se o valor é zero
+func (d Dinheiro) EhZero() bool {
+	return d.centavos == 0
+}
+
+// EhNegativo informa se o valor é menor que zero
+func (d Dinheiro) EhNegativo() bool {
+	return d.centavos < 0
+}
+
+// ========================================
+// FORMATAÇÃO E PARSE
+// ========================================
+
+// String formata no padrão brasileiro: R$ 1.234,56
+func (d Dinheiro) String() string {
+	simbolo, ok := simbolos[d.Moeda()]
+	if !ok {
+		simbolo = d.Moeda()
+	}
+
+	c := d.centavos
+	negativo := c < 0
+	// Usar uint64 para suportar math.MinInt64
+	u := uint64(c)
+	if negativo {
+		u = uint64(-(c + 1)) + 1
+	}
+
+	texto := simbolo + " " + agruparMilhares(strconv.FormatUint(u/100, 10)) +
+		"," + fmt.Sprintf("%02d", u%100)
+	if negativo {
+		return "-" + texto
+	}
+	return texto
+}
+
+// Decimal retorna o valor como string decimal com ponto: "1234.56"
+func (d Dinheiro) Decimal() string {
+	c := d.centavos
+	sinal := ""
+	u := uint64(c)
+	if c < 0 {
+		sinal = "-"
+		u = uint64(-(c + 1)) + 1
+	}
+	return fmt.Sprintf("%s%d.%02d", sinal, u/100, u%100)
+}
+
+func agruparMilhares(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	primeiro := len(s) % 3
+	if primeiro > 0 {
+		b.WriteString(s[:primeiro])
+	}
+	for i := primeiro; i < len(s); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+// Parse interpreta valores no padrão brasileiro.
+// Aceita "R$ 1.234,56", "1234,56", "-R$ 10,00", "US$ 5,00" e "EUR 3,50".
+// Casas decimais além dos centavos são arredondadas (bancário).
+func Parse(s string) (Dinheiro, error) {
+	texto := strings.TrimSpace(s)
+	negativo := false
+	if strings.HasPrefix(texto, "-") {
+		negativo = true
+		texto = strings.TrimSpace(texto[1:])
+	}
+
+	moeda, texto := extrairMoeda(texto)
+
+	texto = strings.TrimSpace(texto)
+	if strings.HasPrefix(texto, "-") && !negativo {
+		negativo = true
+		texto = strings.TrimSpace(texto[1:])
+	}
+
+	inteira, fracao, _ := strings.Cut(texto, ",")
+	inteira, ok := desagrupar(inteira)
+	if !ok {
+		return Dinheiro{}, fmt.Errorf("parse %q: %w", s, ErrFormatoInvalido)
+	}
+	centavos, err := paraCentavos(inteira, fracao)
+	if err != nil {
+		return Dinheiro{}, fmt.Errorf("parse %q: %w", s, err)
+	}
+	if negativo {
+		centavos = -centavos
+	}
+	return Novo(centavos, moeda), nil
+}
+
+// desagrupar remove os pontos de milhar de "1.234.567", exigindo grupos
+// de exatamente 3 dígitos depois do primeiro ("1.5" e "1.2.3" são inválidos)
+func desagrupar(inteira string) (string, bool) {
+	grupos := strings.Split(inteira, ".")
+	if len(grupos) == 1 {
+		return inteira, true
+	}
+	if n := len(grupos[0]); n == 0 || n > 3 {
+		return "", false
+	}
+	for _, g := range grupos[1:] {
+		if len(g) != 3 {
+			return "", false
+		}
+	}
+	return strings.Join(grupos, ""), true
+}
+
+// extrairMoeda separa o símbolo ("R$") ou código ("BRL") do início do texto
+func extrairMoeda(texto string) (string, string) {
+	for codigo, simbolo := range simbolos {
+		if strings.HasPrefix(texto, simbolo) {
+			return codigo, texto[len(simbolo):]
+		}
+	}
+	if len(texto) >= 3 && isLetras(texto[:3]) {
+		return strings.ToUpper(texto[:3]), texto[3:]
+	}
+	return MoedaPadrao, texto
+}
+
+// ParseDecimal interpreta uma string decimal com ponto ("1234.56")
+func ParseDecimal(s, moeda string) (Dinheiro, error) {
+	texto := strings.TrimSpace(s)
+	negativo := strings.HasPrefix(texto, "-")
+	texto = strings.TrimPrefix(strings.TrimPrefix(texto, "-"), "+")
+
+	inteira, fracao, _ := strings.Cut(texto, ".")
+	centavos, err := paraCentavos(inteira, fracao)
+	if err != nil {
+		return Dinheiro{}, fmt.Errorf("parse %q: %w", s, err)
+	}
+	if negativo {
+		centavos = -centavos
+	}
+	return Novo(centavos, moeda), nil
+}
+
+// paraCentavos junta parte inteira e fração (só dígitos) em centavos,
+// arredondando casas extras para o par
+func paraCentavos(inteira, fracao string) (int64, error) {
+	if inteira == "" && fracao == "" {
+		return 0, ErrFormatoInvalido
+	}
+	if inteira == "" {
+		inteira = "0"
+	}
+	if !isDigitos(inteira) || (fracao != "" && !isDigitos(fracao)) {
+		return 0, ErrFormatoInvalido
+	}
+
+	for len(fracao) < 2 {
+		fracao += "0"
+	}
+	resto := fracao[2:]
+	fracao = fracao[:2]
+
+	reais, err := strconv.ParseInt(inteira, 10, 64)
+	if err != nil || reais > math.MaxInt64/100 {
+		return 0, ErrOverflow
+	}
+	cents, _ := strconv.ParseInt(fracao, 10, 64)
+	total := reais*100 + cents
+
+	// Arredondamento bancário das casas extras
+	if resto != "" {
+		primeiro := resto[0]
+		depois := strings.TrimRight(resto[1:], "0")
+		switch {
+		case primeiro > '5', primeiro == '5' && depois != "":
+			total++
+		case primeiro == '5' && total%2 == 1:
+			total++
+		}
+	}
+	if total < 0 {
+		return 0, ErrOverflow
+	}
+	return total, nil
+}
+
+func isDigitos(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetras(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ========================================
+// JSON
+// ========================================
+
+// MarshalJSON codifica o valor como string decimal: "1234.56".
+// A moeda não faz parte do JSON; guarde-a em um campo próprio se necessário.
+func (d Dinheiro) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.Decimal() + `"`), nil
+}
+
+// UnmarshalJSON aceita string decimal ("1234.56") ou número (1234.56).
+// A moeda do receptor é preservada (MoedaPadrao se vazia).
+func (d *Dinheiro) UnmarshalJSON(dados []byte) error {
+	texto := strings.Trim(string(dados), `"`)
+	if texto == "null" {
+		return nil
+	}
+	v, err := ParseDecimal(texto, d.Moeda())
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
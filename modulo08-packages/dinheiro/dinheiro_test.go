@@ -0,0 +1,227 @@
+package dinheiro
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSomar_SemErroDeArredondamento(t *testing.T) {
+	saldo := BRL(0)
+	for i := 0; i < 10; i++ {
+		var err error
+		saldo, err = saldo.Somar(BRL(10))
+		if err != nil {
+			t.Fatalf("Somar erro inesperado: %v", err)
+		}
+	}
+	if saldo.Centavos() != 100 {
+		t.Errorf("10 x R$ 0,10 = %d centavos; esperado 100", saldo.Centavos())
+	}
+}
+
+func TestSomar_Erros(t *testing.T) {
+	if _, err := BRL(100).Somar(Novo(100, MoedaUSD)); !errors.Is(err, ErrMoedaDiferente) {
+		t.Errorf("Somar BRL + USD: erro = %v; esperado ErrMoedaDiferente", err)
+	}
+	if _, err := BRL(math.MaxInt64).Somar(BRL(1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Somar MaxInt64 + 1: erro = %v; esperado ErrOverflow", err)
+	}
+	if _, err := BRL(math.MinInt64).Subtrair(BRL(1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Subtrair MinInt64 - 1: erro = %v; esperado ErrOverflow", err)
+	}
+	if _, err := BRL(math.MinInt64).Negativo(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Negativo MinInt64: erro = %v; esperado ErrOverflow", err)
+	}
+	if _, err := BRL(0).Subtrair(BRL(math.MinInt64)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Subtrair 0 - MinInt64: erro = %v; esperado ErrOverflow", err)
+	}
+}
+
+func TestMultiplicar_ArredondamentoBancario(t *testing.T) {
+	tests := []struct {
+		name     string
+		centavos int64
+		fator    float64
+		esperado int64
+	}{
+		{"meio para o par (baixo)", 25, 0.5, 12}, // 12,5 → 12
+		{"meio para o par (cima)", 35, 0.5, 18},  // 17,5 → 18
+		{"meio com ímpar abaixo", 27, 0.5, 14},   // 13,5 → 14
+		{"acima do meio", 27, 0.54, 15},          // 14,58 → 15
+		{"abaixo do meio", 27, 0.52, 14},         // 14,04 → 14
+		{"negativo", -25, 0.5, -12},              // -12,5 → -12
+		{"inteiro", 1000, 1.1, 1100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := BRL(tt.centavos).Multiplicar(tt.fator)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if r.Centavos() != tt.esperado {
+				t.Errorf("%d * %v = %d; esperado %d", tt.centavos, tt.fator, r.Centavos(), tt.esperado)
+			}
+		})
+	}
+}
+
+func TestMultiplicar_Limites(t *testing.T) {
+	// Resultado exato no limite cabe; um centavo além, não
+	if r, err := BRL(math.MaxInt64).Multiplicar(1); err != nil || r.Centavos() != math.MaxInt64 {
+		t.Errorf("MaxInt64 * 1 = %v, %v", r, err)
+	}
+	if r, err := BRL(math.MaxInt64 - 1).Multiplicar(1); err != nil || r.Centavos() != math.MaxInt64-1 {
+		t.Errorf("(MaxInt64-1) * 1 = %v, %v", r, err)
+	}
+	if r, err := BRL(math.MaxInt64).Multiplicar(1.0000001); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MaxInt64 * 1.0000001 = %v, %v; esperado ErrOverflow", r, err)
+	}
+	if r, err := BRL(1).Multiplicar(math.NaN()); !errors.Is(err, ErrOverflow) {
+		t.Errorf("1 * NaN = %v, %v; esperado ErrOverflow", r, err)
+	}
+	if r, err := BRL(math.MinInt64).Multiplicar(1); err != nil || r.Centavos() != math.MinInt64 {
+		t.Errorf("MinInt64 * 1 = %v, %v", r, err)
+	}
+	if r, err := BRL(math.MinInt64).Multiplicar(-1); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MinInt64 * -1 = %v, %v; esperado ErrOverflow", r, err)
+	}
+	if r, err := DeFloat(float64(math.MaxInt64)/100, MoedaBRL); !errors.Is(err, ErrOverflow) {
+		t.Errorf("DeFloat(MaxInt64/100) = %v, %v; esperado ErrOverflow", r, err)
+	}
+	if r, err := DeFloat(1<<53/100.0, MoedaBRL); err != nil || r.Centavos() != 1<<53 {
+		t.Errorf("DeFloat(2^53/100) = %v, %v", r, err)
+	}
+}
+
+func TestAlocar(t *testing.T) {
+	tests := []struct {
+		name     string
+		centavos int64
+		pesos    []int
+		esperado []int64
+	}{
+		{"tres partes iguais", 100, []int{1, 1, 1}, []int64{34, 33, 33}},
+		{"70/30", 5, []int{70, 30}, []int64{4, 1}},
+		{"com peso zero", 100, []int{1, 0, 1}, []int64{50, 0, 50}},
+		{"negativo", -100, []int{1, 1, 1}, []int64{-34, -33, -33}},
+		{"valor grande", math.MaxInt64, []int{1, 1}, []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2}},
+		{"pesos grandes", 1e12, []int{1 << 40, 1 << 40, 3}, []int64{499999999999, 499999999999, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partes, err := BRL(tt.centavos).Alocar(tt.pesos...)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			for i, p := range partes {
+				if p.Centavos() != tt.esperado[i] {
+					t.Errorf("parte %d = %d; esperado %d", i, p.Centavos(), tt.esperado[i])
+				}
+			}
+		})
+	}
+
+	if _, err := BRL(100).Alocar(0, 0); !errors.Is(err, ErrAlocacaoInvalida) {
+		t.Errorf("Alocar(0, 0): erro = %v; esperado ErrAlocacaoInvalida", err)
+	}
+	if _, err := BRL(100).Alocar(math.MaxInt64, 1); !errors.Is(err, ErrAlocacaoInvalida) {
+		t.Errorf("Alocar com soma dos pesos acima de int64: erro = %v; esperado ErrAlocacaoInvalida", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		valor    Dinheiro
+		esperado string
+	}{
+		{BRL(0), "R$ 0,00"},
+		{BRL(5), "R$ 0,05"},
+		{BRL(123456), "R$ 1.234,56"},
+		{BRL(-123456789), "-R$ 1.234.567,89"},
+		{Novo(1999, MoedaUSD), "US$ 19,99"},
+		{Novo(100, "jpy"), "JPY 1,00"},
+	}
+
+	for _, tt := range tests {
+		if s := tt.valor.String(); s != tt.esperado {
+			t.Errorf("String() = %q; esperado %q", s, tt.esperado)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		entrada   string
+		centavos  int64
+		moeda     string
+		deveErrar bool
+	}{
+		{"R$ 1.234,56", 123456, MoedaBRL, false},
+		{"1234,56", 123456, MoedaBRL, false},
+		{"-R$ 10,00", -1000, MoedaBRL, false},
+		{"R$ -10", -1000, MoedaBRL, false},
+		{"US$ 0,5", 50, MoedaUSD, false},
+		{"EUR 3,50", 350, MoedaEUR, false},
+		{"R$ 0,125", 12, MoedaBRL, false}, // meio para o par
+		{"R$ 0,135", 14, MoedaBRL, false},
+		{"R$ 0,1251", 13, MoedaBRL, false},
+		{"R$", 0, "", true},
+		{"R$ 12,3a", 0, "", true},
+		{"abc", 0, "", true},
+		{"R$ 1.234.567,89", 123456789, MoedaBRL, false},
+		{"1.5", 0, "", true},
+		{"R$ 1.2.3,4", 0, "", true},
+		{"R$ 1234.567,00", 0, "", true},
+		{"R$ .123,00", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entrada, func(t *testing.T) {
+			d, err := Parse(tt.entrada)
+			if tt.deveErrar {
+				if err == nil {
+					t.Errorf("Parse(%q) deveria retornar erro", tt.entrada)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) erro inesperado: %v", tt.entrada, err)
+			}
+			if d.Centavos() != tt.centavos || d.Moeda() != tt.moeda {
+				t.Errorf("Parse(%q) = %d %s; esperado %d %s",
+					tt.entrada, d.Centavos(), d.Moeda(), tt.centavos, tt.moeda)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type Produto struct {
+		Nome  string   `json:"nome"`
+		Preco Dinheiro `json:"preco"`
+	}
+
+	dados, err := json.Marshal(Produto{Nome: "Caneta", Preco: BRL(-150)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(dados) != `{"nome":"Caneta","preco":"-1.50"}` {
+		t.Errorf("Marshal = %s", dados)
+	}
+
+	var p Produto
+	if err := json.Unmarshal([]byte(`{"nome":"Lápis","preco":"1234.5"}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Preco.Igual(BRL(123450)) {
+		t.Errorf("Unmarshal preco = %v; esperado R$ 1.234,50", p.Preco)
+	}
+
+	if err := json.Unmarshal([]byte(`{"preco":"1,50"}`), &p); err == nil {
+		t.Error("Unmarshal com vírgula deveria retornar erro")
+	}
+}
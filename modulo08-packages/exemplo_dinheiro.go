@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-course/modulo08-packages/dinheiro"
+)
+
+/*
+PACKAGE DINHEIRO - VALORES MONETÁRIOS SEM float64
+
+COMPARAÇÃO COM PYTHON:
+--------------------
+Python:
+    from decimal import Decimal, ROUND_HALF_EVEN
+    saldo = Decimal("0.10") * 3
+    saldo.quantize(Decimal("0.01"), rounding=ROUND_HALF_EVEN)
+
+Go:
+    import "go-course/modulo08-packages/dinheiro"
+    saldo, _ := dinheiro.BRL(10).Multiplicar(3)
+    fmt.Println(saldo) // R$ 0,30
+
+Os exemplos de ContaBancaria (módulos 04 e 05) e Produto (módulo 10)
+usam float64 por simplicidade. Em código real, prefira centavos inteiros.
+*/
+
+type Produto struct {
+	ID    int               `json:"id"`
+	Nome  string            `json:"nome"`
+	Preco dinheiro.Dinheiro `json:"preco"`
+}
+
+func main() {
+	fmt.Println("=== O PROBLEMA DO float64 ===")
+
+	saldoFloat := 0.0
+	for i := 0; i < 10; i++ {
+		saldoFloat += 0.10
+	}
+	fmt.Printf("10 depósitos de 0.10 (float64): %.17f\n", saldoFloat)
+
+	saldo := dinheiro.BRL(0)
+	for i := 0; i < 10; i++ {
+		saldo, _ = saldo.Somar(dinheiro.BRL(10))
+	}
+	fmt.Printf("10 depósitos de R$ 0,10 (centavos): %s\n", saldo)
+
+	fmt.Println("\n=== PARSE E FORMATAÇÃO ===")
+
+	valor, err := dinheiro.Parse("R$ 1.234,56")
+	if err != nil {
+		fmt.Println("Erro:", err)
+		return
+	}
+	fmt.Printf("Texto: %s → %d centavos\n", valor, valor.Centavos())
+
+	if _, err := dinheiro.Parse("R$ 12,3x"); err != nil {
+		fmt.Println("Erro esperado:", err)
+	}
+
+	fmt.Println("\n=== ARREDONDAMENTO BANCÁRIO ===")
+
+	// 12,5 centavos → 12 (par) e 17,5 centavos → 18 (par)
+	a, _ := dinheiro.BRL(25).Multiplicar(0.5)
+	b, _ := dinheiro.BRL(35).Multiplicar(0.5)
+	fmt.Printf("R$ 0,25 / 2 = %s\n", a)
+	fmt.Printf("R$ 0,35 / 2 = %s\n", b)
+
+	fmt.Println("\n=== ALOCAÇÃO SEM PERDER CENTAVOS ===")
+
+	conta := dinheiro.BRL(10000)
+	partes, _ := conta.Dividir(3)
+	fmt.Printf("%s dividido por 3: %v\n", conta, partes)
+
+	partes, _ = conta.Alocar(50, 30, 20)
+	fmt.Printf("%s em 50/30/20: %v\n", conta, partes)
+
+	fmt.Println("\n=== MOEDAS DIFERENTES ===")
+
+	if _, err := dinheiro.BRL(100).Somar(dinheiro.Novo(100, dinheiro.MoedaUSD)); err != nil {
+		fmt.Println("Erro:", err)
+	}
+
+	fmt.Println("\n=== JSON ===")
+
+	p := Produto{ID: 1, Nome: "Notebook", Preco: dinheiro.BRL(349990)}
+	dados, _ := json.Marshal(p)
+	fmt.Println(string(dados))
+
+	var p2 Produto
+	json.Unmarshal([]byte(`{"id":2,"nome":"Mouse","preco":"89.90"}`), &p2)
+	fmt.Printf("%s: %s\n", p2.Nome, p2.Preco)
+}
+
+/*
+RESUMO:
+
+CRIAR:
+    dinheiro.BRL(1050)                    // R$ 10,50
+    dinheiro.Novo(1999, dinheiro.MoedaUSD) // US$ 19,99
+    dinheiro.Parse("R$ 1.234,56")
+
+OPERAR (retornam erro em moedas diferentes ou overflow):
+    a.Somar(b)
+    a.Subtrair(b)
+    a.Multiplicar(1.1)
+    a.Alocar(70, 30)
+    a.Dividir(3)
+
+REGRAS:
+✓ Nunca use float64 para dinheiro
+✓ Centavos inteiros = somas exatas
+✓ Arredondamento bancário evita viés
+✓ JSON como string: "1234.56"
+
+Execute com:
+    go run exemplo_dinheiro.go
+*/
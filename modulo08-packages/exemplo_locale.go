@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"go-course/modulo08-packages/locale"
+	"time"
+)
+
+/*
+PACKAGE LOCALE - FORMATAÇÃO PARA O PÚBLICO BRASILEIRO
+
+COMPARAÇÃO COM PYTHON:
+--------------------
+Python:
+    import locale
+    locale.setlocale(locale.LC_ALL, "pt_BR.UTF-8")   # estado global!
+    locale.currency(1234.5, grouping=True)            # 'R$ 1.234,50'
+
+Go:
+    import "go-course/modulo08-packages/locale"
+    locale.PtBR.FormatarMoeda(1234.5, "R$")           // R$ 1.234,50
+
+Em Go não existe locale global do processo: cada *Locale é um valor
+comum, que pode ser passado como parâmetro (ex.: um por usuário).
+*/
+
+func main() {
+	agora := time.Now()
+
+	fmt.Println("=== NÚMEROS ===")
+
+	valor := 1234567.891
+	fmt.Printf("fmt.Printf(\"%%.2f\"): %.2f\n", valor)
+	fmt.Printf("pt-BR:               %s\n", locale.PtBR.FormatarNumero(valor, 2))
+	fmt.Printf("en-US:               %s\n", locale.EnUS.FormatarNumero(valor, 2))
+	fmt.Printf("Moeda:               %s\n", locale.Moeda(49.9))
+	fmt.Printf("Porcentagem:         %s\n", locale.PtBR.FormatarPorcentagem(0.256, 1))
+
+	if n, err := locale.PtBR.ParseNumero("8,5"); err == nil {
+		fmt.Printf("Parse de \"8,5\":      %.1f\n", n)
+	}
+
+	fmt.Println("\n=== DATAS ===")
+
+	for _, l := range []*locale.Locale{locale.PtBR, locale.EnUS} {
+		fmt.Printf("[%s]\n", l.Codigo)
+		fmt.Printf("  Curta:      %s\n", l.DataCurta(agora))
+		fmt.Printf("  Abreviada:  %s\n", l.DataAbreviada(agora))
+		fmt.Printf("  Longa:      %s\n", l.DataLonga(agora))
+		fmt.Printf("  Completa:   %s\n", l.DataCompleta(agora))
+		fmt.Printf("  Data/hora:  %s\n", l.DataHora(agora))
+	}
+
+	fmt.Println("\n=== TEMPO RELATIVO ===")
+
+	momentos := []time.Duration{
+		-5 * time.Second,
+		-3 * time.Minute,
+		-2 * time.Hour,
+		-26 * time.Hour,
+		-45 * 24 * time.Hour,
+		90 * time.Minute,
+	}
+	for _, m := range momentos {
+		t := agora.Add(m)
+		fmt.Printf("  %-14s %s\n", locale.PtBR.Relativo(t, agora), locale.EnUS.Relativo(t, agora))
+	}
+
+	fmt.Println("\n=== DURAÇÕES ===")
+
+	inicio := time.Now()
+	time.Sleep(1100 * time.Millisecond)
+	fmt.Printf("  Tempo decorrido: %s\n", locale.Duracao(time.Since(inicio)))
+	fmt.Printf("  90 minutos:      %s\n", locale.Duracao(90*time.Minute))
+	fmt.Printf("  26h10m:          %s\n", locale.EnUS.Duracao(26*time.Hour+10*time.Minute))
+
+	fmt.Println("\n=== ESCOLHENDO O LOCALE ===")
+
+	for _, codigo := range []string{"pt_BR", "en-US", "fr-FR"} {
+		l, err := locale.Obter(codigo)
+		if err != nil {
+			fmt.Println("  Erro:", err)
+			continue
+		}
+		fmt.Printf("  %s: %s\n", codigo, l.FormatarMoeda(10.5, "$"))
+	}
+}
+
+/*
+RESUMO:
+
+NÚMEROS:
+    l.FormatarNumero(1234.5, 2)     // 1.234,50
+    l.FormatarMoeda(1234.5, "R$")   // R$ 1.234,50
+    l.ParseNumero("8,5")            // 8.5
+
+DATAS:
+    l.DataCurta(t)      // 19/10/2026
+    l.DataLonga(t)      // 19 de outubro de 2026
+    l.DataCompleta(t)   // segunda-feira, 19 de outubro de 2026
+
+TEMPO:
+    l.Relativo(t, agora)      // há 3 minutos / em 2 dias
+    l.Duracao(90*time.Minute) // 1 hora e 30 minutos
+
+ATALHOS (locale.Padrao = pt-BR):
+    locale.Moeda(v), locale.Data(t), locale.Relativo(t), locale.Duracao(d)
+
+Execute com:
+    go run exemplo_locale.go
+*/
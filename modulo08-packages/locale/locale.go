@@ -0,0 +1,423 @@
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+PACKAGE LOCALE
+
+Formatação de números, datas e durações por idioma/região.
+
+PROBLEMA:
+    fmt.Printf("R$ %.2f", 1234.5)          // R$ 1234.50  (ponto!)
+    t.Format("2 January 2006")             // 19 October 2026 (inglês!)
+
+SOLUÇÃO:
+    l := locale.PtBR
+    l.FormatarMoeda(1234.5, "R$")          // R$ 1.234,50
+    l.DataLonga(t)                         // 19 de outubro de 2026
+    l.Relativo(t, time.Now())              // há 3 minutos
+    l.Duracao(90 * time.Minute)            // 1 hora e 30 minutos
+
+LOCALES DISPONÍVEIS:
+- pt-BR (padrão)
+- en-US
+*/
+
+// ErrLocaleDesconhecido é retornado por Obter para códigos não suportados
+var ErrLocaleDesconhecido = errors.New("locale desconhecido")
+
+// ErrNumeroInvalido é retornado por ParseNumero
+var ErrNumeroInvalido = errors.New("número inválido")
+
+// unidade guarda as formas singular e plural de uma unidade de tempo
+type unidade struct {
+	singular string
+	plural   string
+}
+
+// Locale reúne as convenções de formatação de um idioma/região
+type Locale struct {
+	Codigo           string
+	SeparadorDecimal string
+	SeparadorMilhar  string
+
+	meses       [12]string
+	mesesAbrev  [12]string
+	diasSemana  [7]string
+	formatoData string // layout de time.Format para DataCurta
+	dataLonga   func(dia int, mes string, ano int) string
+
+	// Tempo relativo e durações
+	agora     string
+	passado   string // "há %s"
+	futuro    string // "em %s"
+	conjuncao string // "e"
+	unidades  [7]unidade
+}
+
+// Índices em Locale.unidades (da maior para a menor)
+const (
+	uAno = iota
+	uMes
+	uSemana
+	uDia
+	uHora
+	uMinuto
+	uSegundo
+)
+
+// PtBR é o locale português do Brasil
+var PtBR = &Locale{
+	Codigo:           "pt-BR",
+	SeparadorDecimal: ",",
+	SeparadorMilhar:  ".",
+	meses: [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+		"julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	mesesAbrev: [12]string{"jan", "fev", "mar", "abr", "mai", "jun",
+		"jul", "ago", "set", "out", "nov", "dez"},
+	diasSemana: [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira",
+		"quinta-feira", "sexta-feira", "sábado"},
+	formatoData: "02/01/2006",
+	dataLonga: func(dia int, mes string, ano int) string {
+		return fmt.Sprintf("%d de %s de %d", dia, mes, ano)
+	},
+	agora:     "agora mesmo",
+	passado:   "há %s",
+	futuro:    "em %s",
+	conjuncao: "e",
+	unidades: [7]unidade{
+		{"ano", "anos"}, {"mês", "meses"}, {"semana", "semanas"}, {"dia", "dias"},
+		{"hora", "horas"}, {"minuto", "minutos"}, {"segundo", "segundos"},
+	},
+}
+
+// EnUS é o locale inglês dos Estados Unidos
+var EnUS = &Locale{
+	Codigo:           "en-US",
+	SeparadorDecimal: ".",
+	SeparadorMilhar:  ",",
+	meses: [12]string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"},
+	mesesAbrev: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+		"Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	diasSemana: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday",
+		"Thursday", "Friday", "Saturday"},
+	formatoData: "01/02/2006",
+	dataLonga: func(dia int, mes string, ano int) string {
+		return fmt.Sprintf("%s %d, %d", mes, dia, ano)
+	},
+	agora:     "just now",
+	passado:   "%s ago",
+	futuro:    "in %s",
+	conjuncao: "and",
+	unidades: [7]unidade{
+		{"year", "years"}, {"month", "months"}, {"week", "weeks"}, {"day", "days"},
+		{"hour", "hours"}, {"minute", "minutes"}, {"second", "seconds"},
+	},
+}
+
+// Padrao é o locale usado pelas funções de conveniência do package
+var Padrao = PtBR
+
+var registrados = map[string]*Locale{
+	"pt-br": PtBR,
+	"pt":    PtBR,
+	"en-us": EnUS,
+	"en":    EnUS,
+}
+
+// Obter retorna o locale pelo código ("pt-BR", "en_US", "en"...)
+func Obter(codigo string) (*Locale, error) {
+	chave := strings.ToLower(strings.ReplaceAll(codigo, "_", "-"))
+	if l, ok := registrados[chave]; ok {
+		return l, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrLocaleDesconhecido, codigo)
+}
+
+// ========================================
+// NÚMEROS
+// ========================================
+
+// FormatarNumero formata com separadores do locale: 1234.5 → "1.234,50" (pt-BR, 2 casas)
+func (l *Locale) FormatarNumero(valor float64, casas int) string {
+	if math.IsNaN(valor) || math.IsInf(valor, 0) {
+		return strconv.FormatFloat(valor, 'f', -1, 64)
+	}
+	texto := strconv.FormatFloat(math.Abs(valor), 'f', casas, 64)
+	inteira, fracao, _ := strings.Cut(texto, ".")
+
+	resultado := l.agrupar(inteira)
+	if fracao != "" {
+		resultado += l.SeparadorDecimal + fracao
+	}
+	if valor < 0 && strings.Trim(texto, "0.") != "" {
+		resultado = "-" + resultado
+	}
+	return resultado
+}
+
+// FormatarInteiro formata um inteiro com separador de milhar: 1234567 → "1.234.567"
+func (l *Locale) FormatarInteiro(valor int64) string {
+	if valor < 0 {
+		return "-" + l.agrupar(strings.TrimPrefix(strconv.FormatInt(valor, 10), "-"))
+	}
+	return l.agrupar(strconv.FormatInt(valor, 10))
+}
+
+// FormatarMoeda substitui o "R$ %.2f": FormatarMoeda(1234.5, "R$") → "R$ 1.234,50"
+func (l *Locale) FormatarMoeda(valor float64, simbolo string) string {
+	texto := simbolo + " " + l.FormatarNumero(math.Abs(valor), 2)
+	if valor < 0 && math.Round(valor*100) != 0 {
+		return "-" + texto
+	}
+	return texto
+}
+
+// FormatarPorcentagem formata uma fração: 0.256 → "25,6%"
+func (l *Locale) FormatarPorcentagem(fracao float64, casas int) string {
+	return l.FormatarNumero(fracao*100, casas) + "%"
+}
+
+// ParseNumero interpreta um número escrito com as convenções do locale.
+// Só aceita sinal, dígitos e os separadores do locale: expoente ("1e5"),
+// "Inf", "NaN" e separador decimal sem dígitos depois ("1,") são erro.
+func (l *Locale) ParseNumero(texto string) (float64, error) {
+	s := strings.TrimSpace(texto)
+	inteira, fracao, temFracao := strings.Cut(s, l.SeparadorDecimal)
+	inteira, ok := l.desagrupar(inteira)
+	digitos := strings.TrimLeft(inteira, "+-")
+	if len(inteira)-len(digitos) > 1 {
+		ok = false // "--1"
+	}
+	if temFracao {
+		ok = ok && (digitos == "" || soDigitos(digitos)) && soDigitos(fracao)
+	} else {
+		ok = ok && soDigitos(digitos)
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrNumeroInvalido, texto)
+	}
+	s = inteira
+	if temFracao {
+		s += "." + fracao
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrNumeroInvalido, texto)
+	}
+	return v, nil
+}
+
+// desagrupar remove os separadores de milhar da parte inteira, exigindo
+// grupos de exatamente 3 dígitos depois do primeiro: em pt-BR "1.5" não
+// é 15
+func (l *Locale) desagrupar(inteira string) (string, bool) {
+	sinal := ""
+	if strings.HasPrefix(inteira, "-") || strings.HasPrefix(inteira, "+") {
+		sinal, inteira = inteira[:1], inteira[1:]
+	}
+	grupos := strings.Split(inteira, l.SeparadorMilhar)
+	if len(grupos) == 1 {
+		return sinal + inteira, true
+	}
+	if n := len(grupos[0]); n == 0 || n > 3 {
+		return "", false
+	}
+	for _, g := range grupos[1:] {
+		if len(g) != 3 {
+			return "", false
+		}
+	}
+	return sinal + strings.Join(grupos, ""), true
+}
+
+// soDigitos diz se s tem pelo menos um caractere e só dígitos ASCII
+func soDigitos(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (l *Locale) agrupar(digitos string) string {
+	if len(digitos) <= 3 {
+		return digitos
+	}
+	var b strings.Builder
+	primeiro := len(digitos) % 3
+	if primeiro > 0 {
+		b.WriteString(digitos[:primeiro])
+	}
+	for i := primeiro; i < len(digitos); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(l.SeparadorMilhar)
+		}
+		b.WriteString(digitos[i : i+3])
+	}
+	return b.String()
+}
+
+// ========================================
+// DATAS
+// ========================================
+
+// Mes retorna o nome do mês: "outubro"
+func (l *Locale) Mes(m time.Month) string {
+	return l.meses[m-1]
+}
+
+// MesAbreviado retorna o nome curto do mês: "out"
+func (l *Locale) MesAbreviado(m time.Month) string {
+	return l.mesesAbrev[m-1]
+}
+
+// DiaSemana retorna o nome do dia da semana: "segunda-feira"
+func (l *Locale) DiaSemana(d time.Weekday) string {
+	return l.diasSemana[d]
+}
+
+// DataCurta formata como "19/10/2026" (pt-BR) ou "10/19/2026" (en-US)
+func (l *Locale) DataCurta(t time.Time) string {
+	return t.Format(l.formatoData)
+}
+
+// DataLonga formata como "19 de outubro de 2026"
+func (l *Locale) DataLonga(t time.Time) string {
+	return l.dataLonga(t.Day(), l.Mes(t.Month()), t.Year())
+}
+
+// DataAbreviada formata com o mês abreviado: "19 de out de 2026"
+func (l *Locale) DataAbreviada(t time.Time) string {
+	return l.dataLonga(t.Day(), l.MesAbreviado(t.Month()), t.Year())
+}
+
+// DataCompleta formata como "segunda-feira, 19 de outubro de 2026"
+func (l *Locale) DataCompleta(t time.Time) string {
+	return l.DiaSemana(t.Weekday()) + ", " + l.DataLonga(t)
+}
+
+// DataHora formata como "19/10/2026 15:04"
+func (l *Locale) DataHora(t time.Time) string {
+	return l.DataCurta(t) + " " + t.Format("15:04")
+}
+
+// Hora formata como "15:04:05"
+func (l *Locale) Hora(t time.Time) string {
+	return t.Format("15:04:05")
+}
+
+// ========================================
+// TEMPO RELATIVO E DURAÇÕES
+// ========================================
+
+// Aproximações usadas para tempo relativo
+var tamanhos = [7]time.Duration{
+	365 * 24 * time.Hour, // ano
+	30 * 24 * time.Hour,  // mês
+	7 * 24 * time.Hour,   // semana
+	24 * time.Hour,       // dia
+	time.Hour,
+	time.Minute,
+	time.Second,
+}
+
+// Relativo descreve t em relação a referencia: "há 3 minutos", "em 2 dias"
+func (l *Locale) Relativo(t, referencia time.Time) string {
+	diff := referencia.Sub(t)
+	futuro := diff < 0
+	if futuro {
+		diff = -diff
+	}
+	if diff < 10*time.Second {
+		return l.agora
+	}
+
+	// Usa apenas a maior unidade: "há 2 horas" (não "há 2 horas e 5 minutos")
+	texto := l.quantidade(int64(diff/time.Second), uSegundo)
+	for i, tam := range tamanhos {
+		if diff >= tam {
+			texto = l.quantidade(int64(diff/tam), i)
+			break
+		}
+	}
+
+	if futuro {
+		return fmt.Sprintf(l.futuro, texto)
+	}
+	return fmt.Sprintf(l.passado, texto)
+}
+
+// Duracao humaniza uma duração com até duas unidades: "1 hora e 30 minutos".
+// Durações menores que um segundo viram "0 segundos".
+func (l *Locale) Duracao(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	// Durações usam dias como maior unidade (semanas/meses são ambíguos)
+	partes := []string{}
+	for i := uDia; i <= uSegundo && len(partes) < 2; i++ {
+		if n := int64(d / tamanhos[i]); n > 0 {
+			partes = append(partes, l.quantidade(n, i))
+			d -= time.Duration(n) * tamanhos[i]
+		} else if len(partes) > 0 {
+			// Não pular unidades: "1 dia e 3 segundos" seria enganoso
+			break
+		}
+	}
+
+	switch len(partes) {
+	case 0:
+		return l.quantidade(0, uSegundo)
+	case 1:
+		return partes[0]
+	default:
+		return partes[0] + " " + l.conjuncao + " " + partes[1]
+	}
+}
+
+func (l *Locale) quantidade(n int64, u int) string {
+	nome := l.unidades[u].plural
+	if n == 1 {
+		nome = l.unidades[u].singular
+	}
+	return l.FormatarInteiro(n) + " " + nome
+}
+
+// ========================================
+// ATALHOS COM O LOCALE PADRÃO
+// ========================================
+
+// Numero formata com o locale Padrao
+func Numero(valor float64, casas int) string {
+	return Padrao.FormatarNumero(valor, casas)
+}
+
+// Moeda formata em reais com o locale Padrao: "R$ 1.234,56"
+func Moeda(valor float64) string {
+	return Padrao.FormatarMoeda(valor, "R$")
+}
+
+// Data formata a data longa com o locale Padrao
+func Data(t time.Time) string {
+	return Padrao.DataLonga(t)
+}
+
+// Relativo descreve t em relação ao momento atual com o locale Padrao
+func Relativo(t time.Time) string {
+	return Padrao.Relativo(t, time.Now())
+}
+
+// Duracao humaniza d com o locale Padrao
+func Duracao(d time.Duration) string {
+	return Padrao.Duracao(d)
+}
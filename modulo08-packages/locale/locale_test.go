@@ -0,0 +1,164 @@
+package locale
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFormatarNumero(t *testing.T) {
+	tests := []struct {
+		name     string
+		l        *Locale
+		valor    float64
+		casas    int
+		esperado string
+	}{
+		{"pt-BR milhar", PtBR, 1234.5, 2, "1.234,50"},
+		{"pt-BR milhão", PtBR, 1234567.891, 2, "1.234.567,89"},
+		{"pt-BR negativo", PtBR, -0.5, 1, "-0,5"},
+		{"pt-BR zero negativo", PtBR, -0.001, 2, "0,00"},
+		{"en-US milhar", EnUS, 1234.5, 2, "1,234.50"},
+		{"sem casas", PtBR, 999, 0, "999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := tt.l.FormatarNumero(tt.valor, tt.casas); r != tt.esperado {
+				t.Errorf("FormatarNumero(%v, %d) = %q; esperado %q", tt.valor, tt.casas, r, tt.esperado)
+			}
+		})
+	}
+}
+
+func TestFormatarMoeda(t *testing.T) {
+	if r := PtBR.FormatarMoeda(-1234.5, "R$"); r != "-R$ 1.234,50" {
+		t.Errorf("FormatarMoeda = %q; esperado %q", r, "-R$ 1.234,50")
+	}
+	if r := Moeda(0.1 + 0.2); r != "R$ 0,30" {
+		t.Errorf("Moeda = %q; esperado %q", r, "R$ 0,30")
+	}
+}
+
+func TestParseNumero(t *testing.T) {
+	tests := []struct {
+		locale    *Locale
+		entrada   string
+		esperado  float64
+		deveErrar bool
+	}{
+		{PtBR, "1.234,56", 1234.56, false},
+		{PtBR, "-1.234.567", -1234567, false},
+		{PtBR, "1234,5", 1234.5, false},
+		{EnUS, "1,234.56", 1234.56, false},
+		{PtBR, "1,2,3", 0, true},
+		{PtBR, "1.5", 0, true},
+		{PtBR, "1.2.3,4", 0, true},
+		{PtBR, "1234.567", 0, true},
+		{PtBR, ".123", 0, true},
+		{EnUS, "1,5", 0, true},
+		{PtBR, "1,e5", 0, true},
+		{PtBR, "1e5", 0, true},
+		{PtBR, "1,", 0, true},
+		{PtBR, "Inf", 0, true},
+		{PtBR, "--1", 0, true},
+		{PtBR, "-0,5", -0.5, false},
+		{PtBR, ",5", 0.5, false},
+	}
+
+	for _, tt := range tests {
+		v, err := tt.locale.ParseNumero(tt.entrada)
+		if tt.deveErrar {
+			if !errors.Is(err, ErrNumeroInvalido) {
+				t.Errorf("%s ParseNumero(%q) = %v, %v; esperado ErrNumeroInvalido", tt.locale.Codigo, tt.entrada, v, err)
+			}
+			continue
+		}
+		if err != nil || v != tt.esperado {
+			t.Errorf("%s ParseNumero(%q) = %v, %v; esperado %v", tt.locale.Codigo, tt.entrada, v, err, tt.esperado)
+		}
+	}
+}
+
+func TestDatas(t *testing.T) {
+	data := time.Date(2026, time.October, 19, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		obtido   string
+		esperado string
+	}{
+		{"pt-BR curta", PtBR.DataCurta(data), "19/10/2026"},
+		{"pt-BR longa", PtBR.DataLonga(data), "19 de outubro de 2026"},
+		{"pt-BR abreviada", PtBR.DataAbreviada(data), "19 de out de 2026"},
+		{"pt-BR completa", PtBR.DataCompleta(data), "segunda-feira, 19 de outubro de 2026"},
+		{"pt-BR data e hora", PtBR.DataHora(data), "19/10/2026 15:04"},
+		{"en-US curta", EnUS.DataCurta(data), "10/19/2026"},
+		{"en-US abreviada", EnUS.DataAbreviada(data), "Oct 19, 2026"},
+		{"en-US completa", EnUS.DataCompleta(data), "Monday, October 19, 2026"},
+	}
+
+	for _, tt := range tests {
+		if tt.obtido != tt.esperado {
+			t.Errorf("%s = %q; esperado %q", tt.name, tt.obtido, tt.esperado)
+		}
+	}
+}
+
+func TestRelativo(t *testing.T) {
+	ref := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		l        *Locale
+		t        time.Time
+		esperado string
+	}{
+		{"agora", PtBR, ref.Add(-3 * time.Second), "agora mesmo"},
+		{"minutos", PtBR, ref.Add(-3 * time.Minute), "há 3 minutos"},
+		{"uma hora", PtBR, ref.Add(-61 * time.Minute), "há 1 hora"},
+		{"futuro", PtBR, ref.Add(49 * time.Hour), "em 2 dias"},
+		{"meses", PtBR, ref.AddDate(0, -2, 0), "há 2 meses"},
+		{"en-US", EnUS, ref.Add(-3 * time.Minute), "3 minutes ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := tt.l.Relativo(tt.t, ref); r != tt.esperado {
+				t.Errorf("Relativo = %q; esperado %q", r, tt.esperado)
+			}
+		})
+	}
+}
+
+func TestDuracao(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		esperado string
+	}{
+		{0, "0 segundos"},
+		{time.Second, "1 segundo"},
+		{90 * time.Minute, "1 hora e 30 minutos"},
+		{26*time.Hour + 10*time.Minute, "1 dia e 2 horas"},
+		{time.Hour + 5*time.Second, "1 hora"},
+	}
+
+	for _, tt := range tests {
+		if r := PtBR.Duracao(tt.d); r != tt.esperado {
+			t.Errorf("Duracao(%v) = %q; esperado %q", tt.d, r, tt.esperado)
+		}
+	}
+
+	if r := EnUS.Duracao(90 * time.Minute); r != "1 hour and 30 minutes" {
+		t.Errorf("EnUS.Duracao = %q", r)
+	}
+}
+
+func TestObter(t *testing.T) {
+	if l, err := Obter("en_US"); err != nil || l != EnUS {
+		t.Errorf("Obter(en_US) = %v, %v", l, err)
+	}
+	if _, err := Obter("fr-FR"); !errors.Is(err, ErrLocaleDesconhecido) {
+		t.Errorf("Obter(fr-FR) erro = %v; esperado ErrLocaleDesconhecido", err)
+	}
+}
@@ -59,6 +59,7 @@ type Usuario struct {
 	Ativo    bool   `json:"ativo"`
 }
 
+// Preco em float64 por simplicidade; veja modulo08-packages/dinheiro
 type Produto struct {
 	ID        int     `json:"id"`
 	Nome      string  `json:"nome"`
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"go-course/exercicios/notas"
+	"go-course/exercicios/notas/api"
+	"go-course/modulo12-http/autenticacao"
+	"go-course/modulo12-http/autorizacao"
+	"go-course/modulo12-http/limitador"
+	"go-course/modulo12-http/metricas"
+	"go-course/modulo12-http/middleware"
+	"go-course/modulo12-http/openapi"
+	"go-course/modulo12-http/roteador"
+	"go-course/modulo12-http/saude"
+	"go-course/modulo12-http/servidor"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	if err := executar(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func executar() error {
+	// -endereco, -config, -timeout-*... (ou NOTAS_ENDERECO, NOTAS_CONFIG...)
+	opcoes := servidor.RegistrarFlags(flag.CommandLine, "NOTAS")
+	caminhoAuditoria := flag.String("auditoria", "", "arquivo do log de auditoria (vazio = memória)")
+	origens := flag.String("cors", "", "origens liberadas para CORS, separadas por vírgula")
+	porMinuto := flag.Int("limite", 120, "requisições por minuto por IP (0 = sem limite)")
+	caminhoPolitica := flag.String("politica", "", "política de autorização em JSON (ex.: politica_notas.json)")
+	dependencia := flag.String("depende-de", "", "URL de um serviço que precisa responder para o /readyz passar")
+	caminhoUsuarios := flag.String("usuarios", "", "contas criadas na partida (JSON, ex.: usuarios_notas.json)")
+	gerarHash := flag.Bool("hash-senha", false, "lê uma senha da entrada padrão, imprime o hash para -usuarios e sai")
+	flag.Parse()
+
+	if *gerarHash {
+		senha, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && senha == "" {
+			return fmt.Errorf("ler senha: %w", err)
+		}
+		hash, err := autenticacao.GerarHashSenha(strings.TrimRight(senha, "\r\n"), 0)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hash)
+		return nil
+	}
+
+	// Com NOTAS_CHAVE_TOKEN, alterações exigem login; sem ela, vale X-Autor.
+	// A chave vem do ambiente, não de flag: flags aparecem no ps.
+	var opcoesAPI api.Opcoes
+	var auth *autenticacao.Servico
+	if chave := os.Getenv("NOTAS_CHAVE_TOKEN"); chave != "" {
+		tokens, err := autenticacao.NovosTokens([]byte(chave), autenticacao.OpcoesTokens{Emissor: "notas"})
+		if err != nil {
+			return fmt.Errorf("NOTAS_CHAVE_TOKEN: %w", err)
+		}
+		// Os papéis da política vão por username: as contas que os têm
+		// vêm de -usuarios, nunca da rota aberta /api/auth/registrar
+		// (quem se cadastra por ela fica só com o papel "padrao")
+		usuarios := autenticacao.NovosUsuarios()
+		if *caminhoUsuarios != "" {
+			if err := usuarios.ImportarArquivo(*caminhoUsuarios); err != nil {
+				return err
+			}
+		}
+		auth = autenticacao.NovoServico(usuarios, tokens)
+		// Leituras continuam abertas, mas quem manda token é identificado
+		// (a política pode negar leitura a anônimos)
+		opcoesAPI.Escrita = []roteador.Middleware{auth.Exigir()}
+		opcoesAPI.Leitura = []roteador.Middleware{auth.Identificar()}
+	}
+
+	cfg, err := opcoes.Config()
+	if err != nil {
+		return err
+	}
+
+	var auditoria notas.Auditoria = &notas.AuditoriaMemoria{}
+	if *caminhoAuditoria != "" {
+		arquivo, err := notas.AbrirAuditoria(*caminhoAuditoria)
+		if err != nil {
+			return err
+		}
+		// Com o desligamento gracioso, o defer roda: nada de log.Fatal no meio
+		defer arquivo.Close()
+		auditoria = arquivo
+	}
+
+	// Reaplica o log: o servidor volta com os alunos da última execução
+	sistema, err := notas.NovoSistemaSeguro(auditoria)
+	if err != nil {
+		return err
+	}
+
+	srv := api.NovoServidor(sistema, opcoesAPI)
+
+	// /metrics no formato do Prometheus; o middleware fica antes do
+	// limitador para contar também os 429
+	reg := metricas.NovoRegistro()
+	srv.Grupo("").Get("/metrics", reg.Handler().ServeHTTP)
+
+	cadeia := []func(http.Handler) http.Handler{
+		middleware.IDRequisicao(),
+		middleware.RegistrarAcesso(nil),
+		middleware.Recuperar(nil),
+		metricas.HTTP(reg, metricas.OpcoesHTTP{Rota: srv.PadraoDe}),
+		middleware.CORS(middleware.OpcoesCORS{
+			Origens:  strings.FieldsFunc(*origens, func(r rune) bool { return r == ',' }),
+			Expostos: []string{"ETag", "Location", "Link", middleware.CabecalhoID, "Retry-After"},
+		}),
+	}
+	if *porMinuto > 0 {
+		// Depois do CORS: o preflight não gasta o limite do cliente
+		l := limitador.NovoGCRA(limitador.Taxa{Requisicoes: *porMinuto, Janela: time.Minute, Rajada: 20}, limitador.Opcoes{})
+		cadeia = append(cadeia, limitador.Limitar(l, limitador.PorIP(0)))
+	}
+	cadeia = append(cadeia, middleware.Gzip(gzip.DefaultCompression))
+	if auth != nil {
+		auth.Rotas(srv.Grupo("/api/auth"))
+	}
+	// Documento gerado das rotas (inclusive /api/auth) e o visualizador
+	srv.Grupo("").Get("/openapi.json", srv.OpenAPI(openapi.Info{Titulo: "API de notas", Versao: "1.0"}).ServeHTTP)
+	srv.Grupo("").Get("/docs", openapi.Visualizador("/openapi.json").ServeHTTP)
+	if *caminhoPolitica != "" {
+		politica, err := autorizacao.LerArquivoPolitica(*caminhoPolitica)
+		if err != nil {
+			return err
+		}
+		srv.UsarPolitica(politica)
+	}
+	handler := middleware.Encadear(srv, cadeia...)
+
+	// /healthz e /readyz ficam fora da cadeia: as sondas do balanceador
+	// não poluem o log, as métricas nem gastam o limite por IP
+	verificacoes := saude.NovoRegistro(saude.Opcoes{})
+	if *caminhoAuditoria != "" {
+		verificacoes.Adicionar("auditoria", saude.Gravavel(filepath.Dir(*caminhoAuditoria)), saude.OpcoesVerificacao{})
+	}
+	if *dependencia != "" {
+		verificacoes.Adicionar("depende_de", saude.Alcancavel(nil, *dependencia), saude.OpcoesVerificacao{})
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", verificacoes.HandlerVida())
+	mux.Handle("/readyz", verificacoes.HandlerProntidao())
+	mux.Handle("/", handler)
+
+	// Ctrl-C: o /readyz passa a responder 503, o tráfego continua por
+	// -espera-desligamento e então as requisições em andamento são
+	// aguardadas (até -timeout-desligamento)
+	cfg.Prontidao = verificacoes
+	return servidor.Executar(context.Background(), cfg, mux, nil)
+}
+
+/*
+Execute:
+    go run 02_api_notas.go -auditoria notas.jsonl
+    go run 02_api_notas.go -endereco unix:/tmp/notas.sock -timeout-desligamento 5s
+    NOTAS_CONFIG=servidor.json go run 02_api_notas.go
+    go run 02_api_notas.go -auditoria notas.jsonl -espera-desligamento 5s
+
+Teste:
+    curl -X POST localhost:8080/api/alunos \
+         -H 'Content-Type: application/json' -H 'X-Autor: prof.ana' \
+         -d '{"nome":"Ana","notas":[8,7.5],"frequencia":90}'
+
+    curl -i localhost:8080/api/alunos/Ana              # ETag no cabeçalho
+    curl 'localhost:8080/api/alunos?pagina=1&por_pagina=10'
+    curl localhost:8080/api/estatisticas
+    curl localhost:8080/metrics                        # contadores e latência por rota
+    curl localhost:8080/openapi.json                   # documento OpenAPI 3 (ou abra /docs)
+    curl localhost:8080/readyz                         # 503 durante o desligamento
+
+    curl -X PUT localhost:8080/api/alunos/Ana/notas/2 \
+         -H 'Content-Type: application/json' -H 'If-Match: "<etag>"' \
+         -d '{"nota":8}'                               # 412 se outro cliente alterou antes
+
+Com login (NOTAS_CHAVE_TOKEN=$(openssl rand -hex 32) go run 02_api_notas.go):
+    curl -X POST localhost:8080/api/auth/registrar -H 'Content-Type: application/json' \
+         -d '{"username":"prof.ana","email":"ana@escola.br","senha":"senha-secreta"}'
+    curl -X POST localhost:8080/api/auth/entrar -H 'Content-Type: application/json' \
+         -d '{"username":"prof.ana","senha":"senha-secreta"}'   # {"token_acesso": ...}
+    curl -X POST localhost:8080/api/alunos -H "Authorization: Bearer $TOKEN" \
+         -H 'Content-Type: application/json' -d '{"nome":"Bia","notas":[9]}'
+
+Com papéis (... go run 02_api_notas.go -politica politica_notas.json -usuarios usuarios.json):
+prof.ana cria alunos e altera os que cadastrou; os demais usuários só
+leem (403 ao tentar alterar). As contas de prof.ana e carla vêm de
+usuarios.json: quem se cadastra por /api/auth/registrar, mesmo com
+o username "carla", fica só com o papel "padrao".
+
+    echo 'senha-da-ana' | go run 02_api_notas.go -hash-senha   # pbkdf2-sha256$600000$...
+    [{"username": "prof.ana", "email": "ana@escola.br", "hash_senha": "pbkdf2-sha256$..."},
+     {"username": "carla", "email": "carla@escola.br", "hash_senha": "pbkdf2-sha256$..."}]
+*/
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-course/modulo12-http/openapi"
+	"go-course/modulo12-http/roteador"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+type Mensagem struct {
+	Texto string `json:"texto" doc:"conteúdo da mensagem"`
+	Autor string `json:"autor"`
+}
+
+// Produto segue o de modulo10-json/01_json_basico.go
+type Produto struct {
+	ID        int     `json:"id"`
+	Nome      string  `json:"nome"`
+	Preco     float64 `json:"preco" doc:"em reais"`
+	Estoque   int     `json:"estoque,omitempty"`
+	Descricao string  `json:"descricao,omitempty"`
+}
+
+var produtos = []Produto{
+	{ID: 1, Nome: "Caderno", Preco: 12.5, Estoque: 40},
+	{ID: 2, Nome: "Caneta", Preco: 2.9, Estoque: 300, Descricao: "azul"},
+}
+
+func responderJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// registrarTempo é um middleware: mede cada requisição do grupo
+func registrarTempo(proximo http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inicio := time.Now()
+		proximo.ServeHTTP(w, r)
+		log.Printf("%s %s (rota %s) em %v", r.Method, r.URL.Path, roteador.Padrao(r), time.Since(inicio))
+	})
+}
+
+func main() {
+	r := roteador.Novo()
+
+	// Diferente do ServeMux, "/" casa só com "/"
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Bem-vindo ao servidor Go!")
+	})
+
+	api := r.Grupo("/api", registrarTempo)
+	api.Get("/mensagens/{autor}", func(w http.ResponseWriter, r *http.Request) {
+		autor := roteador.Parametro(r, "autor")
+		responderJSON(w, Mensagem{Texto: "Olá, " + autor + "!", Autor: "Sistema"})
+	}).Documentar(roteador.Documentacao{
+		Resumo:   "Saudação para o autor",
+		Tags:     []string{"mensagens"},
+		Resposta: Mensagem{},
+	})
+	api.Post("/mensagens", func(w http.ResponseWriter, r *http.Request) {
+		var msg Mensagem
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		responderJSON(w, msg)
+	}).Documentar(roteador.Documentacao{
+		Resumo:     "Publica uma mensagem",
+		Tags:       []string{"mensagens"},
+		Requisicao: Mensagem{},
+		Resposta:   Mensagem{},
+		Status:     http.StatusCreated,
+	})
+	api.Get("/produtos", func(w http.ResponseWriter, r *http.Request) {
+		responderJSON(w, produtos)
+	}).Documentar(roteador.Documentacao{
+		Resumo:   "Lista os produtos",
+		Tags:     []string{"produtos"},
+		Resposta: []Produto{},
+	})
+	api.Get("/produtos/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range produtos {
+			if strconv.Itoa(p.ID) == roteador.Parametro(r, "id") {
+				responderJSON(w, p)
+				return
+			}
+		}
+		http.Error(w, "produto não encontrado", http.StatusNotFound)
+	}).Documentar(roteador.Documentacao{
+		Resumo:   "Busca um produto pelo ID",
+		Tags:     []string{"produtos"},
+		Resposta: Produto{},
+	})
+	api.Get("/arquivos/{caminho...}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "arquivo pedido: %q\n", roteador.Parametro(r, "caminho"))
+	})
+
+	// A documentação sai das próprias rotas (veja o package openapi)
+	r.Get("/openapi.json", openapi.Handler(r, openapi.Info{Titulo: "Exemplo do roteador", Versao: "1.0"}).ServeHTTP)
+	r.Get("/docs", openapi.Visualizador("/openapi.json").ServeHTTP)
+
+	fmt.Println("\n=== Rotas ===")
+	r.EscreverTabela(os.Stdout)
+
+	fmt.Println("\nServidor rodando em http://localhost:8080")
+	log.Fatal(http.ListenAndServe(":8080", r))
+}
+
+/*
+Execute:
+    go run 03_roteador.go
+
+Teste:
+    curl localhost:8080/api/mensagens/Ana
+    curl -X POST localhost:8080/api/mensagens -d '{"texto":"oi","autor":"Ana"}'
+    curl localhost:8080/api/produtos/2
+    curl localhost:8080/api/arquivos/docs/2024/plano.pdf
+    curl -i -X DELETE localhost:8080/api/mensagens/Ana   # 405 com Allow: GET, HEAD, OPTIONS
+    curl -i localhost:8080/inexistente                   # 404
+    curl localhost:8080/openapi.json                     # documento OpenAPI 3
+
+Abra http://localhost:8080/docs para ver a documentação no navegador.
+*/
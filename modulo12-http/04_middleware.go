@@ -0,0 +1,87 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"go-course/modulo12-http/cache"
+	"go-course/modulo12-http/middleware"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type Mensagem struct {
+	Texto string `json:"texto"`
+	Autor string `json:"autor"`
+}
+
+func jsonHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Mensagem{Texto: "Olá do servidor Go!", Autor: "Sistema"})
+}
+
+// quebradoHandler simula um bug: sem Recuperar, o net/http fecha a
+// conexão e o cliente recebe "empty reply from server"
+func quebradoHandler(w http.ResponseWriter, r *http.Request) {
+	var contagem map[string]int
+	contagem["visitas"]++ // panic: assignment to entry in nil map
+}
+
+// longoHandler gera uma resposta grande o bastante para o gzip
+func longoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, strings.Repeat("Go é simples. ", 1000))
+}
+
+func main() {
+	// Middlewares por rota: 304 para quem já tem a mensagem; a resposta
+	// longa fica guardada no servidor (sem compactar: o Gzip vem antes)
+	respostas := cache.Novo(cache.Opcoes{TTL: 30 * time.Second})
+	mux := http.NewServeMux()
+	mux.Handle("/api/mensagem", middleware.Encadear(http.HandlerFunc(jsonHandler),
+		cache.Controle("public, max-age=60"),
+		cache.ETag(cache.OpcoesETag{}),
+	))
+	mux.HandleFunc("/api/quebrado", quebradoHandler)
+	mux.Handle("/api/longo", middleware.Encadear(http.HandlerFunc(longoHandler),
+		cache.Armazenar(respostas),
+		cache.ETag(cache.OpcoesETag{}),
+	))
+
+	logger := log.New(os.Stdout, "[http] ", log.LstdFlags)
+
+	// O primeiro é o mais externo: o ID já existe quando o log é escrito
+	handler := middleware.Encadear(mux,
+		middleware.IDRequisicao(),
+		middleware.RegistrarAcesso(logger),
+		middleware.Recuperar(logger),
+		middleware.CORS(middleware.OpcoesCORS{Origens: []string{"http://localhost:3000"}}),
+		middleware.Gzip(gzip.DefaultCompression),
+	)
+
+	fmt.Println("Servidor rodando em http://localhost:8080")
+	log.Fatal(http.ListenAndServe(":8080", handler))
+}
+
+/*
+Execute:
+    go run 04_middleware.go
+
+Teste:
+    curl -i localhost:8080/api/mensagem                 # X-Request-ID na resposta
+    curl -i localhost:8080/api/quebrado                 # 500 {"erro":"erro interno",...}
+    curl -i -H 'Accept-Encoding: gzip' localhost:8080/api/longo --output -
+    curl -i -X OPTIONS localhost:8080/api/mensagem \
+         -H 'Origin: http://localhost:3000' -H 'Access-Control-Request-Method: PUT'
+
+Cache:
+    curl -i localhost:8080/api/mensagem                 # ETag: "..." e Cache-Control
+    curl -i -H 'If-None-Match: "<etag>"' localhost:8080/api/mensagem   # 304, sem corpo
+    curl -si localhost:8080/api/longo | grep X-Cache    # MISS, depois HIT (Age: n)
+
+No terminal do servidor:
+    [http] GET /api/quebrado 500 60B 210µs id=9f2c...
+*/
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-course/modulo12-http/servidor"
+	"log"
+	"net/http"
+	"time"
+)
+
+// lentoHandler simula um relatório demorado: aperte Ctrl-C enquanto
+// ele roda e veja a resposta chegar antes de o servidor sair
+func lentoHandler(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-time.After(5 * time.Second):
+		fmt.Fprintln(w, "relatório pronto")
+	case <-r.Context().Done():
+		// Cliente desistiu ou o prazo de desligamento acabou
+		log.Printf("relatório cancelado: %v", r.Context().Err())
+	}
+}
+
+func main() {
+	opcoes := servidor.RegistrarFlags(flag.CommandLine, "EXEMPLO")
+	flag.Parse()
+	cfg, err := opcoes.Config()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Bem-vindo ao servidor Go!")
+	})
+	mux.HandleFunc("/lento", lentoHandler)
+
+	if err := servidor.Executar(context.Background(), cfg, mux, nil); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Até logo!")
+}
+
+/*
+Execute:
+    go run 05_desligamento_gracioso.go
+    go run 05_desligamento_gracioso.go -endereco :9090 -timeout-desligamento 2s
+    EXEMPLO_ENDERECO=unix:/tmp/exemplo.sock go run 05_desligamento_gracioso.go
+
+Teste (em outro terminal) e aperte Ctrl-C no servidor logo depois:
+    curl localhost:8080/lento
+    curl --unix-socket /tmp/exemplo.sock http://x/lento
+
+Com -timeout-desligamento 2s o relatório é cancelado e o servidor
+termina com "desligamento forçado".
+
+Socket activation com systemd (o systemd abre a porta 80 e passa o
+socket pronto; o processo nem precisa de permissão de root):
+
+    # exemplo.socket
+    [Socket]
+    ListenStream=80
+
+    # exemplo.service
+    [Service]
+    ExecStart=/usr/local/bin/exemplo -endereco systemd
+*/
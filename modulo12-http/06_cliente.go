@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-course/exercicios/notas/api"
+	"go-course/modulo07-erros/erros"
+	"go-course/modulo12-http/cliente"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	base := flag.String("url", "http://localhost:8080", "endereço da API de notas (02_api_notas.go)")
+	prazo := flag.Duration("prazo", 15*time.Second, "prazo total, novas tentativas inclusive")
+	flag.Parse()
+
+	cabecalhos := http.Header{"X-Autor": {"cliente-exemplo"}}
+	if token := os.Getenv("NOTAS_TOKEN"); token != "" {
+		cabecalhos.Set("Authorization", "Bearer "+token)
+	}
+	c := cliente.Novo(*base, cliente.Opcoes{
+		TimeoutTentativa: 3 * time.Second,
+		Cabecalhos:       cabecalhos,
+		AoRetentar: func(tentativa int, espera time.Duration, err error) {
+			log.Printf("tentativa %d falhou (%v); nova tentativa em %v", tentativa, err, espera.Round(time.Millisecond))
+		},
+	})
+
+	// O context limita tudo: tentativas e esperas entre elas
+	ctx, cancelar := context.WithTimeout(context.Background(), *prazo)
+	defer cancelar()
+
+	var criado api.AlunoJSON
+	err := c.PostJSON(ctx, "/api/alunos", map[string]any{"nome": "Ana", "notas": []float64{8, 7.5}}, &criado)
+	var errHTTP erros.ErroHTTP
+	switch {
+	case errors.As(err, &errHTTP) && errHTTP.StatusCode == http.StatusConflict:
+		fmt.Println("Ana já estava cadastrada")
+	case err != nil:
+		log.Fatal(err)
+	default:
+		fmt.Printf("Cadastrada: %s, média %.2f\n", criado.Nome, criado.Media)
+	}
+
+	var pagina api.Pagina
+	if err := c.GetJSON(ctx, "/api/alunos?por_pagina=5", &pagina); err != nil {
+		log.Fatal(err)
+	}
+	for _, a := range pagina.Itens {
+		fmt.Printf("  %-10s %5.2f  %s\n", a.Nome, a.Media, a.Conceito)
+	}
+
+	// 404 não é passageiro: volta na hora, sem novas tentativas
+	err = c.GetJSON(ctx, "/api/alunos/Ninguem", nil)
+	if errors.As(err, &errHTTP) {
+		fmt.Printf("GET /api/alunos/Ninguem: %d %s\n", errHTTP.StatusCode, errHTTP.Mensagem)
+	}
+}
+
+/*
+Execute (com a API rodando: go run 02_api_notas.go):
+    go run 06_cliente.go
+    go run 06_cliente.go -url http://localhost:9090 -prazo 5s
+
+Teste as novas tentativas: rode o cliente com a API parada e suba-a
+em seguida; o log mostra as esperas crescendo até a conexão voltar
+(ou o -prazo acabar). Com -limite 1 na API, o 429 traz Retry-After
+e o cliente espera o tempo pedido.
+*/
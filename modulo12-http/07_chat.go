@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"go-course/modulo12-http/chat"
+	"go-course/modulo12-http/middleware"
+	"go-course/modulo12-http/servidor"
+	"go-course/modulo12-http/websocket"
+	"log"
+	"net/http"
+)
+
+// pagina é um cliente mínimo: o WebSocket do navegador já responde
+// aos pings e faz o handshake sozinho
+const pagina = `<!doctype html>
+<meta charset="utf-8">
+<title>Chat</title>
+<form id="entrar">
+  <input id="sala" value="geral"> <input id="apelido" placeholder="apelido" required>
+  <button>Entrar</button>
+</form>
+<pre id="log" style="height: 60vh; overflow: auto"></pre>
+<form id="falar"><input id="texto" size="60" autocomplete="off"> <button>Enviar</button></form>
+<script>
+let ws;
+const log = t => { const l = document.getElementById("log"); l.textContent += t + "\n"; l.scrollTop = 1e9; };
+document.getElementById("entrar").onsubmit = e => {
+  e.preventDefault();
+  const q = new URLSearchParams({sala: sala.value, apelido: apelido.value});
+  ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/chat?" + q);
+  ws.onmessage = e => {
+    const m = JSON.parse(e.data), hora = new Date(m.em).toLocaleTimeString();
+    const texto = {
+      mensagem: m.apelido + ": " + m.texto,
+      entrou: "→ " + m.apelido + " entrou",
+      saiu: "← " + m.apelido + " saiu",
+      apelido: m.texto + " agora é " + m.apelido,
+      membros: "na sala: " + m.membros.join(", "),
+      erro: "erro: " + m.texto,
+    }[m.tipo];
+    log((m.historico ? "(antes) " : "") + hora + " " + texto);
+  };
+  ws.onclose = e => log("conexão encerrada (" + e.code + ") " + e.reason);
+};
+document.getElementById("falar").onsubmit = e => {
+  e.preventDefault();
+  if (ws && texto.value) { ws.send(texto.value); texto.value = ""; }
+};
+</script>
+`
+
+func main() {
+	opcoes := servidor.RegistrarFlags(flag.CommandLine, "CHAT")
+	historico := flag.Int("historico", 50, "mensagens de cada sala repassadas a quem entra")
+	flag.Parse()
+	cfg, err := opcoes.Config()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hub := chat.NovoHub(chat.Opcoes{Historico: *historico})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(pagina))
+	})
+	mux.Handle("/chat", hub.Handler(websocket.Opcoes{}))
+
+	// Os middlewares deixam o Hijack passar (veja middleware.go)
+	handler := middleware.Encadear(mux, middleware.IDRequisicao(), middleware.RegistrarAcesso(nil), middleware.Recuperar(nil))
+	err = servidor.Executar(context.Background(), cfg, handler, nil)
+	// Shutdown não espera conexões WebSocket (foram tomadas com Hijack):
+	// fechar o hub manda a cada cliente um close 1001 ("saindo")
+	hub.Fechar()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+/*
+Execute:
+    go run 07_chat.go
+    go run 07_chat.go -endereco :9090 -historico 10
+
+Abra http://localhost:8080 em duas abas, entre com apelidos
+diferentes e converse. Comandos: /nick novo, /quem, /sair.
+
+Sem navegador, com o cliente do package websocket:
+    conn, _, err := websocket.Discar(ctx, "ws://localhost:8080/chat?sala=geral&apelido=bot", websocket.Opcoes{})
+    conn.EscreverMensagem(websocket.Texto, []byte("olá, sala"))
+*/
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"go-course/modulo12-http/middleware"
+	"go-course/modulo12-http/servidor"
+	"go-course/modulo12-http/sse"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+type Mensagem struct {
+	Texto string `json:"texto"`
+	Autor string `json:"autor"`
+}
+
+// painel atualiza sozinho: o EventSource reconecta e manda o
+// Last-Event-ID sem nenhum código a mais
+const painel = `<!doctype html>
+<meta charset="utf-8">
+<title>Painel</title>
+<p>Estado: <b id="estado">conectando…</b></p>
+<form id="enviar">
+  <input id="autor" placeholder="autor" required> <input id="texto" size="50" required>
+  <button>Publicar</button>
+</form>
+<ul id="lista"></ul>
+<script>
+const estado = document.getElementById("estado"), lista = document.getElementById("lista");
+const fonte = new EventSource("/eventos");
+fonte.onopen = () => estado.textContent = "ao vivo";
+fonte.onerror = () => estado.textContent = fonte.readyState === EventSource.CLOSED ? "encerrado" : "reconectando…";
+fonte.addEventListener("mensagem", e => {
+  const m = JSON.parse(e.data), li = document.createElement("li");
+  li.textContent = "#" + e.lastEventId + " " + m.autor + ": " + m.texto;
+  lista.prepend(li);
+});
+fonte.addEventListener("lacuna", () => estado.textContent = "eventos perdidos: recarregue a página");
+document.getElementById("enviar").onsubmit = e => {
+  e.preventDefault();
+  fetch("/api/mensagem", {method: "POST", headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({autor: autor.value, texto: texto.value})});
+  texto.value = "";
+};
+</script>
+`
+
+func main() {
+	opcoes := servidor.RegistrarFlags(flag.CommandLine, "EVENTOS")
+	historico := flag.Int("historico", 100, "eventos guardados para quem reconecta")
+	flag.Parse()
+	cfg, err := opcoes.Config()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	canal := sse.NovoCanal(sse.Opcoes{Historico: *historico})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(painel))
+	})
+	mux.Handle("/eventos", canal)
+	mux.HandleFunc("/api/mensagem", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+			return
+		}
+		var m Mensagem
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&m); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(m.Texto) == "" || strings.TrimSpace(m.Autor) == "" {
+			http.Error(w, "texto e autor são obrigatórios", http.StatusUnprocessableEntity)
+			return
+		}
+		e, err := canal.Publicar("mensagem", m)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{"id": e.ID, "clientes": canal.Clientes()})
+	})
+
+	// Shutdown espera as respostas em andamento, e um fluxo SSE não
+	// acaba sozinho: no sinal, fechar o canal encerra os fluxos primeiro
+	ctx, parar := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer parar()
+	go func() {
+		<-ctx.Done()
+		canal.Fechar()
+	}()
+
+	// O gzip não atrasa os eventos: o Flush de cada um passa pelo compressor
+	handler := middleware.Encadear(mux,
+		middleware.IDRequisicao(),
+		middleware.RegistrarAcesso(nil),
+		middleware.Recuperar(nil),
+		middleware.Gzip(gzip.DefaultCompression),
+	)
+	if err := servidor.Executar(ctx, cfg, handler, nil); err != nil {
+		log.Fatal(err)
+	}
+}
+
+/*
+Execute:
+    go run 08_eventos.go
+    go run 08_eventos.go -endereco :9090 -historico 20
+
+Abra http://localhost:8080 em algumas abas e publique mensagens.
+
+Pelo terminal:
+    curl -N http://localhost:8080/eventos
+    curl -N -H "Last-Event-ID: 3" http://localhost:8080/eventos   # retoma depois do 3
+    curl -X POST -d '{"autor":"ana","texto":"oi"}' http://localhost:8080/api/mensagem
+
+Ctrl-C no servidor: os painéis mostram "reconectando…" e voltam
+sozinhos quando ele sobe de novo.
+*/
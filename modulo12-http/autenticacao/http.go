@@ -0,0 +1,363 @@
+package autenticacao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+/*
+ROTAS HTTP (registradas num grupo, ex.: "/api/auth")
+
+    POST /registrar   {"username","email","senha"}   → 201 usuário
+    POST /entrar      {"username","senha"}           → 200 par de tokens
+    POST /renovar     {"token_renovacao"}            → 200 par novo (o antigo é revogado)
+    POST /sair        [{"token_renovacao"}]          → 204 (Bearer obrigatório)
+    GET  /eu                                         → 200 usuário (Bearer obrigatório)
+
+Quem se cadastra por /registrar recebe Usuario.AutoCadastro: o
+username é escolha livre, então não dá papéis. Contas privilegiadas
+são criadas fora da aplicação (Usuarios.Importar).
+
+Rotas protegidas usam Exigir; sem token válido a resposta é 401 com
+WWW-Authenticate: Bearer (RFC 6750). Todos os erros respondem
+application/problem+json (package problema). Rotas abertas usam Identificar:
+sem token passam como anônimas. O handler lê o usuário com
+UsuarioDe(r.Context()).
+
+Login errado sempre responde a mesma coisa, exista o usuário ou não.
+Para conter tentativas em massa, ponha um limitador por IP na frente
+de /entrar (veja modulo12-http/limitador).
+*/
+
+// TamanhoMaximoCorpo limita os corpos JSON das rotas de autenticação
+const TamanhoMaximoCorpo = 64 << 10
+
+// Servico junta cadastro e tokens para as rotas e o middleware
+type Servico struct {
+	usuarios *Usuarios
+	tokens   *Tokens
+}
+
+// NovoServico cria o serviço de autenticação
+func NovoServico(usuarios *Usuarios, tokens *Tokens) *Servico {
+	return &Servico{usuarios: usuarios, tokens: tokens}
+}
+
+// Rotas registra as rotas de autenticação no grupo, documentadas para
+// o OpenAPI
+func (s *Servico) Rotas(g *roteador.GrupoRotas) {
+	g.Post("/registrar", s.registrar).Documentar(roteador.Documentacao{
+		Resumo:     "Cadastra um usuário",
+		Descricao:  "409 se o username já existe; 422 para username, email ou senha inválidos.",
+		Tags:       []string{"autenticacao"},
+		Requisicao: entradaRegistro{},
+		Resposta:   Usuario{},
+		Status:     http.StatusCreated,
+		Erros:      []int{400, 409, 422},
+	})
+	g.Post("/entrar", s.entrar).Documentar(roteador.Documentacao{
+		Resumo:     "Troca usuário e senha por um par de tokens",
+		Descricao:  "401 para credenciais erradas; 403 para usuário inativo.",
+		Tags:       []string{"autenticacao"},
+		Requisicao: entradaLogin{},
+		Resposta:   Par{},
+		Erros:      []int{400, 401, 403},
+	})
+	g.Post("/renovar", s.renovar).Documentar(roteador.Documentacao{
+		Resumo:     "Troca o token de renovação por um par novo",
+		Descricao:  "O token usado é revogado; 401 se ele for inválido ou já tiver sido usado.",
+		Tags:       []string{"autenticacao"},
+		Requisicao: entradaRenovacao{},
+		Resposta:   Par{},
+		Erros:      []int{400, 401},
+	})
+
+	protegidas := g.Grupo("", s.Exigir())
+	protegidas.Post("/sair", s.sair).Documentar(roteador.Documentacao{
+		Resumo:    "Revoga o token de acesso (e o de renovação, se enviado)",
+		Descricao: "Exige Authorization: Bearer; o corpo é opcional.",
+		Tags:      []string{"autenticacao"},
+		Status:    http.StatusNoContent,
+		Erros:     []int{400, 401},
+	})
+	protegidas.Get("/eu", s.eu).Documentar(roteador.Documentacao{
+		Resumo:    "Usuário do token",
+		Descricao: "Exige Authorization: Bearer.",
+		Tags:      []string{"autenticacao"},
+		Resposta:  Usuario{},
+		Erros:     []int{401},
+	})
+}
+
+// entradaRegistro é o corpo de POST /registrar
+type entradaRegistro struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Senha    string `json:"senha"`
+}
+
+// entradaLogin é o corpo de POST /entrar
+type entradaLogin struct {
+	Username string `json:"username"`
+	Senha    string `json:"senha"`
+}
+
+// entradaRenovacao é o corpo de POST /renovar (e, opcional, de /sair)
+type entradaRenovacao struct {
+	Token string `json:"token_renovacao"`
+}
+
+// ========================================
+// CONTEXT
+// ========================================
+
+type chaveIdentidade struct{}
+
+// identidade é o que Exigir guarda no context
+type identidade struct {
+	usuario Usuario
+	claims  Claims
+}
+
+// ComUsuario devolve um context com o usuário autenticado (usado por
+// Exigir; útil em testes de handlers)
+func ComUsuario(ctx context.Context, u Usuario) context.Context {
+	return context.WithValue(ctx, chaveIdentidade{}, identidade{usuario: u})
+}
+
+// UsuarioDe retorna o usuário autenticado pela requisição, se houver
+func UsuarioDe(ctx context.Context) (Usuario, bool) {
+	id, ok := ctx.Value(chaveIdentidade{}).(identidade)
+	return id.usuario, ok
+}
+
+// ========================================
+// MIDDLEWARE
+// ========================================
+
+// Exigir só deixa passar requisições com "Authorization: Bearer <token
+// de acesso>" válido, de um usuário ativo; as demais recebem 401
+func (s *Servico) Exigir() func(http.Handler) http.Handler {
+	return s.autenticar(true)
+}
+
+// Identificar põe o usuário no context quando a requisição traz um
+// token válido e deixa passar sem usuário quando não traz nenhum (rotas
+// abertas que a autorização trata como "anonimo"). Um token inválido
+// continua sendo 401: o cliente achou que estava autenticado.
+func (s *Servico) Identificar() func(http.Handler) http.Handler {
+	return s.autenticar(false)
+}
+
+func (s *Servico) autenticar(obrigatorio bool) func(http.Handler) http.Handler {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := tokenBearer(r)
+			if !ok {
+				if obrigatorio {
+					negar(w, r, "", "token de acesso ausente")
+					return
+				}
+				proximo.ServeHTTP(w, r)
+				return
+			}
+			c, err := s.tokens.Validar(token, TipoAcesso)
+			if err != nil {
+				negar(w, r, "invalid_token", err.Error())
+				return
+			}
+			// O token pode ter sido emitido antes de o usuário ser desativado
+			u, err := s.usuarios.Buscar(c.Sujeito)
+			if err != nil || !u.Ativo {
+				negar(w, r, "invalid_token", ErrUsuarioInativo.Error())
+				return
+			}
+			ctx := context.WithValue(r.Context(), chaveIdentidade{}, identidade{usuario: u, claims: c})
+			proximo.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// tokenBearer lê o token de "Authorization: Bearer ..."
+func tokenBearer(r *http.Request) (string, bool) {
+	esquema, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(esquema, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// negar responde 401 com o desafio Bearer da RFC 6750
+func negar(w http.ResponseWriter, r *http.Request, codigo, mensagem string) {
+	desafio := `Bearer realm="api"`
+	if codigo != "" {
+		desafio += `, error="` + codigo + `"`
+	}
+	problemas.Responder(w, r, &erroResposta{status: http.StatusUnauthorized, mensagem: mensagem, desafio: desafio})
+}
+
+// ========================================
+// HANDLERS
+// ========================================
+
+func (s *Servico) registrar(w http.ResponseWriter, r *http.Request) {
+	var entrada entradaRegistro
+	if !lerJSON(w, r, &entrada) {
+		return
+	}
+	u, err := s.usuarios.autoRegistrar(entrada.Username, entrada.Email, entrada.Senha)
+	if err != nil {
+		problemas.Responder(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusCreated, u)
+}
+
+func (s *Servico) entrar(w http.ResponseWriter, r *http.Request) {
+	var entrada entradaLogin
+	if !lerJSON(w, r, &entrada) {
+		return
+	}
+	u, err := s.usuarios.Autenticar(entrada.Username, entrada.Senha)
+	if errors.Is(err, ErrUsuarioInativo) {
+		problemas.Responder(w, r, err)
+		return
+	}
+	if err != nil {
+		problemas.Responder(w, r, ErrCredenciais)
+		return
+	}
+	par, err := s.tokens.Emitir(u.Username)
+	s.responderPar(w, r, par, err)
+}
+
+func (s *Servico) renovar(w http.ResponseWriter, r *http.Request) {
+	var entrada entradaRenovacao
+	if !lerJSON(w, r, &entrada) {
+		return
+	}
+	c, err := s.tokens.Validar(entrada.Token, TipoRenovacao)
+	if err == nil {
+		if u, errBusca := s.usuarios.Buscar(c.Sujeito); errBusca != nil || !u.Ativo {
+			err = ErrUsuarioInativo
+		}
+	}
+	if err != nil {
+		responderErro(w, r, http.StatusUnauthorized, err.Error())
+		return
+	}
+	par, err := s.tokens.Renovar(entrada.Token)
+	if err != nil {
+		responderErro(w, r, http.StatusUnauthorized, err.Error())
+		return
+	}
+	s.responderPar(w, r, par, nil)
+}
+
+func (s *Servico) sair(w http.ResponseWriter, r *http.Request) {
+	id, _ := r.Context().Value(chaveIdentidade{}).(identidade)
+	var entrada entradaRenovacao
+	// O corpo é opcional: sem ele, só o token de acesso é revogado
+	if r.ContentLength != 0 && !lerJSON(w, r, &entrada) {
+		return
+	}
+	if entrada.Token != "" {
+		c, err := s.tokens.ler(entrada.Token)
+		if err != nil || c.Tipo != TipoRenovacao || c.Sujeito != id.usuario.Username {
+			responderErro(w, r, http.StatusBadRequest, "token_renovacao inválido")
+			return
+		}
+		s.tokens.revogar(c)
+	}
+	s.tokens.revogar(id.claims)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Servico) eu(w http.ResponseWriter, r *http.Request) {
+	u, _ := UsuarioDe(r.Context())
+	responderJSON(w, http.StatusOK, u)
+}
+
+// ========================================
+// AUXILIARES
+// ========================================
+
+func (s *Servico) responderPar(w http.ResponseWriter, r *http.Request, par Par, err error) {
+	if err != nil {
+		problemas.Responder(w, r, err) // 500: a causa vai só para o log
+		return
+	}
+	// Tokens não devem ficar em cache de proxies
+	w.Header().Set("Cache-Control", "no-store")
+	responderJSON(w, http.StatusOK, par)
+}
+
+// lerJSON decodifica um único objeto JSON; em caso de erro já responde
+func lerJSON(w http.ResponseWriter, r *http.Request, destino any) bool {
+	if tipo, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); tipo != "application/json" {
+		responderErro(w, r, http.StatusUnsupportedMediaType, "Content-Type deve ser application/json")
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, TamanhoMaximoCorpo))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(destino)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("o corpo deve conter um único objeto JSON")
+	}
+	if err != nil {
+		responderErro(w, r, http.StatusBadRequest, "corpo inválido: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// erroResposta é um erro já com o status da resposta; desafio, se não
+// vazio, vai em WWW-Authenticate
+type erroResposta struct {
+	status   int
+	mensagem string
+	desafio  string
+}
+
+func (e *erroResposta) Error() string { return e.mensagem }
+
+// problemas traduz os erros do package em problem+json; as mensagens
+// dos sentinels podem ir para o cliente
+var problemas = novosProblemas()
+
+func novosProblemas() *problema.Registro {
+	reg := problema.Padrao()
+	reg.Sentinela(ErrUsuarioExistente, problema.Regra{Status: http.StatusConflict, Publica: true})
+	reg.Sentinela(ErrUsernameInvalido, problema.Regra{Status: http.StatusUnprocessableEntity, Publica: true, Campo: "username"})
+	reg.Sentinela(ErrEmailInvalido, problema.Regra{Status: http.StatusUnprocessableEntity, Publica: true, Campo: "email"})
+	reg.Sentinela(ErrSenhaCurta, problema.Regra{Status: http.StatusUnprocessableEntity, Publica: true, Campo: "senha"})
+	reg.Sentinela(ErrSenhaLonga, problema.Regra{Status: http.StatusUnprocessableEntity, Publica: true, Campo: "senha"})
+	reg.Sentinela(ErrCredenciais, problema.Regra{Status: http.StatusUnauthorized, Publica: true})
+	reg.Sentinela(ErrUsuarioInativo, problema.Regra{Status: http.StatusForbidden, Publica: true})
+	problema.RegistrarTipo(reg, func(e *erroResposta) problema.Problema {
+		p := problema.Problema{Status: e.status, Detalhe: e.mensagem}
+		if e.desafio != "" {
+			p.Cabecalhos = http.Header{"Www-Authenticate": {e.desafio}}
+		}
+		return p
+	})
+	return reg
+}
+
+func responderErro(w http.ResponseWriter, r *http.Request, status int, mensagem string) {
+	problemas.Responder(w, r, &erroResposta{status: status, mensagem: mensagem})
+}
+
+func responderJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,216 @@
+package autenticacao
+
+import (
+	"encoding/json"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func novoRoteadorTeste(t *testing.T) (*roteador.Roteador, *Usuarios) {
+	t.Helper()
+	usuarios := novosUsuariosTeste()
+	tokens, _ := novosTokensTeste(t)
+	s := NovoServico(usuarios, tokens)
+
+	r := roteador.Novo()
+	s.Rotas(r.Grupo("/auth"))
+	quem := func(w http.ResponseWriter, req *http.Request) {
+		u, _ := UsuarioDe(req.Context())
+		w.Write([]byte(u.Username))
+	}
+	r.Grupo("/api", s.Exigir()).Get("/quem", quem)
+	r.Grupo("/aberta", s.Identificar()).Get("/quem", quem)
+	return r, usuarios
+}
+
+func requisitar(h http.Handler, metodo, caminho, corpo string, cabecalhos ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(metodo, caminho, strings.NewReader(corpo))
+	if corpo != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(cabecalhos); i += 2 {
+		req.Header.Set(cabecalhos[i], cabecalhos[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func entrar(t *testing.T, h http.Handler, username, senha string) Par {
+	t.Helper()
+	rec := requisitar(h, "POST", "/auth/entrar", `{"username":"`+username+`","senha":"`+senha+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("entrar = %d %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Error("resposta com tokens deve ter Cache-Control: no-store")
+	}
+	var par Par
+	json.NewDecoder(rec.Body).Decode(&par)
+	return par
+}
+
+func TestFluxoCompleto(t *testing.T) {
+	h, _ := novoRoteadorTeste(t)
+
+	rec := requisitar(h, "POST", "/auth/registrar", `{"username":"ana","email":"ana@escola.br","senha":"senha-secreta"}`)
+	if rec.Code != http.StatusCreated || strings.Contains(rec.Body.String(), "pbkdf2") || !strings.Contains(rec.Body.String(), `"auto_cadastro":true`) {
+		t.Fatalf("registrar = %d %s", rec.Code, rec.Body)
+	}
+	if rec := requisitar(h, "POST", "/auth/registrar", `{"username":"ana","email":"a@escola.br","senha":"senha-secreta"}`); rec.Code != http.StatusConflict {
+		t.Errorf("registro duplicado = %d", rec.Code)
+	}
+
+	par := entrar(t, h, "ana", "senha-secreta")
+	bearer := "Bearer " + par.Acesso
+
+	rec = requisitar(h, "GET", "/api/quem", "", "Authorization", bearer)
+	if rec.Code != http.StatusOK || rec.Body.String() != "ana" {
+		t.Fatalf("rota protegida = %d %q", rec.Code, rec.Body)
+	}
+	rec = requisitar(h, "GET", "/auth/eu", "", "Authorization", bearer)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"username":"ana"`) {
+		t.Errorf("eu = %d %s", rec.Code, rec.Body)
+	}
+
+	// Renovar: par novo; o token de renovação antigo não serve mais
+	rec = requisitar(h, "POST", "/auth/renovar", `{"token_renovacao":"`+par.Renovacao+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("renovar = %d %s", rec.Code, rec.Body)
+	}
+	var novo Par
+	json.NewDecoder(rec.Body).Decode(&novo)
+	if rec := requisitar(h, "POST", "/auth/renovar", `{"token_renovacao":"`+par.Renovacao+`"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("renovar de novo com o mesmo token = %d", rec.Code)
+	}
+
+	// Sair revoga o acesso e a renovação informada
+	rec = requisitar(h, "POST", "/auth/sair", `{"token_renovacao":"`+novo.Renovacao+`"}`, "Authorization", "Bearer "+novo.Acesso)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("sair = %d %s", rec.Code, rec.Body)
+	}
+	if rec := requisitar(h, "GET", "/api/quem", "", "Authorization", "Bearer "+novo.Acesso); rec.Code != http.StatusUnauthorized {
+		t.Errorf("acesso após sair = %d", rec.Code)
+	}
+	if rec := requisitar(h, "POST", "/auth/renovar", `{"token_renovacao":"`+novo.Renovacao+`"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("renovar após sair = %d", rec.Code)
+	}
+	// O primeiro token de acesso não foi revogado: vale até expirar
+	if rec := requisitar(h, "GET", "/api/quem", "", "Authorization", bearer); rec.Code != http.StatusOK {
+		t.Errorf("outro token de acesso = %d", rec.Code)
+	}
+}
+
+func TestExigir_Negado(t *testing.T) {
+	h, usuarios := novoRoteadorTeste(t)
+	usuarios.Registrar("ana", "ana@escola.br", "senha-secreta")
+	par := entrar(t, h, "ana", "senha-secreta")
+
+	testes := []struct {
+		nome, autorizacao, desafio string
+	}{
+		{"sem cabeçalho", "", `Bearer realm="api"`},
+		{"Basic", "Basic YW5hOnNlbmhh", `Bearer realm="api"`},
+		{"token lixo", "Bearer abc.def.ghi", `Bearer realm="api", error="invalid_token"`},
+		{"token de renovação", "Bearer " + par.Renovacao, `Bearer realm="api", error="invalid_token"`},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			rec := requisitar(h, "GET", "/api/quem", "", "Authorization", tt.autorizacao)
+			if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != tt.desafio {
+				t.Errorf("= %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != problema.TipoContent {
+				t.Errorf("Content-Type = %q; esperado %q", ct, problema.TipoContent)
+			}
+		})
+	}
+
+	// Usuário desativado: o token que ele já tinha deixa de valer
+	usuarios.DefinirAtivo("ana", false)
+	if rec := requisitar(h, "GET", "/api/quem", "", "Authorization", "Bearer "+par.Acesso); rec.Code != http.StatusUnauthorized {
+		t.Errorf("usuário inativo = %d", rec.Code)
+	}
+	if rec := requisitar(h, "POST", "/auth/entrar", `{"username":"ana","senha":"senha-secreta"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("login de inativo = %d", rec.Code)
+	}
+}
+
+func TestIdentificar(t *testing.T) {
+	h, usuarios := novoRoteadorTeste(t)
+	usuarios.Registrar("ana", "ana@escola.br", "senha-secreta")
+	par := entrar(t, h, "ana", "senha-secreta")
+
+	testes := []struct {
+		nome, autorizacao string
+		status            int
+		corpo             string
+	}{
+		{"sem token passa anônimo", "", http.StatusOK, ""},
+		{"token válido identifica", "Bearer " + par.Acesso, http.StatusOK, "ana"},
+		{"token inválido é 401", "Bearer abc.def.ghi", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			rec := requisitar(h, "GET", "/aberta/quem", "", "Authorization", tt.autorizacao)
+			if rec.Code != tt.status || (tt.status == http.StatusOK && rec.Body.String() != tt.corpo) {
+				t.Errorf("= %d %q; esperado %d %q", rec.Code, rec.Body, tt.status, tt.corpo)
+			}
+		})
+	}
+}
+
+func TestEntrar_Erros(t *testing.T) {
+	h, usuarios := novoRoteadorTeste(t)
+	usuarios.Registrar("ana", "ana@escola.br", "senha-secreta")
+
+	semUsuario := requisitar(h, "POST", "/auth/entrar", `{"username":"ninguem","senha":"senha-secreta"}`)
+	senhaErrada := requisitar(h, "POST", "/auth/entrar", `{"username":"ana","senha":"outra-senha"}`)
+	if semUsuario.Code != http.StatusUnauthorized || senhaErrada.Code != http.StatusUnauthorized ||
+		semUsuario.Body.String() != senhaErrada.Body.String() {
+		t.Errorf("respostas diferentes revelam usernames: %s / %s", semUsuario.Body, senhaErrada.Body)
+	}
+	if rec := requisitar(h, "POST", "/auth/entrar", `{"username":"ana","senha":1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("JSON inválido = %d", rec.Code)
+	}
+	req := httptest.NewRequest("POST", "/auth/entrar", strings.NewReader(`username=ana`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("formulário = %d", rec.Code)
+	}
+}
+
+func TestRegistrar_Problemas(t *testing.T) {
+	h, usuarios := novoRoteadorTeste(t)
+	usuarios.Registrar("ana", "ana@escola.br", "senha-secreta")
+
+	testes := []struct {
+		nome, corpo string
+		status      int
+		campo       string
+	}{
+		{"já existe", `{"username":"ana","email":"a@escola.br","senha":"senha-secreta"}`, http.StatusConflict, ""},
+		{"senha curta", `{"username":"bruno","email":"b@escola.br","senha":"curta"}`, http.StatusUnprocessableEntity, "senha"},
+		{"email inválido", `{"username":"bruno","email":"b","senha":"senha-secreta"}`, http.StatusUnprocessableEntity, "email"},
+		{"corpo inválido", `{"username":1}`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			rec := requisitar(h, "POST", "/auth/registrar", tt.corpo)
+			var p problema.Problema
+			json.NewDecoder(rec.Body).Decode(&p)
+			if rec.Code != tt.status || rec.Header().Get("Content-Type") != problema.TipoContent || p.Status != tt.status || p.Instancia != "/auth/registrar" {
+				t.Fatalf("= %d %q %+v", rec.Code, rec.Header().Get("Content-Type"), p)
+			}
+			if tt.campo != "" && (len(p.Erros) != 1 || p.Erros[0].Campo != tt.campo) {
+				t.Errorf("erros = %+v; esperado o campo %s", p.Erros, tt.campo)
+			}
+		})
+	}
+}
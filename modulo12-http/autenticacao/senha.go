@@ -0,0 +1,165 @@
+package autenticacao
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+PACKAGE AUTENTICACAO
+
+O Usuario de modulo10-json/01_json_basico.go tem um campo Senha com
+json:"-", mas nada guarda nem confere senhas. Este package completa o
+ciclo, só com a biblioteca padrão:
+
+    senha.go     hash PBKDF2-HMAC-SHA256 com sal e comparação em tempo constante
+    token.go     tokens assinados com HMAC (JWT HS256), renovação e revogação
+    usuarios.go  cadastro em memória e login
+    http.go      rotas /registrar, /entrar, /renovar, /sair, /eu e o
+                 middleware Exigir, que põe o usuário no context
+
+    usuarios := autenticacao.NovosUsuarios()
+    tokens, err := autenticacao.NovosTokens(chave, autenticacao.OpcoesTokens{})
+    servico := autenticacao.NovoServico(usuarios, tokens)
+    servico.Rotas(r.Grupo("/api/auth"))
+    api := r.Grupo("/api", servico.Exigir())
+    // no handler: u, ok := autenticacao.UsuarioDe(req.Context())
+
+NUNCA GUARDE A SENHA:
+Guarda-se um hash LENTO e com SAL. Lento (centenas de milhares de
+iterações) para que testar um dicionário contra um banco vazado custe
+caro; sal aleatório para que senhas iguais tenham hashes diferentes.
+sha256(senha) puro é rápido demais: não serve.
+
+Formato guardado (as iterações vão junto, então dá para aumentá-las
+no futuro sem invalidar os hashes antigos; veja PrecisaRehash):
+
+    pbkdf2-sha256$600000$<sal base64>$<hash base64>
+
+O Go 1.24 trouxe crypto/pbkdf2; aqui o algoritmo (RFC 8018) está
+escrito em poucas linhas sobre crypto/hmac, para rodar no Go 1.21.
+*/
+
+// Parâmetros do hash de senha
+const (
+	IteracoesPadrao  = 600_000 // recomendação OWASP (2023) para PBKDF2-HMAC-SHA256
+	TamanhoMinSenha  = 8
+	TamanhoMaxSenha  = 1024 // evita gastar CPU com "senhas" de megabytes
+	tamanhoSal       = 16
+	tamanhoHash      = 32
+	prefixoHashSenha = "pbkdf2-sha256"
+)
+
+// Erros de senha
+var (
+	ErrSenhaCurta = fmt.Errorf("senha deve ter ao menos %d caracteres", TamanhoMinSenha)
+	ErrSenhaLonga = fmt.Errorf("senha deve ter no máximo %d bytes", TamanhoMaxSenha)
+	ErrHashSenha  = errors.New("hash de senha em formato desconhecido")
+)
+
+// GerarHashSenha calcula o hash a guardar no lugar da senha
+// (iteracoes <= 0 usa IteracoesPadrao)
+func GerarHashSenha(senha string, iteracoes int) (string, error) {
+	if err := validarSenha(senha); err != nil {
+		return "", err
+	}
+	if iteracoes <= 0 {
+		iteracoes = IteracoesPadrao
+	}
+	sal := make([]byte, tamanhoSal)
+	if _, err := rand.Read(sal); err != nil {
+		return "", err
+	}
+	hash := pbkdf2([]byte(senha), sal, iteracoes, tamanhoHash)
+	return strings.Join([]string{
+		prefixoHashSenha,
+		strconv.Itoa(iteracoes),
+		base64.RawStdEncoding.EncodeToString(sal),
+		base64.RawStdEncoding.EncodeToString(hash),
+	}, "$"), nil
+}
+
+// ConferirSenha diz se senha corresponde ao hash guardado. A
+// comparação final é em tempo constante: o tempo de resposta não
+// revela quantos bytes do hash acertaram.
+func ConferirSenha(senha, hashGuardado string) bool {
+	iteracoes, sal, esperado, err := lerHashSenha(hashGuardado)
+	if err != nil || len(senha) > TamanhoMaxSenha {
+		return false
+	}
+	calculado := pbkdf2([]byte(senha), sal, iteracoes, len(esperado))
+	return subtle.ConstantTimeCompare(calculado, esperado) == 1
+}
+
+// PrecisaRehash diz se o hash foi gerado com menos iterações que as
+// atuais; nesse caso, recalcule-o no próximo login bem-sucedido
+func PrecisaRehash(hashGuardado string, iteracoes int) bool {
+	if iteracoes <= 0 {
+		iteracoes = IteracoesPadrao
+	}
+	atual, _, _, err := lerHashSenha(hashGuardado)
+	return err != nil || atual < iteracoes
+}
+
+func validarSenha(senha string) error {
+	if len(senha) > TamanhoMaxSenha {
+		return ErrSenhaLonga
+	}
+	if utf8.RuneCountInString(senha) < TamanhoMinSenha {
+		return ErrSenhaCurta
+	}
+	return nil
+}
+
+func lerHashSenha(s string) (iteracoes int, sal, hash []byte, err error) {
+	partes := strings.Split(s, "$")
+	if len(partes) != 4 || partes[0] != prefixoHashSenha {
+		return 0, nil, nil, ErrHashSenha
+	}
+	if iteracoes, err = strconv.Atoi(partes[1]); err != nil || iteracoes < 1 {
+		return 0, nil, nil, ErrHashSenha
+	}
+	if sal, err = base64.RawStdEncoding.DecodeString(partes[2]); err != nil {
+		return 0, nil, nil, ErrHashSenha
+	}
+	if hash, err = base64.RawStdEncoding.DecodeString(partes[3]); err != nil || len(hash) == 0 {
+		return 0, nil, nil, ErrHashSenha
+	}
+	return iteracoes, sal, hash, nil
+}
+
+// pbkdf2 implementa PBKDF2 (RFC 8018, seção 5.2) com HMAC-SHA256:
+// cada bloco T_i = U_1 ^ U_2 ^ ... ^ U_c, com U_1 = HMAC(senha, sal || i)
+// e U_j = HMAC(senha, U_{j-1})
+func pbkdf2(senha, sal []byte, iteracoes, tamanho int) []byte {
+	prf := hmac.New(sha256.New, senha)
+	blocos := (tamanho + prf.Size() - 1) / prf.Size()
+
+	chave := make([]byte, 0, blocos*prf.Size())
+	var u, t []byte
+	for i := 1; i <= blocos; i++ {
+		prf.Reset()
+		prf.Write(sal)
+		prf.Write([]byte{byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)})
+		u = prf.Sum(u[:0])
+		t = append(t[:0], u...)
+		for j := 1; j < iteracoes; j++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for k := range t {
+				t[k] ^= u[k]
+			}
+		}
+		chave = append(chave, t...)
+	}
+	return chave[:tamanho]
+}
@@ -0,0 +1,90 @@
+package autenticacao
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// iteracoesTeste deixa os testes rápidos; o formato guarda o valor
+const iteracoesTeste = 1000
+
+func TestPBKDF2_VetoresRFC7914(t *testing.T) {
+	// RFC 7914, seção 11: PBKDF2-HMAC-SHA256
+	testes := []struct {
+		senha, sal string
+		iteracoes  int
+		esperado   string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tt := range testes {
+		obtido := hex.EncodeToString(pbkdf2([]byte(tt.senha), []byte(tt.sal), tt.iteracoes, 64))
+		if obtido != tt.esperado {
+			t.Errorf("pbkdf2(%q, %q, %d) = %s; esperado %s", tt.senha, tt.sal, tt.iteracoes, obtido, tt.esperado)
+		}
+	}
+}
+
+func TestHashSenha(t *testing.T) {
+	h1, err := GerarHashSenha("correto cavalo bateria", iteracoesTeste)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, _ := GerarHashSenha("correto cavalo bateria", iteracoesTeste)
+	if h1 == h2 {
+		t.Error("a mesma senha deve gerar hashes diferentes (sal aleatório)")
+	}
+	if !strings.HasPrefix(h1, "pbkdf2-sha256$1000$") || strings.Contains(h1, "cavalo") {
+		t.Errorf("hash = %q", h1)
+	}
+
+	testes := []struct {
+		senha, hash string
+		esperado    bool
+	}{
+		{"correto cavalo bateria", h1, true},
+		{"correto cavalo bateria", h2, true},
+		{"correto cavalo Bateria", h1, false},
+		{"", h1, false},
+		{"correto cavalo bateria", "sha256$abc", false},
+		{"correto cavalo bateria", "pbkdf2-sha256$0$AAAA$AAAA", false},
+		{"correto cavalo bateria", "", false},
+	}
+	for _, tt := range testes {
+		if obtido := ConferirSenha(tt.senha, tt.hash); obtido != tt.esperado {
+			t.Errorf("ConferirSenha(%q, %q) = %v; esperado %v", tt.senha, tt.hash, obtido, tt.esperado)
+		}
+	}
+}
+
+func TestHashSenha_Validacao(t *testing.T) {
+	testes := []struct {
+		senha    string
+		esperado error
+	}{
+		{"curta", ErrSenhaCurta},
+		{"çãéíóúâê", nil}, // 8 caracteres, 16 bytes
+		{"çãéíóúâ", ErrSenhaCurta},
+		{strings.Repeat("a", TamanhoMaxSenha+1), ErrSenhaLonga},
+	}
+	for _, tt := range testes {
+		if _, err := GerarHashSenha(tt.senha, iteracoesTeste); err != tt.esperado {
+			t.Errorf("GerarHashSenha(%.10q) erro = %v; esperado %v", tt.senha, err, tt.esperado)
+		}
+	}
+}
+
+func TestPrecisaRehash(t *testing.T) {
+	h, _ := GerarHashSenha("uma senha boa", iteracoesTeste)
+	if PrecisaRehash(h, iteracoesTeste) {
+		t.Error("mesmas iterações não precisam de rehash")
+	}
+	if !PrecisaRehash(h, 2*iteracoesTeste) || !PrecisaRehash(h, 0) {
+		t.Error("menos iterações que as atuais precisam de rehash")
+	}
+	if !PrecisaRehash("md5$abc", iteracoesTeste) {
+		t.Error("formato desconhecido precisa de rehash")
+	}
+}
@@ -0,0 +1,288 @@
+package autenticacao
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+TOKENS (JWT HS256)
+
+Um token é "cabeçalho.claims.assinatura", cada parte em base64url:
+
+    {"alg":"HS256","typ":"JWT"} . {"sub":"ana","exp":1700000000,...} . HMAC-SHA256
+
+Qualquer um LÊ as claims (não é criptografia); só quem tem a chave
+consegue ASSINAR. O servidor não guarda sessões: confere a assinatura
+e a expiração a cada requisição.
+
+PAR DE TOKENS:
+- acesso: curto (15 min), vai em "Authorization: Bearer ..."
+- renovação: longo (7 dias), só serve em /renovar para obter um par novo
+
+Na renovação o token usado é revogado (rotação): um token de renovação
+roubado e já usado pelo dono não serve de novo.
+
+REVOGAÇÃO:
+Tokens assinados valem até expirar. Para "sair" antes disso, o ID do
+token (jti) entra numa lista de revogados, que só precisa guardá-lo
+até a expiração original. A lista fica em memória: com várias
+instâncias, ela teria de ir para um armazenamento compartilhado.
+*/
+
+// Tipos de token (claim "tipo")
+const (
+	TipoAcesso    = "acesso"
+	TipoRenovacao = "renovacao"
+)
+
+// TamanhoMinChave é o mínimo para a chave HMAC (256 bits)
+const TamanhoMinChave = 32
+
+// Erros de token
+var (
+	ErrTokenInvalido = errors.New("token inválido")
+	ErrTokenExpirado = errors.New("token expirado")
+	ErrTokenRevogado = errors.New("token revogado")
+	ErrChaveCurta    = fmt.Errorf("chave de assinatura deve ter ao menos %d bytes", TamanhoMinChave)
+)
+
+// Claims é o conteúdo de um token
+type Claims struct {
+	Sujeito string `json:"sub"` // username
+	ID      string `json:"jti"` // identificador único, usado na revogação
+	Tipo    string `json:"tipo"`
+	Emissor string `json:"iss,omitempty"`
+	Emitido int64  `json:"iat"` // segundos desde 1970 (NumericDate)
+	Expira  int64  `json:"exp"`
+}
+
+// ExpiraEm converte exp para time.Time
+func (c Claims) ExpiraEm() time.Time {
+	return time.Unix(c.Expira, 0)
+}
+
+// Par é a resposta de login e renovação
+type Par struct {
+	Acesso    string `json:"token_acesso"`
+	Renovacao string `json:"token_renovacao"`
+	Tipo      string `json:"tipo_token"` // sempre "Bearer"
+	ExpiraEm  int    `json:"expira_em"`  // segundos de validade do token de acesso
+}
+
+// OpcoesTokens ajusta a emissão; o valor zero usa os padrões
+type OpcoesTokens struct {
+	DuracaoAcesso    time.Duration // 0 = 15 min
+	DuracaoRenovacao time.Duration // 0 = 7 dias
+	Emissor          string        // claim "iss", conferida na validação se não vazia
+	Agora            func() time.Time
+}
+
+// Tokens emite, valida e revoga tokens assinados com uma chave HMAC
+type Tokens struct {
+	chave     []byte
+	opcoes    OpcoesTokens
+	agora     func() time.Time
+	mu        sync.Mutex
+	revogados map[string]int64 // jti → exp
+}
+
+// cabecalhoHS256 é sempre o mesmo: já fica codificado
+var cabecalhoHS256 = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// NovosTokens cria o emissor. A chave deve ser secreta e aleatória,
+// com ao menos TamanhoMinChave bytes (ex.: 32 bytes de crypto/rand).
+func NovosTokens(chave []byte, opcoes OpcoesTokens) (*Tokens, error) {
+	if len(chave) < TamanhoMinChave {
+		return nil, ErrChaveCurta
+	}
+	if opcoes.DuracaoAcesso <= 0 {
+		opcoes.DuracaoAcesso = 15 * time.Minute
+	}
+	if opcoes.DuracaoRenovacao <= 0 {
+		opcoes.DuracaoRenovacao = 7 * 24 * time.Hour
+	}
+	t := &Tokens{
+		chave:     append([]byte(nil), chave...),
+		opcoes:    opcoes,
+		agora:     opcoes.Agora,
+		revogados: make(map[string]int64),
+	}
+	if t.agora == nil {
+		t.agora = time.Now
+	}
+	return t, nil
+}
+
+// Emitir cria um par acesso + renovação para o usuário
+func (t *Tokens) Emitir(sujeito string) (Par, error) {
+	acesso, err := t.assinar(sujeito, TipoAcesso, t.opcoes.DuracaoAcesso)
+	if err != nil {
+		return Par{}, err
+	}
+	renovacao, err := t.assinar(sujeito, TipoRenovacao, t.opcoes.DuracaoRenovacao)
+	if err != nil {
+		return Par{}, err
+	}
+	return Par{
+		Acesso:    acesso,
+		Renovacao: renovacao,
+		Tipo:      "Bearer",
+		ExpiraEm:  int(t.opcoes.DuracaoAcesso / time.Second),
+	}, nil
+}
+
+// Validar confere assinatura, tipo, emissor, expiração e revogação.
+// O erro é ErrTokenInvalido, ErrTokenExpirado ou ErrTokenRevogado.
+func (t *Tokens) Validar(token, tipo string) (Claims, error) {
+	c, err := t.ler(token)
+	if err != nil {
+		return Claims{}, err
+	}
+	if c.Tipo != tipo {
+		return Claims{}, fmt.Errorf("%w: esperado token de %s", ErrTokenInvalido, tipo)
+	}
+	if t.agora().Unix() >= c.Expira {
+		return Claims{}, ErrTokenExpirado
+	}
+	if t.revogado(c.ID) {
+		return Claims{}, ErrTokenRevogado
+	}
+	return c, nil
+}
+
+// Renovar troca um token de renovação válido por um par novo e
+// revoga o token usado
+func (t *Tokens) Renovar(tokenRenovacao string) (Par, error) {
+	c, err := t.Validar(tokenRenovacao, TipoRenovacao)
+	if err != nil {
+		return Par{}, err
+	}
+	// Revogar antes de emitir: duas renovações simultâneas com o
+	// mesmo token não podem ambas passar
+	if !t.revogar(c) {
+		return Par{}, ErrTokenRevogado
+	}
+	return t.Emitir(c.Sujeito)
+}
+
+// Revogar invalida um token (de qualquer tipo) até a sua expiração.
+// Token expirado já não vale: revogá-lo não é erro.
+func (t *Tokens) Revogar(token string) error {
+	c, err := t.ler(token)
+	if err != nil {
+		return err
+	}
+	t.revogar(c)
+	return nil
+}
+
+// Revogados conta os tokens na lista de revogação
+func (t *Tokens) Revogados() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.revogados)
+}
+
+// revogar inclui o jti na lista; false se ele já estava lá
+func (t *Tokens) revogar(c Claims) bool {
+	agora := t.agora().Unix()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Limpeza oportunista: um jti só precisa ficar até expirar
+	for id, exp := range t.revogados {
+		if agora >= exp {
+			delete(t.revogados, id)
+		}
+	}
+	if _, ok := t.revogados[c.ID]; ok {
+		return false
+	}
+	if agora < c.Expira {
+		t.revogados[c.ID] = c.Expira
+	}
+	return true
+}
+
+func (t *Tokens) revogado(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.revogados[id]
+	return ok
+}
+
+func (t *Tokens) assinar(sujeito, tipo string, duracao time.Duration) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	agora := t.agora()
+	claims, err := json.Marshal(Claims{
+		Sujeito: sujeito,
+		ID:      hex.EncodeToString(id),
+		Tipo:    tipo,
+		Emissor: t.opcoes.Emissor,
+		Emitido: agora.Unix(),
+		Expira:  agora.Add(duracao).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	conteudo := cabecalhoHS256 + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return conteudo + "." + base64.RawURLEncoding.EncodeToString(t.mac(conteudo)), nil
+}
+
+// ler confere formato, algoritmo, assinatura e emissor (não a expiração)
+func (t *Tokens) ler(token string) (Claims, error) {
+	partes := strings.Split(token, ".")
+	if len(partes) != 3 {
+		return Claims{}, ErrTokenInvalido
+	}
+
+	// O algoritmo é fixo: aceitar o "alg" do próprio token permitiria
+	// "alg":"none" ou a troca por outro algoritmo
+	dados, err := base64.RawURLEncoding.DecodeString(partes[0])
+	if err != nil {
+		return Claims{}, ErrTokenInvalido
+	}
+	var cabecalho struct {
+		Alg string `json:"alg"`
+	}
+	if json.Unmarshal(dados, &cabecalho) != nil || cabecalho.Alg != "HS256" {
+		return Claims{}, ErrTokenInvalido
+	}
+
+	assinatura, err := base64.RawURLEncoding.DecodeString(partes[2])
+	if err != nil || !hmac.Equal(assinatura, t.mac(partes[0]+"."+partes[1])) {
+		return Claims{}, ErrTokenInvalido
+	}
+
+	// Só depois de conferida a assinatura o conteúdo merece confiança
+	dados, err = base64.RawURLEncoding.DecodeString(partes[1])
+	if err != nil {
+		return Claims{}, ErrTokenInvalido
+	}
+	var c Claims
+	if json.Unmarshal(dados, &c) != nil || c.Sujeito == "" || c.ID == "" {
+		return Claims{}, ErrTokenInvalido
+	}
+	if t.opcoes.Emissor != "" && c.Emissor != t.opcoes.Emissor {
+		return Claims{}, fmt.Errorf("%w: emissor %q", ErrTokenInvalido, c.Emissor)
+	}
+	return c, nil
+}
+
+func (t *Tokens) mac(conteudo string) []byte {
+	m := hmac.New(sha256.New, t.chave)
+	m.Write([]byte(conteudo))
+	return m.Sum(nil)
+}
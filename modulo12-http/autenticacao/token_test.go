@@ -0,0 +1,174 @@
+package autenticacao
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var chaveTeste = []byte("0123456789abcdef0123456789abcdef")
+
+// relogio é um time.Now controlado pelo teste
+type relogio struct {
+	mu    sync.Mutex
+	atual time.Time
+}
+
+func (r *relogio) Agora() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.atual
+}
+
+func (r *relogio) Avancar(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.atual = r.atual.Add(d)
+}
+
+func novosTokensTeste(t *testing.T) (*Tokens, *relogio) {
+	t.Helper()
+	rel := &relogio{atual: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+	tokens, err := NovosTokens(chaveTeste, OpcoesTokens{Emissor: "notas", Agora: rel.Agora})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens, rel
+}
+
+func TestTokens_EmitirValidar(t *testing.T) {
+	tokens, rel := novosTokensTeste(t)
+	par, err := tokens.Emitir("ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if par.Tipo != "Bearer" || par.ExpiraEm != 900 {
+		t.Errorf("par = %+v", par)
+	}
+
+	c, err := tokens.Validar(par.Acesso, TipoAcesso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Sujeito != "ana" || c.Emissor != "notas" || !c.ExpiraEm().Equal(rel.Agora().Add(15*time.Minute)) {
+		t.Errorf("claims = %+v", c)
+	}
+
+	// Um tipo não serve no lugar do outro
+	if _, err := tokens.Validar(par.Renovacao, TipoAcesso); !errors.Is(err, ErrTokenInvalido) {
+		t.Errorf("renovação como acesso: erro = %v", err)
+	}
+	if _, err := tokens.Validar(par.Renovacao, TipoRenovacao); err != nil {
+		t.Errorf("renovação: %v", err)
+	}
+
+	rel.Avancar(15 * time.Minute)
+	if _, err := tokens.Validar(par.Acesso, TipoAcesso); err != ErrTokenExpirado {
+		t.Errorf("depois de 15 min: erro = %v; esperado ErrTokenExpirado", err)
+	}
+	if _, err := tokens.Validar(par.Renovacao, TipoRenovacao); err != nil {
+		t.Errorf("renovação ainda vale: %v", err)
+	}
+}
+
+func TestTokens_Adulterados(t *testing.T) {
+	tokens, _ := novosTokensTeste(t)
+	par, _ := tokens.Emitir("ana")
+	partes := strings.Split(par.Acesso, ".")
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	outraChave, _ := NovosTokens([]byte(strings.Repeat("x", 32)), OpcoesTokens{Emissor: "notas"})
+	deOutraChave, _ := outraChave.Emitir("ana")
+	outroEmissor, _ := NovosTokens(chaveTeste, OpcoesTokens{Emissor: "outro"})
+	deOutroEmissor, _ := outroEmissor.Emitir("ana")
+
+	testes := map[string]string{
+		"vazio":              "",
+		"duas partes":        partes[0] + "." + partes[1],
+		"claims trocadas":    partes[0] + "." + b64([]byte(`{"sub":"admin","jti":"x","tipo":"acesso","exp":9999999999}`)) + "." + partes[2],
+		"alg none":           b64([]byte(`{"alg":"none"}`)) + "." + partes[1] + ".",
+		"alg trocado":        b64([]byte(`{"alg":"HS512","typ":"JWT"}`)) + "." + partes[1] + "." + partes[2],
+		"assinatura cortada": partes[0] + "." + partes[1] + "." + partes[2][:10],
+		"outra chave":        deOutraChave.Acesso,
+		"outro emissor":      deOutroEmissor.Acesso,
+	}
+	for nome, token := range testes {
+		if _, err := tokens.Validar(token, TipoAcesso); !errors.Is(err, ErrTokenInvalido) {
+			t.Errorf("%s: erro = %v; esperado ErrTokenInvalido", nome, err)
+		}
+	}
+}
+
+func TestTokens_RenovarRevoga(t *testing.T) {
+	tokens, _ := novosTokensTeste(t)
+	par, _ := tokens.Emitir("ana")
+
+	novo, err := tokens.Renovar(par.Renovacao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if novo.Acesso == par.Acesso || novo.Renovacao == par.Renovacao {
+		t.Error("renovação deve emitir tokens novos")
+	}
+	if _, err := tokens.Renovar(par.Renovacao); err != ErrTokenRevogado {
+		t.Errorf("reuso do token de renovação: erro = %v; esperado ErrTokenRevogado", err)
+	}
+	if _, err := tokens.Renovar(novo.Acesso); !errors.Is(err, ErrTokenInvalido) {
+		t.Errorf("token de acesso em Renovar: erro = %v", err)
+	}
+
+	// Renovações simultâneas com o mesmo token: só uma passa
+	par, _ = tokens.Emitir("bruno")
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sucessos := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tokens.Renovar(par.Renovacao); err == nil {
+				mu.Lock()
+				sucessos++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if sucessos != 1 {
+		t.Errorf("renovações aceitas = %d; esperado 1", sucessos)
+	}
+}
+
+func TestTokens_Revogar(t *testing.T) {
+	tokens, rel := novosTokensTeste(t)
+	par, _ := tokens.Emitir("ana")
+	if err := tokens.Revogar(par.Acesso); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.Validar(par.Acesso, TipoAcesso); err != ErrTokenRevogado {
+		t.Errorf("erro = %v; esperado ErrTokenRevogado", err)
+	}
+	if err := tokens.Revogar("lixo"); !errors.Is(err, ErrTokenInvalido) {
+		t.Errorf("Revogar(lixo) = %v", err)
+	}
+
+	// A lista só guarda o jti até a expiração original
+	if tokens.Revogados() != 1 {
+		t.Fatalf("Revogados = %d; esperado 1", tokens.Revogados())
+	}
+	rel.Avancar(time.Hour)
+	outro, _ := tokens.Emitir("bruno")
+	tokens.Revogar(outro.Acesso)
+	if tokens.Revogados() != 1 {
+		t.Errorf("Revogados = %d; o jti expirado deveria ter saído", tokens.Revogados())
+	}
+}
+
+func TestNovosTokens_ChaveCurta(t *testing.T) {
+	if _, err := NovosTokens([]byte("curta"), OpcoesTokens{}); err != ErrChaveCurta {
+		t.Errorf("erro = %v; esperado ErrChaveCurta", err)
+	}
+}
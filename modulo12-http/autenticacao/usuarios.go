@@ -0,0 +1,261 @@
+package autenticacao
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Usuario segue o de modulo10-json/01_json_basico.go; Senha guarda o
+// hash (GerarHashSenha), nunca a senha, e não vai para o JSON
+type Usuario struct {
+	ID       int       `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Senha    string    `json:"-"`
+	Admin    bool      `json:"admin,omitempty"` // vira um papel em modulo12-http/autorizacao
+	Ativo    bool      `json:"ativo"`
+	CriadoEm time.Time `json:"criado_em"`
+	// AutoCadastro marca quem se cadastrou pela rota aberta /registrar:
+	// qualquer um escolhe o username ali, então papéis ligados a nomes
+	// (modulo12-http/autorizacao) não valem para essas contas
+	AutoCadastro bool `json:"auto_cadastro,omitempty"`
+}
+
+// Erros de cadastro e login
+var (
+	ErrUsernameInvalido     = errors.New("username deve ter de 3 a 32 caracteres entre a-z, 0-9, '.', '_' e '-'")
+	ErrEmailInvalido        = errors.New("email inválido")
+	ErrUsuarioExistente     = errors.New("username já cadastrado")
+	ErrUsuarioNaoEncontrado = errors.New("usuário não encontrado")
+	ErrUsuarioInativo       = errors.New("usuário inativo")
+	// ErrCredenciais não diz se foi o usuário ou a senha que errou:
+	// a diferença ajudaria quem tenta descobrir usernames
+	ErrCredenciais = errors.New("usuário ou senha incorretos")
+)
+
+var padraoUsername = regexp.MustCompile(`^[a-z0-9._-]{3,32}$`)
+
+// Usuarios é um cadastro em memória, seguro para uso concorrente
+type Usuarios struct {
+	// Iteracoes do PBKDF2 para hashes novos (0 = IteracoesPadrao).
+	// Ajuste antes do primeiro uso.
+	Iteracoes int
+
+	mu      sync.RWMutex
+	porNome map[string]*Usuario
+	proxID  int
+
+	ficticio     string // hash para comparar quando o usuário não existe
+	ficticioOnce sync.Once
+}
+
+// NovosUsuarios cria um cadastro vazio
+func NovosUsuarios() *Usuarios {
+	return &Usuarios{porNome: make(map[string]*Usuario), proxID: 1}
+}
+
+// Registrar cadastra um usuário ativo, criado pela aplicação (não é
+// AutoCadastro). O username é guardado em minúsculas: "Ana" e "ana"
+// são o mesmo usuário.
+func (u *Usuarios) Registrar(username, email, senha string) (Usuario, error) {
+	return u.registrar(username, email, senha, true)
+}
+
+// autoRegistrar é o cadastro da rota aberta /registrar
+func (u *Usuarios) autoRegistrar(username, email, senha string) (Usuario, error) {
+	return u.registrar(username, email, senha, false)
+}
+
+func (u *Usuarios) registrar(username, email, senha string, confiavel bool) (Usuario, error) {
+	username, email, err := validarCadastro(username, email)
+	if err != nil {
+		return Usuario{}, err
+	}
+	// O hash é lento: calcular fora do lock
+	hash, err := GerarHashSenha(senha, u.Iteracoes)
+	if err != nil {
+		return Usuario{}, err
+	}
+	return u.adicionar(Usuario{Username: username, Email: email, Senha: hash, AutoCadastro: !confiavel})
+}
+
+// validarCadastro normaliza username e email
+func validarCadastro(username, email string) (string, string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !padraoUsername.MatchString(username) {
+		return "", "", ErrUsernameInvalido
+	}
+	endereco, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || endereco.Name != "" {
+		return "", "", fmt.Errorf("%w: %q", ErrEmailInvalido, email)
+	}
+	return username, endereco.Address, nil
+}
+
+// adicionar guarda um usuário já validado, ativo, com o próximo ID
+func (u *Usuarios) adicionar(novo Usuario) (Usuario, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.porNome[novo.Username]; ok {
+		return Usuario{}, ErrUsuarioExistente
+	}
+	novo.ID = u.proxID
+	novo.Ativo = true
+	novo.CriadoEm = time.Now()
+	u.proxID++
+	u.porNome[novo.Username] = &novo
+	return novo, nil
+}
+
+// UsuarioInicial é uma conta criada fora da aplicação, por quem opera
+// o servidor: a senha chega já como hash (GerarHashSenha)
+type UsuarioInicial struct {
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	HashSenha string `json:"hash_senha"`
+	Admin     bool   `json:"admin,omitempty"`
+}
+
+// Importar cadastra as contas de um JSON com uma lista de
+// UsuarioInicial. É assim que entram as contas privilegiadas: a rota
+// /registrar só cria contas AutoCadastro. Para no primeiro erro.
+func (u *Usuarios) Importar(r io.Reader) error {
+	var iniciais []UsuarioInicial
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&iniciais); err != nil {
+		return fmt.Errorf("importar usuários: %w", err)
+	}
+	for i, inicial := range iniciais {
+		if err := u.importar(inicial); err != nil {
+			return fmt.Errorf("importar usuário %d (%q): %w", i+1, inicial.Username, err)
+		}
+	}
+	return nil
+}
+
+func (u *Usuarios) importar(inicial UsuarioInicial) error {
+	username, email, err := validarCadastro(inicial.Username, inicial.Email)
+	if err != nil {
+		return err
+	}
+	if _, _, _, err := lerHashSenha(inicial.HashSenha); err != nil {
+		return err
+	}
+	_, err = u.adicionar(Usuario{Username: username, Email: email, Senha: inicial.HashSenha, Admin: inicial.Admin})
+	return err
+}
+
+// ImportarArquivo é Importar lendo de um arquivo
+func (u *Usuarios) ImportarArquivo(caminho string) error {
+	f, err := os.Open(caminho)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := u.Importar(f); err != nil {
+		return fmt.Errorf("%s: %w", caminho, err)
+	}
+	return nil
+}
+
+// Autenticar confere username e senha. Usuário inexistente custa o
+// mesmo tempo que senha errada (um hash é calculado de qualquer jeito).
+func (u *Usuarios) Autenticar(username, senha string) (Usuario, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	u.mu.RLock()
+	atual, ok := u.porNome[username]
+	var copia Usuario
+	if ok {
+		copia = *atual
+	}
+	u.mu.RUnlock()
+
+	if !ok {
+		ConferirSenha(senha, u.hashFicticio())
+		return Usuario{}, ErrCredenciais
+	}
+	if !ConferirSenha(senha, copia.Senha) {
+		return Usuario{}, ErrCredenciais
+	}
+	if !copia.Ativo {
+		return Usuario{}, ErrUsuarioInativo
+	}
+
+	// Hash antigo, com menos iterações: aproveita a senha em mãos
+	if PrecisaRehash(copia.Senha, u.Iteracoes) {
+		if hash, err := GerarHashSenha(senha, u.Iteracoes); err == nil {
+			u.mu.Lock()
+			if atual.Senha == copia.Senha {
+				atual.Senha = hash
+			}
+			u.mu.Unlock()
+		}
+	}
+	return copia, nil
+}
+
+// Buscar retorna o usuário pelo username
+func (u *Usuarios) Buscar(username string) (Usuario, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	atual, ok := u.porNome[strings.ToLower(strings.TrimSpace(username))]
+	if !ok {
+		return Usuario{}, ErrUsuarioNaoEncontrado
+	}
+	return *atual, nil
+}
+
+// DefinirAtivo ativa ou desativa um usuário; desativado, ele não
+// entra e os tokens que já tem deixam de ser aceitos por Exigir
+func (u *Usuarios) DefinirAtivo(username string, ativo bool) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	atual, ok := u.porNome[strings.ToLower(strings.TrimSpace(username))]
+	if !ok {
+		return ErrUsuarioNaoEncontrado
+	}
+	atual.Ativo = ativo
+	return nil
+}
+
+// DefinirAdmin concede ou retira o Admin; vale na próxima requisição,
+// pois Exigir relê o usuário a cada uma
+func (u *Usuarios) DefinirAdmin(username string, admin bool) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	atual, ok := u.porNome[strings.ToLower(strings.TrimSpace(username))]
+	if !ok {
+		return ErrUsuarioNaoEncontrado
+	}
+	atual.Admin = admin
+	return nil
+}
+
+// Listar retorna os usuários ordenados por ID
+func (u *Usuarios) Listar() []Usuario {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	lista := make([]Usuario, 0, len(u.porNome))
+	for _, atual := range u.porNome {
+		lista = append(lista, *atual)
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].ID < lista[j].ID })
+	return lista
+}
+
+func (u *Usuarios) hashFicticio() string {
+	u.ficticioOnce.Do(func() {
+		u.ficticio, _ = GerarHashSenha("senha-que-ninguem-usa", u.Iteracoes)
+	})
+	return u.ficticio
+}
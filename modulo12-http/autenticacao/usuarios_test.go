@@ -0,0 +1,139 @@
+package autenticacao
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func novosUsuariosTeste() *Usuarios {
+	u := NovosUsuarios()
+	u.Iteracoes = iteracoesTeste
+	return u
+}
+
+func TestUsuarios_Registrar(t *testing.T) {
+	u := novosUsuariosTeste()
+	ana, err := u.Registrar(" Ana.Costa ", "ana@escola.br", "senha-secreta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ana.ID != 1 || ana.Username != "ana.costa" || !ana.Ativo || ana.Senha == "senha-secreta" {
+		t.Errorf("usuário = %+v", ana)
+	}
+	dados, _ := json.Marshal(ana)
+	if strings.Contains(string(dados), "pbkdf2") || strings.Contains(string(dados), "senha") {
+		t.Errorf("o JSON não deve ter a senha: %s", dados)
+	}
+
+	testes := []struct {
+		username, email, senha string
+		esperado               error
+	}{
+		{"ANA.COSTA", "outra@escola.br", "senha-secreta", ErrUsuarioExistente},
+		{"an", "a@escola.br", "senha-secreta", ErrUsernameInvalido},
+		{"ana costa", "a@escola.br", "senha-secreta", ErrUsernameInvalido},
+		{"bruno", "não é email", "senha-secreta", ErrEmailInvalido},
+		{"bruno", "Bruno <b@escola.br>", "senha-secreta", ErrEmailInvalido},
+		{"bruno", "b@escola.br", "curta", ErrSenhaCurta},
+	}
+	for _, tt := range testes {
+		if _, err := u.Registrar(tt.username, tt.email, tt.senha); !errors.Is(err, tt.esperado) {
+			t.Errorf("Registrar(%q, %q) erro = %v; esperado %v", tt.username, tt.email, err, tt.esperado)
+		}
+	}
+	if n := len(u.Listar()); n != 1 {
+		t.Errorf("Listar = %d usuários; esperado 1", n)
+	}
+	if ana.AutoCadastro {
+		t.Error("Registrar é o cadastro da aplicação, não AutoCadastro")
+	}
+}
+
+func TestUsuarios_Importar(t *testing.T) {
+	hash, err := GerarHashSenha("senha-da-carla", iteracoesTeste)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := novosUsuariosTeste()
+	err = u.Importar(strings.NewReader(`[
+	  {"username": "Carla", "email": "carla@escola.br", "hash_senha": "` + hash + `"},
+	  {"username": "diretor", "email": "dir@escola.br", "hash_senha": "` + hash + `", "admin": true}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	carla, err := u.Autenticar("carla", "senha-da-carla")
+	if err != nil || carla.AutoCadastro || carla.Admin {
+		t.Errorf("carla = %+v, %v", carla, err)
+	}
+	if diretor, _ := u.Buscar("diretor"); !diretor.Admin {
+		t.Errorf("diretor = %+v", diretor)
+	}
+
+	testes := []struct {
+		nome, json string
+		esperado   error
+	}{
+		{"já cadastrado", `[{"username": "carla", "email": "c@escola.br", "hash_senha": "` + hash + `"}]`, ErrUsuarioExistente},
+		{"senha em texto", `[{"username": "rui", "email": "r@escola.br", "hash_senha": "senha-secreta"}]`, ErrHashSenha},
+		{"username inválido", `[{"username": "r", "email": "r@escola.br", "hash_senha": "` + hash + `"}]`, ErrUsernameInvalido},
+	}
+	for _, tt := range testes {
+		if err := u.Importar(strings.NewReader(tt.json)); !errors.Is(err, tt.esperado) {
+			t.Errorf("%s: erro = %v; esperado %v", tt.nome, err, tt.esperado)
+		}
+	}
+	if err := u.Importar(strings.NewReader(`[{"username": "rui", "senha": "x"}]`)); err == nil {
+		t.Error("campo desconhecido deveria ser erro")
+	}
+}
+
+func TestUsuarios_Autenticar(t *testing.T) {
+	u := novosUsuariosTeste()
+	u.Registrar("ana", "ana@escola.br", "senha-secreta")
+
+	if ana, err := u.Autenticar("Ana", "senha-secreta"); err != nil || ana.Username != "ana" {
+		t.Fatalf("Autenticar = %+v, %v", ana, err)
+	}
+	// Usuário inexistente e senha errada: o mesmo erro
+	if _, err := u.Autenticar("ana", "senha-errada"); err != ErrCredenciais {
+		t.Errorf("senha errada: erro = %v", err)
+	}
+	if _, err := u.Autenticar("ninguem", "senha-secreta"); err != ErrCredenciais {
+		t.Errorf("usuário inexistente: erro = %v", err)
+	}
+
+	u.DefinirAtivo("ana", false)
+	if _, err := u.Autenticar("ana", "senha-secreta"); err != ErrUsuarioInativo {
+		t.Errorf("inativo: erro = %v", err)
+	}
+	if err := u.DefinirAtivo("ninguem", true); err != ErrUsuarioNaoEncontrado {
+		t.Errorf("DefinirAtivo(ninguem) = %v", err)
+	}
+
+	u.DefinirAdmin("ana", true)
+	if ana, _ := u.Buscar("ana"); !ana.Admin {
+		t.Error("DefinirAdmin não foi aplicado")
+	}
+}
+
+func TestUsuarios_RehashNoLogin(t *testing.T) {
+	u := novosUsuariosTeste()
+	u.Registrar("ana", "ana@escola.br", "senha-secreta")
+	antes, _ := u.Buscar("ana")
+
+	// Mais iterações: o próximo login recalcula o hash
+	u.Iteracoes = 2 * iteracoesTeste
+	if _, err := u.Autenticar("ana", "senha-secreta"); err != nil {
+		t.Fatal(err)
+	}
+	depois, _ := u.Buscar("ana")
+	if depois.Senha == antes.Senha || PrecisaRehash(depois.Senha, u.Iteracoes) {
+		t.Errorf("hash não foi atualizado: %q", depois.Senha)
+	}
+	if _, err := u.Autenticar("ana", "senha-secreta"); err != nil {
+		t.Errorf("login com o hash novo: %v", err)
+	}
+}
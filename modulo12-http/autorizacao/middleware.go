@@ -0,0 +1,72 @@
+package autorizacao
+
+import (
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"net/http"
+)
+
+// FuncaoRecurso identifica o recurso da requisição. ok = false quer
+// dizer "não existe": a requisição segue, e o handler responde 404
+// (em vez de um 403 que não ajudaria ninguém).
+type FuncaoRecurso func(r *http.Request) (recurso Recurso, ok bool)
+
+// Tipo é a FuncaoRecurso mais simples: recurso sem dono, com o ID
+// vindo do parâmetro de rota (ex.: Tipo("aluno", "nome"))
+func Tipo(tipo, parametro string) FuncaoRecurso {
+	return func(r *http.Request) (Recurso, bool) {
+		rec := Recurso{Tipo: tipo}
+		if parametro != "" {
+			rec.ID = roteador.Parametro(r, parametro)
+		}
+		return rec, true
+	}
+}
+
+// Exigir é o middleware de uma rota: só segue se o usuário puder
+// fazer acao sobre o recurso. Use depois de autenticacao.Exigir (ou
+// sem ele, para rotas abertas: vale o papel "anonimo").
+//
+//	api.Grupo("", politica.Exigir("remover", recursoAluno)).Delete("/alunos/{nome}", remover)
+func (p *Politica) Exigir(acao string, recurso FuncaoRecurso) func(http.Handler) http.Handler {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec, ok := recurso(r)
+			if ok {
+				if err := p.Autorizar(r.Context(), acao, rec); err != nil {
+					ResponderNegado(w, r, err)
+					return
+				}
+			}
+			proximo.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ResponderNegado escreve a resposta de um erro de Autorizar em
+// problem+json (package problema), com o status de ProblemaAcesso
+func ResponderNegado(w http.ResponseWriter, r *http.Request, err error) {
+	problemas.Responder(w, r, err)
+}
+
+// ProblemaAcesso traduz um *ErroAcesso: 401 para anônimo (falta se
+// autenticar) e 403 para usuário autenticado (autenticar de novo não
+// adianta). Para um problema.Registro próprio:
+//
+//	problema.RegistrarTipo(reg, autorizacao.ProblemaAcesso)
+func ProblemaAcesso(e *ErroAcesso) problema.Problema {
+	p := problema.Problema{Status: http.StatusForbidden, Detalhe: e.Error()}
+	if e.Usuario == "" {
+		p.Status = http.StatusUnauthorized
+		p.Cabecalhos = http.Header{"Www-Authenticate": {`Bearer realm="api"`}}
+	}
+	return p
+}
+
+var problemas = novosProblemas()
+
+func novosProblemas() *problema.Registro {
+	reg := problema.Padrao()
+	problema.RegistrarTipo(reg, ProblemaAcesso)
+	return reg
+}
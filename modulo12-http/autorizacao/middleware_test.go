@@ -0,0 +1,56 @@
+package autorizacao
+
+import (
+	"go-course/modulo12-http/autenticacao"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExigir(t *testing.T) {
+	p := novaPoliticaTeste(t)
+	donos := map[string]string{"Bia": "prof.ana"}
+	recursoAluno := func(r *http.Request) (Recurso, bool) {
+		nome := roteador.Parametro(r, "nome")
+		dono, ok := donos[nome]
+		return Recurso{Tipo: "aluno", ID: nome, Dono: dono}, ok
+	}
+
+	r := roteador.Novo()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	r.Grupo("", p.Exigir("ler", Tipo("aluno", "nome"))).Get("/alunos/{nome}", ok)
+	r.Grupo("", p.Exigir("remover", recursoAluno)).Delete("/alunos/{nome}", ok)
+
+	testes := []struct {
+		nome, metodo, caminho, usuario string
+		status                         int
+		desafio                        bool
+	}{
+		{"leitura anônima", "GET", "/alunos/Bia", "", http.StatusNoContent, false},
+		{"remoção anônima", "DELETE", "/alunos/Bia", "", http.StatusUnauthorized, true},
+		{"remoção pelo dono", "DELETE", "/alunos/Bia", "prof.ana", http.StatusNoContent, false},
+		{"remoção por outro", "DELETE", "/alunos/Bia", "bruno", http.StatusForbidden, false},
+		{"recurso inexistente segue para o handler", "DELETE", "/alunos/Caio", "bruno", http.StatusNoContent, false},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			req := httptest.NewRequest(tt.metodo, tt.caminho, nil)
+			if tt.usuario != "" {
+				req = req.WithContext(autenticacao.ComUsuario(req.Context(), autenticacao.Usuario{Username: tt.usuario}))
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d; esperado %d (%s)", rec.Code, tt.status, rec.Body)
+			}
+			if (rec.Header().Get("WWW-Authenticate") != "") != tt.desafio {
+				t.Errorf("WWW-Authenticate = %q", rec.Header().Get("WWW-Authenticate"))
+			}
+			if negado := rec.Code >= 400; negado && rec.Header().Get("Content-Type") != problema.TipoContent {
+				t.Errorf("Content-Type = %q; esperado %q", rec.Header().Get("Content-Type"), problema.TipoContent)
+			}
+		})
+	}
+}
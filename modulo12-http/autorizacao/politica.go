@@ -0,0 +1,269 @@
+package autorizacao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"go-course/modulo12-http/autenticacao"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+PACKAGE AUTORIZACAO
+
+Autenticação diz QUEM é o usuário (modulo12-http/autenticacao);
+autorização diz o que ele PODE fazer. Aqui, com RBAC (controle de
+acesso por papéis): o usuário tem papéis, cada papel tem permissões
+"ação sobre tipo de recurso".
+
+    p, err := autorizacao.LerArquivoPolitica("politica.json")
+    err = p.Autorizar(ctx, "remover", autorizacao.Recurso{Tipo: "aluno", ID: "Bia", Dono: "prof.ana"})
+    if errors.Is(err, erros.ErrNaoAutorizado) { ... }
+
+POLÍTICA (JSON):
+
+    {
+      "papeis": {
+        "leitor":    {"permissoes": [{"acoes": ["ler"], "recursos": ["*"]}]},
+        "professor": {"herda": ["leitor"], "permissoes": [
+                        {"acoes": ["criar"], "recursos": ["aluno"]},
+                        {"acoes": ["alterar", "remover"], "recursos": ["aluno", "nota"], "somente_dono": true}]},
+        "admin":     {"permissoes": [{"acoes": ["*"], "recursos": ["*"]}]}
+      },
+      "anonimo":     ["leitor"],             papéis de quem não se autenticou
+      "padrao":      ["leitor"],             papéis de todo usuário autenticado
+      "papel_admin": "admin",                papel de quem tem Usuario.Admin
+      "usuarios":    {"prof.ana": ["professor"]}
+    }
+
+- "*" em acoes ou recursos vale para qualquer um
+- "herda" soma as permissões de outros papéis (sem ciclos)
+- "somente_dono": só vale se Recurso.Dono for o próprio usuário
+- "usuarios" não vale para contas com Usuario.AutoCadastro: quem se
+  cadastra sozinho escolhe o username, e pegaria os papéis de "carla"
+  só por se chamar carla. Essas contas ficam com "padrao".
+
+Tudo que não é permitido é negado. O erro é um *ErroAcesso, que
+embrulha erros.ErrNaoAutorizado (modulo07-erros/erros).
+*/
+
+// Definicao é o formato JSON da política
+type Definicao struct {
+	Papeis     map[string]Papel    `json:"papeis"`
+	Anonimo    []string            `json:"anonimo,omitempty"`
+	Padrao     []string            `json:"padrao,omitempty"`
+	PapelAdmin string              `json:"papel_admin,omitempty"`
+	Usuarios   map[string][]string `json:"usuarios,omitempty"`
+}
+
+// Papel agrupa permissões
+type Papel struct {
+	Herda      []string    `json:"herda,omitempty"`
+	Permissoes []Permissao `json:"permissoes"`
+}
+
+// Permissao libera ações sobre tipos de recurso
+type Permissao struct {
+	Acoes       []string `json:"acoes"`
+	Recursos    []string `json:"recursos"`
+	SomenteDono bool     `json:"somente_dono,omitempty"`
+}
+
+// Recurso é o alvo de uma ação
+type Recurso struct {
+	Tipo string // "aluno", "nota"...
+	ID   string // opcional, só para a mensagem de erro
+	Dono string // username do dono ("" = sem dono conhecido)
+}
+
+func (r Recurso) String() string {
+	if r.ID == "" {
+		return r.Tipo
+	}
+	return r.Tipo + " " + r.ID
+}
+
+// ErroAcesso descreve uma negação; errors.Is(err, erros.ErrNaoAutorizado) é true
+type ErroAcesso struct {
+	Usuario string // "" = anônimo
+	Acao    string
+	Recurso Recurso
+	Motivo  string
+}
+
+func (e *ErroAcesso) Error() string {
+	quem := "anônimo"
+	if e.Usuario != "" {
+		quem = e.Usuario
+	}
+	return fmt.Sprintf("%s não pode %s %s: %s", quem, e.Acao, e.Recurso, e.Motivo)
+}
+
+func (e *ErroAcesso) Unwrap() error {
+	return erros.ErrNaoAutorizado
+}
+
+// Politica é uma Definicao validada, com a herança já resolvida
+type Politica struct {
+	def      Definicao
+	efetivas map[string][]Permissao // papel → permissões próprias + herdadas
+}
+
+// NovaPolitica valida a definição: papéis citados devem existir,
+// herança sem ciclos e permissões com ação e recurso
+func NovaPolitica(def Definicao) (*Politica, error) {
+	var problemas []error
+	existe := func(onde, papel string) {
+		if _, ok := def.Papeis[papel]; !ok {
+			problemas = append(problemas, fmt.Errorf("%s: papel %q não definido", onde, papel))
+		}
+	}
+	for nome, papel := range def.Papeis {
+		for _, h := range papel.Herda {
+			existe("papel "+nome+" herda", h)
+		}
+		for i, perm := range papel.Permissoes {
+			if len(perm.Acoes) == 0 || len(perm.Recursos) == 0 {
+				problemas = append(problemas, fmt.Errorf("papel %s, permissão %d: acoes e recursos são obrigatórios", nome, i+1))
+			}
+		}
+	}
+	for _, papel := range def.Anonimo {
+		existe("anonimo", papel)
+	}
+	for _, papel := range def.Padrao {
+		existe("padrao", papel)
+	}
+	if def.PapelAdmin != "" {
+		existe("papel_admin", def.PapelAdmin)
+	}
+	usuarios := make(map[string][]string, len(def.Usuarios))
+	for username, papeis := range def.Usuarios {
+		for _, papel := range papeis {
+			existe("usuário "+username, papel)
+		}
+		usuarios[strings.ToLower(username)] = papeis
+	}
+	def.Usuarios = usuarios
+	if len(problemas) > 0 {
+		return nil, fmt.Errorf("política inválida: %w", errors.Join(problemas...))
+	}
+
+	p := &Politica{def: def, efetivas: make(map[string][]Permissao, len(def.Papeis))}
+	for nome := range def.Papeis {
+		if err := p.resolver(nome, nil); err != nil {
+			return nil, fmt.Errorf("política inválida: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// resolver junta as permissões do papel com as herdadas; caminho
+// detecta ciclos (a herda b herda a)
+func (p *Politica) resolver(nome string, caminho []string) error {
+	if _, ok := p.efetivas[nome]; ok {
+		return nil
+	}
+	for _, anterior := range caminho {
+		if anterior == nome {
+			return fmt.Errorf("herança circular: %s", strings.Join(append(caminho, nome), " → "))
+		}
+	}
+	papel := p.def.Papeis[nome]
+	perms := append([]Permissao(nil), papel.Permissoes...)
+	for _, h := range papel.Herda {
+		if err := p.resolver(h, append(caminho, nome)); err != nil {
+			return err
+		}
+		perms = append(perms, p.efetivas[h]...)
+	}
+	p.efetivas[nome] = perms
+	return nil
+}
+
+// LerPolitica lê a política em JSON (campos desconhecidos são erro:
+// um erro de digitação não deve virar permissão a menos)
+func LerPolitica(r io.Reader) (*Politica, error) {
+	var def Definicao
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("política: %w", err)
+	}
+	return NovaPolitica(def)
+}
+
+// LerArquivoPolitica lê a política de um arquivo JSON
+func LerArquivoPolitica(caminho string) (*Politica, error) {
+	f, err := os.Open(caminho)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := LerPolitica(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", caminho, err)
+	}
+	return p, nil
+}
+
+// Papeis retorna os papéis do usuário da requisição (ordenados)
+func (p *Politica) Papeis(ctx context.Context) []string {
+	u, autenticado := autenticacao.UsuarioDe(ctx)
+	if !autenticado {
+		return append([]string(nil), p.def.Anonimo...)
+	}
+	conjunto := make(map[string]bool)
+	for _, papel := range p.def.Padrao {
+		conjunto[papel] = true
+	}
+	if !u.AutoCadastro {
+		for _, papel := range p.def.Usuarios[u.Username] {
+			conjunto[papel] = true
+		}
+	}
+	if u.Admin && p.def.PapelAdmin != "" {
+		conjunto[p.def.PapelAdmin] = true
+	}
+	papeis := make([]string, 0, len(conjunto))
+	for papel := range conjunto {
+		papeis = append(papeis, papel)
+	}
+	sort.Strings(papeis)
+	return papeis
+}
+
+// Autorizar decide se o usuário do context (autenticacao.UsuarioDe)
+// pode fazer acao sobre recurso. Nil se pode; senão *ErroAcesso.
+func (p *Politica) Autorizar(ctx context.Context, acao string, recurso Recurso) error {
+	u, _ := autenticacao.UsuarioDe(ctx)
+	negado := &ErroAcesso{Usuario: u.Username, Acao: acao, Recurso: recurso, Motivo: "sem permissão"}
+
+	for _, papel := range p.Papeis(ctx) {
+		for _, perm := range p.efetivas[papel] {
+			if !contem(perm.Acoes, acao) || !contem(perm.Recursos, recurso.Tipo) {
+				continue
+			}
+			if !perm.SomenteDono || (u.Username != "" && recurso.Dono == u.Username) {
+				return nil
+			}
+			// Continua procurando: outro papel pode permitir sem a restrição
+			negado.Motivo = "permitido só ao dono"
+		}
+	}
+	return negado
+}
+
+func contem(lista []string, valor string) bool {
+	for _, item := range lista {
+		if item == "*" || item == valor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package autorizacao
+
+import (
+	"context"
+	"errors"
+	"go-course/modulo07-erros/erros"
+	"go-course/modulo12-http/autenticacao"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const politicaTeste = `{
+  "papeis": {
+    "leitor":    {"permissoes": [{"acoes": ["ler"], "recursos": ["*"]}]},
+    "professor": {"herda": ["leitor"], "permissoes": [
+                   {"acoes": ["criar"], "recursos": ["aluno"]},
+                   {"acoes": ["alterar", "remover"], "recursos": ["aluno", "nota"], "somente_dono": true}]},
+    "coordenador": {"herda": ["professor"], "permissoes": [{"acoes": ["alterar"], "recursos": ["nota"]}]},
+    "admin":     {"permissoes": [{"acoes": ["*"], "recursos": ["*"]}]}
+  },
+  "anonimo": ["leitor"],
+  "padrao": ["leitor"],
+  "papel_admin": "admin",
+  "usuarios": {"Prof.Ana": ["professor"], "carla": ["coordenador"]}
+}`
+
+func novaPoliticaTeste(t *testing.T) *Politica {
+	t.Helper()
+	p, err := LerPolitica(strings.NewReader(politicaTeste))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func comUsuario(username string, admin bool) context.Context {
+	return autenticacao.ComUsuario(context.Background(), autenticacao.Usuario{Username: username, Admin: admin})
+}
+
+func TestAutorizar(t *testing.T) {
+	p := novaPoliticaTeste(t)
+	anonimo := context.Background()
+	ana := comUsuario("prof.ana", false)
+	bruno := comUsuario("bruno", false)
+	carla := comUsuario("carla", false)
+	diretor := comUsuario("diretor", true)
+
+	daAna := Recurso{Tipo: "aluno", ID: "Bia", Dono: "prof.ana"}
+	notaDaAna := Recurso{Tipo: "nota", ID: "Bia", Dono: "prof.ana"}
+	semDono := Recurso{Tipo: "aluno", ID: "Caio"}
+
+	testes := []struct {
+		nome    string
+		ctx     context.Context
+		acao    string
+		recurso Recurso
+		pode    bool
+	}{
+		{"anônimo lê", anonimo, "ler", daAna, true},
+		{"anônimo não cria", anonimo, "criar", semDono, false},
+		{"usuário comum lê", bruno, "ler", Recurso{Tipo: "estatisticas"}, true},
+		{"usuário comum não cria", bruno, "criar", semDono, false},
+		{"professor cria", ana, "criar", semDono, true},
+		{"professor altera o seu", ana, "alterar", daAna, true},
+		{"professor não altera o dos outros", ana, "remover", Recurso{Tipo: "aluno", Dono: "prof.rui"}, false},
+		{"professor não altera sem dono", ana, "alterar", semDono, false},
+		{"coordenador altera qualquer nota", carla, "alterar", notaDaAna, true},
+		{"coordenador herda professor", carla, "criar", semDono, true},
+		{"coordenador não remove o dos outros", carla, "remover", daAna, false},
+		{"admin faz tudo", diretor, "remover", daAna, true},
+		{"ação desconhecida", ana, "exportar", daAna, false},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			err := p.Autorizar(tt.ctx, tt.acao, tt.recurso)
+			if tt.pode && err != nil {
+				t.Errorf("negado: %v", err)
+			}
+			if !tt.pode && !errors.Is(err, erros.ErrNaoAutorizado) {
+				t.Errorf("erro = %v; esperado ErrNaoAutorizado", err)
+			}
+		})
+	}
+}
+
+func TestErroAcesso(t *testing.T) {
+	p := novaPoliticaTeste(t)
+	err := p.Autorizar(comUsuario("prof.ana", false), "remover", Recurso{Tipo: "aluno", ID: "Bia", Dono: "prof.rui"})
+	var acesso *ErroAcesso
+	if !errors.As(err, &acesso) {
+		t.Fatalf("erro %T não é *ErroAcesso", err)
+	}
+	if acesso.Usuario != "prof.ana" || acesso.Acao != "remover" || acesso.Recurso.ID != "Bia" {
+		t.Errorf("ErroAcesso = %+v", acesso)
+	}
+	if msg := err.Error(); msg != "prof.ana não pode remover aluno Bia: permitido só ao dono" {
+		t.Errorf("mensagem = %q", msg)
+	}
+	if msg := p.Autorizar(context.Background(), "criar", Recurso{Tipo: "aluno"}).Error(); !strings.HasPrefix(msg, "anônimo não pode criar aluno") {
+		t.Errorf("mensagem = %q", msg)
+	}
+}
+
+func TestPapeis(t *testing.T) {
+	p := novaPoliticaTeste(t)
+	testes := []struct {
+		ctx      context.Context
+		esperado []string
+	}{
+		{context.Background(), []string{"leitor"}},
+		{comUsuario("bruno", false), []string{"leitor"}},
+		{comUsuario("prof.ana", false), []string{"leitor", "professor"}},
+		{comUsuario("prof.ana", true), []string{"admin", "leitor", "professor"}},
+		// Conta da rota aberta /registrar: o nome não dá papel
+		{autenticacao.ComUsuario(context.Background(), autenticacao.Usuario{Username: "carla", AutoCadastro: true}), []string{"leitor"}},
+	}
+	for _, tt := range testes {
+		if obtido := p.Papeis(tt.ctx); !reflect.DeepEqual(obtido, tt.esperado) {
+			t.Errorf("Papeis = %v; esperado %v", obtido, tt.esperado)
+		}
+	}
+}
+
+func TestPoliticaInvalida(t *testing.T) {
+	testes := []struct {
+		nome, json, trecho string
+	}{
+		{"herda inexistente", `{"papeis": {"a": {"herda": ["x"], "permissoes": []}}}`, `papel "x" não definido`},
+		{"ciclo", `{"papeis": {"a": {"herda": ["b"], "permissoes": []}, "b": {"herda": ["a"], "permissoes": []}}}`, "herança circular"},
+		{"usuário com papel inexistente", `{"papeis": {}, "usuarios": {"ana": ["admin"]}}`, `usuário ana: papel "admin"`},
+		{"papel_admin inexistente", `{"papeis": {}, "papel_admin": "admin"}`, "papel_admin"},
+		{"permissão vazia", `{"papeis": {"a": {"permissoes": [{"acoes": ["ler"]}]}}}`, "acoes e recursos são obrigatórios"},
+		{"campo desconhecido", `{"papeis": {}, "admins": ["ana"]}`, "unknown field"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			_, err := LerPolitica(strings.NewReader(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.trecho) {
+				t.Errorf("erro = %v; esperado conter %q", err, tt.trecho)
+			}
+		})
+	}
+}
+
+func TestLerArquivoPolitica(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "politica.json")
+	os.WriteFile(caminho, []byte(politicaTeste), 0o644)
+	if _, err := LerArquivoPolitica(caminho); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(caminho, []byte(`{"papeis": `), 0o644)
+	if _, err := LerArquivoPolitica(caminho); err == nil || !strings.Contains(err.Error(), caminho) {
+		t.Errorf("erro = %v; esperado citar o arquivo", err)
+	}
+}
@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+/*
+PACKAGE CACHE
+
+O jsonHandler de 01_servidor_basico.go monta e envia o corpo inteiro a
+cada requisição, mesmo que o cliente já tenha a mesma resposta. Com
+GET condicional o cliente guarda um validador e pergunta "mudou?":
+
+    1ª  GET /api/mensagem
+        ← 200  ETag: "9f2c1a..."  (corpo)
+    2ª  GET /api/mensagem  If-None-Match: "9f2c1a..."
+        ← 304 Not Modified        (sem corpo)
+
+    api := r.Grupo("/api", cache.ETag(cache.OpcoesETag{}))
+    api.Grupo("", cache.Controle("public, max-age=60")).Get("/estatisticas", estatisticas)
+
+ETag guarda a resposta do handler, calcula um hash do corpo (se o
+handler não definiu um ETag próprio) e responde 304 quando
+If-None-Match casa. Handlers que definem Last-Modified também ganham
+If-Modified-Since. Respostas maiores que o limite, ou com Flush (SSE),
+passam direto, sem ETag.
+
+ETAG FORTE × FRACO:
+    "abc"     forte: corpo idêntico byte a byte (serve para Range)
+    W/"abc"   fraco: conteúdo equivalente (ex.: mesmo JSON compactado
+              ou não). O middleware.Gzip enfraquece um ETag forte ao
+              compactar, como o nginx
+
+O cache do lado do servidor (Armazenar, em respostas.go) evita rodar
+o handler de novo.
+*/
+
+// OpcoesETag configura o middleware ETag; os campos zerados usam os
+// padrões
+type OpcoesETag struct {
+	Fraco bool // gera W/"..." em vez de "..."
+	// TamanhoMaximo é o maior corpo guardado para calcular o hash
+	// (0 = 1 MB); acima disso a resposta sai sem ETag
+	TamanhoMaximo int
+}
+
+// ETag responde GET e HEAD condicionais com 304
+func ETag(opcoes OpcoesETag) func(http.Handler) http.Handler {
+	if opcoes.TamanhoMaximo <= 0 {
+		opcoes.TamanhoMaximo = 1 << 20
+	}
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				proximo.ServeHTTP(w, r)
+				return
+			}
+			g := &gravador{ResponseWriter: w, limite: opcoes.TamanhoMaximo}
+			proximo.ServeHTTP(g, r)
+			if g.direto {
+				return
+			}
+			h := w.Header()
+			// HEAD costuma vir sem corpo: o hash não seria o do GET
+			if g.statusFinal() == http.StatusOK && h.Get("ETag") == "" && len(g.corpo) > 0 {
+				h.Set("ETag", calcularETag(g.corpo, opcoes.Fraco))
+			}
+			if g.statusFinal() == http.StatusOK && naoModificado(r, h) {
+				responderNaoModificado(w)
+				return
+			}
+			g.soltar()
+		})
+	}
+}
+
+// Controle define o Cache-Control das respostas que ainda não têm um.
+// Use num grupo do roteador para valer por rota:
+//
+//	api.Grupo("", cache.Controle("no-store")).Get("/alunos/{nome}/boletim", boletim)
+func Controle(valor string) func(http.Handler) http.Handler {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Antes do handler: ele ainda pode trocar o valor
+			if w.Header().Get("Cache-Control") == "" {
+				w.Header().Set("Cache-Control", valor)
+			}
+			proximo.ServeHTTP(w, r)
+		})
+	}
+}
+
+// calcularETag é um hash do corpo (os primeiros 8 bytes do SHA-256
+// bastam para distinguir versões do mesmo recurso)
+func calcularETag(corpo []byte, fraco bool) string {
+	soma := sha256.Sum256(corpo)
+	etag := `"` + hex.EncodeToString(soma[:8]) + `"`
+	if fraco {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// naoModificado aplica as pré-condições de leitura (RFC 9110, 13.2.2):
+// If-None-Match tem prioridade e usa comparação fraca; sem ele, vale
+// If-Modified-Since contra o Last-Modified
+func naoModificado(r *http.Request, h http.Header) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := h.Get("ETag")
+		return etag != "" && correspondeFraco(inm, etag)
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modificado, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !modificado.After(ims)
+}
+
+// correspondeFraco compara ignorando o W/ ("*" casa com qualquer um)
+func correspondeFraco(cabecalho, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidato := range strings.Split(cabecalho, ",") {
+		candidato = strings.TrimSpace(candidato)
+		if candidato == "*" || strings.TrimPrefix(candidato, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// responderNaoModificado envia o 304: ficam ETag, Cache-Control, Vary
+// e Last-Modified; saem os cabeçalhos que descrevem o corpo
+func responderNaoModificado(w http.ResponseWriter) {
+	h := w.Header()
+	for _, nome := range []string{"Content-Type", "Content-Length", "Content-Encoding", "Content-Range"} {
+		h.Del(nome)
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// ========================================
+// GRAVADOR DE RESPOSTA
+// ========================================
+
+// gravador guarda status e corpo até limite bytes. Passando do limite,
+// ou com Flush/Hijack, envia o que tem e o resto vai direto (direto).
+type gravador struct {
+	http.ResponseWriter
+	limite int
+	status int
+	corpo  []byte
+	direto bool
+}
+
+func (g *gravador) WriteHeader(status int) {
+	if status < 200 { // 1xx passa direto
+		g.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if g.status == 0 {
+		g.status = status
+	}
+}
+
+func (g *gravador) Write(p []byte) (int, error) {
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+	if !g.direto && len(g.corpo)+len(p) > g.limite {
+		if err := g.soltar(); err != nil {
+			return 0, err
+		}
+	}
+	if g.direto {
+		return g.ResponseWriter.Write(p)
+	}
+	g.corpo = append(g.corpo, p...)
+	return len(p), nil
+}
+
+func (g *gravador) statusFinal() int {
+	if g.status == 0 {
+		return http.StatusOK // o net/http responde 200 se nada foi escrito
+	}
+	return g.status
+}
+
+// soltar envia o cabeçalho e o que estiver guardado; a partir daqui a
+// resposta segue sem passar pelo gravador
+func (g *gravador) soltar() error {
+	if g.direto {
+		return nil
+	}
+	g.direto = true
+	if g.status == 0 && len(g.corpo) == 0 {
+		return nil // nada escrito: o net/http responde 200 vazio
+	}
+	g.ResponseWriter.WriteHeader(g.statusFinal())
+	corpo := g.corpo
+	g.corpo = nil
+	if len(corpo) == 0 {
+		return nil
+	}
+	_, err := g.ResponseWriter.Write(corpo)
+	return err
+}
+
+// Flush desiste de guardar: quem dá Flush quer os bytes já (SSE)
+func (g *gravador) Flush() {
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+	g.soltar()
+	http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// Hijack permite WebSocket através do middleware
+func (g *gravador) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(g.ResponseWriter).Hijack()
+	if err == nil {
+		g.direto = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap permite que http.ResponseController chegue ao writer original
+func (g *gravador) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
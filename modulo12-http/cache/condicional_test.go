@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func jsonFixo(corpo string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(corpo))
+	})
+}
+
+func TestETag(t *testing.T) {
+	h := ETag(OpcoesETag{})(jsonFixo(`{"texto":"olá"}`))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/api/mensagem", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(etag, `"`) || rec.Body.String() != `{"texto":"olá"}` {
+		t.Fatalf("1ª resposta: %d %q %q", rec.Code, etag, rec.Body.String())
+	}
+
+	testes := []struct {
+		nome        string
+		metodo      string
+		ifNoneMatch string
+		status      int
+	}{
+		{"mesmo ETag", "GET", etag, http.StatusNotModified},
+		{"ETag na lista", "GET", `"outro", ` + etag, http.StatusNotModified},
+		{"comparação fraca", "GET", "W/" + etag, http.StatusNotModified},
+		{"asterisco", "GET", "*", http.StatusNotModified},
+		{"ETag antigo", "GET", `"antigo"`, http.StatusOK},
+		{"POST não é condicional", "POST", etag, http.StatusOK},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			req := httptest.NewRequest(tt.metodo, "/api/mensagem", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d; esperado %d", rec.Code, tt.status)
+			}
+			if tt.status == http.StatusNotModified {
+				if rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" || rec.Header().Get("ETag") != etag {
+					t.Errorf("304 com corpo ou cabeçalhos errados: %v %q", rec.Header(), rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestETag_Opcoes(t *testing.T) {
+	t.Run("fraco", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ETag(OpcoesETag{Fraco: true})(jsonFixo("{}")).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if !strings.HasPrefix(rec.Header().Get("ETag"), `W/"`) {
+			t.Errorf("ETag = %q", rec.Header().Get("ETag"))
+		}
+	})
+	t.Run("grande demais passa direto", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ETag(OpcoesETag{TamanhoMaximo: 10})(jsonFixo(strings.Repeat("x", 50))).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Header().Get("ETag") != "" || rec.Body.Len() != 50 {
+			t.Errorf("ETag = %q, corpo %d bytes", rec.Header().Get("ETag"), rec.Body.Len())
+		}
+	})
+	t.Run("ETag do handler vence", func(t *testing.T) {
+		h := ETag(OpcoesETag{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v7"`)
+			w.Write([]byte("aluno"))
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-None-Match", `"v7"`)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("status = %d", rec.Code)
+		}
+	})
+	t.Run("erro não ganha ETag", func(t *testing.T) {
+		h := ETag(OpcoesETag{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "não existe", http.StatusNotFound)
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+			t.Errorf("%d %v", rec.Code, rec.Header())
+		}
+	})
+	t.Run("Flush passa direto", func(t *testing.T) {
+		h := ETag(OpcoesETag{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("data: 1\n\n"))
+			http.NewResponseController(w).Flush()
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/eventos", nil))
+		if !rec.Flushed || rec.Header().Get("ETag") != "" {
+			t.Errorf("flushed = %v, ETag = %q", rec.Flushed, rec.Header().Get("ETag"))
+		}
+	})
+}
+
+func TestETag_IfModifiedSince(t *testing.T) {
+	modificado := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := ETag(OpcoesETag{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", modificado.Format(http.TimeFormat))
+		w.Write([]byte("relatório"))
+	}))
+	testes := []struct {
+		nome      string
+		cabecalho map[string]string
+		status    int
+	}{
+		{"mesma data", map[string]string{"If-Modified-Since": modificado.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"depois", map[string]string{"If-Modified-Since": modificado.Add(time.Hour).Format(http.TimeFormat)}, http.StatusNotModified},
+		{"antes", map[string]string{"If-Modified-Since": modificado.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"data inválida", map[string]string{"If-Modified-Since": "ontem"}, http.StatusOK},
+		// If-None-Match tem prioridade sobre If-Modified-Since
+		{"If-None-Match diferente", map[string]string{
+			"If-Modified-Since": modificado.Format(http.TimeFormat),
+			"If-None-Match":     `"outro"`,
+		}, http.StatusOK},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.cabecalho {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d; esperado %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestControle(t *testing.T) {
+	testes := []struct {
+		nome     string
+		handler  http.Handler
+		esperado string
+	}{
+		{"aplica", jsonFixo("{}"), "public, max-age=60"},
+		{"handler troca", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "no-store")
+		}), "no-store"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Controle("public, max-age=60")(tt.handler).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+			if got := rec.Header().Get("Cache-Control"); got != tt.esperado {
+				t.Errorf("Cache-Control = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+CACHE DE RESPOSTAS NO SERVIDOR
+
+ETag economiza banda, mas o handler ainda roda. Para respostas caras e
+iguais para todos (estatísticas, listas públicas), guarde a resposta:
+
+    respostas := cache.Novo(cache.Opcoes{TTL: 30 * time.Second, Vary: []string{"Accept-Language"}})
+    api.Grupo("", cache.Armazenar(respostas)).Get("/estatisticas", estatisticas)
+    ...
+    respostas.Invalidar("/api/estatisticas") // depois de uma alteração
+
+O que entra:
+  - só GET com status 200
+  - nada com Cache-Control no-store/private/no-cache nem Set-Cookie
+  - nada com Vary em cabeçalhos fora de Opcoes.Vary: a chave não
+    saberia separar as versões
+  - max-age/s-maxage menor que o TTL encurta a validade
+
+O que é pulado (o handler roda, nada é guardado):
+  - requisições com Authorization ou Cookie, a não ser que estejam em
+    Vary: senão um usuário veria a resposta de outro
+  - Cache-Control: no-store na requisição (no-cache busca de novo e
+    guarda a resposta nova)
+
+A chave é o caminho com a query mais os valores dos cabeçalhos em
+Vary. Acima de MaxEntradas ou MaxBytes saem as menos usadas (LRU).
+As respostas levam X-Cache: HIT ou MISS e, no HIT, Age.
+
+ORDEM NA CADEIA: Gzip, Armazenar, ETag. Assim o cache guarda o corpo
+sem compactar (o Gzip compacta na saída) e já com o ETag, e responde
+304 sozinho nos HITs.
+*/
+
+// Opcoes configura o cache; os campos zerados usam os padrões
+type Opcoes struct {
+	TTL         time.Duration // validade máxima de uma resposta (0 = 1 min)
+	MaxEntradas int           // 0 = 1000
+	MaxBytes    int           // soma dos corpos (0 = 32 MB)
+	MaxResposta int           // maior corpo guardado (0 = 1 MB)
+	// Vary são os cabeçalhos da requisição que mudam a resposta
+	// (ex.: Accept-Language): cada combinação é uma entrada
+	Vary []string
+}
+
+// Cache guarda respostas inteiras em memória. É seguro para uso
+// concorrente.
+type Cache struct {
+	opcoes Opcoes
+	vary   map[string]bool // nomes canônicos de Opcoes.Vary
+
+	mu       sync.Mutex
+	entradas map[string]*list.Element
+	uso      *list.List // frente = usada mais recentemente
+	bytes    int
+}
+
+type entrada struct {
+	chave     string
+	caminho   string
+	cabecalho http.Header
+	corpo     []byte
+	criada    time.Time
+	expira    time.Time
+}
+
+// Novo cria um cache vazio
+func Novo(opcoes Opcoes) *Cache {
+	if opcoes.TTL <= 0 {
+		opcoes.TTL = time.Minute
+	}
+	if opcoes.MaxEntradas <= 0 {
+		opcoes.MaxEntradas = 1000
+	}
+	if opcoes.MaxBytes <= 0 {
+		opcoes.MaxBytes = 32 << 20
+	}
+	if opcoes.MaxResposta <= 0 {
+		opcoes.MaxResposta = 1 << 20
+	}
+	c := &Cache{opcoes: opcoes, vary: make(map[string]bool), entradas: make(map[string]*list.Element), uso: list.New()}
+	for _, nome := range opcoes.Vary {
+		c.vary[http.CanonicalHeaderKey(nome)] = true
+	}
+	return c
+}
+
+// Armazenar serve do cache quando possível; senão roda o handler e
+// guarda a resposta
+func Armazenar(c *Cache) func(http.Handler) http.Handler {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !c.cacheavel(r) {
+				proximo.ServeHTTP(w, r)
+				return
+			}
+			chave := c.chave(r)
+			if !temDiretiva(r.Header.Get("Cache-Control"), "no-cache") {
+				if e, ok := c.buscar(chave); ok {
+					servir(w, r, e, "HIT")
+					return
+				}
+			}
+			if r.Method == http.MethodHead {
+				// O corpo do HEAD não serve para o GET
+				proximo.ServeHTTP(w, r)
+				return
+			}
+
+			// Sem as pré-condições o handler responde 200 completo, que
+			// é o que interessa guardar; o 304 sai daqui mesmo
+			interna := r.Clone(r.Context())
+			interna.Header.Del("If-None-Match")
+			interna.Header.Del("If-Modified-Since")
+
+			// Cabeçalhos de quem está por fora (X-Request-ID, CORS, o Vary
+			// do Gzip) são refeitos a cada requisição: não entram no cache
+			antes := w.Header().Clone()
+			g := &gravador{ResponseWriter: w, limite: c.opcoes.MaxResposta}
+			proximo.ServeHTTP(g, interna)
+			if g.direto {
+				return
+			}
+			if g.statusFinal() != http.StatusOK {
+				g.soltar()
+				return
+			}
+			e := &entrada{
+				chave:     chave,
+				caminho:   r.URL.Path,
+				cabecalho: novosCabecalhos(antes, w.Header()),
+				corpo:     g.corpo,
+				criada:    time.Now(),
+			}
+			if validade, ok := c.validade(w.Header(), e.cabecalho); ok {
+				e.expira = e.criada.Add(validade)
+				c.guardar(e)
+			}
+			// w.Header() já tem os cabeçalhos do handler
+			w.Header().Set("X-Cache", "MISS")
+			if naoModificado(r, w.Header()) {
+				responderNaoModificado(w)
+				return
+			}
+			g.soltar()
+		})
+	}
+}
+
+// Invalidar remove todas as entradas do caminho (qualquer query ou Vary)
+func (c *Cache) Invalidar(caminho string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, el := range c.entradas {
+		if e := el.Value.(*entrada); e.caminho == caminho {
+			c.remover(el)
+		}
+	}
+}
+
+// Limpar esvazia o cache
+func (c *Cache) Limpar() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entradas = make(map[string]*list.Element)
+	c.uso.Init()
+	c.bytes = 0
+}
+
+// Tamanho informa quantas entradas e quantos bytes de corpo estão guardados
+func (c *Cache) Tamanho() (entradas, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entradas), c.bytes
+}
+
+// cacheavel olha só a requisição
+func (c *Cache) cacheavel(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if temDiretiva(r.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+	for _, pessoal := range []string{"Authorization", "Cookie"} {
+		if r.Header.Get(pessoal) != "" && !c.vary[pessoal] {
+			return false
+		}
+	}
+	return true
+}
+
+// chave junta caminho, query e os cabeçalhos em Vary (na ordem das
+// opções, para a mesma requisição gerar sempre a mesma chave)
+func (c *Cache) chave(r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(r.URL.RequestURI())
+	for _, nome := range c.opcoes.Vary {
+		b.WriteString("\n")
+		b.WriteString(strings.Join(r.Header.Values(nome), ","))
+	}
+	return b.String()
+}
+
+// validade decide se a resposta pode ser guardada e por quanto tempo.
+// Cache-Control e Set-Cookie valem venham de onde vierem; o Vary, só o
+// de dentro (o de fora é tratado por quem o pôs, como o Gzip)
+func (c *Cache) validade(h, novos http.Header) (time.Duration, bool) {
+	if h.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+	controle := h.Get("Cache-Control")
+	for _, proibida := range []string{"no-store", "private", "no-cache"} {
+		if temDiretiva(controle, proibida) {
+			return 0, false
+		}
+	}
+	for _, v := range novos.Values("Vary") {
+		for _, nome := range strings.Split(v, ",") {
+			nome = strings.TrimSpace(nome)
+			if nome == "*" || nome != "" && !c.vary[http.CanonicalHeaderKey(nome)] {
+				return 0, false
+			}
+		}
+	}
+	validade := c.opcoes.TTL
+	for _, diretiva := range []string{"s-maxage", "max-age"} {
+		if segundos, ok := valorDiretiva(controle, diretiva); ok {
+			if d := time.Duration(segundos) * time.Second; d < validade {
+				validade = d
+			}
+			break // s-maxage vale mais que max-age para um cache compartilhado
+		}
+	}
+	return validade, validade > 0
+}
+
+func (c *Cache) buscar(chave string) (*entrada, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entradas[chave]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entrada)
+	if time.Now().After(e.expira) {
+		c.remover(el)
+		return nil, false
+	}
+	c.uso.MoveToFront(el)
+	return e, true
+}
+
+func (c *Cache) guardar(e *entrada) {
+	if len(e.corpo) > c.opcoes.MaxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if antiga, ok := c.entradas[e.chave]; ok {
+		c.remover(antiga)
+	}
+	c.entradas[e.chave] = c.uso.PushFront(e)
+	c.bytes += len(e.corpo)
+	for len(c.entradas) > c.opcoes.MaxEntradas || c.bytes > c.opcoes.MaxBytes {
+		c.remover(c.uso.Back())
+	}
+}
+
+// remover tira a entrada do mapa e da lista (com c.mu travado)
+func (c *Cache) remover(el *list.Element) {
+	e := c.uso.Remove(el).(*entrada)
+	delete(c.entradas, e.chave)
+	c.bytes -= len(e.corpo)
+}
+
+// novosCabecalhos são os valores que o handler (e os middlewares de
+// dentro) acrescentaram a antes
+func novosCabecalhos(antes, depois http.Header) http.Header {
+	novos := make(http.Header)
+	for nome, valores := range depois {
+		for _, v := range valores {
+			if !contem(antes[nome], v) {
+				novos[nome] = append(novos[nome], v)
+			}
+		}
+	}
+	return novos
+}
+
+func contem(lista []string, v string) bool {
+	for _, item := range lista {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// servir responde com uma entrada guardada (o corpo nunca é alterado:
+// várias requisições leem o mesmo slice)
+func servir(w http.ResponseWriter, r *http.Request, e *entrada, estado string) {
+	h := w.Header()
+	for nome, valores := range e.cabecalho {
+		for _, v := range valores {
+			if !contem(h[nome], v) {
+				h[nome] = append(h[nome], v)
+			}
+		}
+	}
+	h.Set("X-Cache", estado)
+	h.Set("Age", strconv.Itoa(int(time.Since(e.criada).Seconds())))
+	if naoModificado(r, h) {
+		responderNaoModificado(w)
+		return
+	}
+	h.Set("Content-Length", strconv.Itoa(len(e.corpo)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(e.corpo)
+	}
+}
+
+// temDiretiva procura uma diretiva em Cache-Control (sem diferenciar
+// maiúsculas)
+func temDiretiva(controle, nome string) bool {
+	for _, parte := range strings.Split(controle, ",") {
+		diretiva, _, _ := strings.Cut(strings.TrimSpace(parte), "=")
+		if strings.EqualFold(diretiva, nome) {
+			return true
+		}
+	}
+	return false
+}
+
+// valorDiretiva lê o número de uma diretiva como max-age=60
+func valorDiretiva(controle, nome string) (int, bool) {
+	for _, parte := range strings.Split(controle, ",") {
+		diretiva, valor, ok := strings.Cut(strings.TrimSpace(parte), "=")
+		if ok && strings.EqualFold(diretiva, nome) {
+			n, err := strconv.Atoi(strings.Trim(valor, `"`))
+			return n, err == nil && n >= 0
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// contador responde "resposta N" e conta quantas vezes rodou
+func contador(n *atomic.Int32, ajustar func(w http.ResponseWriter, r *http.Request)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := n.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		if ajustar != nil {
+			ajustar(w, r)
+		}
+		fmt.Fprintf(w, "resposta %d", v)
+	})
+}
+
+func pedir(h http.Handler, metodo, alvo string, cabecalhos ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(metodo, alvo, nil)
+	for i := 0; i+1 < len(cabecalhos); i += 2 {
+		req.Header.Set(cabecalhos[i], cabecalhos[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestArmazenar(t *testing.T) {
+	var n atomic.Int32
+	c := Novo(Opcoes{})
+	h := Armazenar(c)(contador(&n, nil))
+
+	primeira := pedir(h, "GET", "/api/estatisticas")
+	segunda := pedir(h, "GET", "/api/estatisticas")
+	if primeira.Header().Get("X-Cache") != "MISS" || segunda.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("X-Cache = %q, %q", primeira.Header().Get("X-Cache"), segunda.Header().Get("X-Cache"))
+	}
+	if segunda.Body.String() != "resposta 1" || segunda.Header().Get("Content-Type") != "text/plain" || segunda.Header().Get("Age") == "" {
+		t.Errorf("HIT: %q %v", segunda.Body.String(), segunda.Header())
+	}
+	if pedir(h, "GET", "/api/estatisticas?turma=b").Body.String() != "resposta 2" {
+		t.Error("query diferente deveria ser outra entrada")
+	}
+	if rec := pedir(h, "HEAD", "/api/estatisticas"); rec.Body.Len() != 0 || rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("HEAD: %q %v", rec.Body.String(), rec.Header())
+	}
+	if pedir(h, "POST", "/api/estatisticas").Header().Get("X-Cache") != "" {
+		t.Error("POST não passa pelo cache")
+	}
+
+	c.Invalidar("/api/estatisticas")
+	if entradas, _ := c.Tamanho(); entradas != 0 {
+		t.Errorf("%d entradas depois de Invalidar", entradas)
+	}
+	if got := pedir(h, "GET", "/api/estatisticas").Body.String(); got != "resposta 4" {
+		t.Errorf("depois de Invalidar: %q", got)
+	}
+}
+
+func TestArmazenar_NaoGuarda(t *testing.T) {
+	testes := []struct {
+		nome       string
+		ajustar    func(w http.ResponseWriter, r *http.Request)
+		cabecalhos []string
+	}{
+		{"no-store", func(w http.ResponseWriter, r *http.Request) { w.Header().Set("Cache-Control", "no-store") }, nil},
+		{"private", func(w http.ResponseWriter, r *http.Request) { w.Header().Set("Cache-Control", "private, max-age=60") }, nil},
+		{"max-age=0", func(w http.ResponseWriter, r *http.Request) { w.Header().Set("Cache-Control", "max-age=0") }, nil},
+		{"Set-Cookie", func(w http.ResponseWriter, r *http.Request) { w.Header().Set("Set-Cookie", "sessao=1") }, nil},
+		{"Vary não configurado", func(w http.ResponseWriter, r *http.Request) { w.Header().Set("Vary", "Accept-Language") }, nil},
+		{"status 404", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) }, nil},
+		{"Authorization", nil, []string{"Authorization", "Bearer x"}},
+		{"Cookie", nil, []string{"Cookie", "sessao=1"}},
+		{"requisição no-store", nil, []string{"Cache-Control", "no-store"}},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			var n atomic.Int32
+			c := Novo(Opcoes{})
+			h := Armazenar(c)(contador(&n, tt.ajustar))
+			pedir(h, "GET", "/x", tt.cabecalhos...)
+			pedir(h, "GET", "/x", tt.cabecalhos...)
+			if n.Load() != 2 {
+				t.Errorf("o handler rodou %d vezes; esperado 2", n.Load())
+			}
+		})
+	}
+}
+
+func TestArmazenar_Vary(t *testing.T) {
+	var n atomic.Int32
+	c := Novo(Opcoes{Vary: []string{"Accept-Language"}})
+	h := Armazenar(c)(contador(&n, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Accept-Language")
+	}))
+	pt := pedir(h, "GET", "/", "Accept-Language", "pt-BR")
+	en := pedir(h, "GET", "/", "Accept-Language", "en")
+	pt2 := pedir(h, "GET", "/", "Accept-Language", "pt-BR")
+	if pt.Body.String() == en.Body.String() || pt2.Body.String() != pt.Body.String() || n.Load() != 2 {
+		t.Errorf("pt=%q en=%q pt2=%q execuções=%d", pt.Body.String(), en.Body.String(), pt2.Body.String(), n.Load())
+	}
+}
+
+func TestArmazenar_CabecalhosDeFora(t *testing.T) {
+	// Um middleware de fora (ID da requisição, Vary do Gzip) não entra na entrada
+	var n atomic.Int32
+	var id atomic.Int32
+	c := Novo(Opcoes{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", fmt.Sprint(id.Add(1)))
+		w.Header().Add("Vary", "Accept-Encoding")
+		Armazenar(c)(contador(&n, nil)).ServeHTTP(w, r)
+	})
+	pedir(h, "GET", "/")
+	rec := pedir(h, "GET", "/")
+	if rec.Header().Get("X-Cache") != "HIT" || rec.Header().Get("X-Request-ID") != "2" || len(rec.Header().Values("Vary")) != 1 {
+		t.Errorf("cabeçalhos do HIT: %v", rec.Header())
+	}
+}
+
+func TestArmazenar_ComETag(t *testing.T) {
+	var n atomic.Int32
+	c := Novo(Opcoes{})
+	h := Armazenar(c)(ETag(OpcoesETag{})(contador(&n, nil)))
+
+	etag := pedir(h, "GET", "/").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("sem ETag")
+	}
+	// HIT: o cache responde o 304 sem rodar o handler
+	rec := pedir(h, "GET", "/", "If-None-Match", etag)
+	if rec.Code != http.StatusNotModified || rec.Header().Get("X-Cache") != "HIT" || n.Load() != 1 {
+		t.Errorf("status %d, X-Cache %q, execuções %d", rec.Code, rec.Header().Get("X-Cache"), n.Load())
+	}
+
+	// MISS com If-None-Match: o handler responde 200 completo (que é
+	// guardado) e o cliente recebe 304
+	c.Limpar()
+	rec = pedir(h, "GET", "/", "If-None-Match", `"qualquer"`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("ETag diferente: %d", rec.Code)
+	}
+	if entradas, _ := c.Tamanho(); entradas != 1 {
+		t.Errorf("%d entradas", entradas)
+	}
+}
+
+func TestArmazenar_Concorrente(t *testing.T) {
+	var n atomic.Int32
+	c := Novo(Opcoes{MaxEntradas: 5})
+	h := Armazenar(c)(contador(&n, nil))
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			alvo := fmt.Sprintf("/item/%d", i%10)
+			if rec := pedir(h, "GET", alvo); rec.Code != http.StatusOK {
+				t.Errorf("%s: %d", alvo, rec.Code)
+			}
+			if i%7 == 0 {
+				c.Invalidar(alvo)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if entradas, _ := c.Tamanho(); entradas > 5 {
+		t.Errorf("%d entradas; máximo 5", entradas)
+	}
+}
+
+func TestArmazenar_Limites(t *testing.T) {
+	t.Run("MaxEntradas tira a menos usada", func(t *testing.T) {
+		var n atomic.Int32
+		c := Novo(Opcoes{MaxEntradas: 2})
+		h := Armazenar(c)(contador(&n, nil))
+		pedir(h, "GET", "/a")
+		pedir(h, "GET", "/b")
+		pedir(h, "GET", "/a") // /a passa a ser a mais recente
+		pedir(h, "GET", "/c") // sai /b
+		if pedir(h, "GET", "/a").Header().Get("X-Cache") != "HIT" || pedir(h, "GET", "/b").Header().Get("X-Cache") != "MISS" {
+			t.Error("LRU errado")
+		}
+	})
+	t.Run("MaxBytes", func(t *testing.T) {
+		var n atomic.Int32
+		c := Novo(Opcoes{MaxBytes: 25})
+		h := Armazenar(c)(contador(&n, nil)) // "resposta N" = 10 bytes
+		for _, alvo := range []string{"/a", "/b", "/c"} {
+			pedir(h, "GET", alvo)
+		}
+		if entradas, bytes := c.Tamanho(); entradas != 2 || bytes != 20 {
+			t.Errorf("entradas=%d bytes=%d", entradas, bytes)
+		}
+	})
+	t.Run("MaxResposta passa direto", func(t *testing.T) {
+		c := Novo(Opcoes{MaxResposta: 100})
+		h := Armazenar(c)(jsonFixo(strings.Repeat("x", 500)))
+		if rec := pedir(h, "GET", "/"); rec.Body.Len() != 500 {
+			t.Errorf("corpo com %d bytes", rec.Body.Len())
+		}
+		if entradas, _ := c.Tamanho(); entradas != 0 {
+			t.Error("resposta grande não deveria ser guardada")
+		}
+	})
+	t.Run("TTL e max-age", func(t *testing.T) {
+		var n atomic.Int32
+		c := Novo(Opcoes{TTL: time.Hour})
+		h := Armazenar(c)(contador(&n, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "public, max-age=1")
+		}))
+		pedir(h, "GET", "/")
+		c.mu.Lock()
+		for _, el := range c.entradas {
+			e := el.Value.(*entrada)
+			if d := e.expira.Sub(e.criada); d != time.Second {
+				t.Errorf("validade = %v; esperado o max-age", d)
+			}
+			e.expira = time.Now().Add(-time.Millisecond) // vence agora
+		}
+		c.mu.Unlock()
+		if pedir(h, "GET", "/").Header().Get("X-Cache") != "MISS" {
+			t.Error("entrada vencida não deveria ser servida")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"go-course/modulo12-http/websocket"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Handler liga o hub a conexões WebSocket:
+//
+//	ws://host/chat?sala=geral&apelido=ana
+//
+// O cliente manda texto puro; o servidor manda cada Mensagem em JSON.
+// Comandos: "/nick novo", "/quem" e "/sair".
+//
+// Sem Opcoes.PrazoLeitura, o servidor desiste de um cliente calado
+// por 60s; os pings a cada metade do prazo mantêm vivos os que
+// continuam lá (o navegador responde sozinho).
+func (h *Hub) Handler(opcoes websocket.Opcoes) http.Handler {
+	if opcoes.PrazoLeitura <= 0 {
+		opcoes.PrazoLeitura = 60 * time.Second
+	}
+	if opcoes.TamanhoMaxMensagem <= 0 {
+		opcoes.TamanhoMaxMensagem = int64(4*h.opcoes.TamanhoMaxTexto) + 64 // até 4 bytes por caractere
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sala := r.URL.Query().Get("sala")
+		if sala == "" {
+			sala = "geral"
+		}
+		// Entrar antes do handshake: apelido em uso ainda é um 409 HTTP,
+		// que o cliente vê sem abrir o WebSocket
+		m, err := h.Entrar(sala, r.URL.Query().Get("apelido"))
+		if err != nil {
+			http.Error(w, err.Error(), statusEntrada(err))
+			return
+		}
+		conn, err := websocket.Aceitar(w, r, opcoes)
+		if err != nil {
+			m.Sair()
+			return
+		}
+
+		escrevendo := make(chan struct{})
+		go func() {
+			defer close(escrevendo)
+			escreverMembro(conn, m, opcoes.PrazoLeitura/2)
+		}()
+		lerMembro(conn, m)
+
+		m.Sair() // fecha Recebidas e encerra a goroutine de escrita
+		<-escrevendo
+		conn.Close()
+	})
+}
+
+// lerMembro trata o que o cliente manda, até a conexão acabar
+func lerMembro(conn *websocket.Conexao, m *Membro) {
+	for {
+		tipo, dados, err := conn.LerMensagem()
+		if err != nil {
+			return
+		}
+		if tipo != websocket.Texto {
+			conn.Fechar(websocket.CodigoTipoNaoSuportado, "o chat só aceita texto")
+			continue // o close de volta encerra a leitura
+		}
+
+		texto := string(dados)
+		switch comando, arg, _ := strings.Cut(strings.TrimSpace(texto), " "); comando {
+		case "/nick":
+			err = m.TrocarApelido(strings.TrimSpace(arg))
+		case "/quem":
+			err = m.PedirMembros()
+		case "/sair":
+			conn.Fechar(websocket.CodigoNormal, "até logo")
+		default:
+			err = m.Falar(texto)
+		}
+		if err != nil {
+			enviarJSON(conn, Mensagem{Tipo: TipoErro, Texto: err.Error(), Em: time.Now()})
+		}
+	}
+}
+
+// escreverMembro repassa as mensagens da sala ao cliente e faz os
+// pings; um cliente que não dá conta é fechado com 1008
+func escreverMembro(conn *websocket.Conexao, m *Membro, intervaloPing time.Duration) {
+	ping := time.NewTicker(intervaloPing)
+	defer ping.Stop()
+	for {
+		select {
+		case msg, ok := <-m.Recebidas():
+			if !ok {
+				switch m.Err() {
+				case ErrLento:
+					conn.Fechar(websocket.CodigoViolacaoPolitica, ErrLento.Error())
+				case ErrHubFechado:
+					conn.Fechar(websocket.CodigoSaindo, ErrHubFechado.Error())
+				}
+				return
+			}
+			if err := enviarJSON(conn, msg); err != nil {
+				// LerMensagem vai falhar também; a leitura encerra o membro
+				conn.Close()
+				return
+			}
+		case <-ping.C:
+			if err := conn.Ping(nil); err != nil && !errors.Is(err, websocket.ErrFechada) {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func enviarJSON(conn *websocket.Conexao, msg Mensagem) error {
+	dados, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.EscreverMensagem(websocket.Texto, dados)
+}
+
+func statusEntrada(err error) int {
+	switch {
+	case errors.Is(err, ErrApelidoEmUso):
+		return http.StatusConflict
+	case errors.Is(err, ErrHubFechado):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,118 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-course/modulo12-http/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func servidorChat(t *testing.T, hub *Hub) string {
+	t.Helper()
+	srv := httptest.NewServer(hub.Handler(websocket.Opcoes{}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func conectar(t *testing.T, url string) *websocket.Conexao {
+	t.Helper()
+	ctx, cancelar := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelar()
+	conn, _, err := websocket.Discar(ctx, url, websocket.Opcoes{PrazoLeitura: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func lerJSON(t *testing.T, conn *websocket.Conexao) Mensagem {
+	t.Helper()
+	_, dados, err := conn.LerMensagem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Mensagem
+	if err := json.Unmarshal(dados, &m); err != nil {
+		t.Fatalf("%s: %v", dados, err)
+	}
+	return m
+}
+
+func TestHandler(t *testing.T) {
+	hub := NovoHub(Opcoes{})
+	defer hub.Fechar()
+	url := servidorChat(t, hub)
+
+	ana := conectar(t, url+"?sala=go&apelido=ana")
+	if m := lerJSON(t, ana); m.Tipo != TipoEntrou || m.Sala != "go" {
+		t.Errorf("primeira mensagem = %+v", m)
+	}
+	ana.EscreverMensagem(websocket.Texto, []byte("primeira!"))
+	lerJSON(t, ana)
+
+	// Quem chega depois recebe o histórico
+	rui := conectar(t, url+"?sala=go&apelido=rui")
+	if m := lerJSON(t, rui); m.Texto != "primeira!" || !m.Historico {
+		t.Errorf("histórico = %+v", m)
+	}
+	lerJSON(t, rui) // entrou rui
+	lerJSON(t, ana) // entrou rui
+
+	rui.EscreverMensagem(websocket.Texto, []byte("/nick rui_dev"))
+	if m := lerJSON(t, ana); m.Tipo != TipoApelido || m.Apelido != "rui_dev" || m.Texto != "rui" {
+		t.Errorf("troca de apelido = %+v", m)
+	}
+	lerJSON(t, rui)
+
+	rui.EscreverMensagem(websocket.Texto, []byte("/nick ana"))
+	if m := lerJSON(t, rui); m.Tipo != TipoErro || m.Texto != ErrApelidoEmUso.Error() {
+		t.Errorf("erro só para rui = %+v", m)
+	}
+	rui.EscreverMensagem(websocket.Texto, []byte("/quem"))
+	if m := lerJSON(t, rui); m.Tipo != TipoMembros || strings.Join(m.Membros, ",") != "ana,rui_dev" {
+		t.Errorf("membros = %+v", m)
+	}
+
+	// Binário não é aceito: 1003
+	rui.EscreverMensagem(websocket.Binario, []byte{1, 2})
+	if _, _, err := rui.LerMensagem(); websocket.CodigoDe(err) != websocket.CodigoTipoNaoSuportado {
+		t.Errorf("binário: %v", err)
+	}
+	if m := lerJSON(t, ana); m.Tipo != TipoSaiu || m.Apelido != "rui_dev" {
+		t.Errorf("saída = %+v", m)
+	}
+
+	// O hub fechando encerra as conexões com 1001
+	hub.Fechar()
+	if _, _, err := ana.LerMensagem(); websocket.CodigoDe(err) != websocket.CodigoSaindo {
+		t.Errorf("hub fechado: %v", err)
+	}
+}
+
+func TestHandler_Recusas(t *testing.T) {
+	hub := NovoHub(Opcoes{})
+	defer hub.Fechar()
+	url := servidorChat(t, hub)
+	conectar(t, url+"?apelido=ana") // sala "geral"
+
+	testes := []struct {
+		consulta string
+		status   int
+	}{
+		{"?apelido=ana", http.StatusConflict},
+		{"?apelido=a", http.StatusBadRequest},
+		{"?sala=Sala!&apelido=rui", http.StatusBadRequest},
+	}
+	for _, tt := range testes {
+		_, resp, err := websocket.Discar(context.Background(), url+tt.consulta, websocket.Opcoes{})
+		if !errors.Is(err, websocket.ErrHandshake) || resp == nil || resp.StatusCode != tt.status {
+			t.Errorf("%s: err = %v, resp = %v; esperado %d", tt.consulta, err, resp, tt.status)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+package chat
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+PACKAGE CHAT
+
+O projeto "Chat Server" do ROADMAP: salas, apelidos e histórico.
+O Hub não sabe de WebSocket: conhece Membros, que recebem Mensagens
+por um channel. http.go liga cada Membro a uma conexão do package
+websocket.
+
+    hub := chat.NovoHub(chat.Opcoes{})
+    defer hub.Fechar()
+    mux.Handle("/chat", hub.Handler(websocket.Opcoes{}))
+
+    // ws://localhost:8080/chat?sala=geral&apelido=ana
+
+UMA GOROUTINE POR SALA:
+Cada sala tem uma goroutine dona dos membros e do histórico; entrar,
+sair, falar e trocar de apelido são eventos mandados por um channel
+(o padrão "compartilhe memória comunicando" de modulo05-goroutines).
+Sem mutex nos membros: só a goroutine da sala os toca.
+
+Quando o último membro sai, a goroutine termina e a sala some do Hub
+(com o histórico): o nome vem do cliente (?sala=), e salas eternas
+seriam goroutines e memória sem limite.
+
+BACKPRESSURE:
+Cada membro tem uma fila (channel com buffer de Opcoes.TamanhoFila).
+A sala nunca espera por um membro: se a fila está cheia, o membro é
+desconectado com ErrLento. Um cliente lento não atrasa a sala toda, e
+a memória por membro tem teto.
+
+HISTÓRICO:
+As últimas Opcoes.Historico mensagens de cada sala vão para quem
+entra, antes do aviso de entrada, marcadas com "historico": true.
+*/
+
+// Tipos de Mensagem
+const (
+	TipoMensagem = "mensagem"
+	TipoEntrou   = "entrou"
+	TipoSaiu     = "saiu"
+	TipoApelido  = "apelido" // Texto traz o apelido anterior
+	TipoMembros  = "membros" // Membros traz a lista, só para quem pediu
+	TipoErro     = "erro"    // só para quem causou
+)
+
+// Mensagem é o que os membros recebem
+type Mensagem struct {
+	Tipo      string    `json:"tipo"`
+	Sala      string    `json:"sala"`
+	Apelido   string    `json:"apelido,omitempty"`
+	Texto     string    `json:"texto,omitempty"`
+	Membros   []string  `json:"membros,omitempty"`
+	Em        time.Time `json:"em"`
+	Historico bool      `json:"historico,omitempty"`
+}
+
+// Erros do chat
+var (
+	ErrApelidoInvalido = errors.New("apelido deve ter de 2 a 20 letras, números, '_' ou '-'")
+	ErrApelidoEmUso    = errors.New("apelido em uso na sala")
+	ErrSalaInvalida    = errors.New("sala deve ter de 1 a 32 letras minúsculas, números ou '-'")
+	ErrTextoInvalido   = errors.New("mensagem vazia ou longa demais")
+	ErrLento           = errors.New("cliente lento: mensagens acumuladas demais")
+	ErrHubFechado      = errors.New("chat encerrado")
+)
+
+var (
+	padraoApelido = regexp.MustCompile(`^[\p{L}\p{N}_-]{2,20}$`)
+	padraoSala    = regexp.MustCompile(`^[a-z0-9-]{1,32}$`)
+)
+
+// Opcoes configura o Hub; os campos zerados usam os padrões
+type Opcoes struct {
+	Historico       int // mensagens guardadas por sala (0 = 50)
+	TamanhoFila     int // mensagens pendentes por membro (0 = 64)
+	TamanhoMaxTexto int // em caracteres (0 = 2000)
+	// Agora substitui time.Now (para testes)
+	Agora func() time.Time
+}
+
+// Hub guarda as salas; seguro para uso concorrente
+type Hub struct {
+	opcoes Opcoes
+
+	mu      sync.Mutex
+	salas   map[string]*sala
+	fechado bool
+	grupo   sync.WaitGroup
+}
+
+// NovoHub cria um hub sem salas; elas nascem no primeiro Entrar
+func NovoHub(opcoes Opcoes) *Hub {
+	if opcoes.Historico <= 0 {
+		opcoes.Historico = 50
+	}
+	if opcoes.TamanhoFila <= 0 {
+		opcoes.TamanhoFila = 64
+	}
+	// O histórico e o aviso de entrada precisam caber na fila
+	if opcoes.TamanhoFila < opcoes.Historico+2 {
+		opcoes.TamanhoFila = opcoes.Historico + 2
+	}
+	if opcoes.TamanhoMaxTexto <= 0 {
+		opcoes.TamanhoMaxTexto = 2000
+	}
+	if opcoes.Agora == nil {
+		opcoes.Agora = time.Now
+	}
+	return &Hub{opcoes: opcoes, salas: make(map[string]*sala)}
+}
+
+// Entrar põe apelido na sala, criando-a se preciso. O membro recebe o
+// histórico e depois as mensagens novas em Recebidas.
+func (h *Hub) Entrar(nomeSala, apelido string) (*Membro, error) {
+	if !padraoSala.MatchString(nomeSala) {
+		return nil, ErrSalaInvalida
+	}
+	if !padraoApelido.MatchString(apelido) {
+		return nil, ErrApelidoInvalido
+	}
+
+	h.mu.Lock()
+	if h.fechado {
+		h.mu.Unlock()
+		return nil, ErrHubFechado
+	}
+	s, ok := h.salas[nomeSala]
+	if !ok {
+		s = novaSala(nomeSala, h)
+		h.salas[nomeSala] = s
+		h.grupo.Add(1)
+		go func() {
+			defer h.grupo.Done()
+			s.executar()
+		}()
+	}
+	// A sala não se encerra enquanto houver uma entrada a caminho
+	s.chegando++
+	h.mu.Unlock()
+
+	m := &Membro{
+		sala:   s,
+		fila:   make(chan Mensagem, h.opcoes.TamanhoFila),
+		limite: h.opcoes.TamanhoMaxTexto,
+	}
+	resposta := make(chan error, 1)
+	if err := m.enviar(evento{tipo: eventoEntrar, membro: m, texto: apelido, resposta: resposta}); err != nil {
+		return nil, err
+	}
+	if err := <-resposta; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Salas retorna os nomes das salas, em ordem
+func (h *Hub) Salas() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	nomes := make([]string, 0, len(h.salas))
+	for nome := range h.salas {
+		nomes = append(nomes, nome)
+	}
+	sort.Strings(nomes)
+	return nomes
+}
+
+// Fechar encerra todas as salas: as filas dos membros são fechadas e
+// Err passa a retornar ErrHubFechado
+func (h *Hub) Fechar() {
+	h.mu.Lock()
+	if h.fechado {
+		h.mu.Unlock()
+		return
+	}
+	h.fechado = true
+	for _, s := range h.salas {
+		close(s.fim)
+	}
+	h.mu.Unlock()
+	h.grupo.Wait()
+}
+
+// ========================================
+// MEMBRO
+// ========================================
+
+// Membro é a presença de um apelido em uma sala
+type Membro struct {
+	sala   *sala
+	fila   chan Mensagem
+	limite int
+
+	// escritos só pela goroutine da sala, antes de fechar a fila
+	apelido string
+	motivo  error
+
+	sairOnce sync.Once
+}
+
+// Recebidas entrega as mensagens da sala; é fechado quando o membro
+// sai, é desconectado por lentidão ou o hub fecha (veja Err)
+func (m *Membro) Recebidas() <-chan Mensagem {
+	return m.fila
+}
+
+// Err diz por que Recebidas foi fechado: nil (Sair), ErrLento ou
+// ErrHubFechado. Só tem valor depois que Recebidas fecha.
+func (m *Membro) Err() error {
+	return m.motivo
+}
+
+// Falar manda texto para todos da sala, inclusive o próprio membro
+func (m *Membro) Falar(texto string) error {
+	texto = strings.TrimSpace(texto)
+	if texto == "" || utf8.RuneCountInString(texto) > m.limite {
+		return ErrTextoInvalido
+	}
+	return m.enviar(evento{tipo: eventoFalar, membro: m, texto: texto})
+}
+
+// TrocarApelido muda o apelido, se o novo estiver livre na sala
+func (m *Membro) TrocarApelido(novo string) error {
+	if !padraoApelido.MatchString(novo) {
+		return ErrApelidoInvalido
+	}
+	resposta := make(chan error, 1)
+	if err := m.enviar(evento{tipo: eventoApelido, membro: m, texto: novo, resposta: resposta}); err != nil {
+		return err
+	}
+	return <-resposta
+}
+
+// PedirMembros pede a lista de apelidos, que chega em Recebidas como
+// uma Mensagem TipoMembros
+func (m *Membro) PedirMembros() error {
+	return m.enviar(evento{tipo: eventoMembros, membro: m})
+}
+
+// Sair tira o membro da sala; pode ser chamado mais de uma vez
+func (m *Membro) Sair() {
+	m.sairOnce.Do(func() {
+		m.enviar(evento{tipo: eventoSair, membro: m})
+	})
+}
+
+// enviar entrega um evento à goroutine da sala, a não ser que o hub
+// tenha fechado (aí não há mais quem receba)
+func (m *Membro) enviar(e evento) error {
+	select {
+	case m.sala.eventos <- e:
+		return nil
+	case <-m.sala.fim:
+		return ErrHubFechado
+	}
+}
+
+// ========================================
+// SALA
+// ========================================
+
+type tipoEvento int
+
+const (
+	eventoEntrar tipoEvento = iota
+	eventoSair
+	eventoFalar
+	eventoApelido
+	eventoMembros
+)
+
+type evento struct {
+	tipo     tipoEvento
+	membro   *Membro
+	texto    string
+	resposta chan error
+}
+
+type sala struct {
+	nome    string
+	opcoes  Opcoes
+	hub     *Hub
+	eventos chan evento
+	fim     chan struct{} // fechado por Hub.Fechar ou quando a sala esvazia
+
+	chegando int // Entrar em andamento (com hub.mu)
+
+	// da goroutine executar
+	membros   map[*Membro]bool
+	apelidos  map[string]*Membro // em minúsculas: "Ana" e "ana" colidem
+	historico []Mensagem         // circular, até opcoes.Historico
+	inicio    int
+}
+
+func novaSala(nome string, h *Hub) *sala {
+	return &sala{
+		nome:     nome,
+		opcoes:   h.opcoes,
+		hub:      h,
+		eventos:  make(chan evento),
+		fim:      make(chan struct{}),
+		membros:  make(map[*Membro]bool),
+		apelidos: make(map[string]*Membro),
+	}
+}
+
+// executar é a goroutine da sala: processa eventos até o hub fechar ou
+// a sala ficar vazia
+func (s *sala) executar() {
+	for {
+		var e evento
+		select {
+		case e = <-s.eventos:
+		case <-s.fim:
+			for m := range s.membros {
+				m.motivo = ErrHubFechado
+				close(m.fila)
+			}
+			return
+		}
+
+		switch e.tipo {
+		case eventoEntrar:
+			s.hub.mu.Lock()
+			s.chegando--
+			s.hub.mu.Unlock()
+			e.resposta <- s.entrar(e.membro, e.texto)
+		case eventoSair:
+			if s.membros[e.membro] {
+				s.remover(e.membro, nil)
+			}
+		case eventoFalar:
+			if s.membros[e.membro] {
+				msg := s.nova(TipoMensagem, e.membro.apelido, e.texto)
+				s.guardar(msg)
+				s.difundir(msg)
+			}
+		case eventoApelido:
+			e.resposta <- s.trocarApelido(e.membro, e.texto)
+		case eventoMembros:
+			if s.membros[e.membro] {
+				msg := s.nova(TipoMembros, "", "")
+				msg.Membros = s.listar()
+				s.entregar(e.membro, msg)
+			}
+		}
+		if len(s.membros) == 0 && s.encerrar() {
+			return
+		}
+	}
+}
+
+// encerrar tira a sala vazia do hub, a não ser que alguém esteja
+// entrando: quem chegar depois cria uma sala nova. Fechar fim libera
+// quem ainda mandar eventos (um membro que já saiu chamando Sair).
+func (s *sala) encerrar() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if s.chegando > 0 || s.hub.fechado {
+		return false // com o hub fechando, Fechar fecha fim
+	}
+	delete(s.hub.salas, s.nome)
+	close(s.fim)
+	return true
+}
+
+func (s *sala) entrar(m *Membro, apelido string) error {
+	if _, ok := s.apelidos[strings.ToLower(apelido)]; ok {
+		return ErrApelidoEmUso
+	}
+	m.apelido = apelido
+	s.membros[m] = true
+	s.apelidos[strings.ToLower(apelido)] = m
+
+	// A fila comporta o histórico inteiro mais o aviso (NovoHub)
+	for i := range s.historico {
+		msg := s.historico[(s.inicio+i)%len(s.historico)]
+		msg.Historico = true
+		m.fila <- msg
+	}
+	s.difundir(s.nova(TipoEntrou, apelido, ""))
+	return nil
+}
+
+func (s *sala) trocarApelido(m *Membro, novo string) error {
+	if !s.membros[m] {
+		return ErrHubFechado
+	}
+	if dono, ok := s.apelidos[strings.ToLower(novo)]; ok && dono != m {
+		return ErrApelidoEmUso
+	}
+	antigo := m.apelido
+	delete(s.apelidos, strings.ToLower(antigo))
+	m.apelido = novo
+	s.apelidos[strings.ToLower(novo)] = m
+	s.difundir(s.nova(TipoApelido, novo, antigo))
+	return nil
+}
+
+// remover tira o membro, fecha a fila e avisa os outros
+func (s *sala) remover(m *Membro, motivo error) {
+	delete(s.membros, m)
+	delete(s.apelidos, strings.ToLower(m.apelido))
+	m.motivo = motivo
+	close(m.fila)
+	s.difundir(s.nova(TipoSaiu, m.apelido, ""))
+}
+
+// difundir entrega a todos sem nunca bloquear; quem está com a fila
+// cheia é desconectado (e a saída dele também é difundida)
+func (s *sala) difundir(msg Mensagem) {
+	var lentos []*Membro
+	for m := range s.membros {
+		select {
+		case m.fila <- msg:
+		default:
+			lentos = append(lentos, m)
+		}
+	}
+	for _, m := range lentos {
+		if s.membros[m] { // pode já ter saído numa remoção em cascata
+			s.remover(m, ErrLento)
+		}
+	}
+}
+
+// entregar manda só para m, com a mesma regra de difundir
+func (s *sala) entregar(m *Membro, msg Mensagem) {
+	select {
+	case m.fila <- msg:
+	default:
+		s.remover(m, ErrLento)
+	}
+}
+
+func (s *sala) guardar(msg Mensagem) {
+	if len(s.historico) < s.opcoes.Historico {
+		s.historico = append(s.historico, msg)
+		return
+	}
+	s.historico[s.inicio] = msg
+	s.inicio = (s.inicio + 1) % len(s.historico)
+}
+
+func (s *sala) nova(tipo, apelido, texto string) Mensagem {
+	return Mensagem{Tipo: tipo, Sala: s.nome, Apelido: apelido, Texto: texto, Em: s.opcoes.Agora()}
+}
+
+func (s *sala) listar() []string {
+	nomes := make([]string, 0, len(s.membros))
+	for m := range s.membros {
+		nomes = append(nomes, m.apelido)
+	}
+	sort.Strings(nomes)
+	return nomes
+}
@@ -0,0 +1,290 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receber lê n mensagens de m, falhando se demorarem
+func receber(t *testing.T, m *Membro, n int) []Mensagem {
+	t.Helper()
+	var msgs []Mensagem
+	for len(msgs) < n {
+		select {
+		case msg, ok := <-m.Recebidas():
+			if !ok {
+				t.Fatalf("Recebidas fechou depois de %d mensagens: %v", len(msgs), m.Err())
+			}
+			msgs = append(msgs, msg)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("esperava %d mensagens, chegaram %d", n, len(msgs))
+		}
+	}
+	return msgs
+}
+
+func resumo(msgs []Mensagem) []string {
+	var r []string
+	for _, m := range msgs {
+		s := m.Tipo + " " + m.Apelido
+		if m.Texto != "" {
+			s += ": " + m.Texto
+		}
+		if m.Historico {
+			s += " (histórico)"
+		}
+		r = append(r, s)
+	}
+	return r
+}
+
+func TestHub_Conversa(t *testing.T) {
+	hub := NovoHub(Opcoes{})
+	defer hub.Fechar()
+
+	ana, err := hub.Entrar("geral", "ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	receber(t, ana, 1) // o próprio aviso de entrada
+	rui, _ := hub.Entrar("geral", "rui")
+	receber(t, rui, 1)
+
+	ana.Falar("  oi, rui  ")
+	rui.TrocarApelido("rui_silva")
+	rui.PedirMembros()
+
+	esperado := fmt.Sprint([]string{"entrou rui", "mensagem ana: oi, rui", "apelido rui_silva: rui"})
+	if got := fmt.Sprint(resumo(receber(t, ana, 3))); got != esperado {
+		t.Errorf("ana recebeu %s; esperado %s", got, esperado)
+	}
+	msgs := receber(t, rui, 3)
+	if msgs[2].Tipo != TipoMembros || fmt.Sprint(msgs[2].Membros) != "[ana rui_silva]" {
+		t.Errorf("membros = %+v", msgs[2])
+	}
+
+	// Outra sala não ouve a geral
+	bia, _ := hub.Entrar("outra", "bia")
+	ana.Falar("só na geral")
+	receber(t, bia, 1)
+	select {
+	case msg := <-bia.Recebidas():
+		t.Errorf("bia recebeu %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	rui.Sair()
+	rui.Sair() // de novo: nada acontece
+	for range rui.Recebidas() {
+		// o que ficou na fila ("só na geral") e então o fechamento
+	}
+	if rui.Err() != nil {
+		t.Errorf("Err depois de Sair = %v", rui.Err())
+	}
+	if got := resumo(receber(t, ana, 2)); got[1] != "saiu rui_silva" {
+		t.Errorf("ana recebeu %v", got)
+	}
+	if s := fmt.Sprint(hub.Salas()); s != "[geral outra]" {
+		t.Errorf("Salas = %s", s)
+	}
+}
+
+func TestHub_Validacao(t *testing.T) {
+	hub := NovoHub(Opcoes{TamanhoMaxTexto: 5})
+	defer hub.Fechar()
+	ana, _ := hub.Entrar("geral", "Ana")
+	rui, _ := hub.Entrar("geral", "rui")
+
+	testes := []struct {
+		nome     string
+		err      error
+		esperado error
+	}{
+		{"sala inválida", erroEntrar(hub, "Sala Geral", "bia"), ErrSalaInvalida},
+		{"apelido curto", erroEntrar(hub, "geral", "b"), ErrApelidoInvalido},
+		{"apelido com espaço", erroEntrar(hub, "geral", "bia souza"), ErrApelidoInvalido},
+		{"apelido em uso", erroEntrar(hub, "geral", "ANA"), ErrApelidoEmUso},
+		{"apelido livre em outra sala", erroEntrar(hub, "outra", "ana"), nil},
+		{"acentos valem", erroEntrar(hub, "geral", "joão"), nil},
+		{"trocar para um em uso", rui.TrocarApelido("ana"), ErrApelidoEmUso},
+		{"trocar a caixa do próprio", ana.TrocarApelido("ANA"), nil},
+		{"texto vazio", ana.Falar("   "), ErrTextoInvalido},
+		{"texto longo", ana.Falar("123456"), ErrTextoInvalido},
+		{"texto no limite", ana.Falar("ação!"), nil},
+	}
+	for _, tt := range testes {
+		if !errors.Is(tt.err, tt.esperado) {
+			t.Errorf("%s: err = %v; esperado %v", tt.nome, tt.err, tt.esperado)
+		}
+	}
+}
+
+func erroEntrar(hub *Hub, sala, apelido string) error {
+	_, err := hub.Entrar(sala, apelido)
+	return err
+}
+
+func TestHub_Historico(t *testing.T) {
+	agora := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	hub := NovoHub(Opcoes{Historico: 3, Agora: func() time.Time { return agora }})
+	defer hub.Fechar()
+
+	ana, _ := hub.Entrar("geral", "ana")
+	for i := 1; i <= 5; i++ {
+		ana.Falar(fmt.Sprintf("msg %d", i))
+	}
+	receber(t, ana, 6)
+
+	// As 3 últimas, em ordem, antes do aviso de entrada
+	rui, _ := hub.Entrar("geral", "rui")
+	esperado := fmt.Sprint([]string{
+		"mensagem ana: msg 3 (histórico)",
+		"mensagem ana: msg 4 (histórico)",
+		"mensagem ana: msg 5 (histórico)",
+		"entrou rui",
+	})
+	msgs := receber(t, rui, 4)
+	if got := fmt.Sprint(resumo(msgs)); got != esperado {
+		t.Errorf("rui recebeu %s; esperado %s", got, esperado)
+	}
+	if !msgs[0].Em.Equal(agora) || msgs[0].Sala != "geral" {
+		t.Errorf("mensagem = %+v", msgs[0])
+	}
+}
+
+func TestHub_Lento(t *testing.T) {
+	hub := NovoHub(Opcoes{Historico: 1, TamanhoFila: 4})
+	defer hub.Fechar()
+
+	lento, _ := hub.Entrar("geral", "lento")
+	ana, _ := hub.Entrar("geral", "ana")
+	receber(t, ana, 1)
+
+	// lento não lê nada: a fila dele (4) enche com os 2 avisos de
+	// entrada e 2 mensagens, e a terceira o derruba
+	var recebidas []Mensagem
+	for i := 0; i < 5; i++ {
+		ana.Falar(fmt.Sprintf("msg %d", i))
+		recebidas = append(recebidas, receber(t, ana, 1)...) // ana acompanha
+	}
+	recebidas = append(recebidas, receber(t, ana, 1)...)
+	esperado := fmt.Sprint([]string{
+		"mensagem ana: msg 0", "mensagem ana: msg 1", "mensagem ana: msg 2",
+		"saiu lento",
+		"mensagem ana: msg 3", "mensagem ana: msg 4",
+	})
+	if got := fmt.Sprint(resumo(recebidas)); got != esperado {
+		t.Errorf("ana recebeu %s; esperado %s", got, esperado)
+	}
+
+	// O que já estava na fila continua lá; depois, o canal fecha
+	n := 0
+	for range lento.Recebidas() {
+		n++
+	}
+	if n != 4 || !errors.Is(lento.Err(), ErrLento) {
+		t.Errorf("lento: %d mensagens, Err = %v; esperado 4 e ErrLento", n, lento.Err())
+	}
+	// Fora da sala, falar não tem efeito
+	lento.Falar("ainda estou aqui?")
+	ana.PedirMembros()
+	if m := receber(t, ana, 1)[0]; fmt.Sprint(m.Membros) != "[ana]" {
+		t.Errorf("membros = %v", m.Membros)
+	}
+}
+
+func TestHub_Fechar(t *testing.T) {
+	hub := NovoHub(Opcoes{})
+	ana, _ := hub.Entrar("geral", "ana")
+	receber(t, ana, 1)
+
+	hub.Fechar()
+	hub.Fechar()
+	if _, ok := <-ana.Recebidas(); ok || !errors.Is(ana.Err(), ErrHubFechado) {
+		t.Errorf("depois de Fechar: aberto = %v, Err = %v", ok, ana.Err())
+	}
+	if err := ana.Falar("alô?"); !errors.Is(err, ErrHubFechado) {
+		t.Errorf("Falar = %v", err)
+	}
+	ana.Sair()
+	if _, err := hub.Entrar("geral", "rui"); !errors.Is(err, ErrHubFechado) {
+		t.Errorf("Entrar = %v", err)
+	}
+}
+
+func TestHub_SalaVaziaSome(t *testing.T) {
+	hub := NovoHub(Opcoes{})
+	defer hub.Fechar()
+	antes := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		m, err := hub.Entrar(fmt.Sprintf("sala-%d", i), "ana")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Sair()
+	}
+	esperar(t, func() bool { return len(hub.Salas()) == 0 && runtime.NumGoroutine() <= antes })
+
+	// O nome pode ser usado de novo: é uma sala nova, sem histórico
+	ana, _ := hub.Entrar("geral", "ana")
+	ana.Falar("oi")
+	receber(t, ana, 2)
+	ana.Sair()
+	esperar(t, func() bool { return len(hub.Salas()) == 0 })
+	rui, err := hub.Entrar("geral", "rui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(resumo(receber(t, rui, 1))); got != "[entrou rui]" {
+		t.Errorf("rui recebeu %s", got)
+	}
+	rui.Sair()
+}
+
+func TestHub_EntrarEnquantoEsvazia(t *testing.T) {
+	// Entradas e saídas simultâneas na mesma sala: ninguém pode ficar
+	// preso mandando eventos a uma sala que já encerrou
+	hub := NovoHub(Opcoes{})
+	defer hub.Fechar()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				m, err := hub.Entrar("geral", fmt.Sprintf("m%d", i))
+				if err != nil {
+					t.Errorf("Entrar: %v", err)
+					return
+				}
+				m.Falar("oi")
+				m.Sair()
+				m.Falar("já saí") // depois de sair: sem efeito, sem travar
+			}
+		}(i)
+	}
+	terminou := make(chan struct{})
+	go func() { wg.Wait(); close(terminou) }()
+	select {
+	case <-terminou:
+	case <-time.After(10 * time.Second):
+		t.Fatal("entradas e saídas travaram")
+	}
+	esperar(t, func() bool { return len(hub.Salas()) == 0 })
+}
+
+// esperar repete cond até ser verdadeira, falhando depois de 2s
+func esperar(t *testing.T, cond func() bool) {
+	t.Helper()
+	for limite := time.Now().Add(2 * time.Second); !cond(); {
+		if time.Now().After(limite) {
+			t.Fatal("condição não foi atingida a tempo")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
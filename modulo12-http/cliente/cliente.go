@@ -0,0 +1,330 @@
+package cliente
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+/*
+PACKAGE CLIENTE
+
+O README do módulo mostra http.Get e http.Post com os erros ignorados,
+sem prazo e sem nova tentativa. Na prática um serviço externo oscila:
+uma conexão cai, um 503 aparece durante um deploy, um 429 pede calma.
+Este package embrulha o http.Client com:
+
+    - prazo por tentativa, além do context de quem chama
+    - novas tentativas com espera exponencial e jitter, só em métodos
+      idempotentes (ou POST com Idempotency-Key), a não ser que a
+      conexão nem tenha sido aberta
+    - respeito ao Retry-After do servidor
+    - respostas fora de 2xx como erros.ErroHTTP (modulo07-erros/erros)
+    - auxiliares JSON (json.go)
+
+    c := cliente.Novo("http://localhost:8080", cliente.Opcoes{})
+    var aluno AlunoJSON
+    err := c.GetJSON(ctx, "/api/alunos/Ana", &aluno)
+
+    var e erros.ErroHTTP
+    if errors.As(err, &e) && e.StatusCode == 404 { ... }
+
+QUANDO TENTAR DE NOVO:
+    conexão recusada, cortada ou resetada sim (a requisição pode nem ter chegado)
+    prazo da tentativa esgotado           sim
+    408, 425, 429, 500, 502, 503, 504     sim (Retentavel)
+    outros 4xx e 5xx                      não: repetir daria o mesmo erro
+    TLS, certificado, URL malformada      não: não passam sozinhos
+    context de quem chama cancelado       não
+
+ESPERA:
+A espera base dobra a cada tentativa (EsperaInicial, 2x, 4x...) até
+EsperaMaxima, e o valor sorteado fica entre metade e o total da base
+("equal jitter"): clientes que falharam juntos não voltam juntos.
+Um Retry-After maior que a espera calculada tem precedência; maior que
+EsperaMaxima, o cliente desiste em vez de ficar parado.
+*/
+
+// Opcoes configura o Cliente; os campos zerados usam os padrões
+type Opcoes struct {
+	// HTTP faz as requisições (nil = um http.Client sem Timeout: o
+	// prazo vem de TimeoutTentativa e do context)
+	HTTP *http.Client
+	// Tentativas é o total de tentativas, a primeira inclusive (0 = 3)
+	Tentativas int
+	// TimeoutTentativa limita cada tentativa (0 = 10s)
+	TimeoutTentativa time.Duration
+	// EsperaInicial é a espera antes da segunda tentativa (0 = 200ms)
+	EsperaInicial time.Duration
+	// EsperaMaxima limita a espera entre tentativas (0 = 10s)
+	EsperaMaxima time.Duration
+	// Cabecalhos vão em todas as requisições (ex.: Authorization)
+	Cabecalhos http.Header
+	// AoRetentar é chamada antes de cada espera (ex.: para log)
+	AoRetentar func(tentativa int, espera time.Duration, err error)
+
+	// Sortear e Dormir substituem rand.Float64 e a espera real (para testes)
+	Sortear func() float64
+	Dormir  func(ctx context.Context, d time.Duration) error
+}
+
+// Cliente faz requisições com prazo e novas tentativas; seguro para
+// uso concorrente
+type Cliente struct {
+	base   string
+	opcoes Opcoes
+}
+
+// Novo cria um cliente para a URL base (ex.: "http://localhost:8080");
+// caminhos relativos dos métodos são somados a ela
+func Novo(base string, opcoes Opcoes) *Cliente {
+	if opcoes.HTTP == nil {
+		opcoes.HTTP = &http.Client{}
+	}
+	if opcoes.Tentativas <= 0 {
+		opcoes.Tentativas = 3
+	}
+	if opcoes.TimeoutTentativa <= 0 {
+		opcoes.TimeoutTentativa = 10 * time.Second
+	}
+	if opcoes.EsperaInicial <= 0 {
+		opcoes.EsperaInicial = 200 * time.Millisecond
+	}
+	if opcoes.EsperaMaxima <= 0 {
+		opcoes.EsperaMaxima = 10 * time.Second
+	}
+	if opcoes.Sortear == nil {
+		opcoes.Sortear = rand.Float64
+	}
+	if opcoes.Dormir == nil {
+		opcoes.Dormir = dormir
+	}
+	return &Cliente{base: strings.TrimSuffix(base, "/"), opcoes: opcoes}
+}
+
+// URL resolve caminho contra a base; URLs absolutas passam direto
+func (c *Cliente) URL(caminho string) string {
+	if strings.HasPrefix(caminho, "http://") || strings.HasPrefix(caminho, "https://") {
+		return caminho
+	}
+	if !strings.HasPrefix(caminho, "/") {
+		caminho = "/" + caminho
+	}
+	return c.base + caminho
+}
+
+// Fazer envia req, tentando de novo quando vale a pena, e retorna a
+// resposta 2xx; fora de 2xx o erro é um erros.ErroHTTP e o corpo já foi
+// fechado. Feche resp.Body: o prazo da tentativa vale até lá.
+//
+// Para repetir uma requisição com corpo, req.GetBody precisa existir
+// (http.NewRequest o preenche para bytes.Reader, bytes.Buffer e
+// strings.Reader); sem ele, só há uma tentativa.
+func (c *Cliente) Fazer(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	tentativas := c.opcoes.Tentativas
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		tentativas = 1
+	}
+
+	for tentativa := 1; ; tentativa++ {
+		resp, espera, err := c.tentar(ctx, req, tentativa)
+		if err == nil {
+			return resp, nil
+		}
+		if tentativa >= tentativas || ctx.Err() != nil || !Retentavel(err) {
+			return nil, err
+		}
+		if !Idempotente(req) && !naoEnviada(err) {
+			return nil, err
+		}
+
+		base := c.espera(tentativa)
+		if espera > c.opcoes.EsperaMaxima {
+			// O servidor pediu para voltar muito mais tarde
+			return nil, err
+		}
+		if espera < base {
+			espera = base
+		}
+		if c.opcoes.AoRetentar != nil {
+			c.opcoes.AoRetentar(tentativa, espera, err)
+		}
+		if errDormir := c.opcoes.Dormir(ctx, espera); errDormir != nil {
+			return nil, fmt.Errorf("%w (desistindo: %w)", err, errDormir)
+		}
+	}
+}
+
+// tentar faz uma tentativa; em caso de erro, retorna também o
+// Retry-After da resposta (0 se não houver)
+func (c *Cliente) tentar(ctx context.Context, original *http.Request, tentativa int) (*http.Response, time.Duration, error) {
+	ctxTentativa, cancelar := context.WithTimeout(ctx, c.opcoes.TimeoutTentativa)
+	req := original.Clone(ctxTentativa)
+	if tentativa > 1 && original.GetBody != nil {
+		corpo, err := original.GetBody()
+		if err != nil {
+			cancelar()
+			return nil, 0, err
+		}
+		req.Body = corpo
+	}
+	for nome, valores := range c.opcoes.Cabecalhos {
+		if req.Header.Get(nome) == "" {
+			req.Header[nome] = valores
+		}
+	}
+
+	resp, err := c.opcoes.HTTP.Do(req)
+	if err != nil {
+		cancelar()
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			err = &erroTentativa{err: err}
+		}
+		return nil, 0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer cancelar()
+		defer resp.Body.Close()
+		return nil, lerRetryAfter(resp.Header.Get("Retry-After"), time.Now()), erroResposta(req, resp)
+	}
+	resp.Body = &corpoCancelavel{ReadCloser: resp.Body, cancelar: cancelar}
+	return resp, 0, nil
+}
+
+// espera calcula a espera antes da tentativa seguinte à n-ésima
+func (c *Cliente) espera(n int) time.Duration {
+	base := c.opcoes.EsperaInicial
+	for i := 1; i < n && base < c.opcoes.EsperaMaxima; i++ {
+		base *= 2
+	}
+	if base > c.opcoes.EsperaMaxima {
+		base = c.opcoes.EsperaMaxima
+	}
+	return base/2 + time.Duration(c.opcoes.Sortear()*float64(base/2))
+}
+
+// Idempotente diz se repetir req não tem efeito extra: GET, HEAD,
+// OPTIONS, TRACE, PUT, DELETE ou qualquer método com Idempotency-Key
+func Idempotente(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// Retentavel diz se err é passageiro: falha de rede, prazo de uma
+// tentativa esgotado ou um status que costuma passar sozinho. Erros de
+// TLS, de URL ou de corpo que não pode ser reenviado não são.
+func Retentavel(err error) bool {
+	var e erros.ErroHTTP
+	if errors.As(err, &e) {
+		switch e.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+			http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var tentativa *erroTentativa
+	if errors.As(err, &tentativa) {
+		return true
+	}
+	// O context de quem chama acabou: não adianta insistir
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// Conexão fechada ou resetada no meio da troca
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	// *url.Error também é net.Error: só conta se for mesmo prazo
+	var rede net.Error
+	if errors.As(err, &rede) && rede.Timeout() {
+		return true
+	}
+	// Falha ao conectar, ler ou escrever no socket; os alertas de TLS
+	// ("remote error") também chegam como *net.OpError e ficam de fora
+	var op *net.OpError
+	if errors.As(err, &op) {
+		switch op.Op {
+		case "dial", "read", "write":
+			return true
+		}
+	}
+	return false
+}
+
+// naoEnviada diz se a conexão nem chegou a ser aberta: aí repetir é
+// seguro para qualquer método, POST inclusive
+func naoEnviada(err error) bool {
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
+
+// erroTentativa marca o prazo esgotado de uma tentativa (não o do
+// context de quem chama, que encerra tudo)
+type erroTentativa struct{ err error }
+
+func (e *erroTentativa) Error() string { return "prazo da tentativa esgotado: " + e.err.Error() }
+func (e *erroTentativa) Unwrap() error { return e.err }
+
+// erroResposta monta o ErroHTTP de uma resposta fora de 2xx; a
+// mensagem é o "detail" de um problem+json ou o início do corpo
+func erroResposta(req *http.Request, resp *http.Response) error {
+	corpo, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return erros.ErroHTTP{
+		StatusCode: resp.StatusCode,
+		Mensagem:   mensagemErro(resp.StatusCode, resp.Header.Get("Content-Type"), corpo),
+		URL:        req.URL.Redacted(),
+	}
+}
+
+// lerRetryAfter aceita os dois formatos da RFC 9110: segundos ou data
+func lerRetryAfter(valor string, agora time.Time) time.Duration {
+	if valor == "" {
+		return 0
+	}
+	if segundos, err := strconv.Atoi(valor); err == nil && segundos >= 0 {
+		return time.Duration(segundos) * time.Second
+	}
+	if data, err := http.ParseTime(valor); err == nil && data.After(agora) {
+		return data.Sub(agora)
+	}
+	return 0
+}
+
+func dormir(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// corpoCancelavel libera o context da tentativa quando o corpo é fechado
+type corpoCancelavel struct {
+	io.ReadCloser
+	cancelar context.CancelFunc
+}
+
+func (c *corpoCancelavel) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancelar()
+	return err
+}
@@ -0,0 +1,252 @@
+package cliente
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"go-course/modulo07-erros/erros"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// novoTeste cria um cliente sem espera real; esperas guarda as pedidas
+func novoTeste(t *testing.T, h http.HandlerFunc, opcoes Opcoes) (*Cliente, *[]time.Duration) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	var esperas []time.Duration
+	opcoes.Sortear = func() float64 { return 1 }
+	opcoes.Dormir = func(ctx context.Context, d time.Duration) error {
+		esperas = append(esperas, d)
+		return ctx.Err()
+	}
+	return Novo(srv.URL, opcoes), &esperas
+}
+
+// falhar responde status nas primeiras n requisições e 200 depois
+func falhar(n int32, status int, cabecalhos ...string) (http.HandlerFunc, *int32) {
+	var chamadas int32
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&chamadas, 1) <= n {
+			for i := 0; i+1 < len(cabecalhos); i += 2 {
+				w.Header().Set(cabecalhos[i], cabecalhos[i+1])
+			}
+			w.WriteHeader(status)
+			return
+		}
+		io.Copy(w, r.Body)
+	}, &chamadas
+}
+
+func TestFazer_Tentativas(t *testing.T) {
+	testes := []struct {
+		nome     string
+		metodo   string
+		chave    string // Idempotency-Key
+		status   int
+		falhas   int32
+		chamadas int32
+		ok       bool
+	}{
+		{"GET recupera de 503", "GET", "", 503, 2, 3, true},
+		{"GET desiste após 3", "GET", "", 502, 5, 3, false},
+		{"429 é passageiro", "GET", "", 429, 1, 2, true},
+		{"404 não se repete", "GET", "", 404, 1, 1, false},
+		{"400 não se repete", "PUT", "", 400, 1, 1, false},
+		{"POST não se repete", "POST", "", 503, 1, 1, false},
+		{"POST com Idempotency-Key", "POST", "abc-123", 503, 2, 3, true},
+		{"DELETE é idempotente", "DELETE", "", 500, 1, 2, true},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			h, chamadas := falhar(tt.falhas, tt.status)
+			c, _ := novoTeste(t, h, Opcoes{})
+			req, _ := http.NewRequest(tt.metodo, c.URL("/x"), strings.NewReader("corpo"))
+			if tt.chave != "" {
+				req.Header.Set("Idempotency-Key", tt.chave)
+			}
+			resp, err := c.Fazer(req)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v; esperado ok=%v", err, tt.ok)
+			}
+			if *chamadas != tt.chamadas {
+				t.Errorf("chamadas = %d; esperado %d", *chamadas, tt.chamadas)
+			}
+			if err != nil {
+				var e erros.ErroHTTP
+				if !errors.As(err, &e) || e.StatusCode != tt.status {
+					t.Errorf("erro = %#v; esperado ErroHTTP %d", err, tt.status)
+				}
+				return
+			}
+			// O corpo é reenviado inteiro a cada tentativa
+			corpo, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(corpo) != "corpo" {
+				t.Errorf("corpo = %q", corpo)
+			}
+		})
+	}
+}
+
+func TestFazer_Espera(t *testing.T) {
+	h, _ := falhar(4, 503)
+	c, esperas := novoTeste(t, h, Opcoes{Tentativas: 5, EsperaInicial: 100 * time.Millisecond, EsperaMaxima: 300 * time.Millisecond})
+	if err := c.GetJSON(context.Background(), "/x", nil); err != nil {
+		t.Fatal(err)
+	}
+	// Sortear = 1: o topo de cada faixa; dobra até EsperaMaxima
+	esperado := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	if len(*esperas) != len(esperado) {
+		t.Fatalf("esperas = %v; esperado %v", *esperas, esperado)
+	}
+	for i := range esperado {
+		if (*esperas)[i] != esperado[i] {
+			t.Errorf("espera %d = %v; esperado %v", i+1, (*esperas)[i], esperado[i])
+		}
+	}
+
+	// Jitter: Sortear = 0 dá metade da base
+	c.opcoes.Sortear = func() float64 { return 0 }
+	if d := c.espera(1); d != 50*time.Millisecond {
+		t.Errorf("espera com jitter mínimo = %v; esperado 50ms", d)
+	}
+}
+
+func TestFazer_RetryAfter(t *testing.T) {
+	h, chamadas := falhar(1, 429, "Retry-After", "2")
+	c, esperas := novoTeste(t, h, Opcoes{})
+	if err := c.GetJSON(context.Background(), "/x", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*esperas) != 1 || (*esperas)[0] != 2*time.Second {
+		t.Errorf("esperas = %v; esperado [2s]", *esperas)
+	}
+
+	// Retry-After acima de EsperaMaxima: desiste na hora
+	h, chamadas = falhar(1, 503, "Retry-After", "3600")
+	c, esperas = novoTeste(t, h, Opcoes{})
+	if err := c.GetJSON(context.Background(), "/x", nil); err == nil {
+		t.Error("esperado erro com Retry-After de 1 hora")
+	}
+	if *chamadas != 1 || len(*esperas) != 0 {
+		t.Errorf("chamadas = %d, esperas = %v; esperado 1 chamada e nenhuma espera", *chamadas, *esperas)
+	}
+}
+
+func TestFazer_ConexaoRecusada(t *testing.T) {
+	// Porta sem ninguém ouvindo: o POST não saiu, então pode repetir
+	srv := httptest.NewServer(http.NotFoundHandler())
+	endereco := srv.URL
+	srv.Close()
+
+	var tentativas []int
+	c := Novo(endereco, Opcoes{
+		Dormir:     func(ctx context.Context, d time.Duration) error { return nil },
+		AoRetentar: func(n int, _ time.Duration, _ error) { tentativas = append(tentativas, n) },
+	})
+	err := c.PostJSON(context.Background(), "/alunos", aluno{Nome: "Ana"}, nil)
+	if err == nil || len(tentativas) != 2 {
+		t.Errorf("err = %v, novas tentativas = %v; esperado erro após 3 tentativas", err, tentativas)
+	}
+}
+
+func TestLerRetryAfter(t *testing.T) {
+	agora := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	testes := []struct {
+		valor    string
+		esperado time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"Fri, 01 Mar 2024 12:00:30 GMT", 30 * time.Second},
+		{"Fri, 01 Mar 2024 11:00:00 GMT", 0}, // no passado
+		{"amanhã", 0},
+	}
+	for _, tt := range testes {
+		if d := lerRetryAfter(tt.valor, agora); d != tt.esperado {
+			t.Errorf("lerRetryAfter(%q) = %v; esperado %v", tt.valor, d, tt.esperado)
+		}
+	}
+}
+
+func TestFazer_Prazos(t *testing.T) {
+	var chamadas int32
+	lento := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&chamadas, 1) == 1 {
+			<-r.Context().Done() // a primeira tentativa trava
+			return
+		}
+		w.Write([]byte("ok"))
+	}
+
+	// Prazo da tentativa esgotado: tenta de novo
+	c, _ := novoTeste(t, lento, Opcoes{TimeoutTentativa: 50 * time.Millisecond})
+	if err := c.GetJSON(context.Background(), "/x", nil); err != nil {
+		t.Fatalf("err = %v; esperado sucesso na segunda tentativa", err)
+	}
+	if atomic.LoadInt32(&chamadas) != 2 {
+		t.Errorf("chamadas = %d; esperado 2", chamadas)
+	}
+
+	// Prazo de quem chama esgotado: não tenta de novo
+	atomic.StoreInt32(&chamadas, 0)
+	c, _ = novoTeste(t, lento, Opcoes{TimeoutTentativa: time.Minute})
+	ctx, cancelar := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelar()
+	err := c.GetJSON(ctx, "/x", nil)
+	if !errors.Is(err, context.DeadlineExceeded) || atomic.LoadInt32(&chamadas) != 1 {
+		t.Errorf("err = %v, chamadas = %d; esperado DeadlineExceeded e 1 chamada", err, chamadas)
+	}
+}
+
+func TestRetentavel(t *testing.T) {
+	testes := []struct {
+		nome     string
+		err      error
+		esperado bool
+	}{
+		{"503", erros.ErroHTTP{StatusCode: 503}, true},
+		{"429", erros.ErroHTTP{StatusCode: 429}, true},
+		{"501", erros.ErroHTTP{StatusCode: 501}, false},
+		{"404", erros.ErroHTTP{StatusCode: 404}, false},
+		{"conexão recusada", &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, true},
+		{"conexão resetada", &url.Error{Op: "Get", URL: "http://x", Err: syscall.ECONNRESET}, true},
+		{"resposta cortada", &url.Error{Op: "Get", URL: "http://x", Err: io.ErrUnexpectedEOF}, true},
+		{"certificado", &url.Error{Op: "Get", URL: "https://x", Err: x509.UnknownAuthorityError{}}, false},
+		{"alerta TLS", &url.Error{Op: "Get", URL: "https://x", Err: &net.OpError{Op: "remote error", Err: errors.New("tls: bad certificate")}}, false},
+		{"URL malformada", &url.Error{Op: "parse", URL: "http://x y", Err: errors.New("invalid character")}, false},
+		{"corpo não reenviável", errors.New("http: ContentLength=5 with Body length 0"), false},
+		{"prazo da tentativa", &erroTentativa{err: context.DeadlineExceeded}, true},
+		{"cancelado", context.Canceled, false},
+		{"prazo de quem chama", context.DeadlineExceeded, false},
+	}
+	for _, tt := range testes {
+		if got := Retentavel(tt.err); got != tt.esperado {
+			t.Errorf("%s: Retentavel = %v; esperado %v", tt.nome, got, tt.esperado)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	c := Novo("http://api.exemplo/v1/", Opcoes{})
+	testes := map[string]string{
+		"/alunos":             "http://api.exemplo/v1/alunos",
+		"alunos":              "http://api.exemplo/v1/alunos",
+		"https://outro/x?y=1": "https://outro/x?y=1",
+	}
+	for caminho, esperado := range testes {
+		if u := c.URL(caminho); u != esperado {
+			t.Errorf("URL(%q) = %q; esperado %q", caminho, u, esperado)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package cliente
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// JSON envia corpo (se não nil) codificado em JSON e decodifica a
+// resposta em destino (se não nil). Respostas fora de 2xx viram
+// erros.ErroHTTP.
+func (c *Cliente) JSON(ctx context.Context, metodo, caminho string, corpo, destino any) error {
+	var leitor io.Reader
+	if corpo != nil {
+		dados, err := json.Marshal(corpo)
+		if err != nil {
+			return fmt.Errorf("codificar corpo de %s %s: %w", metodo, caminho, err)
+		}
+		// bytes.Reader: http.NewRequest preenche GetBody, e o corpo
+		// pode ser reenviado numa nova tentativa
+		leitor = bytes.NewReader(dados)
+	}
+	req, err := http.NewRequestWithContext(ctx, metodo, c.URL(caminho), leitor)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if corpo != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Fazer(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if destino == nil || resp.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, resp.Body) // devolve a conexão ao pool
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(destino); err != nil {
+		return fmt.Errorf("decodificar resposta de %s %s: %w", metodo, req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// GetJSON faz GET e decodifica a resposta em destino
+func (c *Cliente) GetJSON(ctx context.Context, caminho string, destino any) error {
+	return c.JSON(ctx, http.MethodGet, caminho, nil, destino)
+}
+
+// PostJSON faz POST com corpo em JSON. Sem Idempotency-Key (veja
+// Opcoes.Cabecalhos), não há nova tentativa: o servidor pode ter
+// processado a primeira.
+func (c *Cliente) PostJSON(ctx context.Context, caminho string, corpo, destino any) error {
+	return c.JSON(ctx, http.MethodPost, caminho, corpo, destino)
+}
+
+// PutJSON faz PUT com corpo em JSON
+func (c *Cliente) PutJSON(ctx context.Context, caminho string, corpo, destino any) error {
+	return c.JSON(ctx, http.MethodPut, caminho, corpo, destino)
+}
+
+// Delete faz DELETE, descartando o corpo da resposta
+func (c *Cliente) Delete(ctx context.Context, caminho string) error {
+	return c.JSON(ctx, http.MethodDelete, caminho, nil, nil)
+}
+
+// mensagemErro resume o corpo de uma resposta de erro: o "detail" (ou
+// "title") de um application/problem+json, o "erro" de {"erro": ...}
+// ou o início do texto
+func mensagemErro(status int, contentType string, corpo []byte) string {
+	tipo, _, _ := mime.ParseMediaType(contentType)
+	if tipo == "application/problem+json" || tipo == "application/json" {
+		var p struct {
+			Titulo  string `json:"title"`
+			Detalhe string `json:"detail"`
+			Erro    string `json:"erro"`
+		}
+		if json.Unmarshal(corpo, &p) == nil {
+			for _, m := range []string{p.Detalhe, p.Erro, p.Titulo} {
+				if m != "" {
+					return m
+				}
+			}
+		}
+	}
+	texto := strings.TrimSpace(string(corpo))
+	if texto == "" {
+		return http.StatusText(status)
+	}
+	if len(texto) > 200 {
+		texto = strings.ToValidUTF8(texto[:200], "") + "..."
+	}
+	return texto
+}
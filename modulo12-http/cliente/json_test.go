@@ -0,0 +1,92 @@
+package cliente
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-course/modulo07-erros/erros"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type aluno struct {
+	Nome  string    `json:"nome"`
+	Notas []float64 `json:"notas"`
+}
+
+func TestJSON(t *testing.T) {
+	c, _ := novoTeste(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer x" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == "POST" && r.Header.Get("Content-Type") == "application/json":
+			var a aluno
+			json.NewDecoder(r.Body).Decode(&a)
+			a.Notas = append(a.Notas, 10)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(a)
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte("não é JSON"))
+		}
+	}, Opcoes{Cabecalhos: http.Header{"Authorization": {"Bearer x"}}})
+
+	ctx := context.Background()
+	var criado aluno
+	if err := c.PostJSON(ctx, "/alunos", aluno{Nome: "Ana", Notas: []float64{7}}, &criado); err != nil {
+		t.Fatal(err)
+	}
+	if criado.Nome != "Ana" || len(criado.Notas) != 2 {
+		t.Errorf("criado = %+v", criado)
+	}
+	if err := c.Delete(ctx, "/alunos/Ana"); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+	var a aluno
+	if err := c.GetJSON(ctx, "/alunos/Ana", &a); err == nil || !strings.Contains(err.Error(), "decodificar") {
+		t.Errorf("GetJSON em texto: err = %v", err)
+	}
+}
+
+func TestJSON_ErroHTTP(t *testing.T) {
+	testes := []struct {
+		nome        string
+		contentType string
+		corpo       string
+		mensagem    string
+	}{
+		{"problem+json", "application/problem+json", `{"title":"Not Found","status":404,"detail":"aluno não encontrado"}`, "aluno não encontrado"},
+		{"só título", "application/problem+json", `{"title":"Not Found","status":404}`, "Not Found"},
+		{"json com erro", "application/json; charset=utf-8", `{"erro":"rota não encontrada"}`, "rota não encontrada"},
+		{"texto", "text/plain", "  página sumiu\n", "página sumiu"},
+		{"vazio", "", "", "Not Found"},
+		{"longo", "text/html", strings.Repeat("x", 500), strings.Repeat("x", 200) + "..."},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			c, _ := novoTeste(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(tt.corpo))
+			}, Opcoes{})
+
+			err := c.GetJSON(context.Background(), "/alunos/Zé?token=segredo", nil)
+			var e erros.ErroHTTP
+			if !errors.As(err, &e) {
+				t.Fatalf("err = %v; esperado erros.ErroHTTP", err)
+			}
+			if e.StatusCode != 404 || e.Mensagem != tt.mensagem {
+				t.Errorf("= %d %q; esperado 404 %q", e.StatusCode, e.Mensagem, tt.mensagem)
+			}
+			if !strings.HasSuffix(e.URL, "/alunos/Z%C3%A9?token=segredo") {
+				t.Errorf("URL = %q", e.URL)
+			}
+		})
+	}
+}
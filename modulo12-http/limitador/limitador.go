@@ -0,0 +1,357 @@
+package limitador
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+/*
+PACKAGE LIMITADOR
+
+O exemploRateLimiting de modulo05-goroutines usa time.Tick: um único
+ritmo para todo mundo, sem rajada e com um ticker que nunca é parado.
+Aqui cada chave (IP, chave de API, rota...) tem o seu limite:
+
+    l := limitador.NovoBaldeFichas(limitador.Taxa{Requisicoes: 100, Janela: time.Minute, Rajada: 20}, limitador.Opcoes{})
+    d := l.Permitir("203.0.113.7")
+    if !d.Permitido {
+        // responder 429 e pedir para tentar de novo em d.TentarEm
+    }
+
+ALGORITMOS:
+
+    BaldeFichas (token bucket)
+        Um balde com Rajada fichas que se enche a Requisicoes/Janela.
+        Cada requisição gasta uma ficha. Permite rajadas curtas e
+        mantém a média. Estado: 2 números por chave.
+
+    JanelaDeslizante (sliding window log)
+        Guarda o instante de cada requisição aceita e conta as da
+        última Janela. Exato, mas o estado cresce com o limite
+        (Requisicoes instantes por chave).
+
+    GCRA (Generic Cell Rate Algorithm)
+        Equivalente ao balde de fichas, mas guarda um único instante
+        (o "TAT", quando o balde estaria cheio de novo). É o mais
+        barato: 1 número por chave, sem ponto flutuante.
+
+CONCORRÊNCIA:
+As chaves são divididas em fragmentos (shards), cada um com seu mutex:
+requisições de clientes diferentes raramente disputam o mesmo lock.
+Veja os benchmarks em limitador_bench_test.go.
+
+CHAVES OCIOSAS:
+Uma chave cujo estado voltou a ser igual ao de uma chave nova (balde
+cheio, janela vazia) é removida na próxima varredura do fragmento,
+então a memória acompanha os clientes ATIVOS, não todos que já vieram.
+*/
+
+// Taxa é o limite: Requisicoes por Janela, com rajadas de até Rajada
+type Taxa struct {
+	Requisicoes int
+	Janela      time.Duration
+	Rajada      int // 0 = Requisicoes (JanelaDeslizante ignora)
+}
+
+// String descreve a taxa: "100/1m0s (rajada 20)"
+func (t Taxa) String() string {
+	return fmt.Sprintf("%d/%v (rajada %d)", t.Requisicoes, t.Janela, t.rajada())
+}
+
+func (t Taxa) rajada() int {
+	if t.Rajada > 0 {
+		return t.Rajada
+	}
+	return t.Requisicoes
+}
+
+// intervalo é o tempo para repor uma requisição
+func (t Taxa) intervalo() time.Duration {
+	return t.Janela / time.Duration(t.Requisicoes)
+}
+
+func (t Taxa) validar() {
+	if t.Requisicoes <= 0 || t.Janela <= 0 || t.Rajada < 0 {
+		panic(fmt.Sprintf("limitador: taxa inválida %+v", t))
+	}
+	if t.intervalo() <= 0 {
+		panic(fmt.Sprintf("limitador: %d requisições em %v é rápido demais", t.Requisicoes, t.Janela))
+	}
+}
+
+// Decisao é a resposta para uma requisição
+type Decisao struct {
+	Permitido bool
+	Limite    int           // requisições permitidas de uma vez
+	Restantes int           // quantas ainda cabem agora
+	TentarEm  time.Duration // quando tentar de novo (0 se permitido)
+	Reinicio  time.Duration // até o limite estar todo disponível de novo
+}
+
+// Limitador decide, por chave, se uma requisição pode seguir
+type Limitador interface {
+	Permitir(chave string) Decisao
+	// Taxa retorna o limite configurado
+	Taxa() Taxa
+	// Chaves conta as chaves com estado em memória
+	Chaves() int
+}
+
+// Opcoes ajusta a estrutura interna; o valor zero serve
+type Opcoes struct {
+	// Fragmentos divide as chaves entre mutexes (0 = 4 por CPU)
+	Fragmentos int
+	// Agora substitui time.Now (para testes)
+	Agora func() time.Time
+}
+
+// ========================================
+// BALDE DE FICHAS
+// ========================================
+
+type balde struct {
+	fichas float64
+	ultimo time.Time
+}
+
+// NovoBaldeFichas cria um limitador token bucket
+func NovoBaldeFichas(taxa Taxa, opcoes Opcoes) Limitador {
+	taxa.validar()
+	capacidade := float64(taxa.rajada())
+	porSegundo := float64(taxa.Requisicoes) / taxa.Janela.Seconds()
+
+	encher := func(b *balde, agora time.Time) {
+		if decorrido := agora.Sub(b.ultimo).Seconds(); decorrido > 0 {
+			b.fichas = math.Min(capacidade, b.fichas+decorrido*porSegundo)
+		}
+		b.ultimo = agora
+	}
+	segundos := func(s float64) time.Duration {
+		return time.Duration(math.Ceil(s * float64(time.Second)))
+	}
+
+	return novaTabela(taxa, opcoes, func(b *balde, agora time.Time) {
+		*b = balde{fichas: capacidade, ultimo: agora}
+	}, func(b *balde, agora time.Time) Decisao {
+		encher(b, agora)
+		d := Decisao{Limite: int(capacidade)}
+		if b.fichas >= 1 {
+			b.fichas--
+			d.Permitido = true
+		} else {
+			d.TentarEm = segundos((1 - b.fichas) / porSegundo)
+		}
+		d.Restantes = int(b.fichas)
+		d.Reinicio = segundos((capacidade - b.fichas) / porSegundo)
+		return d
+	}, func(b *balde, agora time.Time) bool {
+		encher(b, agora)
+		return b.fichas >= capacidade
+	})
+}
+
+// ========================================
+// JANELA DESLIZANTE
+// ========================================
+
+// registro guarda os instantes aceitos, do mais antigo ao mais novo.
+// Os que saem da janela só avançam inicio; o slice é compactado quando
+// mais da metade dele já saiu, para não mover tudo a cada requisição.
+type registro struct {
+	instantes []time.Time
+	inicio    int // instantes[:inicio] já saíram da janela
+}
+
+// validos retorna os instantes dentro da janela
+func (r *registro) validos() []time.Time {
+	return r.instantes[r.inicio:]
+}
+
+// descartar remove os instantes que saíram da janela
+func (r *registro) descartar(agora time.Time, janela time.Duration) {
+	limite := agora.Add(-janela)
+	for r.inicio < len(r.instantes) && !r.instantes[r.inicio].After(limite) {
+		r.inicio++
+	}
+	if r.inicio > len(r.instantes)/2 {
+		n := copy(r.instantes, r.instantes[r.inicio:])
+		r.instantes = r.instantes[:n]
+		r.inicio = 0
+	}
+}
+
+// NovaJanelaDeslizante cria um limitador sliding window log
+// (Taxa.Rajada não se aplica: o limite é Requisicoes em qualquer Janela)
+func NovaJanelaDeslizante(taxa Taxa, opcoes Opcoes) Limitador {
+	taxa.validar()
+	taxa.Rajada = 0
+	limite := taxa.Requisicoes
+
+	return novaTabela(taxa, opcoes, func(r *registro, agora time.Time) {
+		*r = registro{instantes: make([]time.Time, 0, min(limite, 16))}
+	}, func(r *registro, agora time.Time) Decisao {
+		r.descartar(agora, taxa.Janela)
+		d := Decisao{Limite: limite}
+		if len(r.validos()) < limite {
+			r.instantes = append(r.instantes, agora)
+			d.Permitido = true
+		} else {
+			// Libera quando o mais antigo sair da janela
+			d.TentarEm = r.validos()[0].Add(taxa.Janela).Sub(agora)
+		}
+		validos := r.validos()
+		d.Restantes = limite - len(validos)
+		if n := len(validos); n > 0 {
+			d.Reinicio = validos[n-1].Add(taxa.Janela).Sub(agora)
+		}
+		return d
+	}, func(r *registro, agora time.Time) bool {
+		r.descartar(agora, taxa.Janela)
+		return len(r.validos()) == 0
+	})
+}
+
+// ========================================
+// GCRA
+// ========================================
+
+// gcra guarda o TAT (theoretical arrival time): o instante em que o
+// balde equivalente estaria cheio de novo
+type gcra struct {
+	tat time.Time
+}
+
+// NovoGCRA cria um limitador GCRA
+func NovoGCRA(taxa Taxa, opcoes Opcoes) Limitador {
+	taxa.validar()
+	intervalo := taxa.intervalo()
+	rajada := taxa.rajada()
+	tolerancia := intervalo * time.Duration(rajada)
+
+	return novaTabela(taxa, opcoes, func(g *gcra, agora time.Time) {
+		g.tat = agora
+	}, func(g *gcra, agora time.Time) Decisao {
+		tat := g.tat
+		if tat.Before(agora) {
+			tat = agora
+		}
+		novo := tat.Add(intervalo)
+		d := Decisao{Limite: rajada}
+		if excesso := novo.Sub(agora) - tolerancia; excesso > 0 {
+			d.TentarEm = excesso
+			novo = tat // recusada: não consome
+		} else {
+			d.Permitido = true
+			g.tat = novo
+		}
+		d.Restantes = int((tolerancia - novo.Sub(agora)) / intervalo)
+		if d.Restantes < 0 {
+			d.Restantes = 0
+		}
+		d.Reinicio = novo.Sub(agora)
+		return d
+	}, func(g *gcra, agora time.Time) bool {
+		return !g.tat.After(agora)
+	})
+}
+
+// ========================================
+// TABELA FRAGMENTADA DE ESTADOS
+// ========================================
+
+// tabela guarda o estado E de cada chave em fragmentos com mutex
+// próprio. O algoritmo entra com três funções: iniciar (chave nova),
+// decidir (uma requisição) e ocioso (estado igual ao de uma chave nova).
+type tabela[E any] struct {
+	taxa       Taxa
+	fragmentos []fragmento[E]
+	semente    maphash.Seed
+	agora      func() time.Time
+
+	iniciar func(*E, time.Time)
+	decidir func(*E, time.Time) Decisao
+	ocioso  func(*E, time.Time) bool
+}
+
+type fragmento[E any] struct {
+	mu        sync.Mutex
+	estados   map[string]*E
+	varredura time.Time
+	_         [64]byte // evita que dois fragmentos dividam a mesma linha de cache
+}
+
+func novaTabela[E any](taxa Taxa, opcoes Opcoes,
+	iniciar func(*E, time.Time), decidir func(*E, time.Time) Decisao, ocioso func(*E, time.Time) bool) *tabela[E] {
+
+	n := opcoes.Fragmentos
+	if n <= 0 {
+		n = 4 * runtime.GOMAXPROCS(0)
+	}
+	t := &tabela[E]{
+		taxa:       taxa,
+		fragmentos: make([]fragmento[E], n),
+		semente:    maphash.MakeSeed(),
+		agora:      opcoes.Agora,
+		iniciar:    iniciar,
+		decidir:    decidir,
+		ocioso:     ocioso,
+	}
+	if t.agora == nil {
+		t.agora = time.Now
+	}
+	for i := range t.fragmentos {
+		t.fragmentos[i].estados = make(map[string]*E)
+	}
+	return t
+}
+
+// Permitir implementa Limitador
+func (t *tabela[E]) Permitir(chave string) Decisao {
+	f := &t.fragmentos[maphash.String(t.semente, chave)%uint64(len(t.fragmentos))]
+	agora := t.agora()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.varrer(t, agora)
+	e, ok := f.estados[chave]
+	if !ok {
+		e = new(E)
+		t.iniciar(e, agora)
+		f.estados[chave] = e
+	}
+	return t.decidir(e, agora)
+}
+
+// varrer remove as chaves ociosas, no máximo uma vez por Janela
+func (f *fragmento[E]) varrer(t *tabela[E], agora time.Time) {
+	if agora.Sub(f.varredura) < t.taxa.Janela {
+		return
+	}
+	f.varredura = agora
+	for chave, e := range f.estados {
+		if t.ocioso(e, agora) {
+			delete(f.estados, chave)
+		}
+	}
+}
+
+// Taxa implementa Limitador
+func (t *tabela[E]) Taxa() Taxa {
+	return t.taxa
+}
+
+// Chaves implementa Limitador
+func (t *tabela[E]) Chaves() int {
+	total := 0
+	for i := range t.fragmentos {
+		f := &t.fragmentos[i]
+		f.mu.Lock()
+		total += len(f.estados)
+		f.mu.Unlock()
+	}
+	return total
+}
@@ -0,0 +1,96 @@
+package limitador
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+BENCHMARKS
+
+    go test -bench . -benchmem -cpu 1,4,8
+
+Cada algoritmo roda em dois cenários:
+- MuitasChaves: cada goroutine usa clientes diferentes (caso comum
+  de uma API pública): os fragmentos quase nunca disputam o mutex
+- UmaChave: todo mundo na mesma chave (pior caso)
+
+BenchmarkMutexGlobal é a referência: um único mapa com um único mutex.
+Numa máquina com vários núcleos, compare -cpu 1 com -cpu 8: o mutex
+global piora com mais goroutines, MuitasChaves se mantém.
+*/
+
+const taxaBench = 1_000_000 // alto o bastante para quase tudo passar
+
+func chavesBench(n int) []string {
+	chaves := make([]string, n)
+	for i := range chaves {
+		chaves[i] = "192.0.2." + strconv.Itoa(i)
+	}
+	return chaves
+}
+
+func benchmarkParalelo(b *testing.B, l Limitador, chaves []string) {
+	var proxima atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		// Cada goroutine começa em um ponto diferente da lista
+		i := int(proxima.Add(1)) * 7919
+		for pb.Next() {
+			l.Permitir(chaves[i%len(chaves)])
+			i++
+		}
+	})
+}
+
+func BenchmarkLimitador(b *testing.B) {
+	taxa := Taxa{Requisicoes: taxaBench, Janela: time.Second, Rajada: 100}
+	cenarios := []struct {
+		nome   string
+		chaves []string
+	}{
+		{"MuitasChaves", chavesBench(10_000)},
+		{"UmaChave", chavesBench(1)},
+	}
+	for _, alg := range algoritmos {
+		for _, c := range cenarios {
+			b.Run(alg.nome+"/"+c.nome, func(b *testing.B) {
+				benchmarkParalelo(b, alg.novo(taxa, Opcoes{}), c.chaves)
+			})
+		}
+	}
+}
+
+func BenchmarkFragmentos(b *testing.B) {
+	taxa := Taxa{Requisicoes: taxaBench, Janela: time.Second}
+	chaves := chavesBench(10_000)
+	for _, n := range []int{1, 8, 64} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			benchmarkParalelo(b, NovoGCRA(taxa, Opcoes{Fragmentos: n}), chaves)
+		})
+	}
+}
+
+// mutexGlobal é o limitador ingênuo: um mapa, um mutex
+type mutexGlobal struct {
+	mu     sync.Mutex
+	estado map[string]time.Time
+}
+
+func (m *mutexGlobal) Permitir(chave string) Decisao {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.estado[chave] = time.Now()
+	return Decisao{Permitido: true}
+}
+
+func (m *mutexGlobal) Taxa() Taxa  { return Taxa{} }
+func (m *mutexGlobal) Chaves() int { return len(m.estado) }
+
+func BenchmarkMutexGlobal(b *testing.B) {
+	benchmarkParalelo(b, &mutexGlobal{estado: make(map[string]time.Time)}, chavesBench(10_000))
+}
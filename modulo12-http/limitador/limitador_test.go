@@ -0,0 +1,241 @@
+package limitador
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// relogio é um time.Now controlado pelo teste
+type relogio struct {
+	mu    sync.Mutex
+	atual time.Time
+}
+
+func novoRelogio() *relogio {
+	return &relogio{atual: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+}
+
+func (r *relogio) Agora() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.atual
+}
+
+func (r *relogio) Avancar(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.atual = r.atual.Add(d)
+}
+
+type construtor func(Taxa, Opcoes) Limitador
+
+var algoritmos = []struct {
+	nome   string
+	novo   construtor
+	rajada bool // respeita Taxa.Rajada
+}{
+	{"BaldeFichas", NovoBaldeFichas, true},
+	{"JanelaDeslizante", NovaJanelaDeslizante, false},
+	{"GCRA", NovoGCRA, true},
+}
+
+// permitidas conta quantas de n requisições seguidas passam
+func permitidas(l Limitador, chave string, n int) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		if l.Permitir(chave).Permitido {
+			total++
+		}
+	}
+	return total
+}
+
+func TestLimitador_LimiteERecuperacao(t *testing.T) {
+	for _, alg := range algoritmos {
+		t.Run(alg.nome, func(t *testing.T) {
+			rel := novoRelogio()
+			// 10 por segundo: uma a cada 100ms
+			l := alg.novo(Taxa{Requisicoes: 10, Janela: time.Second}, Opcoes{Agora: rel.Agora})
+
+			if n := permitidas(l, "ana", 15); n != 10 {
+				t.Fatalf("rajada inicial: %d permitidas; esperado 10", n)
+			}
+			d := l.Permitir("ana")
+			if d.Permitido || d.Restantes != 0 || d.TentarEm <= 0 || d.TentarEm > time.Second {
+				t.Errorf("acima do limite: %+v", d)
+			}
+
+			// Outra chave tem o próprio limite
+			if !l.Permitir("bruno").Permitido {
+				t.Error("chave diferente não deve ser afetada")
+			}
+
+			// Depois de esperar TentarEm, passa de novo
+			rel.Avancar(d.TentarEm)
+			if !l.Permitir("ana").Permitido {
+				t.Errorf("depois de TentarEm (%v) deveria passar", d.TentarEm)
+			}
+
+			// Depois de uma janela inteira, o limite volta todo
+			rel.Avancar(time.Second)
+			if n := permitidas(l, "ana", 15); n != 10 {
+				t.Errorf("após uma janela: %d permitidas; esperado 10", n)
+			}
+		})
+	}
+}
+
+func TestLimitador_Rajada(t *testing.T) {
+	for _, alg := range algoritmos {
+		t.Run(alg.nome, func(t *testing.T) {
+			rel := novoRelogio()
+			l := alg.novo(Taxa{Requisicoes: 60, Janela: time.Minute, Rajada: 5}, Opcoes{Agora: rel.Agora})
+
+			esperado := 60
+			if alg.rajada {
+				esperado = 5
+			}
+			if n := permitidas(l, "k", 100); n != esperado {
+				t.Errorf("rajada: %d permitidas; esperado %d", n, esperado)
+			}
+			if d := l.Permitir("k"); d.Limite != esperado {
+				t.Errorf("Limite = %d; esperado %d", d.Limite, esperado)
+			}
+		})
+	}
+}
+
+func TestLimitador_TaxaMedia(t *testing.T) {
+	// Cliente que tenta 5 vezes por segundo durante 1 minuto com
+	// limite de 1/s: o total aceito deve ficar perto de 60 + rajada
+	for _, alg := range algoritmos {
+		t.Run(alg.nome, func(t *testing.T) {
+			rel := novoRelogio()
+			l := alg.novo(Taxa{Requisicoes: 1, Janela: time.Second, Rajada: 3}, Opcoes{Agora: rel.Agora})
+			total := 0
+			for i := 0; i < 300; i++ {
+				if l.Permitir("k").Permitido {
+					total++
+				}
+				rel.Avancar(200 * time.Millisecond)
+			}
+			maximo := 60 + l.Taxa().rajada()
+			if total < 59 || total > maximo {
+				t.Errorf("aceitas em 60s = %d; esperado entre 59 e %d", total, maximo)
+			}
+		})
+	}
+}
+
+func TestLimitador_Restantes(t *testing.T) {
+	for _, alg := range algoritmos {
+		t.Run(alg.nome, func(t *testing.T) {
+			rel := novoRelogio()
+			l := alg.novo(Taxa{Requisicoes: 3, Janela: time.Second}, Opcoes{Agora: rel.Agora})
+			for esperado := 2; esperado >= 0; esperado-- {
+				d := l.Permitir("k")
+				if !d.Permitido || d.Restantes != esperado || d.TentarEm != 0 {
+					t.Errorf("Restantes = %d, TentarEm = %v; esperado %d, 0", d.Restantes, d.TentarEm, esperado)
+				}
+				if d.Reinicio <= 0 || d.Reinicio > time.Second {
+					t.Errorf("Reinicio = %v", d.Reinicio)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitador_RemoveChavesOciosas(t *testing.T) {
+	for _, alg := range algoritmos {
+		t.Run(alg.nome, func(t *testing.T) {
+			rel := novoRelogio()
+			l := alg.novo(Taxa{Requisicoes: 5, Janela: time.Second}, Opcoes{Agora: rel.Agora, Fragmentos: 1})
+			for _, chave := range []string{"a", "b", "c"} {
+				l.Permitir(chave)
+			}
+			if l.Chaves() != 3 {
+				t.Fatalf("Chaves = %d; esperado 3", l.Chaves())
+			}
+
+			// Meio segundo depois: ninguém está ocioso ainda e a
+			// varredura só roda uma vez por janela
+			rel.Avancar(500 * time.Millisecond)
+			l.Permitir("a")
+			if l.Chaves() != 3 {
+				t.Errorf("Chaves = %d; esperado 3", l.Chaves())
+			}
+
+			// Duas janelas depois, "b" e "c" voltaram ao estado inicial
+			rel.Avancar(2 * time.Second)
+			l.Permitir("d")
+			if l.Chaves() != 1 {
+				t.Errorf("Chaves = %d; esperado 1 (só a chave nova)", l.Chaves())
+			}
+		})
+	}
+}
+
+func TestRegistro_Descartar(t *testing.T) {
+	// Uma requisição por segundo numa janela de 10 s: o slice fica
+	// limitado e os válidos são sempre os 10 últimos
+	var r registro
+	agora := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		r.descartar(agora, 10*time.Second)
+		r.instantes = append(r.instantes, agora)
+		if len(r.instantes) > 20 {
+			t.Fatalf("iteração %d: %d instantes guardados", i, len(r.instantes))
+		}
+		validos := r.validos()
+		if n := min(i+1, 10); len(validos) != n || !validos[n-1].Equal(agora) {
+			t.Fatalf("iteração %d: válidos = %v", i, validos)
+		}
+		agora = agora.Add(time.Second)
+	}
+}
+
+func TestLimitador_Concorrente(t *testing.T) {
+	for _, alg := range algoritmos {
+		t.Run(alg.nome, func(t *testing.T) {
+			rel := novoRelogio()
+			l := alg.novo(Taxa{Requisicoes: 100, Janela: time.Minute}, Opcoes{Agora: rel.Agora})
+
+			var mu sync.Mutex
+			total := 0
+			var wg sync.WaitGroup
+			for g := 0; g < 20; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					n := permitidas(l, "compartilhada", 50)
+					mu.Lock()
+					total += n
+					mu.Unlock()
+				}()
+			}
+			wg.Wait()
+			if total != 100 {
+				t.Errorf("permitidas = %d; esperado exatamente 100", total)
+			}
+		})
+	}
+}
+
+func TestTaxa_Invalida(t *testing.T) {
+	for _, taxa := range []Taxa{
+		{Requisicoes: 0, Janela: time.Second},
+		{Requisicoes: 10, Janela: 0},
+		{Requisicoes: 10, Janela: time.Second, Rajada: -1},
+		{Requisicoes: 10, Janela: 5}, // intervalo < 1ns
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Taxa %+v deveria causar panic", taxa)
+				}
+			}()
+			NovoGCRA(taxa, Opcoes{})
+		}()
+	}
+}
@@ -0,0 +1,158 @@
+package limitador
+
+import (
+	"fmt"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+MIDDLEWARE HTTP
+
+    api.Usar(limitador.Limitar(l, limitador.PorIP(0)))
+
+Toda resposta leva os cabeçalhos do rascunho IETF "RateLimit header
+fields for HTTP":
+
+    RateLimit-Limit: 20          requisições de uma vez
+    RateLimit-Remaining: 7       quantas ainda cabem
+    RateLimit-Reset: 12          segundos até o limite estar todo livre
+    RateLimit-Policy: 100;w=60   a política: 100 a cada 60 s
+
+Acima do limite: 429 Too Many Requests com Retry-After (segundos) e
+corpo application/problem+json (package problema).
+*/
+
+// FuncaoChave extrai a chave de limite da requisição.
+// Chave vazia = requisição não limitada.
+type FuncaoChave func(r *http.Request) string
+
+// PorIP usa o IP do cliente. proxies é quantos proxies confiáveis
+// estão na frente do servidor: 0 usa o endereço da conexão; com n > 0,
+// usa o n-ésimo endereço de X-Forwarded-For a contar da direita, o
+// último que um proxy confiável acrescentou. Os da esquerda vêm do
+// cliente, que os escolhe à vontade, e são ignorados.
+func PorIP(proxies int) FuncaoChave {
+	if proxies < 0 {
+		panic("limitador: PorIP com número de proxies negativo")
+	}
+	return func(r *http.Request) string {
+		if proxies > 0 {
+			// Vários cabeçalhos X-Forwarded-For equivalem a um só, juntos por vírgula
+			enderecos := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+			if i := len(enderecos) - proxies; i >= 0 {
+				if ip := net.ParseIP(strings.TrimSpace(enderecos[i])); ip != nil {
+					return ip.String()
+				}
+			}
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr // socket Unix: sem porta
+		}
+		return host
+	}
+}
+
+// PorCabecalho usa o valor de um cabeçalho, como X-API-Key.
+// Requisições sem o cabeçalho não são limitadas por esta chave.
+func PorCabecalho(nome string) FuncaoChave {
+	return func(r *http.Request) string {
+		return r.Header.Get(nome)
+	}
+}
+
+// PorRota usa método + padrão da rota ("GET /api/alunos/{nome}"),
+// para limitar um endpoint caro para todos os clientes juntos.
+// Fora do roteador, usa o caminho da URL.
+func PorRota() FuncaoChave {
+	return func(r *http.Request) string {
+		padrao := roteador.Padrao(r)
+		if padrao == "" {
+			padrao = r.URL.Path
+		}
+		return r.Method + " " + padrao
+	}
+}
+
+// Combinar junta chaves: Combinar(PorIP(0), PorRota()) limita cada
+// cliente em cada rota. Se alguma parte for vazia, a chave é vazia.
+func Combinar(funcoes ...FuncaoChave) FuncaoChave {
+	return func(r *http.Request) string {
+		partes := make([]string, len(funcoes))
+		for i, f := range funcoes {
+			if partes[i] = f(r); partes[i] == "" {
+				return ""
+			}
+		}
+		return strings.Join(partes, "|")
+	}
+}
+
+// Limitar aplica l a cada requisição, pela chave de FuncaoChave
+func Limitar(l Limitador, chave FuncaoChave) func(http.Handler) http.Handler {
+	taxa := l.Taxa()
+	politica := fmt.Sprintf("%d;w=%d", taxa.Requisicoes, int(math.Ceil(taxa.Janela.Seconds())))
+
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := chave(r)
+			if k == "" {
+				proximo.ServeHTTP(w, r)
+				return
+			}
+			d := l.Permitir(k)
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(d.Limite))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.Restantes))
+			h.Set("RateLimit-Reset", strconv.Itoa(segundosInteiros(d.Reinicio)))
+			h.Set("RateLimit-Policy", politica)
+			if d.Permitido {
+				proximo.ServeHTTP(w, r)
+				return
+			}
+
+			problemas.Responder(w, r, &erroLimite{espera: segundosInteiros(d.TentarEm)})
+		})
+	}
+}
+
+// erroLimite é a recusa de uma requisição acima do limite
+type erroLimite struct {
+	espera int // segundos até poder tentar de novo
+}
+
+func (e *erroLimite) Error() string {
+	return fmt.Sprintf("limite de requisições excedido; tente de novo em %d s", e.espera)
+}
+
+// problemas responde o 429 em problem+json, com Retry-After
+var problemas = novosProblemas()
+
+func novosProblemas() *problema.Registro {
+	reg := problema.Padrao()
+	problema.RegistrarTipo(reg, func(e *erroLimite) problema.Problema {
+		return problema.Problema{
+			Status:     http.StatusTooManyRequests,
+			Detalhe:    e.Error(),
+			Cabecalhos: http.Header{"Retry-After": {strconv.Itoa(e.espera)}},
+		}
+	})
+	return reg
+}
+
+// segundosInteiros arredonda para cima: "Retry-After: 0" faria o
+// cliente tentar de novo cedo demais
+func segundosInteiros(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,113 @@
+package limitador
+
+import (
+	"encoding/json"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimitar(t *testing.T) {
+	rel := novoRelogio()
+	l := NovoGCRA(Taxa{Requisicoes: 2, Janela: time.Minute}, Opcoes{Agora: rel.Agora})
+	h := Limitar(l, PorIP(0))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	requisitar := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/alunos", nil)
+		req.RemoteAddr = ip + ":51234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := requisitar("203.0.113.7")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("1ª requisição = %d", rec.Code)
+	}
+	cab := rec.Header()
+	if cab.Get("RateLimit-Limit") != "2" || cab.Get("RateLimit-Remaining") != "1" ||
+		cab.Get("RateLimit-Reset") != "30" || cab.Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("cabeçalhos = %v", cab)
+	}
+
+	requisitar("203.0.113.7")
+	rec = requisitar("203.0.113.7")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("3ª requisição = %d; esperado 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "30" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("cabeçalhos do 429 = %v", rec.Header())
+	}
+	var corpo problema.Problema
+	if err := json.Unmarshal(rec.Body.Bytes(), &corpo); err != nil || corpo.Status != http.StatusTooManyRequests || corpo.Detalhe == "" ||
+		rec.Header().Get("Content-Type") != problema.TipoContent {
+		t.Errorf("corpo = %q", rec.Body.String())
+	}
+
+	if rec := requisitar("198.51.100.1"); rec.Code != http.StatusOK {
+		t.Errorf("outro IP = %d; esperado 200", rec.Code)
+	}
+}
+
+func TestFuncoesChave(t *testing.T) {
+	var chaves []string
+	coletar := func(f FuncaoChave) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			chaves = append(chaves, f(req))
+		}
+	}
+
+	testes := []struct {
+		nome       string
+		f          FuncaoChave
+		remoto     string
+		cabecalhos map[string]string
+		esperado   string
+	}{
+		{"IP", PorIP(0), "192.0.2.1:4000", nil, "192.0.2.1"},
+		{"IPv6", PorIP(0), "[2001:db8::1]:4000", nil, "2001:db8::1"},
+		{"proxy ignorado", PorIP(0), "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "192.0.2.9"}, "10.0.0.1"},
+		{"proxy confiável", PorIP(1), "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "192.0.2.9"}, "192.0.2.9"},
+		{"cliente forja a esquerda", PorIP(1), "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "1.2.3.4, 192.0.2.9"}, "192.0.2.9"},
+		{"dois proxies", PorIP(2), "10.0.0.2:4000", map[string]string{"X-Forwarded-For": "1.2.3.4, 192.0.2.9, 10.0.0.1"}, "192.0.2.9"},
+		{"menos entradas que proxies", PorIP(2), "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "192.0.2.9"}, "10.0.0.1"},
+		{"proxy com lixo", PorIP(1), "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "não-é-ip"}, "10.0.0.1"},
+		{"chave de API", PorCabecalho("X-API-Key"), "192.0.2.1:4000", map[string]string{"X-API-Key": "abc"}, "abc"},
+		{"sem chave de API", PorCabecalho("X-API-Key"), "192.0.2.1:4000", nil, ""},
+		{"rota", PorRota(), "192.0.2.1:4000", nil, "GET /api/alunos/{nome}"},
+		{"IP + rota", Combinar(PorIP(0), PorRota()), "192.0.2.1:4000", nil, "192.0.2.1|GET /api/alunos/{nome}"},
+		{"parte vazia", Combinar(PorCabecalho("X-API-Key"), PorRota()), "192.0.2.1:4000", nil, ""},
+	}
+	for i, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			r := roteador.Novo()
+			r.Get("/api/alunos/{nome}", coletar(tt.f))
+			req := httptest.NewRequest("GET", "/api/alunos/Ana", nil)
+			req.RemoteAddr = tt.remoto
+			for k, v := range tt.cabecalhos {
+				req.Header.Set(k, v)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			if chaves[i] != tt.esperado {
+				t.Errorf("chave = %q; esperado %q", chaves[i], tt.esperado)
+			}
+		})
+	}
+}
+
+func TestLimitar_ChaveVazia(t *testing.T) {
+	l := NovoBaldeFichas(Taxa{Requisicoes: 1, Janela: time.Hour}, Opcoes{})
+	h := Limitar(l, PorCabecalho("X-API-Key"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("sem chave não há limite: %d %v", rec.Code, rec.Header())
+		}
+	}
+}
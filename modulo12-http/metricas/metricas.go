@@ -0,0 +1,450 @@
+package metricas
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+PACKAGE METRICAS
+
+Contadores, medidores e histogramas com rótulos, expostos no formato
+de texto do Prometheus (o que um servidor Prometheus lê de /metrics):
+
+    reg := metricas.NovoRegistro()
+    pedidos := reg.NovoContador("pedidos_total", "Pedidos recebidos", "canal")
+    pedidos.Com("site").Incrementar()
+
+    fila := reg.NovoMedidor("fila_tamanho", "Itens esperando")
+    fila.Definir(12)
+
+    duracao := reg.NovoHistograma("pedido_duracao_segundos", "Tempo de um pedido", metricas.LimitesPadrao)
+    inicio := time.Now()
+    ...
+    duracao.ObservarDesde(inicio)
+
+    mux.Handle("/metrics", reg.Handler())
+
+O QUE USAR:
+- Contador: só sobe (requisições, erros, jobs processados). No
+  Prometheus, rate(x_total[5m]) dá a taxa por segundo.
+- Medidor: sobe e desce (fila, conexões abertas, memória).
+  NovoMedidorFunc lê o valor na hora da coleta, ex.: len(canal).
+- Histograma: distribuição (latência, tamanho). Cada observação cai
+  nos "baldes" de limite superior; histogram_quantile() estima o p99.
+
+RÓTULOS:
+Cada combinação de valores é uma série separada, guardada para
+sempre: use rótulos com poucos valores possíveis (método, padrão da
+rota, status), nunca IDs, e-mails ou a URL crua.
+
+Nomes inválidos, rótulos em número errado ou métricas repetidas
+causam panic: são erros de programação, como rotas repetidas no
+roteador.
+
+FORMATO DE SAÍDA:
+
+    # HELP pedidos_total Pedidos recebidos
+    # TYPE pedidos_total counter
+    pedidos_total{canal="site"} 3
+    # TYPE pedido_duracao_segundos histogram
+    pedido_duracao_segundos_bucket{le="0.1"} 2
+    pedido_duracao_segundos_bucket{le="+Inf"} 3
+    pedido_duracao_segundos_sum 0.42
+    pedido_duracao_segundos_count 3
+*/
+
+// TipoContent é o Content-Type do formato de texto do Prometheus
+const TipoContent = "text/plain; version=0.0.4; charset=utf-8"
+
+// LimitesPadrao são os baldes de latência (segundos) do cliente oficial
+// do Prometheus: de 5 ms a 10 s
+var LimitesPadrao = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	nomeValido   = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	rotuloValido = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// LimitesLineares retorna n limites: inicio, inicio+largura, ...
+func LimitesLineares(inicio, largura float64, n int) []float64 {
+	limites := make([]float64, n)
+	for i := range limites {
+		limites[i] = inicio + float64(i)*largura
+	}
+	return limites
+}
+
+// LimitesExponenciais retorna n limites: inicio, inicio*fator, ...
+func LimitesExponenciais(inicio, fator float64, n int) []float64 {
+	if inicio <= 0 || fator <= 1 {
+		panic("metricas: limites exponenciais precisam de inicio > 0 e fator > 1")
+	}
+	limites := make([]float64, n)
+	for i := range limites {
+		limites[i] = inicio * math.Pow(fator, float64(i))
+	}
+	return limites
+}
+
+// Registro guarda as métricas de um processo; seguro para uso
+// concorrente
+type Registro struct {
+	mu       sync.RWMutex
+	familias map[string]*familia
+}
+
+// NovoRegistro cria um registro vazio
+func NovoRegistro() *Registro {
+	return &Registro{familias: make(map[string]*familia)}
+}
+
+// familia é uma métrica com todas as suas séries (uma por combinação
+// de valores dos rótulos)
+type familia struct {
+	nome    string
+	ajuda   string
+	tipo    string // counter, gauge, histogram
+	rotulos []string
+	limites []float64      // histogram
+	funcao  func() float64 // gauge lido na coleta
+
+	mu     sync.Mutex
+	series map[string]*serie // chave: valores dos rótulos
+}
+
+// serie é um valor (ou, no histograma, um conjunto de baldes)
+type serie struct {
+	valores []string
+	bits    atomic.Uint64 // float64 de contador e medidor
+
+	mu        sync.Mutex // histograma
+	contagens []uint64   // por balde, não acumuladas
+	soma      float64
+	total     uint64
+}
+
+func (r *Registro) registrar(f *familia) *familia {
+	if !nomeValido.MatchString(f.nome) {
+		panic(fmt.Sprintf("metricas: nome inválido %q", f.nome))
+	}
+	vistos := make(map[string]bool)
+	for _, rotulo := range f.rotulos {
+		if !rotuloValido.MatchString(rotulo) || strings.HasPrefix(rotulo, "__") || vistos[rotulo] ||
+			(f.tipo == "histogram" && rotulo == "le") {
+			panic(fmt.Sprintf("metricas: %s: rótulo inválido ou repetido %q", f.nome, rotulo))
+		}
+		vistos[rotulo] = true
+	}
+	f.series = make(map[string]*serie)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, existe := r.familias[f.nome]; existe {
+		panic(fmt.Sprintf("metricas: %s registrada duas vezes", f.nome))
+	}
+	r.familias[f.nome] = f
+	return f
+}
+
+// semRotulos cria já a série única de uma métrica sem rótulos (ela
+// aparece com 0 desde o início); com rótulos, retorna nil
+func (f *familia) semRotulos() *serie {
+	if len(f.rotulos) > 0 {
+		return nil
+	}
+	return f.serie(nil)
+}
+
+// serie retorna (criando) a série dos valores
+func (f *familia) serie(valores []string) *serie {
+	if len(valores) != len(f.rotulos) {
+		panic(fmt.Sprintf("metricas: %s tem %d rótulos %v, recebeu %d valores",
+			f.nome, len(f.rotulos), f.rotulos, len(valores)))
+	}
+	chave := strings.Join(valores, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.series[chave]
+	if s == nil {
+		s = &serie{valores: append([]string(nil), valores...)}
+		if f.tipo == "histogram" {
+			s.contagens = make([]uint64, len(f.limites))
+		}
+		f.series[chave] = s
+	}
+	return s
+}
+
+func (s *serie) valor() float64 { return math.Float64frombits(s.bits.Load()) }
+
+func (s *serie) somar(v float64) {
+	for {
+		antigo := s.bits.Load()
+		novo := math.Float64bits(math.Float64frombits(antigo) + v)
+		if s.bits.CompareAndSwap(antigo, novo) {
+			return
+		}
+	}
+}
+
+// ========================================
+// CONTADOR
+// ========================================
+
+// Contador só sobe. Sem rótulos, use-o direto; com rótulos, escolha
+// a série com Com.
+type Contador struct {
+	f *familia
+	s *serie
+}
+
+// NovoContador registra um contador; por convenção o nome termina em _total
+func (r *Registro) NovoContador(nome, ajuda string, rotulos ...string) *Contador {
+	f := r.registrar(&familia{nome: nome, ajuda: ajuda, tipo: "counter", rotulos: rotulos})
+	return &Contador{f: f, s: f.semRotulos()}
+}
+
+// Com retorna a série dos valores dos rótulos, na ordem do registro
+func (c *Contador) Com(valores ...string) *Contador {
+	return &Contador{f: c.f, s: c.f.serie(valores)}
+}
+
+// Incrementar soma 1
+func (c *Contador) Incrementar() { c.Somar(1) }
+
+// Somar soma v, que não pode ser negativo
+func (c *Contador) Somar(v float64) {
+	if v < 0 {
+		panic("metricas: contador não pode diminuir")
+	}
+	c.serie().somar(v)
+}
+
+// Valor retorna o valor atual da série
+func (c *Contador) Valor() float64 { return c.serie().valor() }
+
+func (c *Contador) serie() *serie {
+	if c.s != nil {
+		return c.s
+	}
+	return c.f.serie(nil)
+}
+
+// ========================================
+// MEDIDOR
+// ========================================
+
+// Medidor sobe e desce
+type Medidor struct {
+	f *familia
+	s *serie
+}
+
+// NovoMedidor registra um medidor
+func (r *Registro) NovoMedidor(nome, ajuda string, rotulos ...string) *Medidor {
+	f := r.registrar(&familia{nome: nome, ajuda: ajuda, tipo: "gauge", rotulos: rotulos})
+	return &Medidor{f: f, s: f.semRotulos()}
+}
+
+// NovoMedidorFunc registra um medidor cujo valor é lido de f a cada
+// coleta (f deve ser rápida e segura para uso concorrente)
+func (r *Registro) NovoMedidorFunc(nome, ajuda string, f func() float64) {
+	r.registrar(&familia{nome: nome, ajuda: ajuda, tipo: "gauge", funcao: f})
+}
+
+// Com retorna a série dos valores dos rótulos, na ordem do registro
+func (m *Medidor) Com(valores ...string) *Medidor {
+	return &Medidor{f: m.f, s: m.f.serie(valores)}
+}
+
+// Definir troca o valor
+func (m *Medidor) Definir(v float64) { m.serie().bits.Store(math.Float64bits(v)) }
+
+// Somar soma v (negativo para diminuir)
+func (m *Medidor) Somar(v float64) { m.serie().somar(v) }
+
+// Incrementar soma 1
+func (m *Medidor) Incrementar() { m.Somar(1) }
+
+// Decrementar subtrai 1
+func (m *Medidor) Decrementar() { m.Somar(-1) }
+
+// Valor retorna o valor atual da série
+func (m *Medidor) Valor() float64 { return m.serie().valor() }
+
+func (m *Medidor) serie() *serie {
+	if m.s != nil {
+		return m.s
+	}
+	return m.f.serie(nil)
+}
+
+// ========================================
+// HISTOGRAMA
+// ========================================
+
+// Histograma conta observações por faixa de valor
+type Histograma struct {
+	f *familia
+	s *serie
+}
+
+// NovoHistograma registra um histograma com os limites superiores dos
+// baldes, em ordem crescente (o balde +Inf é implícito)
+func (r *Registro) NovoHistograma(nome, ajuda string, limites []float64, rotulos ...string) *Histograma {
+	if len(limites) == 0 {
+		limites = LimitesPadrao
+	}
+	limites = append([]float64(nil), limites...)
+	if math.IsInf(limites[len(limites)-1], +1) {
+		limites = limites[:len(limites)-1]
+	}
+	for i := 1; i < len(limites); i++ {
+		if limites[i] <= limites[i-1] {
+			panic(fmt.Sprintf("metricas: %s: limites fora de ordem %v", nome, limites))
+		}
+	}
+	f := r.registrar(&familia{nome: nome, ajuda: ajuda, tipo: "histogram", rotulos: rotulos, limites: limites})
+	return &Histograma{f: f, s: f.semRotulos()}
+}
+
+// Com retorna a série dos valores dos rótulos, na ordem do registro
+func (h *Histograma) Com(valores ...string) *Histograma {
+	return &Histograma{f: h.f, s: h.f.serie(valores)}
+}
+
+// Observar registra um valor
+func (h *Histograma) Observar(v float64) {
+	s := h.s
+	if s == nil {
+		s = h.f.serie(nil)
+	}
+	// Primeiro balde com limite >= v; len(limites) = só no +Inf
+	i := sort.SearchFloat64s(h.f.limites, v)
+	s.mu.Lock()
+	if i < len(s.contagens) {
+		s.contagens[i]++
+	}
+	s.soma += v
+	s.total++
+	s.mu.Unlock()
+}
+
+// ObservarDesde registra os segundos passados desde inicio
+func (h *Histograma) ObservarDesde(inicio time.Time) {
+	h.Observar(time.Since(inicio).Seconds())
+}
+
+// ========================================
+// EXPOSIÇÃO
+// ========================================
+
+// Handler serve as métricas no formato de texto do Prometheus
+func (r *Registro) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", TipoContent)
+		w.Header().Set("Cache-Control", "no-store")
+		r.Escrever(w)
+	})
+}
+
+// Escrever grava todas as métricas, em ordem de nome
+func (r *Registro) Escrever(w io.Writer) error {
+	r.mu.RLock()
+	familias := make([]*familia, 0, len(r.familias))
+	for _, f := range r.familias {
+		familias = append(familias, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(familias, func(i, j int) bool { return familias[i].nome < familias[j].nome })
+
+	b := bufio.NewWriter(w)
+	for _, f := range familias {
+		f.escrever(b)
+	}
+	return b.Flush()
+}
+
+func (f *familia) escrever(b *bufio.Writer) {
+	if f.ajuda != "" {
+		fmt.Fprintf(b, "# HELP %s %s\n", f.nome, escaparAjuda(f.ajuda))
+	}
+	fmt.Fprintf(b, "# TYPE %s %s\n", f.nome, f.tipo)
+	if f.funcao != nil {
+		fmt.Fprintf(b, "%s %s\n", f.nome, formatar(f.funcao()))
+		return
+	}
+
+	f.mu.Lock()
+	series := make([]*serie, 0, len(f.series))
+	for _, s := range f.series {
+		series = append(series, s)
+	}
+	f.mu.Unlock()
+	sort.Slice(series, func(i, j int) bool {
+		return strings.Join(series[i].valores, "\xff") < strings.Join(series[j].valores, "\xff")
+	})
+
+	for _, s := range series {
+		if f.tipo != "histogram" {
+			fmt.Fprintf(b, "%s%s %s\n", f.nome, f.rotulosDe(s, ""), formatar(s.valor()))
+			continue
+		}
+		s.mu.Lock()
+		acumulado := uint64(0)
+		for i, limite := range f.limites {
+			acumulado += s.contagens[i]
+			fmt.Fprintf(b, "%s_bucket%s %d\n", f.nome, f.rotulosDe(s, formatar(limite)), acumulado)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", f.nome, f.rotulosDe(s, "+Inf"), s.total)
+		fmt.Fprintf(b, "%s_sum%s %s\n", f.nome, f.rotulosDe(s, ""), formatar(s.soma))
+		fmt.Fprintf(b, "%s_count%s %d\n", f.nome, f.rotulosDe(s, ""), s.total)
+		s.mu.Unlock()
+	}
+}
+
+// rotulosDe monta {a="1",b="2"}, com le no fim se informado
+func (f *familia) rotulosDe(s *serie, le string) string {
+	if len(f.rotulos) == 0 && le == "" {
+		return ""
+	}
+	pares := make([]string, 0, len(f.rotulos)+1)
+	for i, rotulo := range f.rotulos {
+		pares = append(pares, rotulo+`="`+escaparValor(s.valores[i])+`"`)
+	}
+	if le != "" {
+		pares = append(pares, `le="`+le+`"`)
+	}
+	return "{" + strings.Join(pares, ",") + "}"
+}
+
+func formatar(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	escapeAjuda = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	escapeValor = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escaparAjuda(s string) string { return escapeAjuda.Replace(s) }
+func escaparValor(s string) string { return escapeValor.Replace(s) }
@@ -0,0 +1,162 @@
+package metricas
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func saida(t *testing.T, reg *Registro) string {
+	t.Helper()
+	var b strings.Builder
+	if err := reg.Escrever(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestEscrever(t *testing.T) {
+	reg := NovoRegistro()
+	pedidos := reg.NovoContador("pedidos_total", "Pedidos recebidos", "canal")
+	pedidos.Com("site").Incrementar()
+	pedidos.Com("site").Somar(2)
+	pedidos.Com("app \"beta\"\n").Incrementar()
+	reg.NovoContador("erros_total", "Erros\nem duas linhas")
+
+	fila := reg.NovoMedidor("fila", "")
+	fila.Definir(5)
+	fila.Decrementar()
+	fila.Somar(0.5)
+	reg.NovoMedidorFunc("conexoes", "Conexões abertas", func() float64 { return 7 })
+
+	duracao := reg.NovoHistograma("duracao_segundos", "Duração", []float64{0.1, 1})
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		duracao.Observar(v)
+	}
+
+	esperado := `# HELP conexoes Conexões abertas
+# TYPE conexoes gauge
+conexoes 7
+# HELP duracao_segundos Duração
+# TYPE duracao_segundos histogram
+duracao_segundos_bucket{le="0.1"} 2
+duracao_segundos_bucket{le="1"} 3
+duracao_segundos_bucket{le="+Inf"} 4
+duracao_segundos_sum 3.65
+duracao_segundos_count 4
+# HELP erros_total Erros\nem duas linhas
+# TYPE erros_total counter
+erros_total 0
+# TYPE fila gauge
+fila 4.5
+# HELP pedidos_total Pedidos recebidos
+# TYPE pedidos_total counter
+pedidos_total{canal="app \"beta\"\n"} 1
+pedidos_total{canal="site"} 3
+`
+	if got := saida(t, reg); got != esperado {
+		t.Errorf("saída:\n%s\nesperado:\n%s", got, esperado)
+	}
+}
+
+func TestHistograma_Rotulos(t *testing.T) {
+	reg := NovoRegistro()
+	h := reg.NovoHistograma("tamanho_bytes", "", LimitesExponenciais(100, 10, 3), "rota")
+	h.Com("/a").Observar(50)
+	h.Com("/a").Observar(5000)
+	h.Com("/b").Observar(math.Inf(1))
+
+	got := saida(t, reg)
+	for _, linha := range []string{
+		`tamanho_bytes_bucket{rota="/a",le="100"} 1`,
+		`tamanho_bytes_bucket{rota="/a",le="1000"} 1`,
+		`tamanho_bytes_bucket{rota="/a",le="10000"} 2`,
+		`tamanho_bytes_bucket{rota="/a",le="+Inf"} 2`,
+		`tamanho_bytes_count{rota="/a"} 2`,
+		`tamanho_bytes_bucket{rota="/b",le="10000"} 0`,
+		`tamanho_bytes_bucket{rota="/b",le="+Inf"} 1`,
+		`tamanho_bytes_sum{rota="/b"} +Inf`,
+	} {
+		if !strings.Contains(got, linha+"\n") {
+			t.Errorf("falta %q em:\n%s", linha, got)
+		}
+	}
+}
+
+func TestLimites(t *testing.T) {
+	if got := LimitesLineares(1, 0.5, 3); got[0] != 1 || got[1] != 1.5 || got[2] != 2 {
+		t.Errorf("lineares = %v", got)
+	}
+	if got := LimitesExponenciais(1, 2, 4); got[3] != 8 {
+		t.Errorf("exponenciais = %v", got)
+	}
+}
+
+func TestPanics(t *testing.T) {
+	testes := []struct {
+		nome string
+		f    func(reg *Registro)
+	}{
+		{"nome inválido", func(reg *Registro) { reg.NovoContador("com-hifen", "") }},
+		{"rótulo inválido", func(reg *Registro) { reg.NovoContador("x", "", "1a") }},
+		{"rótulo repetido", func(reg *Registro) { reg.NovoContador("x", "", "a", "a") }},
+		{"le no histograma", func(reg *Registro) { reg.NovoHistograma("x", "", nil, "le") }},
+		{"repetida", func(reg *Registro) { reg.NovoMedidor("x", ""); reg.NovoContador("x", "") }},
+		{"valores a menos", func(reg *Registro) { reg.NovoContador("x", "", "a", "b").Com("1") }},
+		{"sem Com", func(reg *Registro) { reg.NovoContador("x", "", "a").Incrementar() }},
+		{"contador diminui", func(reg *Registro) { reg.NovoContador("x", "").Somar(-1) }},
+		{"limites fora de ordem", func(reg *Registro) { reg.NovoHistograma("x", "", []float64{1, 1}) }},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("esperado panic")
+				}
+			}()
+			tt.f(NovoRegistro())
+		})
+	}
+}
+
+func TestConcorrencia(t *testing.T) {
+	reg := NovoRegistro()
+	c := reg.NovoContador("c_total", "", "g")
+	m := reg.NovoMedidor("m", "")
+	h := reg.NovoHistograma("h", "", nil)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Com(string(rune('a' + g%2))).Incrementar()
+				m.Incrementar()
+				h.Observar(0.01)
+				if i%100 == 0 {
+					reg.Escrever(&strings.Builder{})
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if a, b := c.Com("a").Valor(), c.Com("b").Valor(); a+b != 8000 || m.Valor() != 8000 {
+		t.Errorf("a=%v b=%v m=%v", a, b, m.Valor())
+	}
+	if got := saida(t, reg); !strings.Contains(got, "h_count 8000\n") {
+		t.Errorf("histograma:\n%s", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := NovoRegistro()
+	reg.NovoContador("x_total", "").Incrementar()
+	w := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Header().Get("Content-Type") != TipoContent || !strings.Contains(w.Body.String(), "x_total 1\n") {
+		t.Errorf("%q\n%s", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
@@ -0,0 +1,141 @@
+package metricas
+
+import (
+	"bufio"
+	"go-course/modulo12-http/roteador"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+MIDDLEWARE HTTP
+
+    reg := metricas.NovoRegistro()
+    api.Usar(metricas.HTTP(reg, metricas.OpcoesHTTP{}))
+    r.Get("/metrics", reg.Handler().ServeHTTP)
+
+Dentro de um grupo do roteador, a rota é o padrão ("/api/alunos/{nome}"),
+não a URL: um aluno a mais não cria séries novas. Por fora do
+roteador (middleware.Encadear), informe OpcoesHTTP.Rota, por exemplo
+com Roteador.PadraoDe.
+
+Séries (com Prefixo "http"):
+
+    http_requisicoes_total{metodo,rota,status}   contador
+    http_duracao_segundos{metodo,rota}           histograma
+    http_em_andamento{metodo,rota}               medidor
+
+HTTP registra as métricas: chame uma vez por registro e reutilize o
+middleware em quantos grupos quiser.
+*/
+
+// RotaDesconhecida é o rótulo das requisições que não casaram com
+// nenhuma rota (404): a URL crua criaria uma série por URL inventada
+const RotaDesconhecida = "desconhecida"
+
+// OpcoesHTTP configura o middleware; os campos zerados usam os padrões
+type OpcoesHTTP struct {
+	Prefixo string                       // início dos nomes (vazio = "http")
+	Limites []float64                    // baldes de latência em segundos (nil = LimitesPadrao)
+	Rota    func(r *http.Request) string // padrão da rota (nil = roteador.Padrao)
+}
+
+// HTTP conta requisições, mede a latência e acompanha as requisições
+// em andamento, por método e rota
+func HTTP(reg *Registro, opcoes OpcoesHTTP) func(http.Handler) http.Handler {
+	if opcoes.Prefixo == "" {
+		opcoes.Prefixo = "http"
+	}
+	if opcoes.Rota == nil {
+		opcoes.Rota = roteador.Padrao
+	}
+	requisicoes := reg.NovoContador(opcoes.Prefixo+"_requisicoes_total",
+		"Requisições atendidas, por método, rota e status", "metodo", "rota", "status")
+	duracao := reg.NovoHistograma(opcoes.Prefixo+"_duracao_segundos",
+		"Tempo até o fim da resposta, por método e rota", opcoes.Limites, "metodo", "rota")
+	andamento := reg.NovoMedidor(opcoes.Prefixo+"_em_andamento",
+		"Requisições sendo atendidas agora, por método e rota", "metodo", "rota")
+
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inicio := time.Now()
+			metodo := metodoConhecido(r.Method)
+			rota := opcoes.Rota(r)
+			if rota == "" {
+				rota = RotaDesconhecida
+			}
+
+			emAndamento := andamento.Com(metodo, rota)
+			emAndamento.Incrementar()
+			rw := &escritorStatus{ResponseWriter: w}
+			defer func() {
+				emAndamento.Decrementar()
+				status := rw.status
+				if p := recover(); p != nil {
+					// Conta e deixa o panic seguir (o Recuperar de fora responde 500)
+					if status == 0 {
+						status = http.StatusInternalServerError
+					}
+					defer panic(p)
+				} else if status == 0 {
+					status = http.StatusOK
+				}
+				requisicoes.Com(metodo, rota, strconv.Itoa(status)).Incrementar()
+				duracao.Com(metodo, rota).ObservarDesde(inicio)
+			}()
+			proximo.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// metodoConhecido limita o rótulo aos métodos padrão: o cliente pode
+// mandar qualquer texto como método
+func metodoConhecido(metodo string) string {
+	switch metodo {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return metodo
+	}
+	return "OUTRO"
+}
+
+// escritorStatus guarda o status da resposta; Flush, Hijack e Unwrap
+// passam adiante (SSE e WebSocket continuam funcionando)
+type escritorStatus struct {
+	http.ResponseWriter
+	status int
+}
+
+func (e *escritorStatus) WriteHeader(status int) {
+	// 1xx informativos (ex.: 103 Early Hints) não são o status final
+	if e.status == 0 && (status >= 200 || status == http.StatusSwitchingProtocols) {
+		e.status = status
+	}
+	e.ResponseWriter.WriteHeader(status)
+}
+
+func (e *escritorStatus) Write(p []byte) (int, error) {
+	if e.status == 0 {
+		e.status = http.StatusOK
+	}
+	return e.ResponseWriter.Write(p)
+}
+
+func (e *escritorStatus) Flush() {
+	if e.status == 0 {
+		e.status = http.StatusOK
+	}
+	http.NewResponseController(e.ResponseWriter).Flush()
+}
+
+func (e *escritorStatus) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(e.ResponseWriter).Hijack()
+	if err == nil && e.status == 0 {
+		e.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (e *escritorStatus) Unwrap() http.ResponseWriter { return e.ResponseWriter }
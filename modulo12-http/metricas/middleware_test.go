@@ -0,0 +1,111 @@
+package metricas
+
+import (
+	"go-course/modulo12-http/roteador"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTP_NoGrupo(t *testing.T) {
+	reg := NovoRegistro()
+	r := roteador.Novo()
+	api := r.Grupo("/api", HTTP(reg, OpcoesHTTP{Limites: []float64{1}}))
+
+	dentro := make(chan float64, 2)
+	api.Get("/alunos/{nome}", func(w http.ResponseWriter, req *http.Request) {
+		if roteador.Parametro(req, "nome") == "Bia" {
+			http.Error(w, "não existe", http.StatusNotFound)
+			return
+		}
+		dentro <- reg.familias["http_em_andamento"].serie([]string{"GET", "/api/alunos/{nome}"}).valor()
+		w.Write([]byte("ok"))
+	})
+	api.Post("/alunos", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/api/alunos/Ana", nil),
+		httptest.NewRequest("GET", "/api/alunos/Rui", nil),
+		httptest.NewRequest("GET", "/api/alunos/Bia", nil),
+		httptest.NewRequest("POST", "/api/alunos", nil),
+		httptest.NewRequest("PROPFIND", "/api/alunos", nil), // 405: nem chega no grupo
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if v := <-dentro; v != 1 {
+		t.Errorf("em andamento durante a requisição = %v", v)
+	}
+
+	got := saida(t, reg)
+	for _, linha := range []string{
+		`http_requisicoes_total{metodo="GET",rota="/api/alunos/{nome}",status="200"} 2`,
+		`http_requisicoes_total{metodo="GET",rota="/api/alunos/{nome}",status="404"} 1`,
+		`http_requisicoes_total{metodo="POST",rota="/api/alunos",status="201"} 1`,
+		`http_duracao_segundos_count{metodo="GET",rota="/api/alunos/{nome}"} 3`,
+		`http_duracao_segundos_bucket{metodo="GET",rota="/api/alunos/{nome}",le="1"} 3`,
+		`http_em_andamento{metodo="GET",rota="/api/alunos/{nome}"} 0`,
+	} {
+		if !strings.Contains(got, linha+"\n") {
+			t.Errorf("falta %q em:\n%s", linha, got)
+		}
+	}
+	if strings.Contains(got, "PROPFIND") || strings.Contains(got, "Ana") {
+		t.Errorf("série com valor do cliente:\n%s", got)
+	}
+}
+
+func TestHTTP_PorFora(t *testing.T) {
+	reg := NovoRegistro()
+	r := roteador.Novo()
+	r.Get("/api/alunos/{nome}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/panico", func(w http.ResponseWriter, req *http.Request) { panic("falhou") })
+	h := HTTP(reg, OpcoesHTTP{Prefixo: "api", Rota: r.PadraoDe})(r)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/api/alunos/Ana", nil),
+		httptest.NewRequest("GET", "/nao/existe", nil),
+		httptest.NewRequest("BREW", "/api/alunos/Ana", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("o panic deveria seguir adiante")
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panico", nil))
+	}()
+
+	got := saida(t, reg)
+	for _, linha := range []string{
+		`api_requisicoes_total{metodo="GET",rota="/api/alunos/{nome}",status="200"} 1`,
+		`api_requisicoes_total{metodo="GET",rota="desconhecida",status="404"} 1`,
+		`api_requisicoes_total{metodo="OUTRO",rota="/api/alunos/{nome}",status="405"} 1`,
+		`api_requisicoes_total{metodo="GET",rota="/panico",status="500"} 1`,
+		`api_em_andamento{metodo="GET",rota="/panico"} 0`,
+	} {
+		if !strings.Contains(got, linha+"\n") {
+			t.Errorf("falta %q em:\n%s", linha, got)
+		}
+	}
+}
+
+func TestEscritorStatus_Flush(t *testing.T) {
+	// O SSE (package sse) precisa do Flush através do middleware
+	reg := NovoRegistro()
+	h := HTTP(reg, OpcoesHTTP{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data: oi\n\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/eventos", nil))
+	if !w.Flushed {
+		t.Error("Flush não chegou ao ResponseWriter")
+	}
+}
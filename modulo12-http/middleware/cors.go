@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+CORS (Cross-Origin Resource Sharing)
+
+Um navegador em https://app.escola.br só lê respostas de
+https://api.escola.br se a API autorizar a origem. Para requisições
+"não simples" (PUT, DELETE, JSON, cabeçalhos próprios) ele antes
+envia um preflight:
+
+    OPTIONS /api/alunos/Ana
+    Origin: https://app.escola.br
+    Access-Control-Request-Method: PUT
+    Access-Control-Request-Headers: content-type, if-match
+
+e só segue se a resposta listar a origem, o método e os cabeçalhos.
+*/
+
+// OpcoesCORS configura o middleware CORS
+type OpcoesCORS struct {
+	// Origens autorizadas, ex.: "https://app.escola.br". "*" libera
+	// qualquer origem, mas só sem Credenciais
+	Origens []string
+	// Metodos aceitos no preflight (vazio = GET, HEAD, POST, PUT, PATCH, DELETE)
+	Metodos []string
+	// Cabecalhos que o cliente pode enviar (vazio = Content-Type, Authorization, If-Match, If-None-Match, X-Request-ID)
+	Cabecalhos []string
+	// Expostos são cabeçalhos da resposta legíveis pelo JavaScript (ex.: ETag, Location)
+	Expostos []string
+	// Credenciais libera cookies e Authorization do navegador; exige
+	// a lista explícita de Origens
+	Credenciais bool
+	// MaxIdade é quanto o navegador pode guardar o preflight (0 = não informar)
+	MaxIdade time.Duration
+}
+
+var (
+	metodosCORSPadrao = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost,
+		http.MethodPut, http.MethodPatch, http.MethodDelete,
+	}
+	cabecalhosCORSPadrao = []string{"Content-Type", "Authorization", "If-Match", "If-None-Match", CabecalhoID}
+)
+
+// CORS responde preflights e acrescenta os cabeçalhos Access-Control-*
+// nas respostas a origens autorizadas. Origens não autorizadas recebem
+// a resposta normal, sem os cabeçalhos: quem bloqueia é o navegador.
+//
+// Origens "*" com Credenciais é erro de configuração (panic): liberaria
+// qualquer site a fazer requisições com o cookie do usuário.
+func CORS(opcoes OpcoesCORS) func(http.Handler) http.Handler {
+	todas := false
+	origens := make(map[string]bool, len(opcoes.Origens))
+	for _, o := range opcoes.Origens {
+		if o == "*" {
+			todas = true
+		}
+		origens[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+	}
+	if todas && opcoes.Credenciais {
+		panic(`middleware: CORS com Origens "*" e Credenciais; liste as origens autorizadas`)
+	}
+
+	metodos := opcoes.Metodos
+	if len(metodos) == 0 {
+		metodos = metodosCORSPadrao
+	}
+	permitidos := make(map[string]bool, len(metodos))
+	for _, m := range metodos {
+		permitidos[strings.ToUpper(m)] = true
+	}
+	cabecalhos := opcoes.Cabecalhos
+	if len(cabecalhos) == 0 {
+		cabecalhos = cabecalhosCORSPadrao
+	}
+	cabecalhosPermitidos := make(map[string]bool, len(cabecalhos))
+	for _, c := range cabecalhos {
+		cabecalhosPermitidos[http.CanonicalHeaderKey(c)] = true
+	}
+
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origem := r.Header.Get("Origin")
+			h := w.Header()
+			// A resposta depende da origem: caches não podem misturá-las
+			h.Add("Vary", "Origin")
+			if origem == "" || (!todas && !origens[strings.ToLower(origem)]) {
+				proximo.ServeHTTP(w, r)
+				return
+			}
+
+			if todas {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origem)
+			}
+			if opcoes.Credenciais {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			metodoPedido := r.Header.Get("Access-Control-Request-Method")
+			if r.Method != http.MethodOptions || metodoPedido == "" {
+				// Requisição comum (não é preflight)
+				if len(opcoes.Expostos) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(opcoes.Expostos, ", "))
+				}
+				proximo.ServeHTTP(w, r)
+				return
+			}
+
+			// Preflight: respondido aqui, sem chegar ao handler
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if !permitidos[strings.ToUpper(metodoPedido)] || !cabecalhosAceitos(r.Header.Get("Access-Control-Request-Headers"), cabecalhosPermitidos) {
+				// Sem Allow-Methods/Allow-Headers o navegador bloqueia
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set("Access-Control-Allow-Methods", strings.Join(metodos, ", "))
+			h.Set("Access-Control-Allow-Headers", strings.Join(cabecalhos, ", "))
+			if opcoes.MaxIdade > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(opcoes.MaxIdade.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// cabecalhosAceitos confere a lista "content-type, if-match" do preflight
+func cabecalhosAceitos(pedidos string, permitidos map[string]bool) bool {
+	for _, c := range strings.Split(pedidos, ",") {
+		c = strings.TrimSpace(c)
+		if c != "" && !permitidos[http.CanonicalHeaderKey(c)] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	chegou := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chegou = true
+		w.Write([]byte("ok"))
+	})
+	restrito := CORS(OpcoesCORS{
+		Origens:  []string{"https://app.escola.br"},
+		Expostos: []string{"ETag", "Location"},
+		MaxIdade: 10 * time.Minute,
+	})(handler)
+	aberto := CORS(OpcoesCORS{Origens: []string{"*"}})(handler)
+
+	testes := []struct {
+		nome         string
+		h            http.Handler
+		metodo       string
+		origem       string
+		pedido       string // Access-Control-Request-Method
+		cabecalhos   string // Access-Control-Request-Headers
+		allowOrigin  string
+		allowMethods bool
+		chegaHandler bool
+	}{
+		{"sem Origin", restrito, "GET", "", "", "", "", false, true},
+		{"origem autorizada", restrito, "GET", "https://app.escola.br", "", "", "https://app.escola.br", false, true},
+		{"origem em maiúsculas", restrito, "GET", "https://APP.escola.br", "", "", "https://APP.escola.br", false, true},
+		{"origem estranha", restrito, "GET", "https://malicioso.com", "", "", "", false, true},
+		{"preflight válido", restrito, "OPTIONS", "https://app.escola.br", "PUT", "Content-Type, If-Match", "https://app.escola.br", true, false},
+		{"preflight método recusado", restrito, "OPTIONS", "https://app.escola.br", "TRACE", "", "https://app.escola.br", false, false},
+		{"preflight cabeçalho recusado", restrito, "OPTIONS", "https://app.escola.br", "PUT", "X-Secreto", "https://app.escola.br", false, false},
+		{"OPTIONS comum", restrito, "OPTIONS", "https://app.escola.br", "", "", "https://app.escola.br", false, true},
+		{"curinga", aberto, "GET", "https://qualquer.com", "", "", "*", false, true},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			chegou = false
+			req := httptest.NewRequest(tt.metodo, "/api/alunos", nil)
+			if tt.origem != "" {
+				req.Header.Set("Origin", tt.origem)
+			}
+			if tt.pedido != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.pedido)
+			}
+			if tt.cabecalhos != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.cabecalhos)
+			}
+			rec := httptest.NewRecorder()
+			tt.h.ServeHTTP(rec, req)
+
+			h := rec.Header()
+			if got := h.Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Allow-Origin = %q; esperado %q", got, tt.allowOrigin)
+			}
+			if got := h.Get("Access-Control-Allow-Methods") != ""; got != tt.allowMethods {
+				t.Errorf("Allow-Methods presente = %v; esperado %v", got, tt.allowMethods)
+			}
+			if chegou != tt.chegaHandler {
+				t.Errorf("handler chamado = %v; esperado %v", chegou, tt.chegaHandler)
+			}
+			if h.Values("Vary")[0] != "Origin" {
+				t.Errorf("Vary = %v", h.Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestCORS_CabecalhosExtras(t *testing.T) {
+	h := CORS(OpcoesCORS{
+		Origens:     []string{"https://app.escola.br"},
+		Expostos:    []string{"ETag"},
+		Credenciais: true,
+		MaxIdade:    10 * time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("OPTIONS", "/", nil)
+	req.Header.Set("Origin", "https://app.escola.br")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight = %d; esperado 204", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Max-Age") != "600" || rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("cabeçalhos = %v", rec.Header())
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://app.escola.br")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Expose-Headers") != "ETag" {
+		t.Errorf("Expose-Headers = %q", rec.Header().Get("Access-Control-Expose-Headers"))
+	}
+}
+
+func TestCORS_CuringaComCredenciais(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error(`Origens "*" com Credenciais deveria causar panic`)
+		}
+	}()
+	CORS(OpcoesCORS{Origens: []string{"https://app.escola.br", "*"}, Credenciais: true})
+}
@@ -0,0 +1,267 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+COMPRESSÃO GZIP
+
+O cliente anuncia o que aceita em Accept-Encoding:
+
+    Accept-Encoding: gzip, deflate, br
+    Accept-Encoding: gzip;q=0, identity   ← recusa gzip explicitamente
+
+Se gzip for aceito, a resposta sai compactada com
+Content-Encoding: gzip. Respostas pequenas (abaixo de TamanhoMinimoGzip)
+não compensam o custo e saem como estão; formatos já compactados
+(imagens, vídeo, zip) também. Vary: Accept-Encoding avisa os caches
+de que a mesma URL tem duas versões. Um ETag forte vira fraco na
+versão compactada (veja modulo12-http/cache).
+
+Os gzip.Writer vêm de um sync.Pool: criar um aloca ~800 KB.
+*/
+
+// TamanhoMinimoGzip é o menor corpo que vale a pena compactar
+const TamanhoMinimoGzip = 1024
+
+// Gzip compacta as respostas para clientes que aceitam gzip.
+// nivel vai de gzip.BestSpeed a gzip.BestCompression (ou gzip.DefaultCompression).
+func Gzip(nivel int) func(http.Handler) http.Handler {
+	if _, err := gzip.NewWriterLevel(nil, nivel); err != nil {
+		panic(fmt.Sprintf("middleware: nível de gzip inválido: %d", nivel))
+	}
+	pool := &sync.Pool{New: func() any {
+		gz, _ := gzip.NewWriterLevel(nil, nivel)
+		return gz
+	}}
+
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || !aceitaGzip(r.Header.Get("Accept-Encoding")) {
+				proximo.ServeHTTP(w, r)
+				return
+			}
+			gw := &escritorGzip{ResponseWriter: w, pool: pool}
+			defer func() {
+				if v := recover(); v != nil {
+					// Nada enviado ainda? Descarta o buffer para que o
+					// Recuperar (mais externo) ainda possa responder 500
+					if !gw.decidido {
+						gw.buffer = nil
+					} else {
+						gw.fechar()
+					}
+					panic(v)
+				}
+				gw.fechar()
+			}()
+			proximo.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// aceitaGzip interpreta Accept-Encoding com pesos (q=0 recusa)
+func aceitaGzip(cabecalho string) bool {
+	aceita := false
+	for _, item := range strings.Split(cabecalho, ",") {
+		codificacao, parametros, _ := strings.Cut(strings.TrimSpace(item), ";")
+		codificacao = strings.ToLower(strings.TrimSpace(codificacao))
+		if codificacao != "gzip" && codificacao != "*" {
+			continue
+		}
+		peso := 1.0
+		if nome, valor, ok := strings.Cut(strings.TrimSpace(parametros), "="); ok && strings.TrimSpace(nome) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(valor), 64); err == nil {
+				peso = q
+			}
+		}
+		if codificacao == "gzip" {
+			return peso > 0 // a menção explícita vale mais que "*"
+		}
+		aceita = peso > 0
+	}
+	return aceita
+}
+
+// escritorGzip acumula os primeiros bytes para decidir se compacta:
+// até TamanhoMinimoGzip nada é enviado; depois a decisão é definitiva
+type escritorGzip struct {
+	http.ResponseWriter
+	pool *sync.Pool
+
+	status   int
+	buffer   []byte
+	gz       *gzip.Writer
+	decidido bool  // cabeçalho já enviado (compactando ou não)
+	err      error // primeira falha de escrita: a resposta não continua
+}
+
+func (g *escritorGzip) WriteHeader(status int) {
+	if status < 200 { // 1xx passa direto
+		g.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if g.status == 0 {
+		g.status = status
+	}
+}
+
+func (g *escritorGzip) Write(p []byte) (int, error) {
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+	if g.decidido {
+		var n int
+		if g.gz != nil {
+			n, g.err = g.gz.Write(p)
+		} else {
+			n, g.err = g.ResponseWriter.Write(p)
+		}
+		return n, g.err
+	}
+
+	if !g.compactavel() {
+		if err := g.decidir(false); err != nil {
+			return 0, err
+		}
+		var n int
+		n, g.err = g.ResponseWriter.Write(p)
+		return n, g.err
+	}
+	g.buffer = append(g.buffer, p...)
+	if len(g.buffer) >= TamanhoMinimoGzip {
+		if err := g.decidir(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// compactavel olha o status e os cabeçalhos definidos pelo handler
+func (g *escritorGzip) compactavel() bool {
+	h := g.Header()
+	if g.status == http.StatusNoContent || g.status == http.StatusNotModified || g.status == http.StatusPartialContent {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	tipo := h.Get("Content-Type")
+	for _, prefixo := range []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "font/woff"} {
+		if strings.HasPrefix(tipo, prefixo) && !strings.HasPrefix(tipo, "image/svg") {
+			return false
+		}
+	}
+	return true
+}
+
+// decidir envia o cabeçalho e o que estiver no buffer; a falha fica
+// em g.err
+func (g *escritorGzip) decidir(compactar bool) error {
+	g.decidido = true
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+	h := g.Header()
+	if compactar {
+		if h.Get("Content-Type") == "" {
+			// Sem isso o net/http detectaria o tipo pelos bytes compactados
+			h.Set("Content-Type", http.DetectContentType(g.buffer))
+		}
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		// Os bytes mudaram: um ETag forte ("abc") passa a fraco (W/"abc"),
+		// que ainda casa com If-None-Match
+		if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			h.Set("ETag", "W/"+etag)
+		}
+		g.gz = g.pool.Get().(*gzip.Writer)
+		g.gz.Reset(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+
+	var err error
+	if len(g.buffer) > 0 {
+		if g.gz != nil {
+			_, err = g.gz.Write(g.buffer)
+		} else {
+			_, err = g.ResponseWriter.Write(g.buffer)
+		}
+	}
+	g.buffer = nil
+	g.err = err
+	return err
+}
+
+// fechar termina a resposta: corpo pequeno sai sem compactar. Depois
+// de uma falha de escrita (cliente desconectado) o rodapé do gzip não
+// é enviado, mas o gzip.Writer volta ao pool do mesmo jeito.
+func (g *escritorGzip) fechar() {
+	if !g.decidido {
+		if g.status == 0 && len(g.buffer) == 0 {
+			return // handler não escreveu nada: o net/http responde 200 vazio
+		}
+		g.decidir(false)
+	}
+	if g.gz != nil {
+		if g.err == nil {
+			g.err = g.gz.Close()
+		}
+		g.gz.Reset(nil)
+		g.pool.Put(g.gz)
+		g.gz = nil
+	}
+}
+
+// Flush envia o que houver, compactando se ainda não houve decisão
+// (quem dá Flush quer os bytes já, ex.: Server-Sent Events)
+func (g *escritorGzip) Flush() {
+	g.FlushError()
+}
+
+// FlushError é o Flush com o erro, usado por http.ResponseController:
+// depois de uma falha de escrita nada mais é enviado
+func (g *escritorGzip) FlushError() error {
+	if !g.decidido {
+		if g.status == 0 {
+			g.status = http.StatusOK
+		}
+		g.decidir(g.compactavel())
+	}
+	if g.err != nil {
+		return g.err
+	}
+	if g.gz != nil {
+		if g.err = g.gz.Flush(); g.err != nil {
+			return g.err
+		}
+	}
+	return http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// Hijack permite WebSocket através do middleware (nada foi escrito ainda)
+func (g *escritorGzip) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := g.ResponseWriter.(http.Hijacker); ok && !g.decidido {
+		g.decidido = true
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("middleware: Hijack indisponível na resposta compactada")
+}
+
+// Unwrap permite que http.ResponseController chegue ao writer original
+func (g *escritorGzip) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
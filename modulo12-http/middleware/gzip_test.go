@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAceitaGzip(t *testing.T) {
+	testes := []struct {
+		cabecalho string
+		esperado  bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"gzip, deflate, br", true},
+		{"deflate, GZIP;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip;q=0, *", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"br, identity", false},
+	}
+	for _, tt := range testes {
+		if got := aceitaGzip(tt.cabecalho); got != tt.esperado {
+			t.Errorf("aceitaGzip(%q) = %v; esperado %v", tt.cabecalho, got, tt.esperado)
+		}
+	}
+}
+
+func TestGzip(t *testing.T) {
+	grande := strings.Repeat("nota 10 ", 500) // 4000 bytes
+	servir := func(corpo, tipo string, status int) http.Handler {
+		return Gzip(gzip.DefaultCompression)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tipo != "" {
+				w.Header().Set("Content-Type", tipo)
+			}
+			if status != 0 {
+				w.WriteHeader(status)
+			}
+			// Em pedaços, como um json.Encoder faria
+			for i := 0; i < len(corpo); i += 300 {
+				io.WriteString(w, corpo[i:min(i+300, len(corpo))])
+			}
+		}))
+	}
+
+	testes := []struct {
+		nome       string
+		h          http.Handler
+		aceita     string
+		compactado bool
+		status     int
+	}{
+		{"grande com gzip", servir(grande, "text/plain", 0), "gzip", true, 200},
+		{"status preservado", servir(grande, "application/json", http.StatusCreated), "gzip", true, 201},
+		{"cliente sem gzip", servir(grande, "text/plain", 0), "", false, 200},
+		{"gzip recusado", servir(grande, "text/plain", 0), "gzip;q=0", false, 200},
+		{"pequeno", servir("ok", "text/plain", 0), "gzip", false, 200},
+		{"imagem", servir(grande, "image/png", 0), "gzip", false, 200},
+		{"svg é texto", servir(grande, "image/svg+xml", 0), "gzip", true, 200},
+		{"sem conteúdo", servir("", "", http.StatusNoContent), "gzip", false, 204},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.aceita != "" {
+				req.Header.Set("Accept-Encoding", tt.aceita)
+			}
+			rec := httptest.NewRecorder()
+			tt.h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d; esperado %d", rec.Code, tt.status)
+			}
+			if rec.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary = %q", rec.Header().Get("Vary"))
+			}
+			compactado := rec.Header().Get("Content-Encoding") == "gzip"
+			if compactado != tt.compactado {
+				t.Fatalf("compactado = %v; esperado %v", compactado, tt.compactado)
+			}
+			if !compactado {
+				return
+			}
+			gr, err := gzip.NewReader(rec.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			corpo, err := io.ReadAll(gr)
+			if err != nil || string(corpo) != grande {
+				t.Errorf("corpo descompactado difere (%d bytes, erro %v)", len(corpo), err)
+			}
+		})
+	}
+}
+
+func TestGzip_ETagFraco(t *testing.T) {
+	grande := strings.Repeat("nota 10 ", 500)
+	testes := []struct {
+		nome, aceita, etag, esperado string
+	}{
+		{"compactado enfraquece", "gzip", `"abc"`, `W/"abc"`},
+		{"fraco continua fraco", "gzip", `W/"abc"`, `W/"abc"`},
+		{"sem gzip fica forte", "", `"abc"`, `"abc"`},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			h := Gzip(gzip.BestSpeed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", tt.etag)
+				io.WriteString(w, grande)
+			}))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", tt.aceita)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if got := rec.Header().Get("ETag"); got != tt.esperado {
+				t.Errorf("ETag = %q; esperado %q", got, tt.esperado)
+			}
+		})
+	}
+}
+
+func TestGzip_ComRecuperar(t *testing.T) {
+	h := Encadear(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("início da resposta"))
+		panic("falha")
+	}), Recuperar(log.New(&bytes.Buffer{}, "", 0)), Gzip(gzip.BestSpeed))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	// Os bytes ainda estavam no buffer do gzip: dá para responder 500
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "início") {
+		t.Errorf("resposta = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// escritorQuebrado simula um cliente que desconectou: toda escrita falha
+type escritorQuebrado struct {
+	httptest.ResponseRecorder
+	escritas, flushes int
+}
+
+func (e *escritorQuebrado) Write(p []byte) (int, error) {
+	e.escritas++
+	return 0, errors.New("conexão fechada")
+}
+
+func (e *escritorQuebrado) Flush() { e.flushes++ }
+
+func TestGzip_FalhaDeEscrita(t *testing.T) {
+	var erros []error
+	h := Gzip(gzip.BestSpeed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, err := w.Write(bytes.Repeat([]byte("a"), 2*TamanhoMinimoGzip))
+		erros = append(erros, err)
+		erros = append(erros, http.NewResponseController(w).Flush())
+		_, err = w.Write([]byte("mais"))
+		erros = append(erros, err)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := &escritorQuebrado{ResponseRecorder: *httptest.NewRecorder()}
+	h.ServeHTTP(w, req)
+
+	// O gzip guarda os bytes até o Flush: a falha aparece nele e, daí em
+	// diante, em toda escrita; o rodapé do gzip não é tentado
+	if erros[1] == nil || erros[2] == nil {
+		t.Errorf("erros = %v", erros)
+	}
+	if w.flushes != 0 || w.escritas != 1 {
+		t.Errorf("depois da falha: %d escritas, %d flushes", w.escritas, w.flushes)
+	}
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+/*
+PACKAGE MIDDLEWARE
+
+Um middleware é uma função func(http.Handler) http.Handler: recebe o
+próximo handler e devolve outro que faz algo antes e/ou depois dele.
+O mesmo tipo de roteador.Middleware, então servem nos dois lugares:
+
+    h := middleware.Encadear(api,
+        middleware.IDRequisicao(),          // 1º: todos os outros já veem o ID
+        middleware.RegistrarAcesso(logger), // mede inclusive o tempo do recover
+        middleware.Recuperar(logger),       // panic → 500 em JSON
+        middleware.CORS(middleware.OpcoesCORS{Origens: []string{"https://app.escola.br"}}),
+        middleware.Gzip(gzip.DefaultCompression),
+    )
+
+A ordem importa: o primeiro da lista é o mais externo (vê a requisição
+primeiro e a resposta por último).
+
+Recuperar segue o padrão de operacaoSegura em
+modulo07-erros/03_panic_recover.go: defer + recover() isolam a falha
+de uma requisição, e o servidor continua atendendo as outras.
+*/
+
+// Encadear aplica os middlewares a h; o primeiro é o mais externo
+func Encadear(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// ========================================
+// RECOVERY
+// ========================================
+
+// Recuperar transforma um panic no handler em 500 com corpo JSON
+// {"erro": "erro interno", "request_id": "..."}. O valor do panic e a
+// pilha vão só para o log, nunca para o cliente.
+func Recuperar(logger *log.Logger) func(http.Handler) http.Handler {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := envolver(w)
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// Sinal do próprio net/http para abortar a resposta: repassar
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				id := ID(r.Context())
+				logf(logger, "panic recuperado [%s] %s %s: %v\n%s", id, r.Method, r.URL.Path, v, debug.Stack())
+
+				if rw.status != 0 {
+					// Cabeçalho já enviado: não dá para trocar o status
+					return
+				}
+				rw.Header().Del("Content-Encoding")
+				rw.Header().Del("Content-Length")
+				rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+				rw.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(rw).Encode(struct {
+					Erro string `json:"erro"`
+					ID   string `json:"request_id,omitempty"`
+				}{"erro interno", id})
+			}()
+			proximo.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// ========================================
+// LOG DE ACESSO
+// ========================================
+
+// RegistrarAcesso escreve uma linha por requisição:
+//
+//	GET /api/alunos 200 512B 1.2ms id=4f1c...
+func RegistrarAcesso(logger *log.Logger) func(http.Handler) http.Handler {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inicio := time.Now()
+			rw := envolver(w)
+			defer func() {
+				status := rw.status
+				v := recover()
+				switch {
+				case v != nil:
+					// panic sem Recuperar mais interno: o net/http vai fechar a conexão
+					status = http.StatusInternalServerError
+				case status == 0:
+					// Sem escrita nenhuma o net/http responde 200
+					status = http.StatusOK
+				}
+				logf(logger, "%s %s %d %dB %v id=%s",
+					r.Method, r.URL.RequestURI(), status, rw.bytes, time.Since(inicio).Round(time.Microsecond), ID(r.Context()))
+				if v != nil {
+					panic(v)
+				}
+			}()
+			proximo.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// ========================================
+// X-REQUEST-ID
+// ========================================
+
+// CabecalhoID é o cabeçalho usado para o ID da requisição
+const CabecalhoID = "X-Request-ID"
+
+type chaveID struct{}
+
+// IDRequisicao garante um ID por requisição: reaproveita o X-Request-ID
+// recebido (de um proxy, por exemplo) se for razoável, senão gera um.
+// O ID volta no cabeçalho da resposta e fica no contexto (veja ID).
+func IDRequisicao() func(http.Handler) http.Handler {
+	return func(proximo http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(CabecalhoID)
+			if !idValido(id) {
+				id = novoID()
+			}
+			w.Header().Set(CabecalhoID, id)
+			proximo.ServeHTTP(w, r.WithContext(ComID(r.Context(), id)))
+		})
+	}
+}
+
+// ComID retorna um contexto com o ID da requisição
+func ComID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, chaveID{}, id)
+}
+
+// ID retorna o ID da requisição guardado no contexto ("" se não houver)
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(chaveID{}).(string)
+	return id
+}
+
+// idValido aceita até 128 caracteres ASCII visíveis: o ID vai para
+// logs e cabeçalhos, então nada de quebras de linha
+func idValido(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func novoID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// Sem aleatoriedade ainda dá para correlacionar pelo instante
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// ========================================
+// RESPONSE WRITER QUE ANOTA STATUS E BYTES
+// ========================================
+
+// registrador guarda o status e os bytes escritos pelo handler
+type registrador struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// envolver reaproveita o registrador se w já for um (evita camadas
+// repetidas quando Recuperar e RegistrarAcesso estão na mesma cadeia)
+func envolver(w http.ResponseWriter) *registrador {
+	if rw, ok := w.(*registrador); ok {
+		return rw
+	}
+	return &registrador{ResponseWriter: w}
+}
+
+func (rw *registrador) WriteHeader(status int) {
+	if rw.status == 0 && status >= 200 { // 1xx (ex.: 103 Early Hints) não é a resposta final
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *registrador) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush mantém o streaming (ex.: Server-Sent Events) funcionando
+func (rw *registrador) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack permite WebSocket através do middleware
+func (rw *registrador) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := rw.ResponseWriter.(http.Hijacker); ok {
+		rw.status = http.StatusSwitchingProtocols
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("middleware: ResponseWriter não suporta Hijack")
+}
+
+// Unwrap permite que http.ResponseController chegue ao writer original
+func (rw *registrador) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func logf(logger *log.Logger, formato string, v ...any) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf(formato, v...)
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEncadear_Ordem(t *testing.T) {
+	var ordem []string
+	marcar := func(nome string) func(http.Handler) http.Handler {
+		return func(proximo http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ordem = append(ordem, nome+">")
+				proximo.ServeHTTP(w, r)
+				ordem = append(ordem, "<"+nome)
+			})
+		}
+	}
+	h := Encadear(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ordem = append(ordem, "handler")
+	}), marcar("a"), marcar("b"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if got := strings.Join(ordem, " "); got != "a> b> handler <b <a" {
+		t.Errorf("ordem = %q", got)
+	}
+}
+
+func TestRecuperar(t *testing.T) {
+	var saida bytes.Buffer
+	logger := log.New(&saida, "", 0)
+
+	h := Encadear(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		panic("mapa nil")
+	}), IDRequisicao(), Recuperar(logger))
+
+	req := httptest.NewRequest("GET", "/api/mensagem", nil)
+	req.Header.Set(CabecalhoID, "abc-123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d; esperado 500", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var corpo map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &corpo); err != nil {
+		t.Fatalf("corpo não é JSON: %q", rec.Body.String())
+	}
+	if corpo["erro"] != "erro interno" || corpo["request_id"] != "abc-123" {
+		t.Errorf("corpo = %v", corpo)
+	}
+	if strings.Contains(rec.Body.String(), "mapa nil") {
+		t.Error("o valor do panic não deve ir para o cliente")
+	}
+	if !strings.Contains(saida.String(), "mapa nil") || !strings.Contains(saida.String(), "abc-123") {
+		t.Errorf("log sem o panic ou o ID: %q", saida.String())
+	}
+}
+
+func TestRecuperar_CabecalhoJaEnviado(t *testing.T) {
+	h := Recuperar(log.New(&bytes.Buffer{}, "", 0))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("parcial"))
+		panic("no meio")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "parcial" {
+		t.Errorf("resposta = %d %q; o status já enviado não muda", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRegistrarAcesso(t *testing.T) {
+	testes := []struct {
+		nome     string
+		handler  http.HandlerFunc
+		esperado string
+	}{
+		{"200 implícito", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("olá")) }, "GET /x?a=1 200 4B"},
+		{"sem corpo", func(w http.ResponseWriter, r *http.Request) {}, "GET /x?a=1 200 0B"},
+		{"404", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) }, "GET /x?a=1 404 19B"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			var saida bytes.Buffer
+			h := RegistrarAcesso(log.New(&saida, "", 0))(tt.handler)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/x?a=1", nil))
+			if !strings.HasPrefix(saida.String(), tt.esperado) {
+				t.Errorf("log = %q; esperado prefixo %q", saida.String(), tt.esperado)
+			}
+		})
+	}
+}
+
+func TestRegistrarAcesso_ComRecuperar(t *testing.T) {
+	var saida bytes.Buffer
+	logger := log.New(&saida, "", 0)
+	h := Encadear(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("falha")
+	}), RegistrarAcesso(logger), Recuperar(logger))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/alunos", nil))
+	if !strings.Contains(saida.String(), "POST /api/alunos 500") {
+		t.Errorf("log = %q", saida.String())
+	}
+}
+
+func TestIDRequisicao(t *testing.T) {
+	var visto string
+	h := IDRequisicao()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		visto = ID(r.Context())
+	}))
+
+	testes := []struct {
+		nome        string
+		recebido    string
+		reaproveita bool
+	}{
+		{"sem cabeçalho", "", false},
+		{"válido", "req-42", true},
+		{"com quebra de linha", "a\nb", false},
+		{"longo demais", strings.Repeat("x", 200), false},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.recebido != "" {
+				req.Header.Set(CabecalhoID, tt.recebido)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(CabecalhoID)
+			if id == "" || id != visto {
+				t.Fatalf("cabeçalho %q, contexto %q", id, visto)
+			}
+			if (id == tt.recebido) != tt.reaproveita {
+				t.Errorf("ID = %q; reaproveitar %q = %v", id, tt.recebido, tt.reaproveita)
+			}
+			if !tt.reaproveita && len(id) != 32 {
+				t.Errorf("ID gerado = %q; esperado 32 dígitos hex", id)
+			}
+		})
+	}
+}
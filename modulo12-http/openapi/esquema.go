@@ -0,0 +1,281 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Esquema é o subconjunto de JSON Schema usado pelo OpenAPI 3.0
+type Esquema struct {
+	Ref                string              `json:"$ref,omitempty"`
+	Tipo               string              `json:"type,omitempty"`
+	Formato            string              `json:"format,omitempty"`
+	Descricao          string              `json:"description,omitempty"`
+	Propriedades       map[string]*Esquema `json:"properties,omitempty"`
+	Obrigatorios       []string            `json:"required,omitempty"`
+	Itens              *Esquema            `json:"items,omitempty"`
+	PropriedadesExtras *Esquema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	tipoTempo         = reflect.TypeOf(time.Time{})
+	tipoRawMessage    = reflect.TypeOf(json.RawMessage{})
+	tipoMarshaler     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	tipoTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Esquemas gera esquemas a partir de tipos Go, seguindo as regras do
+// encoding/json: o nome vem da tag `json`, "-" esconde o campo,
+// omitempty o torna opcional, ",string" o transforma em texto e
+// structs embutidas têm os campos promovidos. A tag `doc` vira a
+// descrição do campo:
+//
+//	type Mensagem struct {
+//	    Texto string `json:"texto" doc:"conteúdo da mensagem"`
+//	    Autor string `json:"autor"`
+//	}
+//
+// Structs com nome viram componentes, referenciados por $ref (o que
+// também resolve tipos recursivos); as anônimas ficam no lugar.
+type Esquemas struct {
+	Componentes map[string]*Esquema
+	nomes       map[reflect.Type]string
+}
+
+// NovosEsquemas cria um gerador sem componentes
+func NovosEsquemas() *Esquemas {
+	return &Esquemas{Componentes: make(map[string]*Esquema), nomes: make(map[reflect.Type]string)}
+}
+
+// De retorna o esquema do tipo do valor v (ex.: De(Mensagem{}))
+func (e *Esquemas) De(v any) *Esquema {
+	if v == nil {
+		return &Esquema{}
+	}
+	return e.Tipo(reflect.TypeOf(v))
+}
+
+// Tipo retorna o esquema de t, registrando os componentes que faltarem
+func (e *Esquemas) Tipo(t reflect.Type) *Esquema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == tipoTempo:
+		return &Esquema{Tipo: "string", Formato: "date-time"}
+	case t == tipoRawMessage:
+		return &Esquema{}
+	case implementa(t, tipoMarshaler):
+		return &Esquema{} // formato próprio, que a reflection não enxerga
+	case implementa(t, tipoTextMarshaler):
+		return &Esquema{Tipo: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Esquema{Tipo: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Esquema{Tipo: "integer", Formato: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Esquema{Tipo: "integer", Formato: "int64"}
+	case reflect.Float32:
+		return &Esquema{Tipo: "number", Formato: "float"}
+	case reflect.Float64:
+		return &Esquema{Tipo: "number", Formato: "double"}
+	case reflect.String:
+		return &Esquema{Tipo: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Esquema{Tipo: "string", Formato: "byte"} // []byte vira base64
+		}
+		return &Esquema{Tipo: "array", Itens: e.Tipo(t.Elem())}
+	case reflect.Map:
+		return &Esquema{Tipo: "object", PropriedadesExtras: e.Tipo(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return e.estrutura(t)
+		}
+		return &Esquema{Ref: "#/components/schemas/" + e.componente(t)}
+	}
+	return &Esquema{} // interface: qualquer valor
+}
+
+// componente registra a struct com nome (uma vez) e retorna o nome
+func (e *Esquemas) componente(t reflect.Type) string {
+	if nome, ok := e.nomes[t]; ok {
+		return nome
+	}
+	nome := nomeComponente(t)
+	for i := 2; e.Componentes[nome] != nil; i++ {
+		nome = nomeComponente(t) + "_" + strconv.Itoa(i) // mesmo nome, packages diferentes
+	}
+	e.nomes[t] = nome
+	e.Componentes[nome] = &Esquema{} // reservado antes: tipos recursivos
+	*e.Componentes[nome] = *e.estrutura(t)
+	return nome
+}
+
+// estrutura monta o objeto com os campos que o encoding/json gravaria
+func (e *Esquemas) estrutura(t reflect.Type) *Esquema {
+	obj := &Esquema{Tipo: "object", Propriedades: make(map[string]*Esquema)}
+	for _, c := range campos(t) {
+		esquema := e.Tipo(c.tipo)
+		if c.comoTexto {
+			esquema = &Esquema{Tipo: "string"}
+		}
+		if esquema.Ref == "" { // no 3.0, irmãos de $ref são ignorados
+			esquema.Descricao = c.doc
+		}
+		obj.Propriedades[c.nome] = esquema
+		if !c.opcional {
+			obj.Obrigatorios = append(obj.Obrigatorios, c.nome)
+		}
+	}
+	sort.Strings(obj.Obrigatorios)
+	return obj
+}
+
+// campo é um campo serializado de uma struct
+type campo struct {
+	nome      string
+	tipo      reflect.Type
+	doc       string
+	opcional  bool
+	comoTexto bool
+	indice    []int // profundidade = len(indice)
+}
+
+// campos lista os campos como o encoding/json: promove os das structs
+// embutidas sem tag e, em nomes repetidos, fica o mais raso (empate
+// no mesmo nível esconde todos)
+func campos(t reflect.Type) []campo {
+	var todos []campo
+	var visitar func(t reflect.Type, indice []int, vistos map[reflect.Type]bool)
+	visitar = func(t reflect.Type, indice []int, vistos map[reflect.Type]bool) {
+		if vistos[t] {
+			return
+		}
+		vistos[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			nome, opcoes, _ := strings.Cut(tag, ",")
+			ft := f.Type
+			if f.Anonymous && nome == "" {
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					visitar(ft, append(append([]int(nil), indice...), i), vistos)
+					continue
+				}
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if nome == "" {
+				nome = f.Name
+			}
+			todos = append(todos, campo{
+				nome:      nome,
+				tipo:      f.Type,
+				doc:       f.Tag.Get("doc"),
+				opcional:  temOpcao(opcoes, "omitempty"),
+				comoTexto: temOpcao(opcoes, "string") && escalar(f.Type),
+				indice:    append(append([]int(nil), indice...), i),
+			})
+		}
+	}
+	visitar(t, nil, map[reflect.Type]bool{})
+
+	porNome := make(map[string][]campo)
+	for _, c := range todos {
+		porNome[c.nome] = append(porNome[c.nome], c)
+	}
+	var resultado []campo
+	for _, c := range todos {
+		disputa := porNome[c.nome]
+		if len(disputa) > 1 {
+			raso := true
+			for _, outro := range disputa {
+				if len(outro.indice) < len(c.indice) ||
+					(len(outro.indice) == len(c.indice) && !mesmoIndice(outro.indice, c.indice)) {
+					raso = false
+				}
+			}
+			if !raso {
+				continue
+			}
+		}
+		resultado = append(resultado, c)
+	}
+	return resultado
+}
+
+func temOpcao(opcoes, opcao string) bool {
+	for _, o := range strings.Split(opcoes, ",") {
+		if o == opcao {
+			return true
+		}
+	}
+	return false
+}
+
+// escalar: ",string" só vale para números, bool e string
+func escalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func implementa(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func mesmoIndice(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// nomeComponente usa o nome do tipo; genéricos como
+// Pagina[go-course/x.Aluno] viram Pagina_Aluno
+func nomeComponente(t reflect.Type) string {
+	nome := t.Name()
+	base, args, generico := strings.Cut(nome, "[")
+	if !generico {
+		return nome
+	}
+	var partes []string
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		if i := strings.LastIndexAny(arg, "./"); i >= 0 {
+			arg = arg[i+1:]
+		}
+		partes = append(partes, arg)
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r == '.' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, base+"_"+strings.Join(partes, "_"))
+}
@@ -0,0 +1,174 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Mensagem struct {
+	Texto string `json:"texto" doc:"conteúdo da mensagem"`
+	Autor string `json:"autor"`
+}
+
+type Base struct {
+	ID       int       `json:"id"`
+	CriadoEm time.Time `json:"criado_em"`
+}
+
+type Produto struct {
+	Base
+	Nome      string            `json:"nome"`
+	Preco     float64           `json:"preco"`
+	Estoque   int               `json:"estoque,omitempty"`
+	Codigo    int64             `json:"codigo,string"`
+	Tags      []string          `json:"tags,omitempty"`
+	Atributos map[string]string `json:"atributos,omitempty"`
+	Foto      []byte            `json:"foto,omitempty"`
+	IP        net.IP            `json:"ip,omitempty"` // TextMarshaler
+	Extra     json.RawMessage   `json:"extra,omitempty"`
+	Senha     string            `json:"-"`
+	Pai       *Produto          `json:"pai,omitempty"` // recursivo
+	Interno   string
+	privado   string
+}
+
+type Pagina[T any] struct {
+	Itens []T `json:"itens"`
+	Total int `json:"total"`
+}
+
+// comoJSON facilita comparar esquemas
+func comoJSON(t *testing.T, v any) string {
+	t.Helper()
+	dados, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(dados)
+}
+
+func TestEsquemas_Tipos(t *testing.T) {
+	testes := []struct {
+		valor    any
+		esperado string
+	}{
+		{true, `{"type":"boolean"}`},
+		{int32(1), `{"type":"integer","format":"int32"}`},
+		{1, `{"type":"integer","format":"int64"}`},
+		{1.5, `{"type":"number","format":"double"}`},
+		{float32(1), `{"type":"number","format":"float"}`},
+		{"", `{"type":"string"}`},
+		{time.Time{}, `{"type":"string","format":"date-time"}`},
+		{[]byte{}, `{"type":"string","format":"byte"}`},
+		{[]int{}, `{"type":"array","items":{"type":"integer","format":"int64"}}`},
+		{[3]bool{}, `{"type":"array","items":{"type":"boolean"}}`},
+		{map[string]float64{}, `{"type":"object","additionalProperties":{"type":"number","format":"double"}}`},
+		{new(string), `{"type":"string"}`},
+		{[]any{}, `{"type":"array","items":{}}`},
+		{struct {
+			A string `json:"a"`
+		}{}, `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]}`},
+		{Mensagem{}, `{"$ref":"#/components/schemas/Mensagem"}`},
+		{[]*Mensagem{}, `{"type":"array","items":{"$ref":"#/components/schemas/Mensagem"}}`},
+	}
+	for _, tt := range testes {
+		e := NovosEsquemas()
+		if got := comoJSON(t, e.De(tt.valor)); got != tt.esperado {
+			t.Errorf("%T: %s; esperado %s", tt.valor, got, tt.esperado)
+		}
+	}
+}
+
+func TestEsquemas_Struct(t *testing.T) {
+	e := NovosEsquemas()
+	e.De(Produto{})
+
+	p := e.Componentes["Produto"]
+	if p == nil || p.Tipo != "object" {
+		t.Fatalf("componente Produto = %+v", p)
+	}
+	var nomes []string
+	for nome := range p.Propriedades {
+		nomes = append(nomes, nome)
+	}
+	// Campos de Base promovidos; Senha, privado ficam de fora
+	for _, nome := range []string{"id", "criado_em", "nome", "preco", "estoque", "codigo", "tags",
+		"atributos", "foto", "ip", "extra", "pai", "Interno"} {
+		if p.Propriedades[nome] == nil {
+			t.Errorf("falta a propriedade %q (tem %v)", nome, nomes)
+		}
+	}
+	if len(p.Propriedades) != 13 {
+		t.Errorf("propriedades = %v", nomes)
+	}
+
+	obrigatorios := strings.Join(p.Obrigatorios, ",")
+	if obrigatorios != "Interno,codigo,criado_em,id,nome,preco" {
+		t.Errorf("required = %s", obrigatorios)
+	}
+	if got := comoJSON(t, p.Propriedades["codigo"]); got != `{"type":"string"}` {
+		t.Errorf(`",string" = %s`, got)
+	}
+	if got := comoJSON(t, p.Propriedades["ip"]); got != `{"type":"string"}` {
+		t.Errorf("TextMarshaler = %s", got)
+	}
+	if got := p.Propriedades["pai"].Ref; got != "#/components/schemas/Produto" {
+		t.Errorf("recursivo = %q", got)
+	}
+
+	e.De(Mensagem{})
+	if d := e.Componentes["Mensagem"].Propriedades["texto"].Descricao; d != "conteúdo da mensagem" {
+		t.Errorf("tag doc = %q", d)
+	}
+}
+
+func TestEsquemas_Nomes(t *testing.T) {
+	e := NovosEsquemas()
+	if got := e.De(Pagina[Mensagem]{}).Ref; got != "#/components/schemas/Pagina_Mensagem" {
+		t.Errorf("genérico = %q", got)
+	}
+	if e.Componentes["Mensagem"] == nil {
+		t.Error("o argumento do genérico também vira componente")
+	}
+
+	// Mesmo nome em outro "package": o segundo ganha sufixo
+	type Mensagem struct {
+		Outro bool `json:"outro"`
+	}
+	if got := e.De(Mensagem{}).Ref; got != "#/components/schemas/Mensagem_2" {
+		t.Errorf("nome repetido = %q", got)
+	}
+	// O mesmo tipo de novo reaproveita o componente
+	antes := len(e.Componentes)
+	if got := e.Tipo(reflect.TypeOf(&Mensagem{})).Ref; got != "#/components/schemas/Mensagem_2" || len(e.Componentes) != antes {
+		t.Errorf("de novo = %q, %d componentes (antes %d)", got, len(e.Componentes), antes)
+	}
+}
+
+func TestCampos_Conflitos(t *testing.T) {
+	type A struct {
+		Nome string `json:"nome"`
+		X    int    `json:"x"`
+	}
+	type B struct {
+		Nome string `json:"nome"`
+		X    int    `json:"x"`
+	}
+	type C struct {
+		A
+		*B
+		Nome string `json:"nome"` // mais raso: vence
+	}
+	var nomes []string
+	for _, c := range campos(reflect.TypeOf(C{})) {
+		nomes = append(nomes, c.nome)
+	}
+	// "x" empata entre A e B no mesmo nível: some, como no encoding/json
+	if got := strings.Join(nomes, ","); got != "nome" {
+		t.Errorf("campos = %s", got)
+	}
+}
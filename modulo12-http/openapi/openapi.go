@@ -0,0 +1,216 @@
+package openapi
+
+import (
+	"encoding/json"
+	"go-course/modulo12-http/problema"
+	"go-course/modulo12-http/roteador"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+PACKAGE OPENAPI
+
+Gera um documento OpenAPI 3 a partir das rotas de um roteador: a
+documentação sai do mesmo código que atende as requisições, em vez
+de um arquivo escrito à mão que envelhece.
+
+    r := roteador.Novo()
+    r.Post("/api/mensagem", criar).Documentar(roteador.Documentacao{
+        Resumo:     "Publica uma mensagem",
+        Requisicao: Mensagem{},
+        Resposta:   Mensagem{},
+        Status:     http.StatusCreated,
+        Erros:      []int{400, 422},
+    })
+    r.Get("/openapi.json", openapi.Handler(r, openapi.Info{Titulo: "API", Versao: "1.0"}).ServeHTTP)
+    r.Get("/docs", openapi.Visualizador("/openapi.json").ServeHTTP)
+
+O QUE ENTRA NO DOCUMENTO:
+- Cada rota do roteador, inclusive as sem Documentar
+- Parâmetros de caminho: {nome} e {resto...} (este vira {resto}, que
+  no OpenAPI não pode ter "/"; a descrição avisa)
+- Corpos de requisição e resposta em application/json, com esquemas
+  gerados por reflection dos valores de exemplo (veja Esquemas)
+- Erros em application/problem+json, com o esquema de problema.Problema
+*/
+
+// Versao é a versão da especificação OpenAPI gerada
+const Versao = "3.0.3"
+
+// Documento é a raiz de um documento OpenAPI
+type Documento struct {
+	OpenAPI     string                          `json:"openapi"`
+	Info        Info                            `json:"info"`
+	Caminhos    map[string]map[string]*Operacao `json:"paths"` // caminho → método → operação
+	Componentes Componentes                     `json:"components"`
+}
+
+// Info descreve a API
+type Info struct {
+	Titulo    string `json:"title"`
+	Versao    string `json:"version"`
+	Descricao string `json:"description,omitempty"`
+}
+
+// Componentes guarda os esquemas referenciados por $ref
+type Componentes struct {
+	Esquemas map[string]*Esquema `json:"schemas"`
+}
+
+// Operacao é um método de um caminho
+type Operacao struct {
+	Resumo     string               `json:"summary,omitempty"`
+	Descricao  string               `json:"description,omitempty"`
+	Tags       []string             `json:"tags,omitempty"`
+	ID         string               `json:"operationId"`
+	Parametros []Parametro          `json:"parameters,omitempty"`
+	Corpo      *Corpo               `json:"requestBody,omitempty"`
+	Respostas  map[string]*Resposta `json:"responses"` // status → resposta
+}
+
+// Parametro é um parâmetro de caminho (ou de consulta)
+type Parametro struct {
+	Nome        string   `json:"name"`
+	Em          string   `json:"in"`
+	Descricao   string   `json:"description,omitempty"`
+	Obrigatorio bool     `json:"required"`
+	Esquema     *Esquema `json:"schema"`
+}
+
+// Corpo é o corpo de uma requisição
+type Corpo struct {
+	Obrigatorio bool                `json:"required"`
+	Conteudo    map[string]Conteudo `json:"content"`
+}
+
+// Resposta é uma resposta possível de uma operação
+type Resposta struct {
+	Descricao string              `json:"description"`
+	Conteudo  map[string]Conteudo `json:"content,omitempty"`
+}
+
+// Conteudo associa um media type ao esquema do corpo
+type Conteudo struct {
+	Esquema *Esquema `json:"schema"`
+}
+
+// Gerar monta o documento com as rotas registradas até agora em r
+func Gerar(r *roteador.Roteador, info Info) *Documento {
+	esquemas := NovosEsquemas()
+	doc := &Documento{
+		OpenAPI:     Versao,
+		Info:        info,
+		Caminhos:    make(map[string]map[string]*Operacao),
+		Componentes: Componentes{Esquemas: esquemas.Componentes},
+	}
+	for _, rota := range r.Rotas() {
+		caminho, parametros := converterPadrao(rota.Padrao)
+		if doc.Caminhos[caminho] == nil {
+			doc.Caminhos[caminho] = make(map[string]*Operacao)
+		}
+		documentacao, _ := r.Documentacao(rota)
+		doc.Caminhos[caminho][strings.ToLower(rota.Metodo)] = operacao(rota, parametros, documentacao, esquemas)
+	}
+	return doc
+}
+
+func operacao(rota roteador.Rota, parametros []Parametro, d roteador.Documentacao, esquemas *Esquemas) *Operacao {
+	op := &Operacao{
+		Resumo:     d.Resumo,
+		Descricao:  d.Descricao,
+		Tags:       d.Tags,
+		ID:         idOperacao(rota),
+		Parametros: parametros,
+		Respostas:  make(map[string]*Resposta),
+	}
+	if d.Requisicao != nil {
+		op.Corpo = &Corpo{Obrigatorio: true, Conteudo: map[string]Conteudo{
+			"application/json": {Esquema: esquemas.De(d.Requisicao)},
+		}}
+	}
+
+	status := d.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	sucesso := &Resposta{Descricao: descricaoStatus(status)}
+	if d.Resposta != nil {
+		sucesso.Conteudo = map[string]Conteudo{"application/json": {Esquema: esquemas.De(d.Resposta)}}
+	}
+	op.Respostas[strconv.Itoa(status)] = sucesso
+
+	for _, s := range d.Erros {
+		op.Respostas[strconv.Itoa(s)] = &Resposta{
+			Descricao: descricaoStatus(s),
+			Conteudo:  map[string]Conteudo{problema.TipoContent: {Esquema: esquemas.De(problema.Problema{})}},
+		}
+	}
+	return op
+}
+
+// converterPadrao troca "{resto...}" por "{resto}" e lista os parâmetros
+func converterPadrao(padrao string) (string, []Parametro) {
+	segmentos := strings.Split(padrao, "/")
+	var parametros []Parametro
+	for i, seg := range segmentos {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+		nome := seg[1 : len(seg)-1]
+		p := Parametro{Em: "path", Obrigatorio: true, Esquema: &Esquema{Tipo: "string"}}
+		if resto, ok := strings.CutSuffix(nome, "..."); ok {
+			nome = resto
+			p.Descricao = "resto do caminho; pode conter \"/\""
+		}
+		p.Nome = nome
+		segmentos[i] = "{" + nome + "}"
+		parametros = append(parametros, p)
+	}
+	return strings.Join(segmentos, "/"), parametros
+}
+
+// idOperacao gera o operationId: "POST /api/alunos/{nome}/notas"
+// vira "post_api_alunos_nome_notas"
+func idOperacao(rota roteador.Rota) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rota.Metodo))
+	for _, seg := range strings.Split(rota.Padrao, "/") {
+		seg = strings.TrimSuffix(strings.Trim(seg, "{}"), "...")
+		if seg != "" {
+			b.WriteString("_" + seg)
+		}
+	}
+	return b.String()
+}
+
+func descricaoStatus(status int) string {
+	if texto := http.StatusText(status); texto != "" {
+		return texto
+	}
+	return "Status " + strconv.Itoa(status)
+}
+
+// Handler serve o documento em JSON. Ele é gerado na primeira
+// requisição, quando todas as rotas (inclusive as registradas depois
+// desta chamada) já existem.
+func Handler(r *roteador.Roteador, info Info) http.Handler {
+	var (
+		uma   sync.Once
+		dados []byte
+		err   error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		uma.Do(func() {
+			dados, err = json.MarshalIndent(Gerar(r, info), "", "  ")
+		})
+		if err != nil {
+			http.Error(w, "openapi: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(dados)
+	})
+}
@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"encoding/json"
+	"go-course/modulo12-http/roteador"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func nada(w http.ResponseWriter, r *http.Request) {}
+
+func rotasExemplo() *roteador.Roteador {
+	r := roteador.Novo()
+	api := r.Grupo("/api")
+	api.Post("/mensagem", nada).Documentar(roteador.Documentacao{
+		Resumo:     "Publica uma mensagem",
+		Tags:       []string{"mensagens"},
+		Requisicao: Mensagem{},
+		Resposta:   Mensagem{},
+		Status:     http.StatusCreated,
+		Erros:      []int{400, 422},
+	})
+	api.Get("/produtos/{id}", nada).Documentar(roteador.Documentacao{Resposta: Produto{}, Erros: []int{404}})
+	api.Delete("/produtos/{id}", nada).Documentar(roteador.Documentacao{Status: http.StatusNoContent})
+	r.Get("/arquivos/{caminho...}", nada)
+	return r
+}
+
+func TestGerar(t *testing.T) {
+	doc := Gerar(rotasExemplo(), Info{Titulo: "Teste", Versao: "1.0"})
+	if doc.OpenAPI != Versao || doc.Info.Titulo != "Teste" {
+		t.Errorf("cabeçalho = %q %+v", doc.OpenAPI, doc.Info)
+	}
+
+	criar := doc.Caminhos["/api/mensagem"]["post"]
+	if criar == nil {
+		t.Fatalf("caminhos = %v", doc.Caminhos)
+	}
+	if criar.Resumo != "Publica uma mensagem" || criar.ID != "post_api_mensagem" || criar.Tags[0] != "mensagens" {
+		t.Errorf("operação = %+v", criar)
+	}
+	if got := criar.Corpo.Conteudo["application/json"].Esquema.Ref; got != "#/components/schemas/Mensagem" {
+		t.Errorf("corpo = %q", got)
+	}
+	if got := criar.Respostas["201"]; got == nil || got.Descricao != "Created" || got.Conteudo["application/json"].Esquema.Ref == "" {
+		t.Errorf("201 = %+v", got)
+	}
+	if got := criar.Respostas["422"]; got == nil || got.Conteudo["application/problem+json"].Esquema.Ref != "#/components/schemas/Problema" {
+		t.Errorf("422 = %+v", got)
+	}
+
+	obter := doc.Caminhos["/api/produtos/{id}"]["get"]
+	if len(obter.Parametros) != 1 || obter.Parametros[0].Nome != "id" || obter.Parametros[0].Em != "path" || !obter.Parametros[0].Obrigatorio {
+		t.Errorf("parâmetros = %+v", obter.Parametros)
+	}
+	if obter.Corpo != nil || obter.Respostas["200"] == nil || obter.Respostas["404"] == nil {
+		t.Errorf("GET produto = %+v", obter)
+	}
+	if remover := doc.Caminhos["/api/produtos/{id}"]["delete"]; remover.Respostas["204"].Conteudo != nil {
+		t.Errorf("204 com corpo: %+v", remover.Respostas["204"])
+	}
+
+	// {caminho...} vira {caminho}; rota sem Documentar ganha só o 200
+	arquivos := doc.Caminhos["/arquivos/{caminho}"]["get"]
+	if arquivos == nil || arquivos.Parametros[0].Nome != "caminho" || arquivos.Parametros[0].Descricao == "" {
+		t.Errorf("arquivos = %+v", arquivos)
+	}
+	if len(arquivos.Respostas) != 1 || arquivos.Respostas["200"] == nil {
+		t.Errorf("respostas sem documentação = %v", arquivos.Respostas)
+	}
+
+	for _, nome := range []string{"Mensagem", "Produto", "Problema", "Campo"} {
+		if doc.Componentes.Esquemas[nome] == nil {
+			t.Errorf("falta o componente %s", nome)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := rotasExemplo()
+	r.Get("/openapi.json", Handler(r, Info{Titulo: "Teste", Versao: "1.0"}).ServeHTTP)
+	r.Get("/docs", Visualizador("/openapi.json").ServeHTTP)
+	// Registrada depois do Handler: entra no documento mesmo assim
+	r.Get("/depois", nada)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status %d, Content-Type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	caminhos := doc["paths"].(map[string]any)
+	for _, c := range []string{"/api/mensagem", "/openapi.json", "/docs", "/depois"} {
+		if caminhos[c] == nil {
+			t.Errorf("falta %s no documento", c)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") || !strings.Contains(w.Body.String(), `const url = "/openapi.json"`) {
+		t.Errorf("visualizador: %q\n%s", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// Visualizador serve uma página que lê o documento em urlDocumento e
+// mostra as operações com seus parâmetros, corpos e respostas. Não
+// depende de CDN: funciona offline e sem Content-Security-Policy extra.
+func Visualizador(urlDocumento string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		paginaVisualizador.Execute(w, urlDocumento)
+	})
+}
+
+// O template escapa a URL dentro do <script> (contexto JS)
+var paginaVisualizador = template.Must(template.New("visualizador").Parse(`<!doctype html>
+<meta charset="utf-8">
+<title>Documentação da API</title>
+<style>
+  body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; }
+  details { border: 1px solid #ccc; border-radius: 4px; margin: .5rem 0; padding: .4rem .8rem; }
+  summary { cursor: pointer; }
+  .metodo { display: inline-block; width: 4.5rem; font-weight: bold; }
+  .get { color: #1565c0; } .post { color: #2e7d32; } .put { color: #ef6c00; }
+  .patch { color: #6a1b9a; } .delete { color: #c62828; }
+  code, pre { background: #f5f5f5; }
+  pre { padding: .5rem; overflow: auto; }
+</style>
+<h1 id="titulo">Carregando…</h1>
+<p id="descricao"></p>
+<div id="operacoes"></div>
+<script>
+const url = {{.}};
+const el = (tag, attrs, ...filhos) => {
+  const e = document.createElement(tag);
+  Object.assign(e, attrs);
+  e.append(...filhos);
+  return e;
+};
+
+fetch(url).then(r => r.json()).then(doc => {
+  document.title = doc.info.title;
+  titulo.textContent = doc.info.title + " " + doc.info.version;
+  descricao.textContent = doc.info.description || "";
+  const esquemas = (doc.components || {}).schemas || {};
+
+  // exemplo monta um JSON ilustrativo a partir do esquema
+  const exemplo = (s, vistos = []) => {
+    if (s.$ref) {
+      const nome = s.$ref.split("/").pop();
+      return vistos.includes(nome) ? "(" + nome + ")" : exemplo(esquemas[nome], [...vistos, nome]);
+    }
+    switch (s.type) {
+      case "object":
+        if (s.additionalProperties) return {"<chave>": exemplo(s.additionalProperties, vistos)};
+        return Object.fromEntries(Object.entries(s.properties || {}).map(([k, v]) => [k, exemplo(v, vistos)]));
+      case "array": return [exemplo(s.items, vistos)];
+      case "integer": case "number": return 0;
+      case "boolean": return false;
+      case "string": return s.format ? "<" + s.format + ">" : "texto";
+    }
+    return null;
+  };
+  const corpo = (conteudo) => Object.entries(conteudo || {}).map(([tipo, c]) =>
+    el("div", {}, el("code", {}, tipo), el("pre", {}, JSON.stringify(exemplo(c.schema), null, 2))));
+
+  for (const [caminho, metodos] of Object.entries(doc.paths).sort()) {
+    for (const [metodo, op] of Object.entries(metodos)) {
+      const d = el("details", {},
+        el("summary", {}, el("span", {className: "metodo " + metodo}, metodo.toUpperCase()),
+          el("code", {}, caminho), " ", op.summary || ""));
+      if (op.description) d.append(el("p", {}, op.description));
+      if (op.parameters) {
+        d.append(el("h4", {}, "Parâmetros"), el("ul", {}, ...op.parameters.map(p =>
+          el("li", {}, el("code", {}, p.name), " (" + p.in + ") " + (p.description || "")))));
+      }
+      if (op.requestBody) d.append(el("h4", {}, "Corpo"), ...corpo(op.requestBody.content));
+      d.append(el("h4", {}, "Respostas"));
+      for (const [status, resp] of Object.entries(op.responses)) {
+        d.append(el("p", {}, el("b", {}, status), " " + resp.description), ...corpo(resp.content));
+      }
+      operacoes.append(d);
+    }
+  }
+}).catch(err => titulo.textContent = "Erro ao carregar " + url + ": " + err);
+</script>
+`))
@@ -0,0 +1,302 @@
+package problema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"go-course/modulo12-http/middleware"
+	"log"
+	"net/http"
+	"sync"
+)
+
+/*
+PACKAGE PROBLEMA
+
+Respostas de erro no formato da RFC 7807 (atualizada pela RFC 9457),
+"Problem Details for HTTP APIs", com Content-Type
+application/problem+json:
+
+    {
+      "type": "about:blank",
+      "title": "Unprocessable Entity",
+      "status": 422,
+      "detail": "2 campos inválidos",
+      "instance": "/api/alunos",
+      "erros": [{"campo": "nome", "mensagem": "obrigatório"}, ...],
+      "request_id": "4f3a..."
+    }
+
+Um Registro traduz a cadeia de um erro (errors.Is / errors.As) em um
+Problema:
+
+    reg := problema.Padrao()                     // tipos de modulo07-erros/erros
+    reg.Sentinela(notas.ErrAlunoDuplicado, problema.Regra{Status: 409, Publica: true})
+    problema.RegistrarTipo(reg, func(e *MeuErro) problema.Problema { ... })
+
+    reg.Responder(w, r, err)
+
+MENSAGEM SEGURA:
+O cliente só vê err.Error() quando a regra é Publica. Nos outros casos
+vê o título (ou a mensagem do próprio sentinel), e erros 5xx viram
+"erro interno": a causa completa, que pode ter caminhos de arquivo,
+SQL ou endereços internos, vai só para o log, com o request_id que o
+cliente recebeu (middleware.IDRequisicao).
+*/
+
+// TipoContent é o Content-Type das respostas de problema
+const TipoContent = "application/problem+json"
+
+// Problema é o corpo de uma resposta de erro (RFC 7807)
+type Problema struct {
+	Tipo      string  `json:"type"`
+	Titulo    string  `json:"title"`
+	Status    int     `json:"status"`
+	Detalhe   string  `json:"detail,omitempty"`
+	Instancia string  `json:"instance,omitempty"`
+	Erros     []Campo `json:"erros,omitempty"` // detalhes de validação
+	RequestID string  `json:"request_id,omitempty"`
+
+	// Cabecalhos vão na resposta, não no corpo (ex.: WWW-Authenticate)
+	Cabecalhos http.Header `json:"-"`
+}
+
+// Campo é o erro de validação de um campo da entrada
+type Campo struct {
+	Campo    string `json:"campo"`
+	Valor    string `json:"valor,omitempty"`
+	Mensagem string `json:"mensagem"`
+}
+
+// Novo cria um problema genérico ("about:blank") para o status
+func Novo(status int, detalhe string) Problema {
+	return Problema{Tipo: "about:blank", Titulo: http.StatusText(status), Status: status, Detalhe: detalhe}
+}
+
+// Escrever envia o problema como resposta
+func Escrever(w http.ResponseWriter, p Problema) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Tipo == "" {
+		p.Tipo = "about:blank"
+	}
+	if p.Titulo == "" {
+		p.Titulo = http.StatusText(p.Status)
+	}
+	for nome, valores := range p.Cabecalhos {
+		w.Header()[nome] = valores
+	}
+	w.Header().Set("Content-Type", TipoContent)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// ========================================
+// REGISTRO
+// ========================================
+
+// Regra descreve o problema de um sentinel
+type Regra struct {
+	Status int
+	Tipo   string // URI que documenta o problema ("" = about:blank)
+	Titulo string // "" = texto do status
+	// Publica usa err.Error() inteiro como detail; senão, só a
+	// mensagem do sentinel (o contexto embrulhado pode ser interno)
+	Publica bool
+	// Campo, se não vazio, vira um item de validação em "erros"
+	Campo string
+}
+
+// conversor tenta traduzir err; false se a regra não se aplica
+type conversor func(err error) (Problema, bool)
+
+// Registro guarda as regras de tradução; seguro para uso concorrente
+type Registro struct {
+	// Logger recebe os erros 5xx com a causa completa (nil = log padrão)
+	Logger *log.Logger
+
+	mu     sync.RWMutex
+	regras []conversor
+}
+
+// NovoRegistro cria um registro vazio: todo erro vira 500
+func NovoRegistro() *Registro {
+	return &Registro{}
+}
+
+// Sentinela associa um erro sentinel (comparado com errors.Is) a uma regra
+func (r *Registro) Sentinela(alvo error, regra Regra) {
+	r.incluir(func(err error) (Problema, bool) {
+		if !errors.Is(err, alvo) {
+			return Problema{}, false
+		}
+		p := Problema{Tipo: regra.Tipo, Titulo: regra.Titulo, Status: regra.Status, Detalhe: alvo.Error()}
+		if regra.Publica {
+			p.Detalhe = err.Error()
+		}
+		if regra.Campo != "" {
+			p.Erros = []Campo{{Campo: regra.Campo, Mensagem: alvo.Error()}}
+		}
+		return p, true
+	})
+}
+
+// RegistrarTipo associa um tipo de erro (encontrado com errors.As) a
+// uma função que monta o problema. É uma função, não um método, porque
+// métodos em Go não podem ter parâmetros de tipo.
+func RegistrarTipo[T error](r *Registro, montar func(T) Problema) {
+	r.incluir(func(err error) (Problema, bool) {
+		var alvo T
+		if !errors.As(err, &alvo) {
+			return Problema{}, false
+		}
+		return montar(alvo), true
+	})
+}
+
+func (r *Registro) incluir(c conversor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.regras = append(r.regras, c)
+}
+
+// Converter traduz err em um Problema. As regras registradas por
+// último têm precedência: assim dá para sobrescrever as de Padrao.
+// Sem regra que se aplique, o problema é 500.
+func (r *Registro) Converter(err error) Problema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := len(r.regras) - 1; i >= 0; i-- {
+		if p, ok := r.regras[i](err); ok {
+			return completar(p)
+		}
+	}
+	return completar(Problema{Status: http.StatusInternalServerError})
+}
+
+// completar preenche o que a regra deixou vazio e nunca deixa um 5xx
+// levar detalhes para o cliente
+func completar(p Problema) Problema {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Tipo == "" {
+		p.Tipo = "about:blank"
+	}
+	if p.Titulo == "" {
+		p.Titulo = http.StatusText(p.Status)
+	}
+	if p.Status >= 500 && p.Detalhe == "" {
+		p.Detalhe = "erro interno"
+	}
+	return p
+}
+
+// Responder converte err, registra no log os 5xx e envia a resposta
+func (r *Registro) Responder(w http.ResponseWriter, req *http.Request, err error) {
+	p := r.Converter(err)
+	p.Instancia = req.URL.Path
+	p.RequestID = middleware.ID(req.Context())
+	if p.Status >= 500 {
+		logf(r.Logger, "erro %d [%s] %s %s: %v", p.Status, p.RequestID, req.Method, req.URL.Path, err)
+	}
+	Escrever(w, p)
+}
+
+func logf(logger *log.Logger, formato string, args ...any) {
+	if logger == nil {
+		log.Printf(formato, args...)
+		return
+	}
+	logger.Printf(formato, args...)
+}
+
+// ========================================
+// REGRAS PADRÃO
+// ========================================
+
+// Padrao cria um registro com os erros de modulo07-erros/erros, de
+// context e do net/http
+func Padrao() *Registro {
+	r := NovoRegistro()
+
+	r.Sentinela(context.Canceled, Regra{Status: http.StatusServiceUnavailable, Titulo: "Requisição cancelada"})
+	r.Sentinela(context.DeadlineExceeded, Regra{Status: http.StatusGatewayTimeout})
+	RegistrarTipo(r, func(e *http.MaxBytesError) Problema {
+		return Problema{Status: http.StatusRequestEntityTooLarge, Detalhe: fmt.Sprintf("corpo acima de %d bytes", e.Limit)}
+	})
+
+	// Banco: a causa (SQL, tabela) nunca sai; só o 500 com request_id
+	RegistrarTipo(r, func(e erros.ErroBanco) Problema {
+		return Problema{Status: http.StatusInternalServerError}
+	})
+	// Serviço externo falhou: 502, sem a URL interna
+	RegistrarTipo(r, func(e erros.ErroHTTP) Problema {
+		return Problema{
+			Status:  http.StatusBadGateway,
+			Detalhe: fmt.Sprintf("serviço externo respondeu %d", e.StatusCode),
+		}
+	})
+
+	// Depois dos tipos acima, então têm precedência:
+	// ErroBanco{Detalhe: ErrNaoEncontrado} é 404, não 500
+	r.Sentinela(erros.ErrNaoEncontrado, Regra{Status: http.StatusNotFound})
+	r.Sentinela(erros.ErrNaoAutorizado, Regra{Status: http.StatusForbidden})
+	r.Sentinela(erros.ErrParametroInvalido, Regra{Status: http.StatusBadRequest, Publica: true})
+	r.Sentinela(erros.ErrFormatoInvalido, Regra{Status: http.StatusBadRequest})
+
+	// Validação: todos os campos inválidos da árvore (errors.Join)
+	r.incluir(func(err error) (Problema, bool) {
+		campos := CamposValidacao(err)
+		if len(campos) == 0 {
+			return Problema{}, false
+		}
+		detalhe := "1 campo inválido"
+		if len(campos) > 1 {
+			detalhe = fmt.Sprintf("%d campos inválidos", len(campos))
+		}
+		return Problema{Status: http.StatusUnprocessableEntity, Detalhe: detalhe, Erros: campos}, true
+	})
+	return r
+}
+
+// ========================================
+// VALIDAÇÃO
+// ========================================
+
+// CamposValidacao reúne todos os erros.ErroValidacao da árvore do erro
+// (errors.As só acha o primeiro; errors.Join pode ter vários)
+func CamposValidacao(err error) []Campo {
+	var campos []Campo
+	percorrer(err, func(e error) {
+		switch v := e.(type) {
+		case erros.ErroValidacao:
+			campos = append(campos, Campo{Campo: v.Campo, Valor: v.Valor, Mensagem: v.Mensagem})
+		case *erros.ErroValidacao:
+			campos = append(campos, Campo{Campo: v.Campo, Valor: v.Valor, Mensagem: v.Mensagem})
+		}
+	})
+	return campos
+}
+
+// percorrer visita cada erro da árvore, em profundidade
+func percorrer(err error, visitar func(error)) {
+	for err != nil {
+		visitar(err)
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, filho := range u.Unwrap() {
+				percorrer(filho, visitar)
+			}
+			return
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		default:
+			return
+		}
+	}
+}
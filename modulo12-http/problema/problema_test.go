@@ -0,0 +1,144 @@
+package problema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"go-course/modulo12-http/middleware"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errSaldo = errors.New("saldo insuficiente")
+
+type erroCota struct{ restante int }
+
+func (e *erroCota) Error() string { return fmt.Sprintf("cota esgotada (%d)", e.restante) }
+
+func TestConverter(t *testing.T) {
+	reg := Padrao()
+	reg.Sentinela(errSaldo, Regra{Status: http.StatusConflict, Tipo: "https://escola.br/problemas/saldo", Publica: true})
+	RegistrarTipo(reg, func(e *erroCota) Problema {
+		return Problema{Status: http.StatusTooManyRequests, Detalhe: fmt.Sprintf("restam %d", e.restante)}
+	})
+
+	testes := []struct {
+		nome    string
+		err     error
+		status  int
+		detalhe string
+	}{
+		{"sentinel embrulhado", fmt.Errorf("buscar turma 7: %w", erros.ErrNaoEncontrado), 404, "recurso não encontrado"},
+		{"mensagem pública", fmt.Errorf("pagar mensalidade: %w", errSaldo), 409, "pagar mensalidade: saldo insuficiente"},
+		{"tipo próprio", fmt.Errorf("importar: %w", &erroCota{restante: 0}), 429, "restam 0"},
+		{"parâmetro inválido", fmt.Errorf("%w: pagina=-1", erros.ErrParametroInvalido), 400, "parâmetro inválido: pagina=-1"},
+		{"não autorizado", fmt.Errorf("x: %w", erros.ErrNaoAutorizado), 403, "não autorizado"},
+		{"banco esconde a causa", erros.ErroBanco{Operacao: "buscar", Tabela: "alunos", Detalhe: errors.New("pq: senha inválida para 10.0.0.5")}, 500, "erro interno"},
+		{"banco com não encontrado", erros.ErroBanco{Operacao: "buscar", Tabela: "alunos", Detalhe: erros.ErrNaoEncontrado}, 404, "recurso não encontrado"},
+		{"serviço externo", erros.ErroHTTP{StatusCode: 503, URL: "http://interno:9000/x", Mensagem: "fora"}, 502, "serviço externo respondeu 503"},
+		{"prazo", fmt.Errorf("consulta: %w", context.DeadlineExceeded), 504, "context deadline exceeded"},
+		{"corpo grande", fmt.Errorf("ler: %w", &http.MaxBytesError{Limit: 1024}), 413, "corpo acima de 1024 bytes"},
+		{"desconhecido", errors.New("open /etc/notas/segredo: permission denied"), 500, "erro interno"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			p := reg.Converter(tt.err)
+			if p.Status != tt.status || p.Detalhe != tt.detalhe {
+				t.Errorf("= %d %q; esperado %d %q", p.Status, p.Detalhe, tt.status, tt.detalhe)
+			}
+			if p.Titulo != http.StatusText(tt.status) || p.Tipo == "" {
+				t.Errorf("título/tipo = %q %q", p.Titulo, p.Tipo)
+			}
+		})
+	}
+	if p := reg.Converter(errSaldo); p.Tipo != "https://escola.br/problemas/saldo" {
+		t.Errorf("Tipo = %q", p.Tipo)
+	}
+}
+
+func TestConverter_Precedencia(t *testing.T) {
+	// Regra registrada depois sobrescreve a de Padrao
+	reg := Padrao()
+	reg.Sentinela(erros.ErrNaoAutorizado, Regra{Status: http.StatusUnauthorized, Titulo: "Faça login"})
+	if p := reg.Converter(erros.ErrNaoAutorizado); p.Status != 401 || p.Titulo != "Faça login" {
+		t.Errorf("= %d %q", p.Status, p.Titulo)
+	}
+	if p := NovoRegistro().Converter(erros.ErrNaoEncontrado); p.Status != 500 {
+		t.Errorf("registro vazio: status = %d; esperado 500", p.Status)
+	}
+}
+
+func TestValidacao(t *testing.T) {
+	err := fmt.Errorf("cadastrar aluno: %w", errors.Join(
+		erros.ErroValidacao{Campo: "nome", Mensagem: "obrigatório"},
+		&erros.ErroValidacao{Campo: "email", Valor: "ana@", Mensagem: "formato inválido"},
+		errors.New("outro erro qualquer"),
+	))
+	p := Padrao().Converter(err)
+	if p.Status != http.StatusUnprocessableEntity || p.Detalhe != "2 campos inválidos" {
+		t.Errorf("= %d %q", p.Status, p.Detalhe)
+	}
+	esperado := []Campo{{Campo: "nome", Mensagem: "obrigatório"}, {Campo: "email", Valor: "ana@", Mensagem: "formato inválido"}}
+	if fmt.Sprint(p.Erros) != fmt.Sprint(esperado) {
+		t.Errorf("Erros = %v; esperado %v", p.Erros, esperado)
+	}
+
+	reg := NovoRegistro()
+	reg.Sentinela(errSaldo, Regra{Status: 422, Campo: "valor"})
+	if p := reg.Converter(errSaldo); len(p.Erros) != 1 || p.Erros[0].Campo != "valor" {
+		t.Errorf("Regra.Campo: Erros = %v", p.Erros)
+	}
+}
+
+func TestResponder(t *testing.T) {
+	var saida bytes.Buffer
+	reg := Padrao()
+	reg.Logger = log.New(&saida, "", 0)
+
+	req := httptest.NewRequest("POST", "/api/alunos", nil)
+	req = req.WithContext(middleware.ComID(req.Context(), "req-42"))
+	rec := httptest.NewRecorder()
+	reg.Responder(rec, req, erros.ErroBanco{Operacao: "inserir", Tabela: "alunos", Detalhe: errors.New("disco cheio")})
+
+	if rec.Code != 500 || rec.Header().Get("Content-Type") != TipoContent {
+		t.Fatalf("= %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var corpo map[string]any
+	json.Unmarshal(rec.Body.Bytes(), &corpo)
+	if corpo["type"] != "about:blank" || corpo["status"] != 500.0 || corpo["instance"] != "/api/alunos" ||
+		corpo["request_id"] != "req-42" || corpo["detail"] != "erro interno" {
+		t.Errorf("corpo = %v", corpo)
+	}
+	if strings.Contains(rec.Body.String(), "disco") {
+		t.Error("a causa interna vazou para o cliente")
+	}
+	if !strings.Contains(saida.String(), "[req-42] POST /api/alunos") || !strings.Contains(saida.String(), "disco cheio") {
+		t.Errorf("log = %q", saida.String())
+	}
+
+	// 4xx não vai para o log
+	saida.Reset()
+	reg.Responder(httptest.NewRecorder(), req, erros.ErrNaoEncontrado)
+	if saida.Len() != 0 {
+		t.Errorf("4xx registrado no log: %q", saida.String())
+	}
+}
+
+func TestEscrever_Cabecalhos(t *testing.T) {
+	rec := httptest.NewRecorder()
+	p := Novo(http.StatusUnauthorized, "faça login")
+	p.Cabecalhos = http.Header{"Www-Authenticate": {`Bearer realm="api"`}}
+	Escrever(rec, p)
+	if rec.Code != 401 || rec.Header().Get("WWW-Authenticate") != `Bearer realm="api"` {
+		t.Errorf("= %d %v", rec.Code, rec.Header())
+	}
+	if strings.Contains(rec.Body.String(), "Bearer") {
+		t.Error("cabeçalhos não vão no corpo")
+	}
+}
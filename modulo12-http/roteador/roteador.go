@@ -0,0 +1,539 @@
+package roteador
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+/*
+PACKAGE ROTEADOR
+
+O http.ServeMux de 01_servidor_basico.go não tem parâmetros de caminho
+nem separa métodos, e "/" captura tudo. Este roteador resolve isso:
+
+    r := roteador.Novo()
+    r.Get("/api/alunos", listar)
+    r.Post("/api/alunos", criar)
+    r.Get("/api/alunos/{nome}", obter)              // roteador.Parametro(req, "nome")
+    r.Get("/arquivos/{caminho...}", servirArquivo)  // captura o resto do caminho
+
+    api := r.Grupo("/api/v2", autenticar)           // prefixo + middleware
+    api.Get("/turmas/{id}", obterTurma)
+
+    r.EscreverTabela(os.Stdout)                     // tabela de rotas
+
+PADRÕES:
+- "/texto"       segmento fixo
+- "/{nome}"      um segmento qualquer (não vazio), lido com Parametro
+- "/{nome...}"   o resto do caminho (só no fim do padrão, pode ser vazio)
+
+Quando mais de uma rota casa, vence a mais específica, segmento a
+segmento: fixo > {parâmetro} > {resto...}. Assim "/alunos/novo" e
+"/alunos/{nome}" convivem. Padrões ambíguos (ex.: "/{id}" e "/{nome}"
+na mesma posição) ou rotas repetidas causam panic no registro, como
+no http.ServeMux.
+
+MÉTODOS:
+- Caminho conhecido com método não registrado → 405 + Allow
+- HEAD usa o handler de GET (o net/http descarta o corpo)
+- OPTIONS sem handler → 204 + Allow
+- Barra final é ignorada: "/api/alunos/" == "/api/alunos"
+
+MIDDLEWARE:
+Um Middleware embrulha um http.Handler. Os de um grupo valem para as
+rotas registradas nele (e nos subgrupos) DEPOIS da chamada a Usar; os
+do Roteador embrulham todas as requisições, inclusive 404 e 405.
+Todas as rotas devem ser registradas antes de o servidor começar.
+
+DOCUMENTAÇÃO:
+Handle (e Get, Post...) devolve o registro da rota, que aceita uma
+descrição dos corpos para geradores como o package openapi:
+
+    r.Post("/api/mensagens", criar).Documentar(roteador.Documentacao{
+        Resumo:     "Publica uma mensagem",
+        Requisicao: Mensagem{},
+        Resposta:   Mensagem{},
+        Status:     http.StatusCreated,
+        Erros:      []int{400, 422},
+    })
+*/
+
+// Middleware embrulha um handler: func(proximo) handler
+type Middleware func(http.Handler) http.Handler
+
+// Rota é uma linha da tabela de rotas
+type Rota struct {
+	Metodo string
+	Padrao string
+}
+
+// Documentacao descreve uma rota para quem gera documentação (ex.: o
+// package openapi). Requisicao e Resposta são valores de exemplo dos
+// corpos JSON, lidos por reflection: Mensagem{}, []Aluno{}...
+type Documentacao struct {
+	Resumo     string
+	Descricao  string
+	Tags       []string
+	Requisicao any   // corpo enviado (nil = sem corpo)
+	Resposta   any   // corpo da resposta de sucesso (nil = sem corpo)
+	Status     int   // status de sucesso (0 = 200)
+	Erros      []int // status de erro que a rota pode responder
+}
+
+// Registro é a rota recém-registrada, para anexar documentação
+type Registro struct {
+	roteador *Roteador
+	rota     Rota
+}
+
+// Roteador despacha requisições pelo método e pelo caminho
+type Roteador struct {
+	GrupoRotas
+	raiz *no
+
+	// NaoEncontrado responde quando nenhum padrão casa (padrão: http.NotFound)
+	NaoEncontrado http.Handler
+	// MetodoNaoPermitido responde 405; o cabeçalho Allow já vem preenchido
+	MetodoNaoPermitido http.Handler
+
+	globais []Middleware
+	cadeia  http.Handler // despachar embrulhado pelos globais (refeito em Usar)
+	rotas   []Rota
+	docs    map[Rota]Documentacao
+}
+
+// GrupoRotas registra rotas com um prefixo e middlewares em comum
+type GrupoRotas struct {
+	roteador    *Roteador
+	prefixo     string
+	middlewares []Middleware
+}
+
+// no é um segmento da árvore de rotas
+type no struct {
+	estaticos map[string]*no
+	parametro *no    // filho {nome}
+	resto     *no    // filho {nome...}
+	nome      string // nome do parâmetro (nos nós parametro e resto)
+	padrao    string // padrão completo, nos nós com handlers
+	handlers  map[string]http.Handler
+}
+
+// Novo cria um roteador vazio
+func Novo() *Roteador {
+	r := &Roteador{raiz: &no{}}
+	r.GrupoRotas = GrupoRotas{roteador: r}
+	r.cadeia = http.HandlerFunc(r.despachar)
+	return r
+}
+
+// Usar acrescenta middlewares que embrulham TODAS as requisições do
+// roteador, inclusive as que terminam em 404 ou 405
+func (r *Roteador) Usar(middlewares ...Middleware) {
+	r.globais = append(r.globais, middlewares...)
+	// A cadeia é montada aqui, uma vez, e não a cada requisição: o
+	// construtor de um middleware (e o estado que ele cria) roda uma vez
+	var h http.Handler = http.HandlerFunc(r.despachar)
+	for i := len(r.globais) - 1; i >= 0; i-- {
+		h = r.globais[i](h)
+	}
+	r.cadeia = h
+}
+
+// Rotas retorna a tabela de rotas ordenada por padrão e método
+func (r *Roteador) Rotas() []Rota {
+	rotas := append([]Rota(nil), r.rotas...)
+	sort.Slice(rotas, func(i, j int) bool {
+		if rotas[i].Padrao != rotas[j].Padrao {
+			return rotas[i].Padrao < rotas[j].Padrao
+		}
+		return ordemMetodo(rotas[i].Metodo) < ordemMetodo(rotas[j].Metodo)
+	})
+	return rotas
+}
+
+// PadraoDe retorna o padrão da rota que atenderia req ("" se nenhuma
+// casa), sem atendê-la. Serve a middlewares que embrulham o roteador
+// por fora, onde Padrao ainda não tem valor (ex.: métricas por rota).
+func (r *Roteador) PadraoDe(req *http.Request) string {
+	var valores []string
+	if n := r.raiz.buscar(dividir(req.URL.EscapedPath()), &valores); n != nil {
+		return n.padrao
+	}
+	return ""
+}
+
+// Documentacao retorna a documentação anexada à rota, se houver
+func (r *Roteador) Documentacao(rota Rota) (Documentacao, bool) {
+	doc, ok := r.docs[rota]
+	return doc, ok
+}
+
+// EscreverTabela imprime as rotas em colunas, para depuração
+func (r *Roteador) EscreverTabela(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MÉTODO\tPADRÃO")
+	for _, rota := range r.Rotas() {
+		fmt.Fprintf(tw, "%s\t%s\n", rota.Metodo, rota.Padrao)
+	}
+	return tw.Flush()
+}
+
+// ServeHTTP implementa http.Handler
+func (r *Roteador) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.cadeia.ServeHTTP(w, req)
+}
+
+func (r *Roteador) despachar(w http.ResponseWriter, req *http.Request) {
+	segmentos := dividir(req.URL.EscapedPath())
+	var valores []string
+	n := r.raiz.buscar(segmentos, &valores)
+	if n == nil {
+		r.naoEncontrado(w, req)
+		return
+	}
+
+	parametros, ok := n.parametros(valores)
+	if !ok { // escape inválido, como "%zz"
+		r.naoEncontrado(w, req)
+		return
+	}
+	h := n.handlers[req.Method]
+	if h == nil && req.Method == http.MethodHead {
+		h = n.handlers[http.MethodGet]
+	}
+	if h == nil {
+		w.Header().Set("Allow", n.permitidos())
+		if req.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.MetodoNaoPermitido != nil {
+			r.MetodoNaoPermitido.ServeHTTP(w, req)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := context.WithValue(req.Context(), chaveRota{}, &rotaEncontrada{padrao: n.padrao, parametros: parametros})
+	h.ServeHTTP(w, req.WithContext(ctx))
+}
+
+func (r *Roteador) naoEncontrado(w http.ResponseWriter, req *http.Request) {
+	if r.NaoEncontrado != nil {
+		r.NaoEncontrado.ServeHTTP(w, req)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// ========================================
+// GRUPOS E REGISTRO
+// ========================================
+
+// Grupo cria um subgrupo: o prefixo é somado ao do grupo atual e os
+// middlewares são acrescentados aos já existentes
+func (g *GrupoRotas) Grupo(prefixo string, middlewares ...Middleware) *GrupoRotas {
+	return &GrupoRotas{
+		roteador:    g.roteador,
+		prefixo:     juntar(g.prefixo, prefixo),
+		middlewares: append(append([]Middleware(nil), g.middlewares...), middlewares...),
+	}
+}
+
+// Usar acrescenta middlewares às rotas registradas a seguir no grupo
+func (g *GrupoRotas) Usar(middlewares ...Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+// Handle registra h para o método e o padrão (relativo ao prefixo do grupo)
+func (g *GrupoRotas) Handle(metodo, padrao string, h http.Handler) *Registro {
+	if metodo == "" || h == nil {
+		panic("roteador: método e handler são obrigatórios")
+	}
+	completo := juntar(g.prefixo, padrao)
+	for i := len(g.middlewares) - 1; i >= 0; i-- {
+		h = g.middlewares[i](h)
+	}
+
+	r := g.roteador
+	n := r.raiz.inserir(completo, dividir(completo))
+	if n.handlers == nil {
+		n.handlers = make(map[string]http.Handler)
+	}
+	if _, existe := n.handlers[metodo]; existe {
+		panic(fmt.Sprintf("roteador: rota %s %s registrada duas vezes", metodo, completo))
+	}
+	n.handlers[metodo] = h
+	rota := Rota{Metodo: metodo, Padrao: completo}
+	r.rotas = append(r.rotas, rota)
+	return &Registro{roteador: r, rota: rota}
+}
+
+// HandleFunc é Handle para funções
+func (g *GrupoRotas) HandleFunc(metodo, padrao string, f http.HandlerFunc) *Registro {
+	return g.Handle(metodo, padrao, f)
+}
+
+// Get registra um handler de GET (que também atende HEAD)
+func (g *GrupoRotas) Get(padrao string, f http.HandlerFunc) *Registro {
+	return g.Handle(http.MethodGet, padrao, f)
+}
+
+// Post registra um handler de POST
+func (g *GrupoRotas) Post(padrao string, f http.HandlerFunc) *Registro {
+	return g.Handle(http.MethodPost, padrao, f)
+}
+
+// Put registra um handler de PUT
+func (g *GrupoRotas) Put(padrao string, f http.HandlerFunc) *Registro {
+	return g.Handle(http.MethodPut, padrao, f)
+}
+
+// Patch registra um handler de PATCH
+func (g *GrupoRotas) Patch(padrao string, f http.HandlerFunc) *Registro {
+	return g.Handle(http.MethodPatch, padrao, f)
+}
+
+// Delete registra um handler de DELETE
+func (g *GrupoRotas) Delete(padrao string, f http.HandlerFunc) *Registro {
+	return g.Handle(http.MethodDelete, padrao, f)
+}
+
+// Documentar anexa doc à rota (substituindo a anterior) e devolve o
+// próprio registro
+func (rg *Registro) Documentar(doc Documentacao) *Registro {
+	r := rg.roteador
+	if r.docs == nil {
+		r.docs = make(map[Rota]Documentacao)
+	}
+	r.docs[rg.rota] = doc
+	return rg
+}
+
+// Rota retorna o método e o padrão completo registrados
+func (rg *Registro) Rota() Rota { return rg.rota }
+
+// ========================================
+// PARÂMETROS
+// ========================================
+
+type chaveRota struct{}
+
+type rotaEncontrada struct {
+	padrao     string
+	parametros map[string]string
+}
+
+// Parametro retorna o valor decodificado de {nome} na rota que atendeu
+// a requisição ("" se não existir). Em "/alunos/{nome}", a URL
+// "/alunos/Ana%2FMaria" dá "Ana/Maria".
+func Parametro(r *http.Request, nome string) string {
+	if rota, ok := r.Context().Value(chaveRota{}).(*rotaEncontrada); ok {
+		return rota.parametros[nome]
+	}
+	return ""
+}
+
+// Padrao retorna o padrão da rota que atendeu a requisição, como
+// "/api/alunos/{nome}" (útil para logs e métricas por rota)
+func Padrao(r *http.Request) string {
+	if rota, ok := r.Context().Value(chaveRota{}).(*rotaEncontrada); ok {
+		return rota.padrao
+	}
+	return ""
+}
+
+// ========================================
+// ÁRVORE DE ROTAS
+// ========================================
+
+// inserir cria (ou reaproveita) os nós do padrão e retorna o último
+func (n *no) inserir(padrao string, segmentos []string) *no {
+	atual := n
+	for i, seg := range segmentos {
+		nome, ehParametro, ehResto := lerSegmento(padrao, seg)
+		switch {
+		case ehResto:
+			if i != len(segmentos)-1 {
+				panic(fmt.Sprintf("roteador: %q: {%s...} só pode ser o último segmento", padrao, nome))
+			}
+			atual.resto = atual.filho(atual.resto, padrao, nome)
+			atual = atual.resto
+		case ehParametro:
+			atual.parametro = atual.filho(atual.parametro, padrao, nome)
+			atual = atual.parametro
+		default:
+			if atual.estaticos == nil {
+				atual.estaticos = make(map[string]*no)
+			}
+			if atual.estaticos[seg] == nil {
+				atual.estaticos[seg] = &no{}
+			}
+			atual = atual.estaticos[seg]
+		}
+	}
+	if atual.padrao != "" && atual.padrao != padrao {
+		panic(fmt.Sprintf("roteador: %q conflita com %q", padrao, atual.padrao))
+	}
+	atual.padrao = padrao
+	return atual
+}
+
+// filho reaproveita o nó de parâmetro existente, exigindo o mesmo nome
+func (n *no) filho(existente *no, padrao, nome string) *no {
+	if existente == nil {
+		return &no{nome: nome}
+	}
+	if existente.nome != nome {
+		panic(fmt.Sprintf("roteador: %q: parâmetro {%s} conflita com {%s} na mesma posição", padrao, nome, existente.nome))
+	}
+	return existente
+}
+
+// buscar percorre a árvore preferindo fixo > {parâmetro} > {resto...},
+// voltando atrás quando um ramo não leva a nenhuma rota. Os valores
+// dos parâmetros (ainda codificados) são acumulados em valores.
+func (n *no) buscar(segmentos []string, valores *[]string) *no {
+	if len(segmentos) == 0 {
+		if n.handlers != nil {
+			return n
+		}
+		if n.resto != nil && n.resto.handlers != nil { // {resto...} vazio
+			*valores = append(*valores, "")
+			return n.resto
+		}
+		return nil
+	}
+
+	seg, restantes := segmentos[0], segmentos[1:]
+	if filho := n.estaticos[desescapar(seg)]; filho != nil {
+		if achado := filho.buscar(restantes, valores); achado != nil {
+			return achado
+		}
+	}
+	if n.parametro != nil && seg != "" {
+		marca := len(*valores)
+		*valores = append(*valores, seg)
+		if achado := n.parametro.buscar(restantes, valores); achado != nil {
+			return achado
+		}
+		*valores = (*valores)[:marca]
+	}
+	if n.resto != nil && n.resto.handlers != nil {
+		*valores = append(*valores, strings.Join(segmentos, "/"))
+		return n.resto
+	}
+	return nil
+}
+
+// parametros associa os valores encontrados aos nomes do padrão
+func (n *no) parametros(valores []string) (map[string]string, bool) {
+	if len(valores) == 0 {
+		return nil, true
+	}
+	var nomes []string
+	for _, seg := range dividir(n.padrao) {
+		if nome, ehParametro, ehResto := lerSegmento(n.padrao, seg); ehParametro || ehResto {
+			nomes = append(nomes, nome)
+		}
+	}
+	parametros := make(map[string]string, len(nomes))
+	for i, nome := range nomes {
+		valor, err := url.PathUnescape(valores[i])
+		if err != nil {
+			return nil, false
+		}
+		parametros[nome] = valor
+	}
+	return parametros, true
+}
+
+// permitidos monta o Allow: os métodos registrados mais HEAD (atendido
+// pelo GET) e OPTIONS (atendido com 204), na ordem GET, HEAD, POST...
+func (n *no) permitidos() string {
+	metodos := make([]string, 0, len(n.handlers)+2)
+	for m := range n.handlers {
+		metodos = append(metodos, m)
+	}
+	if _, ok := n.handlers[http.MethodGet]; ok {
+		if _, ok := n.handlers[http.MethodHead]; !ok {
+			metodos = append(metodos, http.MethodHead)
+		}
+	}
+	if _, ok := n.handlers[http.MethodOptions]; !ok {
+		metodos = append(metodos, http.MethodOptions)
+	}
+	sort.Slice(metodos, func(i, j int) bool {
+		if oi, oj := ordemMetodo(metodos[i]), ordemMetodo(metodos[j]); oi != oj {
+			return oi < oj
+		}
+		return metodos[i] < metodos[j]
+	})
+	return strings.Join(metodos, ", ")
+}
+
+func ordemMetodo(m string) int {
+	ordem := []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions}
+	for i, o := range ordem {
+		if m == o {
+			return i
+		}
+	}
+	return len(ordem)
+}
+
+// lerSegmento reconhece "{nome}" e "{nome...}"
+func lerSegmento(padrao, seg string) (nome string, ehParametro, ehResto bool) {
+	if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+		if strings.ContainsAny(seg, "{}") {
+			panic(fmt.Sprintf("roteador: %q: segmento inválido %q", padrao, seg))
+		}
+		return "", false, false
+	}
+	nome = seg[1 : len(seg)-1]
+	if strings.HasSuffix(nome, "...") {
+		nome, ehResto = strings.TrimSuffix(nome, "..."), true
+	} else {
+		ehParametro = true
+	}
+	if nome == "" || strings.ContainsAny(nome, "{}/.") {
+		panic(fmt.Sprintf("roteador: %q: nome de parâmetro inválido em %q", padrao, seg))
+	}
+	return nome, ehParametro, ehResto
+}
+
+// dividir separa o caminho em segmentos, ignorando barras nas pontas
+func dividir(caminho string) []string {
+	caminho = strings.Trim(caminho, "/")
+	if caminho == "" {
+		return nil
+	}
+	return strings.Split(caminho, "/")
+}
+
+// desescapar decodifica um segmento fixo ("%C3%A9" → "é"); se o escape
+// for inválido, o segmento não casa com nenhum texto fixo
+func desescapar(seg string) string {
+	if v, err := url.PathUnescape(seg); err == nil {
+		return v
+	}
+	return "\x00"
+}
+
+// juntar concatena prefixo e padrão com uma única barra entre eles
+func juntar(prefixo, padrao string) string {
+	completo := strings.TrimSuffix(prefixo, "/") + "/" + strings.TrimPrefix(padrao, "/")
+	if completo != "/" {
+		completo = strings.TrimSuffix(completo, "/")
+	}
+	return completo
+}
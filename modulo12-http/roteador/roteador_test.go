@@ -0,0 +1,275 @@
+package roteador
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// responde escreve "rota|param=valor..." para conferir quem atendeu
+func responde(nome string, parametros ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		partes := []string{nome}
+		for _, p := range parametros {
+			partes = append(partes, p+"="+Parametro(r, p))
+		}
+		w.Write([]byte(strings.Join(partes, "|")))
+	}
+}
+
+func requisitar(h http.Handler, metodo, caminho string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(metodo, caminho, nil))
+	return rec
+}
+
+func TestRoteador_Casamento(t *testing.T) {
+	r := Novo()
+	r.Get("/", responde("raiz"))
+	r.Get("/api/alunos", responde("listar"))
+	r.Get("/api/alunos/novo", responde("novo"))
+	r.Get("/api/alunos/{nome}", responde("obter", "nome"))
+	r.Get("/api/alunos/{nome}/notas/{n}", responde("nota", "nome", "n"))
+	r.Get("/arquivos/{caminho...}", responde("arquivo", "caminho"))
+	r.Get("/api/{recurso}/ajuda", responde("ajuda", "recurso"))
+
+	testes := []struct {
+		caminho  string
+		esperado string
+	}{
+		{"/", "raiz"},
+		{"/api/alunos", "listar"},
+		{"/api/alunos/", "listar"},
+		{"/api/alunos/novo", "novo"},
+		{"/api/alunos/Ana", "obter|nome=Ana"},
+		{"/api/alunos/Ana%20Costa", "obter|nome=Ana Costa"},
+		{"/api/alunos/Ana%2FMaria", "obter|nome=Ana/Maria"},
+		{"/api/alunos/Ana/notas/2", "nota|nome=Ana|n=2"},
+		{"/arquivos/a/b/c.txt", "arquivo|caminho=a/b/c.txt"},
+		{"/arquivos", "arquivo|caminho="},
+		// fixo "alunos" não leva a "ajuda": volta atrás e tenta {recurso}
+		{"/api/alunos/ajuda", "obter|nome=ajuda"},
+		{"/api/turmas/ajuda", "ajuda|recurso=turmas"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.caminho, func(t *testing.T) {
+			rec := requisitar(r, "GET", tt.caminho)
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.esperado {
+				t.Errorf("GET %s = %d %q; esperado %q", tt.caminho, rec.Code, rec.Body.String(), tt.esperado)
+			}
+		})
+	}
+
+	for _, caminho := range []string{"/api", "/api/alunos/Ana/notas", "/outra"} {
+		if rec := requisitar(r, "GET", caminho); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d; esperado 404", caminho, rec.Code)
+		}
+	}
+}
+
+func TestRoteador_Metodos(t *testing.T) {
+	r := Novo()
+	r.Get("/alunos/{nome}", responde("obter"))
+	r.Delete("/alunos/{nome}", responde("remover"))
+	r.Put("/alunos/{nome}", responde("substituir"))
+
+	rec := requisitar(r, "PATCH", "/alunos/Ana")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("PATCH = %d; esperado 405", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, HEAD, PUT, DELETE, OPTIONS" {
+		t.Errorf("Allow = %q", allow)
+	}
+
+	if rec := requisitar(r, "HEAD", "/alunos/Ana"); rec.Code != http.StatusOK {
+		t.Errorf("HEAD = %d; esperado 200 (handler de GET)", rec.Code)
+	}
+	rec = requisitar(r, "OPTIONS", "/alunos/Ana")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Allow") != "GET, HEAD, PUT, DELETE, OPTIONS" {
+		t.Errorf("OPTIONS = %d, Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	r.MetodoNaoPermitido = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte("personalizado"))
+	})
+	rec = requisitar(r, "POST", "/alunos/Ana")
+	if rec.Body.String() != "personalizado" || rec.Header().Get("Allow") == "" {
+		t.Errorf("MetodoNaoPermitido personalizado: %q, Allow %q", rec.Body.String(), rec.Header().Get("Allow"))
+	}
+}
+
+func TestRoteador_GruposEMiddleware(t *testing.T) {
+	var ordem []string
+	marcar := func(nome string) Middleware {
+		return func(proximo http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ordem = append(ordem, nome)
+				proximo.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	r := Novo()
+	r.Usar(marcar("global"))
+	r.Get("/publico", responde("publico"))
+
+	api := r.Grupo("/api", marcar("api"))
+	api.Get("/alunos", responde("alunos"))
+	admin := api.Grupo("/admin/", marcar("admin"))
+	admin.Delete("/alunos/{nome}", responde("remover", "nome"))
+	api.Usar(marcar("tarde")) // só vale para as rotas seguintes
+	api.Get("/turmas", responde("turmas"))
+
+	testes := []struct {
+		metodo, caminho string
+		corpo           string
+		ordem           string
+	}{
+		{"GET", "/publico", "publico", "global"},
+		{"GET", "/api/alunos", "alunos", "global,api"},
+		{"DELETE", "/api/admin/alunos/Ana", "remover|nome=Ana", "global,api,admin"},
+		{"GET", "/api/turmas", "turmas", "global,api,tarde"},
+		{"GET", "/inexistente", "404 page not found\n", "global"},
+	}
+	for _, tt := range testes {
+		ordem = nil
+		rec := requisitar(r, tt.metodo, tt.caminho)
+		if rec.Body.String() != tt.corpo {
+			t.Errorf("%s %s: corpo %q; esperado %q", tt.metodo, tt.caminho, rec.Body.String(), tt.corpo)
+		}
+		if got := strings.Join(ordem, ","); got != tt.ordem {
+			t.Errorf("%s %s: middlewares %q; esperado %q", tt.metodo, tt.caminho, got, tt.ordem)
+		}
+	}
+}
+
+func TestRoteador_GlobaisMontadosUmaVez(t *testing.T) {
+	montagens := 0
+	contar := func(proximo http.Handler) http.Handler {
+		montagens++
+		return proximo
+	}
+	r := Novo()
+	r.Usar(contar)
+	r.Get("/", responde("raiz"))
+	for i := 0; i < 3; i++ {
+		requisitar(r, "GET", "/")
+	}
+	if montagens != 1 {
+		t.Errorf("middleware global montado %d vezes; esperado 1", montagens)
+	}
+}
+
+func TestRoteador_Padrao(t *testing.T) {
+	r := Novo()
+	var padrao string
+	r.Get("/api/alunos/{nome}", func(w http.ResponseWriter, req *http.Request) {
+		padrao = Padrao(req)
+	})
+	requisitar(r, "GET", "/api/alunos/Ana")
+	if padrao != "/api/alunos/{nome}" {
+		t.Errorf("Padrao = %q", padrao)
+	}
+}
+
+func TestRoteador_Conflitos(t *testing.T) {
+	testes := []struct {
+		nome      string
+		registrar func(r *Roteador)
+	}{
+		{"rota repetida", func(r *Roteador) {
+			r.Get("/a/{id}", responde("1"))
+			r.Get("/a/{id}", responde("2"))
+		}},
+		{"parâmetros com nomes diferentes", func(r *Roteador) {
+			r.Get("/a/{id}", responde("1"))
+			r.Delete("/a/{nome}", responde("2"))
+		}},
+		{"resto no meio", func(r *Roteador) {
+			r.Get("/a/{resto...}/b", responde("1"))
+		}},
+		{"segmento malformado", func(r *Roteador) {
+			r.Get("/a/x{id}", responde("1"))
+		}},
+		{"parâmetro sem nome", func(r *Roteador) {
+			r.Get("/a/{}", responde("1"))
+		}},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("esperava panic")
+				}
+			}()
+			tt.registrar(Novo())
+		})
+	}
+}
+
+func TestRoteador_Tabela(t *testing.T) {
+	r := Novo()
+	r.Post("/api/alunos", responde("criar"))
+	r.Get("/api/alunos", responde("listar"))
+	r.Grupo("/api").Delete("/alunos/{nome}", responde("remover"))
+
+	rotas := r.Rotas()
+	esperadas := []Rota{
+		{"GET", "/api/alunos"},
+		{"POST", "/api/alunos"},
+		{"DELETE", "/api/alunos/{nome}"},
+	}
+	if len(rotas) != len(esperadas) {
+		t.Fatalf("Rotas = %v", rotas)
+	}
+	for i := range esperadas {
+		if rotas[i] != esperadas[i] {
+			t.Errorf("Rotas[%d] = %v; esperado %v", i, rotas[i], esperadas[i])
+		}
+	}
+
+	var b strings.Builder
+	r.EscreverTabela(&b)
+	if !strings.Contains(b.String(), "DELETE  /api/alunos/{nome}") {
+		t.Errorf("tabela:\n%s", b.String())
+	}
+}
+
+func TestRoteador_Documentacao(t *testing.T) {
+	r := Novo()
+	doc := Documentacao{Resumo: "cria", Status: http.StatusCreated, Erros: []int{422}}
+	reg := r.Grupo("/api").Post("/alunos", responde("criar")).Documentar(doc)
+	r.Get("/api/alunos", responde("listar"))
+
+	if reg.Rota() != (Rota{"POST", "/api/alunos"}) {
+		t.Errorf("Rota() = %v", reg.Rota())
+	}
+	if got, ok := r.Documentacao(Rota{"POST", "/api/alunos"}); !ok || got.Resumo != "cria" || got.Status != 201 {
+		t.Errorf("Documentacao(POST) = %+v, %v", got, ok)
+	}
+	if _, ok := r.Documentacao(Rota{"GET", "/api/alunos"}); ok {
+		t.Error("rota sem Documentar tem documentação")
+	}
+}
+
+func TestRoteador_PadraoDe(t *testing.T) {
+	r := Novo()
+	r.Get("/api/alunos/{nome}", responde("obter"))
+	r.Get("/api/alunos/novo", responde("novo"))
+	r.Get("/arquivos/{caminho...}", responde("arquivo"))
+
+	testes := []struct{ url, padrao string }{
+		{"/api/alunos/Ana", "/api/alunos/{nome}"},
+		{"/api/alunos/novo/", "/api/alunos/novo"},
+		{"/arquivos/a/b.txt", "/arquivos/{caminho...}"},
+		{"/api/turmas", ""},
+	}
+	for _, tt := range testes {
+		// O método não importa: um DELETE também "é" da rota (e leva 405)
+		if got := r.PadraoDe(httptest.NewRequest("DELETE", tt.url, nil)); got != tt.padrao {
+			t.Errorf("PadraoDe(%s) = %q; esperado %q", tt.url, got, tt.padrao)
+		}
+	}
+}
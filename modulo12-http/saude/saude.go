@@ -0,0 +1,310 @@
+package saude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+PACKAGE SAUDE
+
+Dois endpoints para quem orquestra o processo (Kubernetes, balanceador
+de carga, systemd):
+
+    /healthz   vida: o processo responde? Se falhar, reinicie-o
+    /readyz    prontidão: pode receber tráfego agora? Se falhar, tire-o
+               do balanceador (sem reiniciar)
+
+Por isso só verificações marcadas com Vida entram no /healthz: um banco
+fora do ar deixa a instância não pronta, mas reiniciá-la não conserta
+nada.
+
+    s := saude.NovoRegistro(saude.Opcoes{})
+    s.Adicionar("auditoria", saude.Gravavel("/var/lib/notas"), saude.OpcoesVerificacao{})
+    s.Adicionar("fila", saude.Fila(func() int { return len(jobs) }, cap(jobs), 0.9),
+        saude.OpcoesVerificacao{Vida: true})
+    r.Get("/healthz", s.HandlerVida().ServeHTTP)
+    r.Get("/readyz", s.HandlerProntidao().ServeHTTP)
+
+    cfg.Prontidao = s // servidor.Executar: /readyz falha já no SIGTERM
+
+Cada verificação:
+  - roda em paralelo com as outras, com o seu próprio prazo
+  - tem o resultado guardado por Cache: um balanceador consultando a
+    cada segundo não vira uma consulta por segundo no banco
+  - roda uma vez só, mesmo com várias requisições esperando por ela;
+    se ignorar o context e travar, quem espera desiste no prazo e
+    nenhuma cópia nova é iniciada até ela voltar
+
+Resposta (200 ou 503):
+
+    {
+      "status": "falha",
+      "verificacoes": {
+        "auditoria": {"status": "ok", "duracao_ms": 0.4, ...},
+        "downstream": {"status": "falha", "erro": "prazo de 2s esgotado", ...}
+      }
+    }
+
+As mensagens de erro vão na resposta: não exponha estes endpoints para
+a internet se elas contiverem caminhos ou endereços internos.
+*/
+
+// Status de um relatório ou de uma verificação
+const (
+	StatusOK         = "ok"
+	StatusFalha      = "falha"
+	StatusDesligando = "desligando"
+)
+
+// Verificacao confere uma dependência; nil significa saudável.
+// Deve respeitar o ctx, que carrega o prazo.
+type Verificacao func(ctx context.Context) error
+
+// Opcoes são os padrões das verificações; os campos zerados usam os
+// valores indicados
+type Opcoes struct {
+	Timeout time.Duration // prazo de cada verificação (0 = 2s)
+	Cache   time.Duration // validade de um resultado (0 = 5s, negativo = sem cache)
+}
+
+// OpcoesVerificacao ajusta uma verificação; os campos zerados usam os
+// de Opcoes
+type OpcoesVerificacao struct {
+	Timeout time.Duration
+	Cache   time.Duration
+	// Vida inclui a verificação no /healthz (além do /readyz): use só
+	// para falhas que um reinício resolve
+	Vida bool
+}
+
+// Resultado é o estado de uma verificação
+type Resultado struct {
+	Status       string    `json:"status"`
+	Erro         string    `json:"erro,omitempty"`
+	DuracaoMs    float64   `json:"duracao_ms"`
+	VerificadoEm time.Time `json:"verificado_em"`
+	DoCache      bool      `json:"do_cache,omitempty"`
+}
+
+// Relatorio junta os resultados; Status é ok só se todos forem ok
+type Relatorio struct {
+	Status       string               `json:"status"`
+	Verificacoes map[string]Resultado `json:"verificacoes,omitempty"`
+}
+
+// OK informa se o relatório é saudável
+func (r Relatorio) OK() bool { return r.Status == StatusOK }
+
+// Registro guarda as verificações nomeadas. É seguro para uso
+// concorrente.
+type Registro struct {
+	opcoes      Opcoes
+	mu          sync.RWMutex
+	verificacao map[string]*estado
+	desligando  atomic.Bool
+}
+
+// estado é uma verificação com o último resultado e a execução em curso
+type estado struct {
+	f       Verificacao
+	timeout time.Duration
+	cache   time.Duration
+	vida    bool
+
+	mu      sync.Mutex
+	ultimo  Resultado
+	temUm   bool
+	rodando chan struct{} // fechado quando a execução atual termina
+}
+
+// NovoRegistro cria um registro vazio (pronto, sem verificações)
+func NovoRegistro(opcoes Opcoes) *Registro {
+	if opcoes.Timeout <= 0 {
+		opcoes.Timeout = 2 * time.Second
+	}
+	if opcoes.Cache == 0 {
+		opcoes.Cache = 5 * time.Second
+	}
+	return &Registro{opcoes: opcoes, verificacao: make(map[string]*estado)}
+}
+
+// Adicionar registra uma verificação. Nome vazio ou repetido e f nil
+// são erros de programação (panic), como rotas repetidas no roteador.
+func (s *Registro) Adicionar(nome string, f Verificacao, opcoes OpcoesVerificacao) {
+	if nome == "" || f == nil {
+		panic("saude: verificação sem nome ou sem função")
+	}
+	if opcoes.Timeout <= 0 {
+		opcoes.Timeout = s.opcoes.Timeout
+	}
+	if opcoes.Cache == 0 {
+		opcoes.Cache = s.opcoes.Cache
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, existe := s.verificacao[nome]; existe {
+		panic(fmt.Sprintf("saude: verificação %q registrada duas vezes", nome))
+	}
+	s.verificacao[nome] = &estado{f: f, timeout: opcoes.Timeout, cache: opcoes.Cache, vida: opcoes.Vida}
+}
+
+// IniciarDesligamento faz o /readyz falhar daqui em diante (o /healthz
+// continua ok: o processo está saindo, não travado). Implementa
+// servidor.Desligavel.
+func (s *Registro) IniciarDesligamento() {
+	s.desligando.Store(true)
+}
+
+// Vida roda as verificações marcadas com Vida
+func (s *Registro) Vida(ctx context.Context) Relatorio {
+	return s.verificar(ctx, true)
+}
+
+// Prontidao roda todas as verificações; durante o desligamento
+// responde StatusDesligando sem rodar nenhuma
+func (s *Registro) Prontidao(ctx context.Context) Relatorio {
+	if s.desligando.Load() {
+		return Relatorio{Status: StatusDesligando}
+	}
+	return s.verificar(ctx, false)
+}
+
+func (s *Registro) verificar(ctx context.Context, soVida bool) Relatorio {
+	s.mu.RLock()
+	selecionadas := make(map[string]*estado, len(s.verificacao))
+	for nome, e := range s.verificacao {
+		if !soVida || e.vida {
+			selecionadas[nome] = e
+		}
+	}
+	s.mu.RUnlock()
+
+	rel := Relatorio{Status: StatusOK, Verificacoes: make(map[string]Resultado, len(selecionadas))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for nome, e := range selecionadas {
+		wg.Add(1)
+		go func(nome string, e *estado) {
+			defer wg.Done()
+			r := e.resultado(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			rel.Verificacoes[nome] = r
+			if r.Status != StatusOK {
+				rel.Status = StatusFalha
+			}
+		}(nome, e)
+	}
+	wg.Wait()
+	return rel
+}
+
+// resultado devolve o último resultado se ainda valer; senão espera a
+// execução em curso (ou inicia uma) até o prazo
+func (e *estado) resultado(ctx context.Context) Resultado {
+	e.mu.Lock()
+	if e.temUm && e.cache > 0 && time.Since(e.ultimo.VerificadoEm) < e.cache {
+		r := e.ultimo
+		e.mu.Unlock()
+		r.DoCache = true
+		return r
+	}
+	if e.rodando == nil {
+		e.rodando = make(chan struct{})
+		go e.executar(e.rodando)
+	}
+	rodando := e.rodando
+	e.mu.Unlock()
+
+	prazo := time.NewTimer(e.timeout)
+	defer prazo.Stop()
+	inicio := time.Now()
+	select {
+	case <-rodando:
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.ultimo
+	case <-prazo.C:
+		return falha(inicio, fmt.Sprintf("prazo de %v esgotado", e.timeout))
+	case <-ctx.Done():
+		return falha(inicio, ctx.Err().Error())
+	}
+}
+
+// executar roda a verificação sem depender do context de quem pediu:
+// o resultado serve para todos que estiverem esperando
+func (e *estado) executar(fim chan struct{}) {
+	inicio := time.Now()
+	ctx, cancelar := context.WithTimeout(context.Background(), e.timeout)
+	defer cancelar()
+
+	err := chamar(ctx, e.f)
+	if err == nil && ctx.Err() != nil {
+		err = fmt.Errorf("prazo de %v esgotado", e.timeout)
+	}
+	r := Resultado{Status: StatusOK, DuracaoMs: milissegundos(time.Since(inicio)), VerificadoEm: time.Now()}
+	if err != nil {
+		r.Status = StatusFalha
+		r.Erro = err.Error()
+	}
+
+	e.mu.Lock()
+	e.ultimo, e.temUm = r, true
+	e.rodando = nil
+	e.mu.Unlock()
+	close(fim)
+}
+
+// chamar converte um panic da verificação em erro
+func chamar(ctx context.Context, f Verificacao) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return f(ctx)
+}
+
+func falha(inicio time.Time, erro string) Resultado {
+	return Resultado{Status: StatusFalha, Erro: erro, DuracaoMs: milissegundos(time.Since(inicio)), VerificadoEm: time.Now()}
+}
+
+func milissegundos(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// ========================================
+// HANDLERS
+// ========================================
+
+// HandlerVida responde o /healthz
+func (s *Registro) HandlerVida() http.Handler {
+	return handler(s.Vida)
+}
+
+// HandlerProntidao responde o /readyz
+func (s *Registro) HandlerProntidao() http.Handler {
+	return handler(s.Prontidao)
+}
+
+func handler(verificar func(context.Context) Relatorio) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rel := verificar(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		status := http.StatusOK
+		if !rel.OK() {
+			status = http.StatusServiceUnavailable
+		}
+		w.WriteHeader(status)
+		if r.Method != http.MethodHead {
+			json.NewEncoder(w).Encode(rel)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package saude
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func TestRegistro_VidaEProntidao(t *testing.T) {
+	s := NovoRegistro(Opcoes{})
+	s.Adicionar("processo", ok, OpcoesVerificacao{Vida: true})
+	s.Adicionar("banco", func(ctx context.Context) error { return errors.New("recusou a conexão") }, OpcoesVerificacao{})
+
+	vida := s.Vida(context.Background())
+	if !vida.OK() || len(vida.Verificacoes) != 1 {
+		t.Errorf("vida = %+v (o banco não entra no /healthz)", vida)
+	}
+	pronto := s.Prontidao(context.Background())
+	if pronto.Status != StatusFalha || pronto.Verificacoes["banco"].Erro != "recusou a conexão" ||
+		pronto.Verificacoes["processo"].Status != StatusOK {
+		t.Errorf("prontidão = %+v", pronto)
+	}
+
+	s.IniciarDesligamento()
+	if got := s.Prontidao(context.Background()); got.Status != StatusDesligando || got.Verificacoes != nil {
+		t.Errorf("prontidão no desligamento = %+v", got)
+	}
+	if !s.Vida(context.Background()).OK() {
+		t.Error("o /healthz não deve falhar no desligamento")
+	}
+}
+
+func TestRegistro_Paralelo(t *testing.T) {
+	s := NovoRegistro(Opcoes{})
+	for _, nome := range []string{"a", "b", "c"} {
+		s.Adicionar(nome, func(ctx context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}, OpcoesVerificacao{})
+	}
+	inicio := time.Now()
+	if rel := s.Prontidao(context.Background()); !rel.OK() {
+		t.Fatalf("%+v", rel)
+	}
+	if d := time.Since(inicio); d > 250*time.Millisecond {
+		t.Errorf("levou %v: as verificações deveriam rodar em paralelo", d)
+	}
+}
+
+func TestRegistro_Timeout(t *testing.T) {
+	testes := []struct {
+		nome string
+		f    Verificacao
+	}{
+		{"respeita o ctx", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		{"ignora o ctx", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+		{"panic", func(ctx context.Context) error { panic("quebrou") }},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			s := NovoRegistro(Opcoes{Timeout: 50 * time.Millisecond})
+			s.Adicionar("x", tt.f, OpcoesVerificacao{})
+			inicio := time.Now()
+			r := s.Prontidao(context.Background()).Verificacoes["x"]
+			if r.Status != StatusFalha || r.Erro == "" {
+				t.Errorf("resultado = %+v", r)
+			}
+			if d := time.Since(inicio); d > 500*time.Millisecond {
+				t.Errorf("esperou %v além do prazo", d)
+			}
+		})
+	}
+}
+
+func TestRegistro_Cache(t *testing.T) {
+	var lentas, semCache atomic.Int32
+	s := NovoRegistro(Opcoes{Cache: time.Hour})
+	s.Adicionar("lenta", func(ctx context.Context) error {
+		lentas.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, OpcoesVerificacao{})
+	s.Adicionar("sem_cache", func(ctx context.Context) error { semCache.Add(1); return nil }, OpcoesVerificacao{Cache: -1})
+
+	// Requisições simultâneas compartilham a mesma execução
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Prontidao(context.Background())
+		}()
+	}
+	wg.Wait()
+	antes := semCache.Load()
+	r := s.Prontidao(context.Background())
+	if !r.Verificacoes["lenta"].DoCache || r.Verificacoes["sem_cache"].DoCache {
+		t.Errorf("cache = %+v", r.Verificacoes)
+	}
+	if n := lentas.Load(); n != 1 {
+		t.Errorf("a verificação lenta rodou %d vezes", n)
+	}
+	if semCache.Load() != antes+1 {
+		t.Error("sem cache, cada consulta deveria rodar a verificação")
+	}
+}
+
+func TestRegistro_Panics(t *testing.T) {
+	testes := []struct {
+		nome string
+		f    func(s *Registro)
+	}{
+		{"sem nome", func(s *Registro) { s.Adicionar("", ok, OpcoesVerificacao{}) }},
+		{"sem função", func(s *Registro) { s.Adicionar("x", nil, OpcoesVerificacao{}) }},
+		{"repetida", func(s *Registro) {
+			s.Adicionar("x", ok, OpcoesVerificacao{})
+			s.Adicionar("x", ok, OpcoesVerificacao{})
+		}},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("esperado panic")
+				}
+			}()
+			tt.f(NovoRegistro(Opcoes{}))
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	s := NovoRegistro(Opcoes{})
+	falhar := atomic.Bool{}
+	s.Adicionar("dependencia", func(ctx context.Context) error {
+		if falhar.Load() {
+			return errors.New("fora do ar")
+		}
+		return nil
+	}, OpcoesVerificacao{Cache: -1})
+
+	testes := []struct {
+		nome    string
+		preparo func()
+		h       http.Handler
+		status  int
+		estado  string
+	}{
+		{"pronto", func() {}, s.HandlerProntidao(), http.StatusOK, StatusOK},
+		{"dependência fora", func() { falhar.Store(true) }, s.HandlerProntidao(), http.StatusServiceUnavailable, StatusFalha},
+		{"vida ignora a dependência", func() {}, s.HandlerVida(), http.StatusOK, StatusOK},
+		{"desligando", s.IniciarDesligamento, s.HandlerProntidao(), http.StatusServiceUnavailable, StatusDesligando},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			tt.preparo()
+			w := httptest.NewRecorder()
+			tt.h.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+			var rel Relatorio
+			if err := json.Unmarshal(w.Body.Bytes(), &rel); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.status || rel.Status != tt.estado || w.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("status %d, relatório %+v, cabeçalhos %v", w.Code, rel, w.Header())
+			}
+		})
+	}
+}
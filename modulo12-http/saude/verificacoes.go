@@ -0,0 +1,77 @@
+package saude
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// ========================================
+// VERIFICAÇÕES PRONTAS
+// ========================================
+
+// Gravavel confere que dá para criar, escrever e apagar um arquivo em
+// dir (disco cheio, permissão, sistema de arquivos montado só leitura)
+func Gravavel(dir string) Verificacao {
+	return func(ctx context.Context) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		arquivo, err := os.CreateTemp(dir, ".saude-*")
+		if err != nil {
+			return fmt.Errorf("gravar em %s: %w", dir, err)
+		}
+		defer os.Remove(arquivo.Name())
+		_, err = arquivo.Write([]byte("ok"))
+		if errFechar := arquivo.Close(); err == nil {
+			err = errFechar
+		}
+		if err != nil {
+			return fmt.Errorf("gravar em %s: %w", dir, err)
+		}
+		return nil
+	}
+}
+
+// Alcancavel faz um GET em url e exige status 2xx ou 3xx. cliente nil
+// usa o http.DefaultClient; o prazo vem do ctx.
+func Alcancavel(cliente *http.Client, url string) Verificacao {
+	if cliente == nil {
+		cliente = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := cliente.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // devolve a conexão ao pool
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// Fila falha quando a fila passa de maximo (0 a 1) da capacidade: os
+// workers não estão dando conta. Para um canal:
+//
+//	saude.Fila(func() int { return len(jobs) }, cap(jobs), 0.9)
+func Fila(tamanho func() int, capacidade int, maximo float64) Verificacao {
+	if capacidade <= 0 || maximo <= 0 || maximo > 1 {
+		panic("saude: Fila precisa de capacidade > 0 e 0 < maximo <= 1")
+	}
+	return func(ctx context.Context) error {
+		n := tamanho()
+		if float64(n) >= maximo*float64(capacidade) {
+			return fmt.Errorf("fila saturada: %d de %d (limite %.0f%%)", n, capacidade, maximo*100)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package saude
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGravavel(t *testing.T) {
+	dir := t.TempDir()
+	if err := Gravavel(dir)(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sobras, _ := os.ReadDir(dir); len(sobras) != 0 {
+		t.Errorf("arquivos deixados para trás: %v", sobras)
+	}
+	if err := Gravavel(filepath.Join(dir, "nao", "existe"))(context.Background()); err == nil {
+		t.Error("diretório inexistente deveria falhar")
+	}
+}
+
+func TestAlcancavel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lento":
+			time.Sleep(200 * time.Millisecond)
+		case "/fora":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	testes := []struct {
+		caminho string
+		contem  string
+	}{
+		{"/ok", ""},
+		{"/fora", "status 503"},
+		{"/lento", "deadline"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.caminho, func(t *testing.T) {
+			ctx, cancelar := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancelar()
+			err := Alcancavel(srv.Client(), srv.URL+tt.caminho)(ctx)
+			if tt.contem == "" && err != nil || tt.contem != "" && (err == nil || !strings.Contains(err.Error(), tt.contem)) {
+				t.Errorf("erro = %v; esperado com %q", err, tt.contem)
+			}
+		})
+	}
+}
+
+func TestFila(t *testing.T) {
+	jobs := make(chan int, 10)
+	verificar := Fila(func() int { return len(jobs) }, cap(jobs), 0.8)
+	for i := 0; i < 7; i++ {
+		jobs <- i
+	}
+	if err := verificar(context.Background()); err != nil {
+		t.Errorf("7 de 10: %v", err)
+	}
+	jobs <- 7
+	if err := verificar(context.Background()); err == nil || !strings.Contains(err.Error(), "8 de 10") {
+		t.Errorf("8 de 10: %v", err)
+	}
+}
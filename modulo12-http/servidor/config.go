@@ -0,0 +1,303 @@
+package servidor
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+CONFIGURAÇÃO
+
+Cada opção pode vir de quatro lugares; o de baixo vence o de cima:
+
+    1. padrão            ConfigPadrao()
+    2. arquivo JSON      -config servidor.json   (ou NOTAS_CONFIG)
+    3. variável          NOTAS_TIMEOUT_ESCRITA=30s
+    4. flag              -timeout-escrita 30s
+
+O prefixo das variáveis ("NOTAS") é escolhido por quem chama:
+
+    opcoes := servidor.RegistrarFlags(flag.CommandLine, "NOTAS")
+    flag.Parse()
+    cfg, err := opcoes.Config()
+
+Arquivo (as chaves são os nomes das flags com "_" no lugar de "-"):
+
+    {
+      "endereco": "unix:/run/notas.sock",
+      "timeout_escrita": "30s",
+      "max_conexoes": 500
+    }
+
+ENDEREÇOS:
+    ":8080", "127.0.0.1:8080"   TCP
+    "unix:/run/notas.sock"      socket Unix
+    "systemd"                   socket já aberto pelo systemd (LISTEN_FDS)
+    "fd:3"                      descritor herdado de quem iniciou o processo
+*/
+
+// Config reúne endereço, timeouts e limites do servidor
+type Config struct {
+	Endereco string `json:"endereco"`
+
+	// TimeoutCabecalho limita a leitura dos cabeçalhos (protege contra
+	// clientes lentos de propósito, o ataque "slowloris")
+	TimeoutCabecalho Duracao `json:"timeout_cabecalho"`
+	// TimeoutLeitura limita a leitura da requisição inteira, com o corpo
+	TimeoutLeitura Duracao `json:"timeout_leitura"`
+	// TimeoutEscrita limita o tempo até o fim da resposta
+	TimeoutEscrita Duracao `json:"timeout_escrita"`
+	// TimeoutOcioso fecha conexões keep-alive paradas
+	TimeoutOcioso Duracao `json:"timeout_ocioso"`
+	// TimeoutDesligamento é quanto esperar as requisições em andamento
+	// depois de SIGINT/SIGTERM antes de fechar tudo à força
+	TimeoutDesligamento Duracao `json:"timeout_desligamento"`
+	// EsperaDesligamento é quanto continuar atendendo depois do sinal,
+	// já avisando Prontidao, para o balanceador de carga tirar a
+	// instância da lista antes de o listener fechar
+	EsperaDesligamento Duracao `json:"espera_desligamento"`
+
+	// MaxCabecalho é o tamanho máximo dos cabeçalhos, em bytes
+	MaxCabecalho int `json:"max_cabecalho"`
+	// MaxConexoes limita as conexões simultâneas (0 = sem limite)
+	MaxConexoes int `json:"max_conexoes"`
+
+	// Listener já aberto (testes, socket activation feita por fora).
+	// Se definido, Endereco é ignorado.
+	Listener net.Listener `json:"-"`
+	// Prontidao é avisada assim que chega o sinal (ex.: *saude.Registro,
+	// que passa a responder 503 no /readyz)
+	Prontidao Desligavel `json:"-"`
+}
+
+// Desligavel recebe o aviso de que o desligamento começou
+type Desligavel interface {
+	IniciarDesligamento()
+}
+
+// ConfigPadrao tem valores seguros para uma API JSON
+func ConfigPadrao() Config {
+	return Config{
+		Endereco:            ":8080",
+		TimeoutCabecalho:    Duracao(5 * time.Second),
+		TimeoutLeitura:      Duracao(15 * time.Second),
+		TimeoutEscrita:      Duracao(30 * time.Second),
+		TimeoutOcioso:       Duracao(2 * time.Minute),
+		TimeoutDesligamento: Duracao(20 * time.Second),
+		MaxCabecalho:        1 << 20, // 1 MB, o mesmo padrão do net/http
+	}
+}
+
+// Validar confere valores negativos e o formato do endereço
+func (c Config) Validar() error {
+	var problemas []error
+	if c.Listener == nil && strings.TrimSpace(c.Endereco) == "" {
+		problemas = append(problemas, errors.New("endereço vazio"))
+	}
+	if strings.HasPrefix(c.Endereco, "fd:") {
+		if n, err := strconv.Atoi(strings.TrimPrefix(c.Endereco, "fd:")); err != nil || n < 3 {
+			problemas = append(problemas, fmt.Errorf("endereço %q: descritor deve ser um número >= 3", c.Endereco))
+		}
+	}
+	duracoes := []struct {
+		nome  string
+		valor Duracao
+	}{
+		{"timeout-cabecalho", c.TimeoutCabecalho},
+		{"timeout-leitura", c.TimeoutLeitura},
+		{"timeout-escrita", c.TimeoutEscrita},
+		{"timeout-ocioso", c.TimeoutOcioso},
+		{"timeout-desligamento", c.TimeoutDesligamento},
+		{"espera-desligamento", c.EsperaDesligamento},
+	}
+	for _, d := range duracoes {
+		if d.valor < 0 {
+			problemas = append(problemas, fmt.Errorf("%s negativo: %v", d.nome, d.valor))
+		}
+	}
+	if c.MaxCabecalho < 0 {
+		problemas = append(problemas, fmt.Errorf("max-cabecalho negativo: %d", c.MaxCabecalho))
+	}
+	if c.MaxConexoes < 0 {
+		problemas = append(problemas, fmt.Errorf("max-conexoes negativo: %d", c.MaxConexoes))
+	}
+	return errors.Join(problemas...)
+}
+
+// LerArquivoConfig aplica um arquivo JSON sobre c. Chaves
+// desconhecidas são erro: um "timout_escrita" digitado errado não
+// pode passar em silêncio.
+func (c *Config) LerArquivoConfig(caminho string) error {
+	arquivo, err := os.Open(caminho)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer arquivo.Close()
+
+	dec := json.NewDecoder(arquivo)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("config %s: %w", caminho, err)
+	}
+	return nil
+}
+
+// ========================================
+// DURAÇÃO LEGÍVEL
+// ========================================
+
+// Duracao é um time.Duration escrito como "30s" ou "1m30s" no JSON e
+// nas flags (time.Duration puro viraria nanossegundos no JSON)
+type Duracao time.Duration
+
+// String implementa flag.Value
+func (d Duracao) String() string {
+	return time.Duration(d).String()
+}
+
+// Set implementa flag.Value
+func (d *Duracao) Set(texto string) error {
+	v, err := time.ParseDuration(strings.TrimSpace(texto))
+	if err != nil {
+		return fmt.Errorf("duração inválida %q (use 500ms, 30s, 2m...)", texto)
+	}
+	*d = Duracao(v)
+	return nil
+}
+
+// MarshalJSON grava a duração como texto
+func (d Duracao) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON aceita "30s" ou um número de segundos
+func (d *Duracao) UnmarshalJSON(dados []byte) error {
+	var texto string
+	if err := json.Unmarshal(dados, &texto); err == nil {
+		return d.Set(texto)
+	}
+	var segundos float64
+	if err := json.Unmarshal(dados, &segundos); err != nil {
+		return fmt.Errorf("duração inválida %s", dados)
+	}
+	*d = Duracao(segundos * float64(time.Second))
+	return nil
+}
+
+// ========================================
+// FLAGS E VARIÁVEIS DE AMBIENTE
+// ========================================
+
+// OpcoesLinha liga a Config às flags e variáveis de ambiente
+type OpcoesLinha struct {
+	fs      *flag.FlagSet
+	prefixo string
+	arquivo string
+	flags   Config // valores recebidos nas flags
+}
+
+// campo descreve uma opção: nome da flag, destino e ajuda
+type campo struct {
+	nome  string
+	valor flag.Value
+	uso   string
+}
+
+func campos(c *Config) []campo {
+	return []campo{
+		{"endereco", (*valorTexto)(&c.Endereco), "endereço: :8080, unix:/caminho.sock, systemd ou fd:N"},
+		{"timeout-cabecalho", &c.TimeoutCabecalho, "tempo máximo para ler os cabeçalhos"},
+		{"timeout-leitura", &c.TimeoutLeitura, "tempo máximo para ler a requisição"},
+		{"timeout-escrita", &c.TimeoutEscrita, "tempo máximo para escrever a resposta"},
+		{"timeout-ocioso", &c.TimeoutOcioso, "tempo até fechar uma conexão keep-alive parada"},
+		{"timeout-desligamento", &c.TimeoutDesligamento, "espera pelas requisições em andamento ao desligar"},
+		{"espera-desligamento", &c.EsperaDesligamento, "tempo atendendo após o sinal, com /readyz em 503, antes de fechar o listener"},
+		{"max-cabecalho", (*valorInteiro)(&c.MaxCabecalho), "tamanho máximo dos cabeçalhos, em bytes"},
+		{"max-conexoes", (*valorInteiro)(&c.MaxConexoes), "conexões simultâneas (0 = sem limite)"},
+	}
+}
+
+// RegistrarFlags cria -config, -endereco, -timeout-* e -max-* em fs.
+// prefixoEnv define as variáveis: "NOTAS" lê NOTAS_ENDERECO,
+// NOTAS_TIMEOUT_ESCRITA, NOTAS_CONFIG...
+func RegistrarFlags(fs *flag.FlagSet, prefixoEnv string) *OpcoesLinha {
+	o := &OpcoesLinha{fs: fs, prefixo: prefixoEnv, flags: ConfigPadrao()}
+	fs.StringVar(&o.arquivo, "config", "", "arquivo de configuração JSON")
+	for _, c := range campos(&o.flags) {
+		fs.Var(c.valor, c.nome, c.uso)
+	}
+	return o
+}
+
+// Config monta a configuração final (chame depois de fs.Parse)
+func (o *OpcoesLinha) Config() (Config, error) {
+	cfg := ConfigPadrao()
+
+	definidas := make(map[string]bool)
+	o.fs.Visit(func(f *flag.Flag) { definidas[f.Name] = true })
+
+	arquivo := o.arquivo
+	if !definidas["config"] {
+		arquivo = os.Getenv(o.variavel("config"))
+	}
+	if arquivo != "" {
+		if err := cfg.LerArquivoConfig(arquivo); err != nil {
+			return Config{}, err
+		}
+	}
+
+	destinos := campos(&cfg)
+	for _, c := range destinos {
+		nomeVar := o.variavel(c.nome)
+		if texto, ok := os.LookupEnv(nomeVar); ok {
+			if err := c.valor.Set(texto); err != nil {
+				return Config{}, fmt.Errorf("variável %s: %w", nomeVar, err)
+			}
+		}
+	}
+
+	for i, c := range campos(&o.flags) {
+		if definidas[c.nome] {
+			destinos[i].valor.Set(c.valor.String())
+		}
+	}
+
+	if err := cfg.Validar(); err != nil {
+		return Config{}, fmt.Errorf("config: %w", err)
+	}
+	return cfg, nil
+}
+
+// variavel converte "timeout-escrita" em "NOTAS_TIMEOUT_ESCRITA"
+func (o *OpcoesLinha) variavel(nome string) string {
+	nome = strings.ToUpper(strings.ReplaceAll(nome, "-", "_"))
+	if o.prefixo == "" {
+		return nome
+	}
+	return strings.ToUpper(o.prefixo) + "_" + nome
+}
+
+type valorTexto string
+
+func (v *valorTexto) String() string     { return string(*v) }
+func (v *valorTexto) Set(s string) error { *v = valorTexto(s); return nil }
+
+type valorInteiro int
+
+func (v *valorInteiro) String() string { return strconv.Itoa(int(*v)) }
+
+func (v *valorInteiro) Set(s string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("número inteiro inválido %q", s)
+	}
+	*v = valorInteiro(n)
+	return nil
+}
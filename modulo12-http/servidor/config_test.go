@@ -0,0 +1,138 @@
+package servidor
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Precedencia(t *testing.T) {
+	dir := t.TempDir()
+	arquivo := filepath.Join(dir, "servidor.json")
+	os.WriteFile(arquivo, []byte(`{
+		"endereco": ":9000",
+		"timeout_escrita": "45s",
+		"timeout_ocioso": 90,
+		"max_conexoes": 10
+	}`), 0o644)
+
+	testes := []struct {
+		nome      string
+		env       map[string]string
+		args      []string
+		verificar func(t *testing.T, c Config)
+	}{
+		{"só padrão", nil, nil, func(t *testing.T, c Config) {
+			if c != ConfigPadrao() {
+				t.Errorf("config = %+v", c)
+			}
+		}},
+		{"arquivo pela flag", nil, []string{"-config", arquivo}, func(t *testing.T, c Config) {
+			if c.Endereco != ":9000" || c.TimeoutEscrita != Duracao(45*time.Second) ||
+				c.TimeoutOcioso != Duracao(90*time.Second) || c.MaxConexoes != 10 {
+				t.Errorf("config = %+v", c)
+			}
+			if c.TimeoutCabecalho != ConfigPadrao().TimeoutCabecalho {
+				t.Error("campo ausente no arquivo deve manter o padrão")
+			}
+		}},
+		{"variável vence arquivo", map[string]string{"NOTAS_CONFIG": arquivo, "NOTAS_ENDERECO": ":7000"}, nil, func(t *testing.T, c Config) {
+			if c.Endereco != ":7000" || c.MaxConexoes != 10 {
+				t.Errorf("config = %+v", c)
+			}
+		}},
+		{"flag vence variável", map[string]string{"NOTAS_ENDERECO": ":7000", "NOTAS_TIMEOUT_ESCRITA": "1m"},
+			[]string{"-endereco", "unix:/tmp/x.sock"}, func(t *testing.T, c Config) {
+				if c.Endereco != "unix:/tmp/x.sock" || c.TimeoutEscrita != Duracao(time.Minute) {
+					t.Errorf("config = %+v", c)
+				}
+			}},
+		{"flag com valor padrão também vence", map[string]string{"NOTAS_MAX_CONEXOES": "50"},
+			[]string{"-max-conexoes", "0"}, func(t *testing.T, c Config) {
+				if c.MaxConexoes != 0 {
+					t.Errorf("MaxConexoes = %d", c.MaxConexoes)
+				}
+			}},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fs := flag.NewFlagSet("teste", flag.ContinueOnError)
+			opcoes := RegistrarFlags(fs, "NOTAS")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			c, err := opcoes.Config()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.verificar(t, c)
+		})
+	}
+}
+
+func TestConfig_Erros(t *testing.T) {
+	dir := t.TempDir()
+	desconhecido := filepath.Join(dir, "errado.json")
+	os.WriteFile(desconhecido, []byte(`{"timout_escrita": "5s"}`), 0o644)
+
+	testes := []struct {
+		nome   string
+		env    map[string]string
+		args   []string
+		contem string
+	}{
+		{"chave desconhecida", nil, []string{"-config", desconhecido}, "timout_escrita"},
+		{"arquivo inexistente", nil, []string{"-config", filepath.Join(dir, "nao.json")}, "config"},
+		{"variável inválida", map[string]string{"APP_TIMEOUT_LEITURA": "rápido"}, nil, "APP_TIMEOUT_LEITURA"},
+		{"negativo", nil, []string{"-timeout-escrita", "-1s"}, "timeout-escrita negativo"},
+		{"espera negativa", nil, []string{"-espera-desligamento", "-5s"}, "espera-desligamento negativo"},
+		{"descritor inválido", nil, []string{"-endereco", "fd:1"}, "descritor"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fs := flag.NewFlagSet("teste", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			opcoes := RegistrarFlags(fs, "app")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			_, err := opcoes.Config()
+			if err == nil || !strings.Contains(err.Error(), tt.contem) {
+				t.Errorf("erro = %v; esperado com %q", err, tt.contem)
+			}
+		})
+	}
+
+	fs := flag.NewFlagSet("teste", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegistrarFlags(fs, "app")
+	if err := fs.Parse([]string{"-timeout-ocioso", "dez"}); err == nil {
+		t.Error("flag com duração inválida deveria falhar no Parse")
+	}
+}
+
+func TestDuracao_JSON(t *testing.T) {
+	d := Duracao(90 * time.Second)
+	dados, _ := json.Marshal(d)
+	if string(dados) != `"1m30s"` {
+		t.Errorf("Marshal = %s", dados)
+	}
+	var lido Duracao
+	if err := json.Unmarshal(dados, &lido); err != nil || lido != d {
+		t.Errorf("Unmarshal = %v, %v", lido, err)
+	}
+	if err := json.Unmarshal([]byte(`"sempre"`), &lido); err == nil {
+		t.Error("duração inválida deveria falhar")
+	}
+}
@@ -0,0 +1,267 @@
+package servidor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+PACKAGE SERVIDOR
+
+Substitui o log.Fatal(http.ListenAndServe(":8080", nil)) de
+01_servidor_basico.go, que tem três problemas:
+
+1. Porta fixa e nenhum timeout: um cliente lento segura a conexão
+   para sempre
+2. Ctrl-C (SIGINT) ou o "docker stop" (SIGTERM) matam o processo no
+   meio das requisições em andamento
+3. Só escuta em TCP
+
+Com o package:
+
+    cfg, _ := opcoes.Config()
+    err := servidor.Executar(context.Background(), cfg, handler, logger)
+
+DESLIGAMENTO GRACIOSO (veja modulo14-context):
+    SIGINT/SIGTERM
+      → avisa cfg.Prontidao (o /readyz passa a falhar)
+      → continua atendendo por EsperaDesligamento (o balanceador
+        percebe e para de mandar tráfego)
+      → para de aceitar conexões (o socket é fechado)
+      → espera as requisições em andamento por até TimeoutDesligamento
+      → se o prazo acabar, fecha as conexões à força (ErrDesligamentoForcado)
+Um segundo Ctrl-C durante a espera encerra o processo na hora.
+
+O contexto das requisições NÃO é cancelado pelo sinal: elas podem
+terminar normalmente. Ele só é cancelado se o prazo acabar.
+*/
+
+// ErrDesligamentoForcado indica que o prazo de desligamento acabou com
+// requisições ainda em andamento (que foram interrompidas)
+var ErrDesligamentoForcado = errors.New("desligamento forçado: requisições em andamento interrompidas")
+
+// Executar atende h até ctx ser cancelado ou o processo receber
+// SIGINT/SIGTERM, e então desliga com calma. Retorna nil num
+// desligamento limpo.
+func Executar(ctx context.Context, cfg Config, h http.Handler, logger *log.Logger) error {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if err := cfg.Validar(); err != nil {
+		return fmt.Errorf("servidor: %w", err)
+	}
+
+	ln, err := Escutar(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.MaxConexoes > 0 {
+		ln = limitarConexoes(ln, cfg.MaxConexoes)
+	}
+
+	// Contexto base das requisições: independente do sinal, cancelado
+	// só se o desligamento passar do prazo
+	base, cancelarRequisicoes := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelarRequisicoes()
+
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: time.Duration(cfg.TimeoutCabecalho),
+		ReadTimeout:       time.Duration(cfg.TimeoutLeitura),
+		WriteTimeout:      time.Duration(cfg.TimeoutEscrita),
+		IdleTimeout:       time.Duration(cfg.TimeoutOcioso),
+		MaxHeaderBytes:    cfg.MaxCabecalho,
+		ErrorLog:          logger,
+		BaseContext:       func(net.Listener) context.Context { return base },
+	}
+
+	sinal, pararSinais := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer pararSinais()
+
+	falha := make(chan error, 1)
+	go func() {
+		falha <- srv.Serve(ln)
+	}()
+	logger.Printf("servidor escutando em %s", descreverEndereco(ln.Addr()))
+
+	select {
+	case err := <-falha:
+		// Serve só retorna sozinho se o listener falhar
+		return fmt.Errorf("servidor: %w", err)
+	case <-sinal.Done():
+	}
+	// A partir daqui um novo Ctrl-C volta ao comportamento padrão (encerrar)
+	pararSinais()
+
+	if cfg.Prontidao != nil {
+		cfg.Prontidao.IniciarDesligamento()
+	}
+	if espera := time.Duration(cfg.EsperaDesligamento); espera > 0 {
+		logger.Printf("desligando: atendendo por mais %v enquanto o tráfego é drenado", espera)
+		time.Sleep(espera)
+	}
+
+	prazo := time.Duration(cfg.TimeoutDesligamento)
+	logger.Printf("desligando: aguardando requisições em andamento (até %v)", prazo)
+	ctxDesligar := context.Background()
+	if prazo > 0 {
+		var cancelar context.CancelFunc
+		ctxDesligar, cancelar = context.WithTimeout(ctxDesligar, prazo)
+		defer cancelar()
+	}
+
+	if err := srv.Shutdown(ctxDesligar); err != nil {
+		// Prazo esgotado: avisa os handlers e derruba as conexões
+		cancelarRequisicoes()
+		srv.Close()
+		return ErrDesligamentoForcado
+	}
+	logger.Printf("servidor desligado")
+	return nil
+}
+
+// ========================================
+// LISTENERS
+// ========================================
+
+// Escutar abre o listener descrito em cfg (ou devolve cfg.Listener)
+func Escutar(cfg Config) (net.Listener, error) {
+	if cfg.Listener != nil {
+		return cfg.Listener, nil
+	}
+	endereco := strings.TrimSpace(cfg.Endereco)
+	switch {
+	case strings.HasPrefix(endereco, "unix:"):
+		return escutarUnix(strings.TrimPrefix(endereco, "unix:"))
+	case endereco == "systemd":
+		return escutarSystemd()
+	case strings.HasPrefix(endereco, "fd:"):
+		fd, err := strconv.Atoi(strings.TrimPrefix(endereco, "fd:"))
+		if err != nil || fd < 3 {
+			return nil, fmt.Errorf("escutar %s: descritor inválido", endereco)
+		}
+		return escutarDescritor(uintptr(fd), endereco)
+	default:
+		ln, err := net.Listen("tcp", endereco)
+		if err != nil {
+			return nil, fmt.Errorf("escutar %s: %w", endereco, err)
+		}
+		return ln, nil
+	}
+}
+
+// escutarUnix cria o socket, removendo um arquivo antigo que tenha
+// sobrado de um processo que morreu sem apagá-lo
+func escutarUnix(caminho string) (net.Listener, error) {
+	if info, err := os.Lstat(caminho); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("escutar unix:%s: o arquivo existe e não é um socket", caminho)
+		}
+		if conn, err := net.Dial("unix", caminho); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("escutar unix:%s: outro processo já está atendendo", caminho)
+		}
+		os.Remove(caminho)
+	}
+	ln, err := net.Listen("unix", caminho)
+	if err != nil {
+		return nil, fmt.Errorf("escutar unix:%s: %w", caminho, err)
+	}
+	// O socket é apagado quando o listener fecha (padrão do net.UnixListener)
+	return ln, nil
+}
+
+// escutarSystemd usa o primeiro socket passado pelo systemd.
+// Protocolo: LISTEN_PID = nosso PID, LISTEN_FDS = quantidade,
+// descritores a partir do 3.
+func escutarSystemd() (net.Listener, error) {
+	pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	quantidade, _ := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if pid != os.Getpid() || quantidade < 1 {
+		return nil, errors.New("escutar systemd: nenhum socket recebido (LISTEN_PID/LISTEN_FDS)")
+	}
+	// Processos filhos não devem achar que os sockets são deles
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	return escutarDescritor(3, "systemd")
+}
+
+func escutarDescritor(fd uintptr, nome string) (net.Listener, error) {
+	arquivo := os.NewFile(fd, nome)
+	if arquivo == nil {
+		return nil, fmt.Errorf("escutar %s: descritor %d inválido", nome, fd)
+	}
+	defer arquivo.Close() // FileListener duplica o descritor
+	ln, err := net.FileListener(arquivo)
+	if err != nil {
+		return nil, fmt.Errorf("escutar %s: %w", nome, err)
+	}
+	return ln, nil
+}
+
+func descreverEndereco(a net.Addr) string {
+	if a.Network() == "unix" {
+		return "unix:" + a.String()
+	}
+	return a.String()
+}
+
+// ========================================
+// LIMITE DE CONEXÕES
+// ========================================
+
+// limitarConexoes faz Accept esperar enquanto houver max conexões
+// abertas (um semáforo com canal, como em modulo05-goroutines)
+func limitarConexoes(ln net.Listener, max int) net.Listener {
+	return &listenerLimitado{Listener: ln, vagas: make(chan struct{}, max), fechado: make(chan struct{})}
+}
+
+type listenerLimitado struct {
+	net.Listener
+	vagas   chan struct{}
+	fechado chan struct{}
+	fechar  sync.Once
+}
+
+func (l *listenerLimitado) Accept() (net.Conn, error) {
+	select {
+	case l.vagas <- struct{}{}:
+	case <-l.fechado:
+		return nil, net.ErrClosed
+	}
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.vagas
+		return nil, err
+	}
+	return &conexaoLimitada{Conn: conn, liberar: func() { <-l.vagas }}, nil
+}
+
+func (l *listenerLimitado) Close() error {
+	l.fechar.Do(func() { close(l.fechado) })
+	return l.Listener.Close()
+}
+
+type conexaoLimitada struct {
+	net.Conn
+	liberar func()
+	uma     sync.Once
+}
+
+func (c *conexaoLimitada) Close() error {
+	err := c.Conn.Close()
+	c.uma.Do(c.liberar)
+	return err
+}
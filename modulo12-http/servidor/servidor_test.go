@@ -0,0 +1,318 @@
+package servidor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// iniciar roda Executar em segundo plano num listener de teste
+func iniciar(t *testing.T, cfg Config, h http.Handler) (ctxCancel context.CancelFunc, resultado <-chan error) {
+	t.Helper()
+	ctx, cancelar := context.WithCancel(context.Background())
+	fim := make(chan error, 1)
+	go func() {
+		fim <- Executar(ctx, cfg, h, log.New(io.Discard, "", 0))
+	}()
+	return cancelar, fim
+}
+
+func escutarTeste(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln
+}
+
+func TestExecutar_DesligamentoGracioso(t *testing.T) {
+	ln := escutarTeste(t)
+	comecou := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(comecou)
+		select {
+		case <-time.After(200 * time.Millisecond):
+			w.Write([]byte("terminou"))
+		case <-r.Context().Done():
+			t.Error("o contexto da requisição não deve ser cancelado no desligamento gracioso")
+		}
+	})
+
+	cfg := ConfigPadrao()
+	cfg.Listener = ln
+	cancelar, fim := iniciar(t, cfg, h)
+
+	var corpo string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			t.Errorf("requisição em andamento falhou: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		corpo = string(b)
+	}()
+
+	<-comecou
+	cancelar() // como um SIGTERM
+
+	if err := <-fim; err != nil {
+		t.Errorf("Executar = %v; esperado nil", err)
+	}
+	wg.Wait()
+	if corpo != "terminou" {
+		t.Errorf("corpo = %q", corpo)
+	}
+
+	// Depois do desligamento, novas conexões são recusadas
+	if _, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second); err == nil {
+		t.Error("o listener deveria estar fechado")
+	}
+}
+
+func TestExecutar_PrazoEsgotado(t *testing.T) {
+	ln := escutarTeste(t)
+	comecou := make(chan struct{})
+	cancelado := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(comecou)
+		<-r.Context().Done() // só termina quando o servidor desistir
+		close(cancelado)
+	})
+
+	cfg := ConfigPadrao()
+	cfg.Listener = ln
+	cfg.TimeoutDesligamento = Duracao(50 * time.Millisecond)
+	cancelar, fim := iniciar(t, cfg, h)
+
+	go http.Get("http://" + ln.Addr().String())
+	<-comecou
+	cancelar()
+
+	if err := <-fim; !errors.Is(err, ErrDesligamentoForcado) {
+		t.Errorf("Executar = %v; esperado ErrDesligamentoForcado", err)
+	}
+	select {
+	case <-cancelado:
+	case <-time.After(time.Second):
+		t.Error("o contexto da requisição deveria ser cancelado após o prazo")
+	}
+}
+
+// prontidaoTeste registra quando o aviso de desligamento chegou
+type prontidaoTeste struct{ avisado chan struct{} }
+
+func (p *prontidaoTeste) IniciarDesligamento() { close(p.avisado) }
+
+func TestExecutar_EsperaDesligamento(t *testing.T) {
+	ln := escutarTeste(t)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+
+	cfg := ConfigPadrao()
+	cfg.Listener = ln
+	cfg.EsperaDesligamento = Duracao(300 * time.Millisecond)
+	p := &prontidaoTeste{avisado: make(chan struct{})}
+	cfg.Prontidao = p
+	cancelar, fim := iniciar(t, cfg, h)
+
+	// Garante que o servidor já atende antes do "sinal"
+	if resp, err := http.Get("http://" + ln.Addr().String()); err != nil {
+		t.Fatal(err)
+	} else {
+		resp.Body.Close()
+	}
+	cancelar()
+
+	select {
+	case <-p.avisado:
+	case <-time.After(time.Second):
+		t.Fatal("Prontidao não foi avisada")
+	}
+	// Durante a espera, novas requisições ainda são atendidas
+	cliente := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := cliente.Get("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("requisição durante a espera: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := <-fim; err != nil {
+		t.Errorf("Executar = %v", err)
+	}
+}
+
+func TestExecutar_Timeouts(t *testing.T) {
+	ln := escutarTeste(t)
+	cfg := ConfigPadrao()
+	cfg.Listener = ln
+	cfg.TimeoutCabecalho = Duracao(100 * time.Millisecond)
+	cancelar, fim := iniciar(t, cfg, http.NotFoundHandler())
+	defer func() { cancelar(); <-fim }()
+
+	// Cliente "slowloris": conecta e não termina os cabeçalhos
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: x\r\n"))
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Errorf("o servidor deveria fechar a conexão lenta: %v", err)
+	}
+}
+
+func TestExecutar_SocketUnix(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "api.sock")
+	// Arquivo velho de um processo que morreu: deve ser substituído
+	velho, err := net.Listen("unix", caminho)
+	if err != nil {
+		t.Skipf("sockets Unix indisponíveis: %v", err)
+	}
+	velho.(*net.UnixListener).SetUnlinkOnClose(false)
+	velho.Close()
+
+	cfg := ConfigPadrao()
+	cfg.Endereco = "unix:" + caminho
+	cancelar, fim := iniciar(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("via unix"))
+	}))
+
+	cliente := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", caminho)
+		},
+	}}
+	var resp *http.Response
+	for tentativa := 0; tentativa < 50; tentativa++ {
+		if resp, err = cliente.Get("http://unix/"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	corpo, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(corpo) != "via unix" {
+		t.Errorf("corpo = %q", corpo)
+	}
+
+	// Um segundo servidor no mesmo caminho deve recusar
+	if _, err := Escutar(Config{Endereco: "unix:" + caminho}); err == nil || !strings.Contains(err.Error(), "outro processo") {
+		t.Errorf("Escutar duplicado = %v", err)
+	}
+
+	cancelar()
+	if err := <-fim; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(caminho); !os.IsNotExist(err) {
+		t.Error("o arquivo do socket deveria ser apagado ao desligar")
+	}
+}
+
+func TestEscutar_Erros(t *testing.T) {
+	arquivo := filepath.Join(t.TempDir(), "comum.txt")
+	os.WriteFile(arquivo, []byte("x"), 0o644)
+
+	t.Setenv("LISTEN_PID", "")
+	testes := []struct {
+		endereco string
+		contem   string
+	}{
+		{"unix:" + arquivo, "não é um socket"},
+		{"systemd", "LISTEN_PID"},
+		{"fd:abc", "descritor"},
+		{"256.0.0.1:80", "escutar"},
+	}
+	for _, tt := range testes {
+		if _, err := Escutar(Config{Endereco: tt.endereco}); err == nil || !strings.Contains(err.Error(), tt.contem) {
+			t.Errorf("Escutar(%q) = %v; esperado erro com %q", tt.endereco, err, tt.contem)
+		}
+	}
+}
+
+func TestLimitarConexoes(t *testing.T) {
+	ln := limitarConexoes(escutarTeste(t), 1)
+	defer ln.Close()
+
+	aceitas := make(chan net.Conn, 2)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			aceitas <- c
+		}
+	}()
+
+	c1, _ := net.Dial("tcp", ln.Addr().String())
+	defer c1.Close()
+	c2, _ := net.Dial("tcp", ln.Addr().String())
+	defer c2.Close()
+
+	primeira := <-aceitas
+	select {
+	case <-aceitas:
+		t.Fatal("a segunda conexão não deveria ser aceita com o limite 1")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	primeira.Close() // libera a vaga
+	select {
+	case c := <-aceitas:
+		c.Close()
+	case <-time.After(time.Second):
+		t.Fatal("a segunda conexão deveria ser aceita depois que a primeira fechou")
+	}
+}
+
+func TestExecutar_LogEndereco(t *testing.T) {
+	ln := escutarTeste(t)
+	var saida bytes.Buffer
+	var mu sync.Mutex
+	logger := log.New(escritorSeguro{&mu, &saida}, "", 0)
+
+	cfg := ConfigPadrao()
+	cfg.Listener = ln
+	ctx, cancelar := context.WithCancel(context.Background())
+	cancelar() // desliga logo depois de começar
+	if err := Executar(ctx, cfg, http.NotFoundHandler(), logger); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(saida.String(), "escutando em "+ln.Addr().String()) || !strings.Contains(saida.String(), "desligado") {
+		t.Errorf("log = %q", saida.String())
+	}
+}
+
+type escritorSeguro struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (e escritorSeguro) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.w.Write(p)
+}
@@ -0,0 +1,311 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+PACKAGE SSE
+
+Server-Sent Events: uma resposta HTTP que não termina, pela qual o
+servidor empurra eventos ao navegador assim que acontecem. Mais simples
+que WebSocket (package websocket) quando só o servidor fala: é HTTP
+comum, passa por proxies e o navegador reconecta sozinho.
+
+    canal := sse.NovoCanal(sse.Opcoes{})
+    mux.Handle("/eventos", canal)
+    canal.Publicar("mensagem", Mensagem{Texto: "oi", Autor: "ana"})
+
+    // no navegador
+    const fonte = new EventSource("/eventos");
+    fonte.addEventListener("mensagem", e => console.log(JSON.parse(e.data)));
+
+FORMATO (text/event-stream):
+
+    retry: 3000            espera do navegador antes de reconectar (ms)
+
+    id: 42                 volta no cabeçalho Last-Event-ID ao reconectar
+    event: mensagem        nome do evento (sem ele, "message")
+    data: {"texto":"oi"}   uma linha data: por linha do conteúdo
+
+    : batimento            comentário: mantém a conexão viva em proxies
+
+RETOMADA:
+Os últimos Opcoes.Historico eventos ficam num buffer circular. Quem
+reconecta com Last-Event-ID recebe o que perdeu. Se o ID já saiu do
+buffer, ou é maior que o último emitido (o servidor reiniciou e os IDs
+recomeçaram), recebe antes um evento "lacuna": aí é melhor recarregar
+o estado inteiro (ex.: GET /api/alunos) do que confiar nos eventos.
+
+CLIENTE LENTO:
+Cada conexão tem uma fila com limite. Quem não acompanha é
+desconectado; o navegador reconecta e retoma pelo Last-Event-ID, sem
+que o Publicar de ninguém tenha esperado.
+*/
+
+// TipoLacuna é o evento enviado quando o Last-Event-ID pedido já
+// saiu do histórico: eventos foram perdidos
+const TipoLacuna = "lacuna"
+
+// ErrCanalFechado é retornado por Publicar depois de Fechar
+var ErrCanalFechado = errors.New("sse: canal fechado")
+
+// Evento é uma mensagem do fluxo
+type Evento struct {
+	ID    uint64
+	Tipo  string // "" = "message"
+	Dados []byte
+}
+
+// escreverEvento grava e no formato text/event-stream
+func escreverEvento(w io.Writer, e Evento) error {
+	var b strings.Builder
+	if e.ID != 0 {
+		b.WriteString("id: " + strconv.FormatUint(e.ID, 10) + "\n")
+	}
+	if e.Tipo != "" {
+		b.WriteString("event: " + e.Tipo + "\n")
+	}
+	// Quebra de linha no conteúdo encerraria o campo: uma linha data: por
+	// linha, que o navegador junta de volta com "\n"
+	dados := strings.ReplaceAll(string(e.Dados), "\r\n", "\n")
+	for _, linha := range strings.Split(dados, "\n") {
+		b.WriteString("data: " + linha + "\n")
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Opcoes configura o Canal; os campos zerados usam os padrões
+type Opcoes struct {
+	Historico   int           // eventos guardados para retomada (0 = 100)
+	TamanhoFila int           // eventos pendentes por conexão (0 = 32)
+	Batimento   time.Duration // intervalo dos comentários de keep-alive (0 = 15s)
+	Reconectar  time.Duration // "retry:" enviado ao navegador (0 = 3s)
+}
+
+// Canal distribui eventos a todas as conexões abertas; seguro para
+// uso concorrente
+type Canal struct {
+	opcoes Opcoes
+
+	mu        sync.Mutex
+	proximoID uint64
+	historico []Evento // circular
+	inicio    int
+	clientes  map[*cliente]struct{}
+	fechado   bool
+}
+
+// cliente é uma conexão aberta; a fila é fechada pelo Canal quando o
+// cliente fica para trás ou o canal fecha
+type cliente struct {
+	fila chan Evento
+}
+
+// NovoCanal cria um canal sem eventos
+func NovoCanal(opcoes Opcoes) *Canal {
+	if opcoes.Historico <= 0 {
+		opcoes.Historico = 100
+	}
+	if opcoes.TamanhoFila <= 0 {
+		opcoes.TamanhoFila = 32
+	}
+	if opcoes.Batimento <= 0 {
+		opcoes.Batimento = 15 * time.Second
+	}
+	if opcoes.Reconectar <= 0 {
+		opcoes.Reconectar = 3 * time.Second
+	}
+	return &Canal{opcoes: opcoes, proximoID: 1, clientes: make(map[*cliente]struct{})}
+}
+
+// Publicar envia v, em JSON, a todas as conexões, com o nome de evento
+// tipo. Nunca espera por um cliente.
+func (c *Canal) Publicar(tipo string, v any) (Evento, error) {
+	dados, err := json.Marshal(v)
+	if err != nil {
+		return Evento{}, err
+	}
+	return c.PublicarDados(tipo, dados)
+}
+
+// PublicarDados envia dados como estão (texto, JSON já pronto...)
+func (c *Canal) PublicarDados(tipo string, dados []byte) (Evento, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fechado {
+		return Evento{}, ErrCanalFechado
+	}
+	e := Evento{ID: c.proximoID, Tipo: tipo, Dados: dados}
+	c.proximoID++
+	c.guardar(e)
+
+	for cl := range c.clientes {
+		select {
+		case cl.fila <- e:
+		default:
+			// Fila cheia: desconecta; o navegador volta com Last-Event-ID
+			c.remover(cl)
+		}
+	}
+	return e, nil
+}
+
+// Clientes retorna quantas conexões estão abertas
+func (c *Canal) Clientes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.clientes)
+}
+
+// Fechar encerra as conexões abertas e recusa as novas com 204, que
+// faz o EventSource parar de reconectar
+func (c *Canal) Fechar() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fechado {
+		return
+	}
+	c.fechado = true
+	for cl := range c.clientes {
+		c.remover(cl)
+	}
+}
+
+// ServeHTTP abre o fluxo de eventos até o cliente desconectar (o
+// context da requisição é cancelado), ficar para trás ou o canal fechar
+func (c *Canal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	ultimo, temUltimo := lerUltimoID(r)
+	cl, pendentes, lacuna, ok := c.assinar(ultimo, temUltimo)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	defer c.cancelar(cl)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream; charset=utf-8")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // nginx: não segurar a resposta
+	w.WriteHeader(http.StatusOK)
+
+	// O WriteTimeout do http.Server mataria um fluxo longo: o prazo é
+	// renovado a cada escrita
+	escrever := func(f func() error) bool {
+		rc.SetWriteDeadline(time.Now().Add(2 * c.opcoes.Batimento))
+		return f() == nil && rc.Flush() == nil
+	}
+
+	ok = escrever(func() error {
+		_, err := io.WriteString(w, "retry: "+strconv.FormatInt(c.opcoes.Reconectar.Milliseconds(), 10)+"\n\n")
+		if err == nil && lacuna {
+			err = escreverEvento(w, Evento{Tipo: TipoLacuna, Dados: []byte(strconv.FormatUint(ultimo, 10))})
+		}
+		for _, e := range pendentes {
+			if err == nil {
+				err = escreverEvento(w, e)
+			}
+		}
+		return err
+	})
+
+	batimento := time.NewTicker(c.opcoes.Batimento)
+	defer batimento.Stop()
+	for ok {
+		select {
+		case e, aberta := <-cl.fila:
+			if !aberta {
+				return
+			}
+			ok = escrever(func() error { return escreverEvento(w, e) })
+		case <-batimento.C:
+			ok = escrever(func() error {
+				_, err := io.WriteString(w, ": batimento\n\n")
+				return err
+			})
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// assinar registra um cliente e, no mesmo lock (sem perder nem repetir
+// eventos), separa os eventos depois de ultimo. lacuna diz que algum
+// evento pedido já saiu do histórico.
+func (c *Canal) assinar(ultimo uint64, temUltimo bool) (cl *cliente, pendentes []Evento, lacuna, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fechado {
+		return nil, nil, false, false
+	}
+	if temUltimo {
+		// Um ID que este canal ainda não emitiu vem de antes de um
+		// reinício (os IDs recomeçam em 1): tudo o que há é novo para o
+		// cliente, e o que veio antes do reinício se perdeu
+		futuro := ultimo >= c.proximoID
+		for i := range c.historico {
+			e := c.historico[(c.inicio+i)%len(c.historico)]
+			if futuro || e.ID > ultimo {
+				pendentes = append(pendentes, e)
+			}
+		}
+		primeiro := c.proximoID // o que viria se não houvesse pendentes
+		if len(pendentes) > 0 {
+			primeiro = pendentes[0].ID
+		}
+		lacuna = futuro || primeiro > ultimo+1
+	}
+	// Os pendentes não passam pela fila: ServeHTTP os escreve direto na
+	// resposta, e a fila só recebe o que for publicado daqui em diante.
+	// Por isso uma retomada com mais pendentes que TamanhoFila não
+	// derruba o cliente.
+	cl = &cliente{fila: make(chan Evento, c.opcoes.TamanhoFila)}
+	c.clientes[cl] = struct{}{}
+	return cl, pendentes, lacuna, true
+}
+
+// cancelar tira o cliente, se o Canal ainda não o tirou
+func (c *Canal) cancelar(cl *cliente) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.clientes[cl]; ok {
+		c.remover(cl)
+	}
+}
+
+// remover: chamar com c.mu travado
+func (c *Canal) remover(cl *cliente) {
+	delete(c.clientes, cl)
+	close(cl.fila)
+}
+
+func (c *Canal) guardar(e Evento) {
+	if len(c.historico) < c.opcoes.Historico {
+		c.historico = append(c.historico, e)
+		return
+	}
+	c.historico[c.inicio] = e
+	c.inicio = (c.inicio + 1) % len(c.historico)
+}
+
+// lerUltimoID lê Last-Event-ID (enviado pelo EventSource ao
+// reconectar) ou ?ultimo_id= (para a primeira conexão de uma página
+// que já tinha eventos)
+func lerUltimoID(r *http.Request) (uint64, bool) {
+	valor := r.Header.Get("Last-Event-ID")
+	if valor == "" {
+		valor = r.URL.Query().Get("ultimo_id")
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(valor), 10, 64)
+	return id, err == nil
+}
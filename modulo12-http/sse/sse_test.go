@@ -0,0 +1,274 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscreverEvento(t *testing.T) {
+	testes := []struct {
+		nome     string
+		evento   Evento
+		esperado string
+	}{
+		{"completo", Evento{ID: 7, Tipo: "mensagem", Dados: []byte(`{"texto":"oi"}`)},
+			"id: 7\nevent: mensagem\ndata: {\"texto\":\"oi\"}\n\n"},
+		{"sem id nem tipo", Evento{Dados: []byte("oi")}, "data: oi\n\n"},
+		{"várias linhas", Evento{Dados: []byte("a\nb\r\nc")}, "data: a\ndata: b\ndata: c\n\n"},
+		{"vazio", Evento{ID: 1}, "id: 1\ndata: \n\n"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			var b strings.Builder
+			if err := escreverEvento(&b, tt.evento); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.esperado {
+				t.Errorf("= %q; esperado %q", b.String(), tt.esperado)
+			}
+		})
+	}
+}
+
+// fluxo é o lado do navegador: lê eventos da resposta
+type fluxo struct {
+	resp     *http.Response
+	leitor   *bufio.Reader
+	retry    string
+	cancelar context.CancelFunc
+}
+
+func abrir(t *testing.T, url, ultimoID string) *fluxo {
+	t.Helper()
+	ctx, cancelar := context.WithCancel(context.Background())
+	t.Cleanup(cancelar)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if ultimoID != "" {
+		req.Header.Set("Last-Event-ID", ultimoID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return &fluxo{resp: resp, leitor: bufio.NewReader(resp.Body), cancelar: cancelar}
+}
+
+// ler devolve o próximo evento, pulando comentários e o retry; ok é
+// false quando o fluxo termina
+func (f *fluxo) ler(t *testing.T) (e Evento, ok bool) {
+	t.Helper()
+	temCampo := false
+	for {
+		linha, err := f.leitor.ReadString('\n')
+		if err != nil {
+			return Evento{}, false
+		}
+		linha = strings.TrimSuffix(linha, "\n")
+		if linha == "" {
+			if temCampo {
+				return e, true
+			}
+			continue
+		}
+		campo, valor, _ := strings.Cut(linha, ": ")
+		switch campo {
+		case "id":
+			e.ID, _ = strconv.ParseUint(valor, 10, 64)
+		case "event":
+			e.Tipo = valor
+		case "data":
+			if e.Dados != nil {
+				e.Dados = append(e.Dados, '\n')
+			}
+			e.Dados = append(append([]byte{}, e.Dados...), valor...)
+		case "retry":
+			f.retry = valor
+			continue
+		default:
+			continue // comentário
+		}
+		temCampo = true
+	}
+}
+
+// esperarClientes espera o ServeHTTP registrar (ou soltar) as conexões
+func esperarClientes(t *testing.T, c *Canal, n int) {
+	t.Helper()
+	for prazo := time.Now().Add(2 * time.Second); c.Clientes() != n; {
+		if time.Now().After(prazo) {
+			t.Fatalf("clientes = %d; esperado %d", c.Clientes(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCanal(t *testing.T) {
+	canal := NovoCanal(Opcoes{Reconectar: 1500 * time.Millisecond})
+	srv := httptest.NewServer(canal)
+	defer srv.Close()
+	defer canal.Fechar() // antes do srv.Close, que espera os fluxos abertos
+
+	a := abrir(t, srv.URL, "")
+	b := abrir(t, srv.URL, "")
+	if ct := a.resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := a.resp.Header.Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	esperarClientes(t, canal, 2)
+
+	canal.Publicar("mensagem", map[string]string{"texto": "oi"})
+	canal.PublicarDados("", []byte("linha 1\nlinha 2"))
+
+	for _, f := range []*fluxo{a, b} {
+		e, _ := f.ler(t)
+		if e.ID != 1 || e.Tipo != "mensagem" || string(e.Dados) != `{"texto":"oi"}` {
+			t.Errorf("primeiro evento = %+v (%s)", e, e.Dados)
+		}
+		e, _ = f.ler(t)
+		if e.ID != 2 || e.Tipo != "" || string(e.Dados) != "linha 1\nlinha 2" {
+			t.Errorf("segundo evento = %+v (%s)", e, e.Dados)
+		}
+		if f.retry != "1500" {
+			t.Errorf("retry = %q", f.retry)
+		}
+	}
+
+	// Desconectar (context cancelado) solta o cliente
+	a.cancelar()
+	esperarClientes(t, canal, 1)
+}
+
+func TestRetomada(t *testing.T) {
+	testes := []struct {
+		nome     string
+		consulta string
+		ultimoID string
+		lacuna   bool
+		ids      []uint64
+	}{
+		{"em dia", "", "5", false, nil},
+		{"dentro do histórico", "", "3", false, []uint64{4, 5}},
+		{"logo antes do histórico", "", "2", false, []uint64{3, 4, 5}},
+		{"fora do histórico", "", "1", true, []uint64{3, 4, 5}},
+		{"de antes de um reinício", "", "500", true, []uint64{3, 4, 5}},
+		{"próximo ID ainda não emitido", "", "6", true, []uint64{3, 4, 5}},
+		{"pela URL", "?ultimo_id=4", "", false, []uint64{5}},
+		{"ID inválido", "", "abc", false, nil},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			canal := NovoCanal(Opcoes{Historico: 3})
+			srv := httptest.NewServer(canal)
+			defer srv.Close()
+			defer canal.Fechar()
+			for i := 1; i <= 5; i++ {
+				canal.Publicar("n", i) // o histórico guarda 3, 4 e 5
+			}
+
+			f := abrir(t, srv.URL+tt.consulta, tt.ultimoID)
+			esperarClientes(t, canal, 1)
+			// Um evento novo marca o fim do que foi reenviado
+			marca, _ := canal.Publicar("marca", nil)
+
+			if tt.lacuna {
+				if e, _ := f.ler(t); e.Tipo != TipoLacuna || e.ID != 0 || string(e.Dados) != tt.ultimoID {
+					t.Errorf("esperado lacuna, veio %+v", e)
+				}
+			}
+			var ids []uint64
+			for {
+				e, ok := f.ler(t)
+				if !ok || e.ID == marca.ID {
+					break
+				}
+				ids = append(ids, e.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.ids) {
+				t.Errorf("reenviados = %v; esperado %v", ids, tt.ids)
+			}
+		})
+	}
+}
+
+func TestClienteLento(t *testing.T) {
+	canal := NovoCanal(Opcoes{TamanhoFila: 2})
+	srv := httptest.NewServer(canal)
+	defer srv.Close()
+	defer canal.Fechar()
+
+	f := abrir(t, srv.URL, "")
+	esperarClientes(t, canal, 1)
+
+	// Publicar nunca espera: quem não lê é desconectado
+	feito := make(chan struct{})
+	go func() {
+		defer close(feito)
+		for i := 0; i < 10000; i++ {
+			canal.Publicar("n", strings.Repeat("x", 1000))
+		}
+	}()
+	select {
+	case <-feito:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publicar esperou pelo cliente lento")
+	}
+	esperarClientes(t, canal, 0)
+
+	// O fluxo termina; o navegador reconectaria com o último ID lido
+	var ultimo uint64
+	for {
+		e, ok := f.ler(t)
+		if !ok {
+			break
+		}
+		ultimo = e.ID
+	}
+	if ultimo == 0 || ultimo >= 10000 {
+		t.Errorf("último evento lido = %d", ultimo)
+	}
+}
+
+func TestFechar(t *testing.T) {
+	canal := NovoCanal(Opcoes{})
+	srv := httptest.NewServer(canal)
+	defer srv.Close()
+
+	f := abrir(t, srv.URL, "")
+	esperarClientes(t, canal, 1)
+	canal.Fechar()
+	if _, ok := f.ler(t); ok {
+		t.Error("fluxo continuou depois de Fechar")
+	}
+	if _, err := canal.Publicar("n", 1); err != ErrCanalFechado {
+		t.Errorf("Publicar depois de Fechar: %v", err)
+	}
+	// 204 faz o EventSource desistir de reconectar
+	if g := abrir(t, srv.URL, ""); g.resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status depois de Fechar = %d", g.resp.StatusCode)
+	}
+}
+
+func TestBatimento(t *testing.T) {
+	canal := NovoCanal(Opcoes{Batimento: 20 * time.Millisecond})
+	srv := httptest.NewServer(canal)
+	defer srv.Close()
+	defer canal.Fechar()
+
+	f := abrir(t, srv.URL, "")
+	f.leitor.ReadString('\n') // retry
+	f.leitor.ReadString('\n')
+	linha, err := f.leitor.ReadString('\n')
+	if err != nil || linha != ": batimento\n" {
+		t.Errorf("esperado batimento, veio %q %v", linha, err)
+	}
+}
@@ -0,0 +1,179 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// guid é fixo na RFC 6455 (seção 1.3); só um servidor WebSocket o conhece
+const guid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// chaveAceite calcula Sec-WebSocket-Accept a partir de Sec-WebSocket-Key
+func chaveAceite(chave string) string {
+	h := sha1.Sum([]byte(chave + guid))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// Aceitar faz o handshake do lado do servidor e toma a conexão do
+// net/http. Em caso de erro, já respondeu ao cliente (400, 403 ou
+// 426) e retorna um erro que embrulha ErrHandshake.
+func Aceitar(w http.ResponseWriter, r *http.Request, opcoes Opcoes) (*Conexao, error) {
+	recusar := func(status int, motivo string) (*Conexao, error) {
+		if status == http.StatusUpgradeRequired {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+		}
+		http.Error(w, motivo, status)
+		return nil, fmt.Errorf("%w: %s", ErrHandshake, motivo)
+	}
+
+	if r.Method != http.MethodGet {
+		return recusar(http.StatusMethodNotAllowed, "WebSocket exige GET")
+	}
+	if !temToken(r.Header, "Connection", "upgrade") || !temToken(r.Header, "Upgrade", "websocket") {
+		return recusar(http.StatusUpgradeRequired, "cabeçalhos Upgrade: websocket e Connection: Upgrade ausentes")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return recusar(http.StatusUpgradeRequired, "versão do WebSocket não suportada")
+	}
+	chave := r.Header.Get("Sec-WebSocket-Key")
+	if decodificada, err := base64.StdEncoding.DecodeString(chave); err != nil || len(decodificada) != 16 {
+		return recusar(http.StatusBadRequest, "Sec-WebSocket-Key inválida")
+	}
+	verificar := opcoes.VerificarOrigem
+	if verificar == nil {
+		verificar = mesmaOrigem
+	}
+	if !verificar(r.Header.Get("Origin"), r.Host) {
+		return recusar(http.StatusForbidden, "origem não permitida")
+	}
+	subprotocolo := escolherSubprotocolo(r.Header, opcoes.Subprotocolos)
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return recusar(http.StatusInternalServerError, "conexão não pode ser tomada: "+err.Error())
+	}
+	// Hijack não limpa prazos que o http.Server tenha definido
+	conn.SetDeadline(time.Time{})
+
+	resposta := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + chaveAceite(chave) + "\r\n"
+	if subprotocolo != "" {
+		resposta += "Sec-WebSocket-Protocol: " + subprotocolo + "\r\n"
+	}
+	if _, err := conn.Write([]byte(resposta + "\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return novaConexao(conn, rw.Reader, false, subprotocolo, opcoes), nil
+}
+
+// Discar abre uma conexão WebSocket como cliente (ws:// ou wss://). A
+// resposta do handshake volta mesmo em caso de erro, se houver.
+func Discar(ctx context.Context, endereco string, opcoes Opcoes) (*Conexao, *http.Response, error) {
+	u, err := url.Parse(endereco)
+	if err != nil {
+		return nil, nil, err
+	}
+	var d net.Dialer
+	var conn net.Conn
+	switch u.Scheme {
+	case "ws":
+		conn, err = d.DialContext(ctx, "tcp", hostPorta(u, "80"))
+	case "wss":
+		td := tls.Dialer{NetDialer: &d}
+		conn, err = td.DialContext(ctx, "tcp", hostPorta(u, "443"))
+	default:
+		return nil, nil, fmt.Errorf("websocket: esquema %q (use ws ou wss)", u.Scheme)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// O context vale para o handshake; depois, os prazos são os de Opcoes
+	if prazo, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(prazo)
+	}
+
+	var bruta [16]byte
+	rand.Read(bruta[:])
+	chave := base64.StdEncoding.EncodeToString(bruta[:])
+
+	req := &http.Request{Method: http.MethodGet, URL: u, Host: u.Host, Header: http.Header{}}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", chave)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(opcoes.Subprotocolos) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(opcoes.Subprotocolos, ", "))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	leitor := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(leitor, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != chaveAceite(chave) {
+		conn.Close()
+		return nil, resp, fmt.Errorf("%w: resposta %s", ErrHandshake, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	return novaConexao(conn, leitor, true, resp.Header.Get("Sec-WebSocket-Protocol"), opcoes), resp, nil
+}
+
+func hostPorta(u *url.URL, padrao string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), padrao)
+}
+
+// temToken procura token (sem diferenciar maiúsculas) numa lista
+// separada por vírgulas, como "Connection: keep-alive, Upgrade"
+func temToken(h http.Header, nome, token string) bool {
+	for _, valor := range h.Values(nome) {
+		for _, t := range strings.Split(valor, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mesmaOrigem aceita navegadores da mesma origem e clientes que não
+// mandam Origin (não são navegadores). Sem essa conferência, qualquer
+// página aberta pelo usuário poderia conectar com os cookies do usuário.
+func mesmaOrigem(origem, host string) bool {
+	if origem == "" {
+		return true
+	}
+	u, err := url.Parse(origem)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
+
+// escolherSubprotocolo pega o primeiro dos nossos que o cliente ofereceu
+func escolherSubprotocolo(h http.Header, nossos []string) string {
+	for _, s := range nossos {
+		if temToken(h, "Sec-WebSocket-Protocol", s) {
+			return s
+		}
+	}
+	return ""
+}
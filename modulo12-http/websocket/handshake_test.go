@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChaveAceite(t *testing.T) {
+	// Exemplo da RFC 6455, seção 1.3
+	if a := chaveAceite("dGhlIHNhbXBsZSBub25jZQ=="); a != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("chaveAceite = %q", a)
+	}
+}
+
+func TestAceitar_Recusas(t *testing.T) {
+	valido := func() *http.Request {
+		r := httptest.NewRequest("GET", "http://escola.br/chat", nil)
+		r.Header.Set("Connection", "keep-alive, Upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		return r
+	}
+	testes := []struct {
+		nome   string
+		mudar  func(r *http.Request)
+		status int
+	}{
+		{"POST", func(r *http.Request) { r.Method = "POST" }, 405},
+		{"sem Upgrade", func(r *http.Request) { r.Header.Del("Upgrade") }, 426},
+		{"versão 8", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Version", "8") }, 426},
+		{"chave curta", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Key", "YWJj") }, 400},
+		{"outra origem", func(r *http.Request) { r.Header.Set("Origin", "https://malicioso.com") }, 403},
+		// httptest.ResponseRecorder não tem Hijack
+		{"mesma origem", func(r *http.Request) { r.Header.Set("Origin", "https://escola.br") }, 500},
+	}
+	for _, tt := range testes {
+		r := valido()
+		tt.mudar(r)
+		rec := httptest.NewRecorder()
+		_, err := Aceitar(rec, r, Opcoes{})
+		if !errors.Is(err, ErrHandshake) || rec.Code != tt.status {
+			t.Errorf("%s: = %d %v; esperado %d", tt.nome, rec.Code, err, tt.status)
+		}
+		if tt.status == 426 && rec.Header().Get("Sec-WebSocket-Version") != "13" {
+			t.Errorf("%s: 426 sem Sec-WebSocket-Version", tt.nome)
+		}
+	}
+}
+
+func TestDiscar_Subprotocolo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Aceitar(w, r, Opcoes{Subprotocolos: []string{"chat.v2", "chat.v1"}})
+		if err != nil {
+			return
+		}
+		conn.EscreverMensagem(Texto, []byte(conn.Subprotocolo()))
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+	conn, _, err := Discar(context.Background(), url, Opcoes{Subprotocolos: []string{"chat.v1", "chat.v2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// Vale a preferência do servidor
+	if _, dados, err := conn.LerMensagem(); err != nil || string(dados) != "chat.v2" || conn.Subprotocolo() != "chat.v2" {
+		t.Errorf("subprotocolo = %q %q %v", dados, conn.Subprotocolo(), err)
+	}
+
+	// Servidor que não é WebSocket: erro com a resposta recebida
+	comum := httptest.NewServer(http.NotFoundHandler())
+	defer comum.Close()
+	_, resp, err := Discar(context.Background(), "ws"+strings.TrimPrefix(comum.URL, "http"), Opcoes{})
+	if !errors.Is(err, ErrHandshake) || resp == nil || resp.StatusCode != 404 {
+		t.Errorf("err = %v, resp = %v; esperado ErrHandshake com 404", err, resp)
+	}
+}
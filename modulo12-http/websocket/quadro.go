@@ -0,0 +1,146 @@
+package websocket
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Opcodes (RFC 6455, seção 5.2)
+const (
+	opContinuacao = 0x0
+	opTexto       = 0x1
+	opBinario     = 0x2
+	opFechar      = 0x8
+	opPing        = 0x9
+	opPong        = 0xA
+)
+
+// tamanhoMaxControle é o limite de payload de close, ping e pong
+const tamanhoMaxControle = 125
+
+// quadro é um frame já desmascarado
+type quadro struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+func (q quadro) controle() bool {
+	return q.opcode&0x8 != 0
+}
+
+// lerQuadro lê um frame. Quadros de dados acima de limite viram
+// CodigoMuitoGrande sem ler o payload; mascarado diz se o frame deve
+// (servidor) ou não pode (cliente) vir com máscara.
+//
+//	 0               1               2               3
+//	 0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7
+//	+-+-+-+-+-------+-+-------------+-------------------------------+
+//	|F|R|R|R| opcode|M| Payload len |    Extended payload length    |
+//	|I|S|S|S|  (4)  |A|     (7)     |             (16/64)           |
+//	|N|V|V|V|       |S|             |                               |
+//	+-+-+-+-+-------+-+-------------+ - - - - - - - - - - - - - - - +
+//	|     ...       |    Masking-key (32), se MASK     |  Payload   |
+func lerQuadro(r io.Reader, mascarado bool, limite int64) (quadro, error) {
+	var cab [2]byte
+	if _, err := io.ReadFull(r, cab[:]); err != nil {
+		return quadro{}, err
+	}
+	q := quadro{fin: cab[0]&0x80 != 0, opcode: cab[0] & 0x0F}
+	if cab[0]&0x70 != 0 {
+		return q, erroProtocolo("bits RSV sem extensão negociada")
+	}
+	switch q.opcode {
+	case opContinuacao, opTexto, opBinario, opFechar, opPing, opPong:
+	default:
+		return q, erroProtocolo("opcode desconhecido")
+	}
+	if (cab[1]&0x80 != 0) != mascarado {
+		if mascarado {
+			return q, erroProtocolo("frame do cliente sem máscara")
+		}
+		return q, erroProtocolo("frame do servidor com máscara")
+	}
+
+	tamanho := int64(cab[1] & 0x7F)
+	switch tamanho {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return q, err
+		}
+		tamanho = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return q, err
+		}
+		if ext[0]&0x80 != 0 {
+			return q, erroProtocolo("tamanho de payload negativo")
+		}
+		tamanho = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if q.controle() && (tamanho > tamanhoMaxControle || !q.fin) {
+		return q, erroProtocolo("frame de controle fragmentado ou acima de 125 bytes")
+	}
+	if !q.controle() && tamanho > limite {
+		return q, &ErroFechamento{Codigo: CodigoMuitoGrande, Motivo: "mensagem grande demais"}
+	}
+
+	var mascara [4]byte
+	if mascarado {
+		if _, err := io.ReadFull(r, mascara[:]); err != nil {
+			return q, err
+		}
+	}
+	q.payload = make([]byte, tamanho)
+	if _, err := io.ReadFull(r, q.payload); err != nil {
+		return q, err
+	}
+	if mascarado {
+		mascarar(q.payload, mascara)
+	}
+	return q, nil
+}
+
+// montarQuadro codifica um frame; com mascara != nil (cliente), o
+// payload vai mascarado. payload não é alterado.
+func montarQuadro(fin bool, opcode byte, payload []byte, mascara *[4]byte) []byte {
+	b := make([]byte, 0, 14+len(payload))
+	primeiro := opcode
+	if fin {
+		primeiro |= 0x80
+	}
+	b = append(b, primeiro)
+
+	var bitMascara byte
+	if mascara != nil {
+		bitMascara = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		b = append(b, bitMascara|byte(n))
+	case n <= 0xFFFF:
+		b = append(b, bitMascara|126)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, bitMascara|127)
+		b = binary.BigEndian.AppendUint64(b, uint64(n))
+	}
+
+	if mascara == nil {
+		return append(b, payload...)
+	}
+	b = append(b, mascara[:]...)
+	inicio := len(b)
+	b = append(b, payload...)
+	mascarar(b[inicio:], *mascara)
+	return b
+}
+
+// mascarar aplica (e desfaz: é um XOR) a máscara do cliente
+func mascarar(dados []byte, mascara [4]byte) {
+	for i := range dados {
+		dados[i] ^= mascara[i%4]
+	}
+}
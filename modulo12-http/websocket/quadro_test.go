@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestQuadro_ExemplosRFC(t *testing.T) {
+	// RFC 6455, seção 5.7
+	testes := []struct {
+		nome      string
+		bytes     []byte
+		mascarado bool
+		fin       bool
+		opcode    byte
+		payload   string
+	}{
+		{"texto sem máscara", []byte{0x81, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f}, false, true, opTexto, "Hello"},
+		{"texto mascarado", []byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58}, true, true, opTexto, "Hello"},
+		{"primeiro fragmento", []byte{0x01, 0x03, 0x48, 0x65, 0x6c}, false, false, opTexto, "Hel"},
+		{"último fragmento", []byte{0x80, 0x02, 0x6c, 0x6f}, false, true, opContinuacao, "lo"},
+		{"ping", []byte{0x89, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f}, false, true, opPing, "Hello"},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			q, err := lerQuadro(bytes.NewReader(tt.bytes), tt.mascarado, 1<<20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.fin != tt.fin || q.opcode != tt.opcode || string(q.payload) != tt.payload {
+				t.Errorf("= %v %x %q", q.fin, q.opcode, q.payload)
+			}
+			if tt.mascarado {
+				return // a máscara é sorteada: os bytes não se repetem
+			}
+			if b := montarQuadro(tt.fin, tt.opcode, []byte(tt.payload), nil); !bytes.Equal(b, tt.bytes) {
+				t.Errorf("montarQuadro = % x; esperado % x", b, tt.bytes)
+			}
+		})
+	}
+}
+
+func TestQuadro_Tamanhos(t *testing.T) {
+	for _, n := range []int{0, 125, 126, 0xFFFF, 0x10000} {
+		payload := bytes.Repeat([]byte{'a'}, n)
+		mascara := [4]byte{1, 2, 3, 4}
+		b := montarQuadro(true, opBinario, payload, &mascara)
+		q, err := lerQuadro(bytes.NewReader(b), true, 1<<20)
+		if err != nil || !bytes.Equal(q.payload, payload) {
+			t.Errorf("%d bytes: err = %v, %d bytes lidos", n, err, len(q.payload))
+		}
+		if bytes.Count(payload, []byte{'a'}) != n {
+			t.Errorf("%d bytes: montarQuadro alterou o payload", n)
+		}
+	}
+}
+
+func TestQuadro_Erros(t *testing.T) {
+	testes := []struct {
+		nome      string
+		bytes     []byte
+		mascarado bool
+		codigo    int
+	}{
+		{"RSV ligado", []byte{0xC1, 0x00}, false, CodigoErroProtocolo},
+		{"opcode reservado", []byte{0x83, 0x00}, false, CodigoErroProtocolo},
+		{"cliente sem máscara", []byte{0x81, 0x00}, true, CodigoErroProtocolo},
+		{"servidor com máscara", []byte{0x81, 0x80, 0, 0, 0, 0}, false, CodigoErroProtocolo},
+		{"ping fragmentado", []byte{0x09, 0x00}, false, CodigoErroProtocolo},
+		{"ping grande", []byte{0x89, 126, 0x00, 126}, false, CodigoErroProtocolo},
+		{"acima do limite", []byte{0x82, 126, 0x04, 0x01}, false, CodigoMuitoGrande},
+	}
+	for _, tt := range testes {
+		_, err := lerQuadro(bytes.NewReader(tt.bytes), tt.mascarado, 1024)
+		var e *ErroFechamento
+		if !errors.As(err, &e) || e.Codigo != tt.codigo {
+			t.Errorf("%s: err = %v; esperado código %d", tt.nome, err, tt.codigo)
+		}
+	}
+}
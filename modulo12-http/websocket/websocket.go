@@ -0,0 +1,381 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+PACKAGE WEBSOCKET
+
+WebSocket (RFC 6455) só com a biblioteca padrão: uma conexão TCP que
+começa como requisição HTTP e vira um canal de mensagens nos dois
+sentidos, sem uma requisição por mensagem.
+
+    // servidor (dentro de um handler)
+    conn, err := websocket.Aceitar(w, r, websocket.Opcoes{})
+    if err != nil {
+        return // Aceitar já respondeu o erro HTTP
+    }
+    defer conn.Close()
+    for {
+        tipo, dados, err := conn.LerMensagem()
+        if err != nil {
+            return // websocket.CodigoDe(err) diz como terminou
+        }
+        conn.EscreverMensagem(tipo, dados) // eco
+    }
+
+    // cliente
+    conn, _, err := websocket.Discar(ctx, "ws://localhost:8080/eco", websocket.Opcoes{})
+
+HANDSHAKE (handshake.go):
+O cliente manda GET com Upgrade: websocket e Sec-WebSocket-Key; o
+servidor responde 101 Switching Protocols com Sec-WebSocket-Accept =
+base64(sha1(chave + GUID fixo)), prova de que entendeu o protocolo.
+Daí em diante a conexão (tomada do net/http com Hijack) só troca frames.
+
+FRAMES (quadro.go):
+Cada mensagem vai em um ou mais frames. Frames do cliente vão
+mascarados (XOR com 4 bytes aleatórios, para confundir proxies
+antigos); os do servidor, não. Uma mensagem pode ser fragmentada:
+o primeiro frame diz o tipo (texto ou binário) e os seguintes são
+"continuação", até um com FIN. Frames de controle (ping, pong, close)
+podem aparecer entre fragmentos e nunca são fragmentados.
+
+CONTROLE:
+    ping   LerMensagem responde sozinho com pong
+    pong   só renova o prazo de leitura (Opcoes.PrazoLeitura)
+    close  LerMensagem devolve o close e retorna *ErroFechamento
+
+CONCORRÊNCIA:
+Uma goroutine lê (LerMensagem) e qualquer número escreve: as escritas
+são serializadas por um mutex, inclusive os pongs da leitura.
+*/
+
+// TipoMensagem é texto (UTF-8) ou binário
+type TipoMensagem int
+
+// Tipos de mensagem
+const (
+	Texto   TipoMensagem = opTexto
+	Binario TipoMensagem = opBinario
+)
+
+// Códigos de fechamento (RFC 6455, seção 7.4.1)
+const (
+	CodigoNormal           = 1000
+	CodigoSaindo           = 1001 // servidor desligando, página fechada
+	CodigoErroProtocolo    = 1002
+	CodigoTipoNaoSuportado = 1003
+	CodigoSemCodigo        = 1005 // close sem payload; nunca vai no frame
+	CodigoAnormal          = 1006 // conexão caiu sem close; nunca vai no frame
+	CodigoDadosInvalidos   = 1007 // ex.: texto que não é UTF-8
+	CodigoViolacaoPolitica = 1008
+	CodigoMuitoGrande      = 1009
+	CodigoErroInterno      = 1011
+)
+
+// Erros da conexão
+var (
+	ErrFechada   = errors.New("websocket: conexão fechada")
+	ErrHandshake = errors.New("websocket: handshake inválido")
+)
+
+// ErroFechamento é o fim da conversa: o close recebido (ou enviado
+// por violação do protocolo)
+type ErroFechamento struct {
+	Codigo int
+	Motivo string
+}
+
+func (e *ErroFechamento) Error() string {
+	if e.Motivo == "" {
+		return fmt.Sprintf("websocket: fechada (%d)", e.Codigo)
+	}
+	return fmt.Sprintf("websocket: fechada (%d): %s", e.Codigo, e.Motivo)
+}
+
+// CodigoDe retorna o código de fechamento de err: o do
+// *ErroFechamento ou CodigoAnormal para qualquer outro erro
+func CodigoDe(err error) int {
+	var e *ErroFechamento
+	if errors.As(err, &e) {
+		return e.Codigo
+	}
+	return CodigoAnormal
+}
+
+func erroProtocolo(motivo string) *ErroFechamento {
+	return &ErroFechamento{Codigo: CodigoErroProtocolo, Motivo: motivo}
+}
+
+// Opcoes configura a conexão; os campos zerados usam os padrões
+type Opcoes struct {
+	// TamanhoMaxMensagem limita a mensagem recebida, somados os
+	// fragmentos (0 = 1 MB); acima dele, close 1009
+	TamanhoMaxMensagem int64
+	// TamanhoQuadro fragmenta mensagens enviadas maiores que ele
+	// (0 = não fragmenta)
+	TamanhoQuadro int
+	// PrazoLeitura é renovado a cada frame recebido, pongs inclusive
+	// (0 = sem prazo). Com pings periódicos, detecta cliente sumido.
+	PrazoLeitura time.Duration
+	// PrazoEscrita limita cada escrita (0 = 10s)
+	PrazoEscrita time.Duration
+
+	// VerificarOrigem decide se o servidor aceita o Origin do
+	// navegador (nil = só a mesma origem, ou sem Origin)
+	VerificarOrigem func(origem, host string) bool
+	// Subprotocolos aceitos, em ordem de preferência
+	Subprotocolos []string
+}
+
+func (o Opcoes) comPadroes() Opcoes {
+	if o.TamanhoMaxMensagem <= 0 {
+		o.TamanhoMaxMensagem = 1 << 20
+	}
+	if o.PrazoEscrita <= 0 {
+		o.PrazoEscrita = 10 * time.Second
+	}
+	return o
+}
+
+// prazoFechamento é quanto se espera o close do outro lado depois de
+// enviar o nosso
+const prazoFechamento = 2 * time.Second
+
+// Conexao é uma conexão WebSocket aberta
+type Conexao struct {
+	conn        net.Conn
+	leitor      *bufio.Reader
+	cliente     bool // frames enviados vão mascarados
+	subprotocol string
+	opcoes      Opcoes
+
+	// leitura: uma goroutine por vez
+	fragmentos []byte
+	tipoFrag   byte
+
+	// escrita
+	mu           sync.Mutex
+	closeEnviado bool
+}
+
+func novaConexao(conn net.Conn, leitor *bufio.Reader, cliente bool, subprotocolo string, opcoes Opcoes) *Conexao {
+	return &Conexao{conn: conn, leitor: leitor, cliente: cliente, subprotocol: subprotocolo, opcoes: opcoes.comPadroes()}
+}
+
+// Subprotocolo retorna o subprotocolo negociado ("" se nenhum)
+func (c *Conexao) Subprotocolo() string {
+	return c.subprotocol
+}
+
+// EnderecoRemoto retorna o endereço do outro lado
+func (c *Conexao) EnderecoRemoto() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// LerMensagem lê a próxima mensagem de dados, juntando fragmentos e
+// tratando pings e closes no caminho. Depois de um erro, a conexão não
+// serve mais: feche-a.
+func (c *Conexao) LerMensagem() (TipoMensagem, []byte, error) {
+	for {
+		c.renovarPrazoLeitura()
+		limite := c.opcoes.TamanhoMaxMensagem - int64(len(c.fragmentos))
+		q, err := lerQuadro(c.leitor, !c.cliente, limite)
+		if err != nil {
+			return 0, nil, c.falhar(err)
+		}
+
+		switch q.opcode {
+		case opPing:
+			if err := c.escrever(opPong, q.payload); err != nil && !errors.Is(err, ErrFechada) {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opFechar:
+			return 0, nil, c.receberFechamento(q.payload)
+		case opContinuacao:
+			if c.tipoFrag == 0 {
+				return 0, nil, c.falhar(erroProtocolo("continuação sem mensagem iniciada"))
+			}
+		default: // texto ou binário
+			if c.tipoFrag != 0 {
+				return 0, nil, c.falhar(erroProtocolo("nova mensagem antes do fim da fragmentada"))
+			}
+			c.tipoFrag = q.opcode
+		}
+
+		c.fragmentos = append(c.fragmentos, q.payload...)
+		if !q.fin {
+			continue
+		}
+		tipo, dados := TipoMensagem(c.tipoFrag), c.fragmentos
+		c.tipoFrag, c.fragmentos = 0, nil
+		if tipo == Texto && !utf8.Valid(dados) {
+			return 0, nil, c.falhar(&ErroFechamento{Codigo: CodigoDadosInvalidos, Motivo: "texto não é UTF-8"})
+		}
+		return tipo, dados, nil
+	}
+}
+
+// falhar envia o close de um *ErroFechamento (violação do protocolo,
+// mensagem grande...) e o retorna; outros erros (rede) passam direto
+func (c *Conexao) falhar(err error) error {
+	var e *ErroFechamento
+	if errors.As(err, &e) {
+		c.Fechar(e.Codigo, e.Motivo)
+	}
+	return err
+}
+
+// receberFechamento valida o close recebido e responde com o mesmo código
+func (c *Conexao) receberFechamento(payload []byte) error {
+	recebido := &ErroFechamento{Codigo: CodigoSemCodigo}
+	switch {
+	case len(payload) == 1:
+		return c.falhar(erroProtocolo("close com payload de 1 byte"))
+	case len(payload) >= 2:
+		recebido.Codigo = int(payload[0])<<8 | int(payload[1])
+		recebido.Motivo = string(payload[2:])
+		if !codigoValido(recebido.Codigo) {
+			return c.falhar(erroProtocolo(fmt.Sprintf("código de close inválido %d", recebido.Codigo)))
+		}
+		if !utf8.ValidString(recebido.Motivo) {
+			return c.falhar(&ErroFechamento{Codigo: CodigoDadosInvalidos, Motivo: "motivo do close não é UTF-8"})
+		}
+	}
+	resposta := recebido.Codigo
+	if resposta == CodigoSemCodigo {
+		resposta = CodigoNormal
+	}
+	c.Fechar(resposta, "")
+	return recebido
+}
+
+// codigoValido diz se o código pode ir num frame de close
+func codigoValido(codigo int) bool {
+	switch {
+	case codigo >= 1000 && codigo <= 1003, codigo >= 1007 && codigo <= 1014:
+		return true
+	case codigo >= 3000 && codigo <= 4999: // bibliotecas e aplicações
+		return true
+	}
+	return false
+}
+
+// EscreverMensagem envia uma mensagem, fragmentada se passar de
+// Opcoes.TamanhoQuadro
+func (c *Conexao) EscreverMensagem(tipo TipoMensagem, dados []byte) error {
+	if tipo != Texto && tipo != Binario {
+		return fmt.Errorf("websocket: tipo de mensagem inválido %d", tipo)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeEnviado {
+		return ErrFechada
+	}
+
+	tamanho := c.opcoes.TamanhoQuadro
+	if tamanho <= 0 || len(dados) <= tamanho {
+		return c.enviar(montarQuadro(true, byte(tipo), dados, c.mascara()))
+	}
+	opcode := byte(tipo)
+	for len(dados) > 0 {
+		n := min(tamanho, len(dados))
+		if err := c.enviar(montarQuadro(n == len(dados), opcode, dados[:n], c.mascara())); err != nil {
+			return err
+		}
+		dados = dados[n:]
+		opcode = opContinuacao
+	}
+	return nil
+}
+
+// Ping envia um ping; o pong renova o prazo de leitura do outro lado
+// quando chegar
+func (c *Conexao) Ping(dados []byte) error {
+	if len(dados) > tamanhoMaxControle {
+		return fmt.Errorf("websocket: ping acima de %d bytes", tamanhoMaxControle)
+	}
+	return c.escrever(opPing, dados)
+}
+
+// Fechar inicia o fechamento: envia o close e dá ao outro lado
+// prazoFechamento para responder, que LerMensagem recebe como
+// *ErroFechamento. Feche a conexão (Close) depois.
+func (c *Conexao) Fechar(codigo int, motivo string) error {
+	if n := tamanhoMaxControle - 2; len(motivo) > n {
+		// Cortar no meio de uma runa daria 1007 (motivo não é UTF-8)
+		for n > 0 && !utf8.RuneStart(motivo[n]) {
+			n--
+		}
+		motivo = motivo[:n]
+	}
+	payload := append([]byte{byte(codigo >> 8), byte(codigo)}, motivo...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeEnviado {
+		return nil
+	}
+	c.closeEnviado = true
+	c.conn.SetReadDeadline(time.Now().Add(prazoFechamento))
+	return c.enviar(montarQuadro(true, opFechar, payload, c.mascara()))
+}
+
+// renovarPrazoLeitura adia o prazo de leitura, a não ser que o close já
+// tenha sido enviado: aí vale o prazoFechamento definido por Fechar, e
+// um outro lado que nunca responde não segura a conexão por
+// PrazoLeitura inteiro
+func (c *Conexao) renovarPrazoLeitura() {
+	if c.opcoes.PrazoLeitura <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closeEnviado {
+		c.conn.SetReadDeadline(time.Now().Add(c.opcoes.PrazoLeitura))
+	}
+}
+
+// Close fecha a conexão TCP, sem handshake de fechamento
+func (c *Conexao) Close() error {
+	return c.conn.Close()
+}
+
+// escrever envia um frame de controle
+func (c *Conexao) escrever(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeEnviado {
+		return ErrFechada
+	}
+	return c.enviar(montarQuadro(true, opcode, payload, c.mascara()))
+}
+
+// enviar escreve bytes já montados; chamar com c.mu travado
+func (c *Conexao) enviar(b []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.opcoes.PrazoEscrita))
+	_, err := c.conn.Write(b)
+	return err
+}
+
+// mascara sorteia a máscara de um frame do cliente (nil no servidor)
+func (c *Conexao) mascara() *[4]byte {
+	if !c.cliente {
+		return nil
+	}
+	var m [4]byte
+	rand.Read(m[:])
+	return &m
+}
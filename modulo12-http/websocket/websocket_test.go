@@ -0,0 +1,256 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// servidorEco repete cada mensagem; o erro final de LerMensagem vai
+// para fim
+func servidorEco(t *testing.T, opcoes Opcoes) (url string, fim <-chan error) {
+	t.Helper()
+	erros := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Aceitar(w, r, opcoes)
+		if err != nil {
+			erros <- err
+			return
+		}
+		defer conn.Close()
+		for {
+			tipo, dados, err := conn.LerMensagem()
+			if err != nil {
+				erros <- err
+				return
+			}
+			conn.EscreverMensagem(tipo, dados)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), erros
+}
+
+func discar(t *testing.T, url string, opcoes Opcoes) *Conexao {
+	t.Helper()
+	ctx, cancelar := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelar()
+	conn, _, err := Discar(ctx, url, opcoes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestEco(t *testing.T) {
+	url, fim := servidorEco(t, Opcoes{TamanhoQuadro: 10})
+	// O cliente também fragmenta: os dois lados remontam
+	conn := discar(t, url, Opcoes{TamanhoQuadro: 7})
+
+	mensagens := []struct {
+		tipo  TipoMensagem
+		dados []byte
+	}{
+		{Texto, []byte("olá")},
+		{Texto, []byte(strings.Repeat("ação ", 100))},
+		{Binario, []byte{0, 1, 2, 0xFF}},
+		{Binario, bytes.Repeat([]byte{7}, 70000)},
+		{Texto, []byte{}},
+	}
+	for _, m := range mensagens {
+		if err := conn.EscreverMensagem(m.tipo, m.dados); err != nil {
+			t.Fatal(err)
+		}
+		tipo, dados, err := conn.LerMensagem()
+		if err != nil || tipo != m.tipo || !bytes.Equal(dados, m.dados) {
+			t.Errorf("eco de %d bytes = tipo %d, %d bytes, %v", len(m.dados), tipo, len(dados), err)
+		}
+	}
+
+	if err := conn.Fechar(CodigoNormal, "tchau"); err != nil {
+		t.Fatal(err)
+	}
+	// O servidor recebe o close e responde com o mesmo código
+	if err := <-fim; CodigoDe(err) != CodigoNormal || !strings.Contains(err.Error(), "tchau") {
+		t.Errorf("servidor terminou com %v", err)
+	}
+	if _, _, err := conn.LerMensagem(); CodigoDe(err) != CodigoNormal {
+		t.Errorf("resposta do close = %v", err)
+	}
+	if err := conn.EscreverMensagem(Texto, []byte("depois")); !errors.Is(err, ErrFechada) {
+		t.Errorf("escrever depois do close: %v", err)
+	}
+}
+
+// conexaoCrua faz o handshake à mão, para mandar frames que o Discar
+// nunca mandaria
+func conexaoCrua(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "ws://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	chave := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: x\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: " + chave + "\r\n\r\n"))
+	leitor := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(leitor, nil)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake: %v %v", resp, err)
+	}
+	return conn, leitor
+}
+
+func TestControle(t *testing.T) {
+	url, _ := servidorEco(t, Opcoes{})
+	conn, leitor := conexaoCrua(t, url)
+	m := &[4]byte{9, 8, 7, 6}
+
+	// Ping entre dois fragmentos: o pong vem antes do eco
+	conn.Write(montarQuadro(false, opTexto, []byte("frag"), m))
+	conn.Write(montarQuadro(true, opPing, []byte("oi?"), m))
+	conn.Write(montarQuadro(true, opContinuacao, []byte("mentada"), m))
+
+	q, err := lerQuadro(leitor, false, 1<<20)
+	if err != nil || q.opcode != opPong || string(q.payload) != "oi?" {
+		t.Fatalf("esperado pong, veio %x %q %v", q.opcode, q.payload, err)
+	}
+	q, err = lerQuadro(leitor, false, 1<<20)
+	if err != nil || q.opcode != opTexto || string(q.payload) != "fragmentada" {
+		t.Errorf("eco = %x %q %v", q.opcode, q.payload, err)
+	}
+}
+
+func TestViolacoes(t *testing.T) {
+	m := &[4]byte{1, 2, 3, 4}
+	testes := []struct {
+		nome    string
+		quadros [][]byte
+		codigo  int
+	}{
+		{"sem máscara", [][]byte{montarQuadro(true, opTexto, []byte("oi"), nil)}, CodigoErroProtocolo},
+		{"continuação solta", [][]byte{montarQuadro(true, opContinuacao, []byte("oi"), m)}, CodigoErroProtocolo},
+		{"mensagem dentro de fragmentada", [][]byte{
+			montarQuadro(false, opTexto, []byte("a"), m),
+			montarQuadro(true, opBinario, []byte("b"), m),
+		}, CodigoErroProtocolo},
+		{"texto inválido", [][]byte{montarQuadro(true, opTexto, []byte{0xC3, 0x28}, m)}, CodigoDadosInvalidos},
+		{"grande demais", [][]byte{montarQuadro(true, opBinario, make([]byte, 2000), m)}, CodigoMuitoGrande},
+		{"fragmentos grandes demais", [][]byte{
+			montarQuadro(false, opBinario, make([]byte, 600), m),
+			montarQuadro(true, opContinuacao, make([]byte, 600), m),
+		}, CodigoMuitoGrande},
+		{"close com código reservado", [][]byte{montarQuadro(true, opFechar, []byte{0x03, 0xED}, m)}, CodigoErroProtocolo}, // 1005
+		{"close de 1 byte", [][]byte{montarQuadro(true, opFechar, []byte{0x03}, m)}, CodigoErroProtocolo},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			url, fim := servidorEco(t, Opcoes{TamanhoMaxMensagem: 1024})
+			conn, leitor := conexaoCrua(t, url)
+			for _, q := range tt.quadros {
+				conn.Write(q)
+			}
+			q, err := lerQuadro(leitor, false, 1<<20)
+			if err != nil || q.opcode != opFechar || len(q.payload) < 2 {
+				t.Fatalf("esperado close, veio %x %q %v", q.opcode, q.payload, err)
+			}
+			if codigo := int(q.payload[0])<<8 | int(q.payload[1]); codigo != tt.codigo {
+				t.Errorf("código = %d; esperado %d (%s)", codigo, tt.codigo, q.payload[2:])
+			}
+			if err := <-fim; CodigoDe(err) != tt.codigo {
+				t.Errorf("servidor terminou com %v", err)
+			}
+		})
+	}
+}
+
+func TestPrazoLeitura(t *testing.T) {
+	url, fim := servidorEco(t, Opcoes{PrazoLeitura: 100 * time.Millisecond})
+	conn := discar(t, url, Opcoes{})
+
+	// Pings mantêm a conexão viva além do prazo
+	for i := 0; i < 4; i++ {
+		time.Sleep(50 * time.Millisecond)
+		conn.Ping(nil)
+	}
+	select {
+	case err := <-fim:
+		t.Fatalf("servidor desistiu com pings chegando: %v", err)
+	default:
+	}
+
+	// Em silêncio, o servidor desiste
+	select {
+	case err := <-fim:
+		var rede net.Error
+		if !errors.As(err, &rede) || !rede.Timeout() || CodigoDe(err) != CodigoAnormal {
+			t.Errorf("err = %v; esperado timeout", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("servidor não desistiu do cliente em silêncio")
+	}
+}
+
+func TestFechar_OutroLadoNaoResponde(t *testing.T) {
+	// O servidor manda o close; o cliente continua mandando pings e
+	// nunca responde. Vale prazoFechamento, não o PrazoLeitura de 1 min.
+	resultado := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Aceitar(w, r, Opcoes{PrazoLeitura: time.Minute})
+		if err != nil {
+			resultado <- err
+			return
+		}
+		defer conn.Close()
+		conn.Fechar(CodigoNormal, strings.Repeat("é", 100)) // 200 bytes
+		for {
+			if _, _, err := conn.LerMensagem(); err != nil {
+				resultado <- err
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	conn, leitor := conexaoCrua(t, "ws"+strings.TrimPrefix(srv.URL, "http"))
+
+	q, err := lerQuadro(leitor, false, 1<<20)
+	if err != nil || q.opcode != opFechar {
+		t.Fatalf("esperado close, veio %x %v", q.opcode, err)
+	}
+	if motivo := q.payload[2:]; len(q.payload) > tamanhoMaxControle || !utf8.Valid(motivo) {
+		t.Errorf("motivo com %d bytes, UTF-8 válido = %v", len(motivo), utf8.Valid(motivo))
+	}
+
+	inicio := time.Now()
+	pings := time.NewTicker(100 * time.Millisecond)
+	defer pings.Stop()
+	for {
+		select {
+		case err := <-resultado:
+			var rede net.Error
+			if !errors.As(err, &rede) || !rede.Timeout() {
+				t.Errorf("err = %v; esperado timeout", err)
+			}
+			if d := time.Since(inicio); d > prazoFechamento+time.Second {
+				t.Errorf("servidor esperou %v pelo close", d)
+			}
+			return
+		case <-pings.C:
+			conn.Write(montarQuadro(true, opPing, nil, &[4]byte{1, 2, 3, 4}))
+		case <-time.After(5 * time.Second):
+			t.Fatal("servidor não desistiu do close")
+		}
+	}
+}
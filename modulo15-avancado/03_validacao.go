@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"go-course/modulo15-avancado/validar"
+	"strings"
+	"unicode"
+)
+
+// ========================================
+// VALIDAÇÃO COM TAGS (reflection)
+// ========================================
+
+// Mesmo Usuario de modulo07-erros/01_erros_basicos.go, agora com as
+// regras na tag em vez de um Validar escrito à mão
+type Usuario struct {
+	Nome      string    `json:"nome" validate:"required,min=3,max=50"`
+	Username  string    `json:"username" validate:"required,semespaco"`
+	Email     string    `json:"email" validate:"required,email"`
+	Idade     int       `json:"idade" validate:"min=18,max=130"`
+	Senha     string    `json:"senha" validate:"required,min=8,sensivel"`
+	Confirmar string    `json:"confirmar_senha" validate:"eqfield=Senha,sensivel"`
+	Plano     string    `json:"plano" validate:"oneof=gratis pro"`
+	Endereco  Endereco  `json:"endereco"`
+	Contatos  []Contato `json:"contatos" validate:"max=3"`
+	Tags      []string  `json:"tags" validate:"omitempty,dive,min=2,max=20"`
+}
+
+type Endereco struct {
+	Cidade string `json:"cidade" validate:"required"`
+	UF     string `json:"uf" validate:"len=2"`
+}
+
+type Contato struct {
+	Tipo  string `json:"tipo" validate:"oneof=email telefone"`
+	Valor string `json:"valor" validate:"required"`
+}
+
+func main() {
+	// Regra própria: vale em qualquer tag depois de registrada
+	validar.Registrar("semespaco", func(c validar.Campo) error {
+		if strings.IndexFunc(c.Valor.String(), unicode.IsSpace) >= 0 {
+			return errors.New("não pode ter espaços")
+		}
+		return nil
+	})
+
+	fmt.Println("=== USUÁRIO VÁLIDO ===")
+	ok := Usuario{
+		Nome: "João Silva", Username: "joao", Email: "joao@email.com", Idade: 25,
+		Senha: "senha-forte", Confirmar: "senha-forte", Plano: "pro",
+		Endereco: Endereco{Cidade: "Recife", UF: "PE"},
+		Contatos: []Contato{{Tipo: "email", Valor: "joao@email.com"}},
+	}
+	fmt.Println("  erro:", validar.Struct(ok))
+
+	fmt.Println("\n=== TODOS OS ERROS DE UMA VEZ ===")
+	ruim := Usuario{
+		Nome: "Jo", Username: "joão silva", Email: "joao@", Idade: 15,
+		Senha: "12345678", Confirmar: "87654321", Plano: "vip",
+		Endereco: Endereco{UF: "Pernambuco"},
+		Contatos: []Contato{{Tipo: "email", Valor: "x@y.com"}, {Tipo: "fax"}},
+		Tags:     []string{"go", "x"},
+	}
+	err := validar.Struct(ruim)
+
+	// errors.Join: cada erro é um erros.ErroValidacao com o caminho
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ev erros.ErroValidacao
+		if errors.As(e, &ev) {
+			fmt.Printf("  %-22s %-26q %s\n", ev.Campo, ev.Valor, ev.Mensagem)
+		}
+	}
+}
+
+/*
+RESUMO:
+
+TAGS (lidas com reflect.StructField.Tag.Get("validate")):
+    required, min=N, max=N, len=N, email, url, oneof=a b c
+    eqfield=Campo, nefield, gtfield, ltfield   (entre campos)
+    omitempty   zerado pula o resto
+    dive        as regras seguintes valem para cada item
+    sensivel    o valor não aparece no erro
+
+COMPARAÇÃO COM O Validar À MÃO (modulo07-erros):
+    ✓ Devolve todos os erros, não só o primeiro
+    ✓ Caminho completo: endereco.uf, contatos[1].valor
+    ✓ Regras reaproveitadas entre tipos
+    ✗ Erro na tag só aparece ao validar (panic), não ao compilar
+    ✗ Mais lento que if's (a análise da tag fica em cache por tipo)
+
+NUMA API: erros.ErroValidacao é o que modulo12-http/problema
+transforma em 422 com a lista de campos.
+
+Execute:
+    go run 03_validacao.go
+*/
@@ -0,0 +1,259 @@
+package validar
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+REGRAS EMBUTIDAS
+
+    required          não pode ser zero (texto, slice e mapa: não vazios)
+    min=N, max=N      texto: caracteres; número: valor; slice/mapa: itens
+    len=N             tamanho exato (ou valor exato, em números)
+    email             endereço simples: nome@dominio
+    url               URL absoluta (http://host/...)
+    oneof=a b c       um dos valores, separados por espaço
+    eqfield=Campo     igual a outro campo da mesma struct
+    nefield=Campo     diferente de outro campo
+    gtfield=Campo     maior que outro campo (números, textos, time.Time)
+    ltfield=Campo     menor que outro campo
+
+Regras próprias com Registrar:
+
+    validar.Registrar("cpf", func(c validar.Campo) error {
+        if !cpfValido(c.Valor.String()) {
+            return errors.New("CPF inválido")
+        }
+        return nil
+    })
+*/
+
+func embutidas() map[string]Regra {
+	return map[string]Regra{
+		"required": obrigatorio,
+		"min":      limite("min"),
+		"max":      limite("max"),
+		"len":      limite("len"),
+		"email":    email,
+		"url":      enderecoURL,
+		"oneof":    umDe,
+		"eqfield":  entreCampos("eqfield"),
+		"nefield":  entreCampos("nefield"),
+		"gtfield":  entreCampos("gtfield"),
+		"ltfield":  entreCampos("ltfield"),
+	}
+}
+
+// conferirParametro valida o parâmetro das regras embutidas quando o
+// tipo é analisado, antes de qualquer valor; alvo é o tipo conferido
+// (interface só é conhecida na validação)
+func conferirParametro(t, alvo reflect.Type, nome, parametro string) error {
+	switch nome {
+	case "min", "max", "len":
+		if _, err := strconv.ParseFloat(parametro, 64); err != nil {
+			return fmt.Errorf("parâmetro %q não é um número", parametro)
+		}
+		if alvo.Kind() != reflect.Interface && !mensuravel(alvo.Kind()) {
+			return fmt.Errorf("não se aplica a %s", alvo)
+		}
+	case "oneof":
+		if strings.TrimSpace(parametro) == "" {
+			return errors.New("sem valores")
+		}
+	case "eqfield", "nefield", "gtfield", "ltfield":
+		if _, ok := t.FieldByName(parametro); !ok {
+			return fmt.Errorf("campo %q não existe", parametro)
+		}
+	}
+	return nil
+}
+
+func obrigatorio(c Campo) error {
+	if zerado(c.Valor) {
+		return errors.New("obrigatório")
+	}
+	return nil
+}
+
+// limite mede texto em caracteres (não bytes), coleções em itens e
+// números pelo valor
+func limite(tipo string) Regra {
+	return func(c Campo) error {
+		n, _ := strconv.ParseFloat(c.Parametro, 64)
+		medida, unidade, ok := medir(c.Valor)
+		if !ok {
+			return fmt.Errorf("%s não se aplica a %s", tipo, c.Valor.Kind())
+		}
+		p := c.Parametro
+		switch {
+		case tipo == "min" && medida < n:
+			if unidade == "" {
+				return fmt.Errorf("deve ser no mínimo %s", p)
+			}
+			return fmt.Errorf("deve ter pelo menos %s %s", p, unidade)
+		case tipo == "max" && medida > n:
+			if unidade == "" {
+				return fmt.Errorf("deve ser no máximo %s", p)
+			}
+			return fmt.Errorf("deve ter no máximo %s %s", p, unidade)
+		case tipo == "len" && medida != n:
+			if unidade == "" {
+				return fmt.Errorf("deve ser igual a %s", p)
+			}
+			return fmt.Errorf("deve ter exatamente %s %s", p, unidade)
+		}
+		return nil
+	}
+}
+
+// mensuravel diz se min/max/len se aplicam ao tipo (os casos de medir)
+func mensuravel(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// medir devolve o número comparado por min/max/len e a unidade da
+// mensagem ("" para números)
+func medir(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), "caracteres", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), "itens", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+	return 0, "", false
+}
+
+// email aceita só "nome@dominio": o net/mail sozinho também aceitaria
+// "Ana <ana@x.com>"
+func email(c Campo) error {
+	texto := textoDe(c.Valor)
+	endereco, err := mail.ParseAddress(texto)
+	if err != nil || endereco.Address != texto || !strings.Contains(texto[strings.LastIndex(texto, "@"):], ".") {
+		return errors.New("email inválido")
+	}
+	return nil
+}
+
+func enderecoURL(c Campo) error {
+	u, err := url.Parse(textoDe(c.Valor))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("URL inválida (esperado algo como https://exemplo.com)")
+	}
+	return nil
+}
+
+func umDe(c Campo) error {
+	opcoes := strings.Fields(c.Parametro)
+	atual := fmt.Sprint(c.Valor.Interface())
+	for _, o := range opcoes {
+		if atual == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("deve ser um de: %s", strings.Join(opcoes, ", "))
+}
+
+func textoDe(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// ========================================
+// ENTRE CAMPOS
+// ========================================
+
+func entreCampos(tipo string) Regra {
+	return func(c Campo) error {
+		f, _ := c.Struct.Type().FieldByName(c.Parametro)
+		outro, err := c.Struct.FieldByIndexErr(f.Index)
+		if err != nil {
+			outro = reflect.Value{} // dentro de um ponteiro embutido nil
+		}
+		for outro.Kind() == reflect.Pointer && !outro.IsNil() {
+			outro = outro.Elem()
+		}
+		nome := nomeExibicao(f)
+
+		switch tipo {
+		case "eqfield":
+			if !iguais(c.Valor, outro) {
+				return fmt.Errorf("deve ser igual a %s", nome)
+			}
+		case "nefield":
+			if iguais(c.Valor, outro) {
+				return fmt.Errorf("deve ser diferente de %s", nome)
+			}
+		case "gtfield", "ltfield":
+			ordem, ok := comparar(c.Valor, outro)
+			if !ok {
+				return fmt.Errorf("não dá para comparar com %s", nome)
+			}
+			if tipo == "gtfield" && ordem <= 0 {
+				return fmt.Errorf("deve ser maior que %s", nome)
+			}
+			if tipo == "ltfield" && ordem >= 0 {
+				return fmt.Errorf("deve ser menor que %s", nome)
+			}
+		}
+		return nil
+	}
+}
+
+func iguais(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// comparar devolve -1, 0 ou 1; ok é false para tipos sem ordem
+func comparar(a, b reflect.Value) (int, bool) {
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+	if ta, ok := a.Interface().(time.Time); ok {
+		if tb, ok := b.Interface().(time.Time); ok {
+			return ta.Compare(tb), true
+		}
+		return 0, false
+	}
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+	// Só números: tamanho de slice não é ordem
+	na, unidadeA, okA := medir(a)
+	nb, unidadeB, okB := medir(b)
+	if !okA || !okB || unidadeA != "" || unidadeB != "" {
+		return 0, false
+	}
+	switch {
+	case na < nb:
+		return -1, true
+	case na > nb:
+		return 1, true
+	}
+	return 0, true
+}
@@ -0,0 +1,76 @@
+package validar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegrasEmbutidas(t *testing.T) {
+	testes := []struct {
+		regra     string
+		parametro string
+		valor     any
+		valido    bool
+	}{
+		{"required", "", "", false},
+		{"required", "", "x", true},
+		{"required", "", []int{}, false},
+		{"required", "", 0, false},
+		{"required", "", false, false},
+		{"min", "3", "ção", true}, // caracteres, não bytes
+		{"min", "3", "ab", false},
+		{"min", "1.5", 1.4, false},
+		{"min", "2", []int{1, 2}, true},
+		{"max", "2", map[string]int{"a": 1, "b": 2, "c": 3}, false},
+		{"max", "10", uint8(10), true},
+		{"len", "2", "ab", true},
+		{"len", "2", [3]int{}, false},
+		{"email", "", "ana@escola.br", true},
+		{"email", "", "Ana <ana@escola.br>", false},
+		{"email", "", "ana@localhost", false},
+		{"email", "", "ana.escola.br", false},
+		{"url", "", "https://exemplo.com/a?b=1", true},
+		{"url", "", "exemplo.com", false},
+		{"url", "", "/caminho", false},
+		{"oneof", "pix boleto cartao", "pix", true},
+		{"oneof", "pix boleto cartao", "dinheiro", false},
+		{"oneof", "1 2 3", 2, true},
+	}
+	regras := embutidas()
+	for _, tt := range testes {
+		err := regras[tt.regra](Campo{Valor: reflect.ValueOf(tt.valor), Parametro: tt.parametro})
+		if (err == nil) != tt.valido {
+			t.Errorf("%s=%s com %#v: erro = %v; esperado válido = %v", tt.regra, tt.parametro, tt.valor, err, tt.valido)
+		}
+	}
+}
+
+func TestLimite_TipoSemTamanho(t *testing.T) {
+	err := limite("min")(Campo{Valor: reflect.ValueOf(true), Parametro: "1"})
+	if err == nil {
+		t.Error("min em bool deveria falhar")
+	}
+}
+
+func TestComparar(t *testing.T) {
+	agora := time.Now()
+	testes := []struct {
+		a, b  any
+		ordem int
+		ok    bool
+	}{
+		{1, 2, -1, true},
+		{2.5, 2.5, 0, true},
+		{"b", "a", 1, true},
+		{agora.Add(time.Hour), agora, 1, true},
+		{[]int{1}, []int{1, 2}, 0, false},
+		{agora, 1, 0, false},
+	}
+	for _, tt := range testes {
+		ordem, ok := comparar(reflect.ValueOf(tt.a), reflect.ValueOf(tt.b))
+		if ordem != tt.ordem || ok != tt.ok {
+			t.Errorf("comparar(%v, %v) = %d, %v", tt.a, tt.b, ordem, ok)
+		}
+	}
+}
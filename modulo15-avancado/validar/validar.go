@@ -0,0 +1,374 @@
+package validar
+
+import (
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*
+PACKAGE VALIDAR
+
+O Usuario.Validar de modulo07-erros/01_erros_basicos.go é escrito à
+mão e para no primeiro erro. Aqui as regras ficam na tag do campo e
+são lidas com reflection (veja 02_reflection.go):
+
+    type Usuario struct {
+        Nome      string    `json:"nome" validate:"required,min=3,max=50"`
+        Email     string    `json:"email" validate:"required,email"`
+        Idade     int       `json:"idade" validate:"min=18"`
+        Senha     string    `json:"senha" validate:"required,min=8,sensivel"`
+        Confirmar string    `json:"confirmar" validate:"eqfield=Senha,sensivel"`
+        Endereco  *Endereco `json:"endereco"`                    // validado por dentro
+        Tags      []string  `json:"tags" validate:"max=5,dive,min=2"`
+    }
+
+    err := validar.Struct(u)
+
+O erro junta (errors.Join) um erros.ErroValidacao por campo inválido,
+com o caminho completo: "endereco.cep", "telefones[1].numero",
+"notas[ana]". modulo12-http/problema transforma isso num 422 com a
+lista de campos.
+
+TAG:
+  - regras separadas por vírgula, parâmetro depois do "=": min=3
+  - cada campo para na primeira regra que falhar (um erro por campo)
+  - omitempty: valor zerado pula as outras regras
+  - dive: as regras seguintes valem para cada item da slice/mapa
+  - sensivel: o valor não aparece no erro (senhas, tokens)
+  - ponteiro nil só é conferido pelo required; as outras regras valem
+    para o valor apontado. Ponteiro não nil conta como presente para
+    required e omitempty, mesmo apontando para zero (um *int com 0)
+  - "-": o campo é ignorado, inclusive o que tiver dentro
+  - structs, ponteiros para struct e slices/mapas de structs são
+    validados por dentro mesmo sem tag
+
+O caminho usa o nome da tag json quando houver (é o que o cliente da
+API mandou); senão, o nome do campo em Go. Já eqfield=Senha e afins
+usam o nome em Go do outro campo.
+
+Tag malformada (regra desconhecida, min=abc, min num bool, dive num
+campo que não é coleção, eqfield para um campo que não existe) é erro
+de programação: panic na primeira validação do tipo, como as rotas
+repetidas do roteador.
+*/
+
+// Regra confere um campo; o texto do erro vira a Mensagem do
+// erros.ErroValidacao
+type Regra func(c Campo) error
+
+// Campo é o que uma Regra recebe
+type Campo struct {
+	// Valor do campo (ou do item, depois de dive), com ponteiros já
+	// seguidos
+	Valor reflect.Value
+	// Parametro é o texto depois do "=" na tag ("" se não houver)
+	Parametro string
+	// Struct é a struct que contém o campo, para regras entre campos
+	Struct reflect.Value
+}
+
+// Palavras da tag que não são regras
+const (
+	marcadorOmitir   = "omitempty"
+	marcadorDive     = "dive"
+	marcadorSensivel = "sensivel"
+)
+
+// Validador guarda as regras e a análise das tags de cada tipo. É
+// seguro para uso concorrente.
+type Validador struct {
+	mu     sync.RWMutex
+	regras map[string]Regra
+	tipos  sync.Map // reflect.Type → []campoTipo
+}
+
+// Novo cria um validador com as regras embutidas (veja regras.go)
+func Novo() *Validador {
+	v := &Validador{regras: make(map[string]Regra)}
+	for nome, r := range embutidas() {
+		v.regras[nome] = r
+	}
+	return v
+}
+
+var padrao = Novo()
+
+// Struct valida s (struct ou ponteiro para struct) com o validador
+// padrão
+func Struct(s any) error {
+	return padrao.Struct(s)
+}
+
+// Registrar acrescenta (ou troca) uma regra no validador padrão
+func Registrar(nome string, r Regra) {
+	padrao.Registrar(nome, r)
+}
+
+// Registrar acrescenta uma regra, que passa a valer nas tags; com o
+// nome de uma embutida, substitui a embutida
+func (v *Validador) Registrar(nome string, r Regra) {
+	if nome == "" || r == nil || strings.ContainsAny(nome, ",= ") {
+		panic(fmt.Sprintf("validar: regra inválida %q", nome))
+	}
+	switch nome {
+	case marcadorOmitir, marcadorDive, marcadorSensivel:
+		panic(fmt.Sprintf("validar: %q é reservado", nome))
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.regras[nome] = r
+}
+
+// Struct valida todos os campos de s e devolve nil ou um errors.Join
+// de erros.ErroValidacao
+func (v *Validador) Struct(s any) error {
+	valor := reflect.ValueOf(s)
+	for valor.Kind() == reflect.Pointer && !valor.IsNil() {
+		valor = valor.Elem()
+	}
+	if valor.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validar: Struct recebeu %T, esperado struct ou ponteiro para struct", s))
+	}
+	var problemas []error
+	v.validarStruct(valor, "", &problemas)
+	return errors.Join(problemas...)
+}
+
+// ========================================
+// ANÁLISE DAS TAGS
+// ========================================
+
+// campoTipo é um campo exportado com as regras já separadas
+type campoTipo struct {
+	indice   int
+	nome     string // caminho mostrado no erro
+	regras   []regraTag
+	sensivel bool
+	embutido bool // struct embutida sem nome json: campos sobem de nível
+}
+
+type regraTag struct {
+	nome      string
+	parametro string
+}
+
+func (v *Validador) campos(t reflect.Type) []campoTipo {
+	if c, ok := v.tipos.Load(t); ok {
+		return c.([]campoTipo)
+	}
+	var campos []campoTipo
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("validate")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		c := campoTipo{indice: i, nome: nomeExibicao(f)}
+		c.embutido = f.Anonymous && !temNomeJSON(f)
+		alvo := semPonteiro(f.Type) // o que as próximas regras conferem
+		for _, parte := range strings.Split(tag, ",") {
+			parte = strings.TrimSpace(parte)
+			if parte == "" {
+				continue
+			}
+			nome, parametro, _ := strings.Cut(parte, "=")
+			if nome == marcadorSensivel {
+				c.sensivel = true
+				continue
+			}
+			v.conferirRegra(t, f, alvo, nome, parametro)
+			if nome == marcadorDive && alvo.Kind() != reflect.Interface {
+				alvo = semPonteiro(alvo.Elem())
+			}
+			c.regras = append(c.regras, regraTag{nome: nome, parametro: parametro})
+		}
+		campos = append(campos, c)
+	}
+	v.tipos.Store(t, campos)
+	return campos
+}
+
+// conferirRegra dá panic em tags que nunca funcionariam; alvo é o tipo
+// que a regra confere (o do campo ou, depois de dive, o dos itens)
+func (v *Validador) conferirRegra(t reflect.Type, f reflect.StructField, alvo reflect.Type, nome, parametro string) {
+	onde := t.Name() + "." + f.Name
+	switch nome {
+	case marcadorOmitir:
+		return
+	case marcadorDive:
+		switch alvo.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface:
+			return
+		}
+		panic(fmt.Sprintf("validar: dive em %s: %s não é slice, array nem mapa", onde, alvo))
+	}
+	v.mu.RLock()
+	_, existe := v.regras[nome]
+	v.mu.RUnlock()
+	if !existe {
+		panic(fmt.Sprintf("validar: regra desconhecida %q em %s", nome, onde))
+	}
+	if err := conferirParametro(t, alvo, nome, parametro); err != nil {
+		panic(fmt.Sprintf("validar: %s em %s: %v", nome, onde, err))
+	}
+}
+
+// nomeExibicao é o nome da tag json ou, sem ela, o nome em Go
+func nomeExibicao(f reflect.StructField) string {
+	if nome, _, _ := strings.Cut(f.Tag.Get("json"), ","); nome != "" && nome != "-" {
+		return nome
+	}
+	return f.Name
+}
+
+// semPonteiro é o tipo apontado, depois de todos os ponteiros
+func semPonteiro(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func temNomeJSON(f reflect.StructField) bool {
+	nome, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return nome != "" && nome != "-"
+}
+
+// ========================================
+// PERCURSO
+// ========================================
+
+func (v *Validador) validarStruct(s reflect.Value, prefixo string, problemas *[]error) {
+	for _, c := range v.campos(s.Type()) {
+		caminho := juntar(prefixo, c.nome)
+		if c.embutido {
+			caminho = prefixo
+		}
+		v.validarValor(s.Field(c.indice), s, caminho, c.regras, c.sensivel, problemas)
+	}
+}
+
+// validarValor aplica as regras a valor e depois desce nele (struct,
+// slice, mapa). Depois de um dive, as regras restantes valem para
+// cada item.
+func (v *Validador) validarValor(valor, pai reflect.Value, caminho string, regras []regraTag, sensivel bool, problemas *[]error) {
+	apontado := false
+	for valor.Kind() == reflect.Pointer || valor.Kind() == reflect.Interface {
+		if valor.IsNil() {
+			break
+		}
+		valor = valor.Elem()
+		apontado = true
+	}
+	// Ponteiro não nil é valor presente, mesmo apontando para zero
+	vazio := !apontado && zerado(valor)
+	nulo := (valor.Kind() == reflect.Pointer || valor.Kind() == reflect.Interface) && valor.IsNil()
+
+	for i, r := range regras {
+		if nulo && r.nome != "required" {
+			// Ponteiro nil só é conferido pelo required
+			return
+		}
+		if apontado && r.nome == "required" {
+			continue
+		}
+		switch r.nome {
+		case marcadorOmitir:
+			if vazio {
+				return
+			}
+			continue
+		case marcadorDive:
+			v.percorrerItens(valor, pai, caminho, regras[i+1:], sensivel, problemas)
+			return
+		}
+		if err := v.aplicar(r, valor, pai); err != nil {
+			*problemas = append(*problemas, erros.ErroValidacao{
+				Campo:    caminho,
+				Valor:    valorErro(valor, sensivel),
+				Mensagem: err.Error(),
+			})
+			return
+		}
+	}
+
+	switch valor.Kind() {
+	case reflect.Struct:
+		v.validarStruct(valor, caminho, problemas)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if contemStructs(valor.Type().Elem()) {
+			v.percorrerItens(valor, pai, caminho, nil, sensivel, problemas)
+		}
+	}
+}
+
+func (v *Validador) percorrerItens(valor, pai reflect.Value, caminho string, regras []regraTag, sensivel bool, problemas *[]error) {
+	switch valor.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < valor.Len(); i++ {
+			v.validarValor(valor.Index(i), pai, fmt.Sprintf("%s[%d]", caminho, i), regras, sensivel, problemas)
+		}
+	case reflect.Map:
+		chaves := valor.MapKeys()
+		// Ordem fixa: a mesma entrada dá sempre a mesma lista de erros
+		sort.Slice(chaves, func(i, j int) bool {
+			return fmt.Sprint(chaves[i].Interface()) < fmt.Sprint(chaves[j].Interface())
+		})
+		for _, k := range chaves {
+			v.validarValor(valor.MapIndex(k), pai, fmt.Sprintf("%s[%v]", caminho, k.Interface()), regras, sensivel, problemas)
+		}
+	}
+}
+
+func (v *Validador) aplicar(r regraTag, valor, pai reflect.Value) error {
+	v.mu.RLock()
+	regra := v.regras[r.nome]
+	v.mu.RUnlock()
+	return regra(Campo{Valor: valor, Parametro: r.parametro, Struct: pai})
+}
+
+// zerado trata slice e mapa vazios como ausentes (não só nil)
+func zerado(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// contemStructs diz se vale descer nos itens de uma coleção
+func contemStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Interface
+}
+
+// valorErro mostra só valores simples, e nunca os marcados sensivel
+func valorErro(v reflect.Value, sensivel bool) string {
+	if sensivel {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface())
+	}
+	return ""
+}
+
+func juntar(prefixo, nome string) string {
+	if prefixo == "" {
+		return nome
+	}
+	return prefixo + "." + nome
+}
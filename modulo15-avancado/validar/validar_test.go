@@ -0,0 +1,304 @@
+package validar
+
+import (
+	"errors"
+	"fmt"
+	"go-course/modulo07-erros/erros"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type Endereco struct {
+	Rua string `json:"rua" validate:"required"`
+	CEP string `json:"cep" validate:"len=8"`
+}
+
+type Telefone struct {
+	Numero string `json:"numero" validate:"required,min=8"`
+}
+
+type Base struct {
+	ID int `json:"id" validate:"min=1"`
+}
+
+type Cadastro struct {
+	Base
+	Nome      string            `json:"nome" validate:"required,min=3,max=50"`
+	Email     string            `json:"email" validate:"required,email"`
+	Idade     int               `json:"idade" validate:"min=18"`
+	Senha     string            `json:"senha" validate:"required,min=8,sensivel"`
+	Confirmar string            `json:"confirmar" validate:"eqfield=Senha,sensivel"`
+	Endereco  *Endereco         `json:"endereco"`
+	Telefones []Telefone        `json:"telefones" validate:"max=3"`
+	Tags      []string          `json:"tags" validate:"omitempty,dive,min=2"`
+	Notas     map[string]int    `json:"notas" validate:"dive,min=0,max=10"`
+	Apelido   *string           `json:"apelido" validate:"min=2"`
+	Extra     map[string]string `validate:"-"`
+	interno   string
+}
+
+func valido() Cadastro {
+	return Cadastro{
+		Base:      Base{ID: 1},
+		Nome:      "Ana Souza",
+		Email:     "ana@escola.br",
+		Idade:     30,
+		Senha:     "senha-secreta",
+		Confirmar: "senha-secreta",
+		Endereco:  &Endereco{Rua: "Rua A", CEP: "01001000"},
+		Telefones: []Telefone{{Numero: "11999990000"}},
+		Notas:     map[string]int{"matematica": 9},
+	}
+}
+
+// lista devolve os erros.ErroValidacao do errors.Join, na ordem
+func lista(t *testing.T, err error) []erros.ErroValidacao {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var saida []erros.ErroValidacao
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ev erros.ErroValidacao
+		if !errors.As(e, &ev) {
+			t.Fatalf("erro de tipo inesperado: %T", e)
+		}
+		saida = append(saida, ev)
+	}
+	return saida
+}
+
+func TestStruct_Valido(t *testing.T) {
+	c := valido()
+	if err := Struct(c); err != nil {
+		t.Errorf("Struct(valor) = %v", err)
+	}
+	if err := Struct(&c); err != nil {
+		t.Errorf("Struct(ponteiro) = %v", err)
+	}
+}
+
+func TestStruct_TodosOsErros(t *testing.T) {
+	apelido := "A"
+	c := Cadastro{
+		Nome:      "Al",
+		Email:     "ana@",
+		Idade:     15,
+		Senha:     "curta",
+		Confirmar: "outra",
+		Endereco:  &Endereco{CEP: "123"},
+		Telefones: []Telefone{{Numero: "11999990000"}, {Numero: "123"}, {}, {Numero: "x"}},
+		Tags:      []string{"go", "x"},
+		Notas:     map[string]int{"portugues": 11, "historia": -1, "artes": 7},
+		Apelido:   &apelido,
+	}
+	esperado := []erros.ErroValidacao{
+		{Campo: "id", Valor: "0", Mensagem: "deve ser no mínimo 1"},
+		{Campo: "nome", Valor: "Al", Mensagem: "deve ter pelo menos 3 caracteres"},
+		{Campo: "email", Valor: "ana@", Mensagem: "email inválido"},
+		{Campo: "idade", Valor: "15", Mensagem: "deve ser no mínimo 18"},
+		{Campo: "senha", Mensagem: "deve ter pelo menos 8 caracteres"},
+		{Campo: "confirmar", Mensagem: "deve ser igual a senha"},
+		{Campo: "endereco.rua", Mensagem: "obrigatório"},
+		{Campo: "endereco.cep", Valor: "123", Mensagem: "deve ter exatamente 8 caracteres"},
+		{Campo: "telefones", Mensagem: "deve ter no máximo 3 itens"},
+		{Campo: "tags[1]", Valor: "x", Mensagem: "deve ter pelo menos 2 caracteres"},
+		{Campo: "notas[historia]", Valor: "-1", Mensagem: "deve ser no mínimo 0"},
+		{Campo: "notas[portugues]", Valor: "11", Mensagem: "deve ser no máximo 10"},
+		{Campo: "apelido", Valor: "A", Mensagem: "deve ter pelo menos 2 caracteres"},
+	}
+	got := lista(t, Struct(c))
+	if !reflect.DeepEqual(got, esperado) {
+		t.Errorf("erros:\n%s\nesperado:\n%s", formatar(got), formatar(esperado))
+	}
+
+	// Com no máximo 3 telefones, os itens são validados por dentro
+	c.Telefones = c.Telefones[:3]
+	got = lista(t, Struct(c))
+	for _, campo := range []string{"telefones[1].numero", "telefones[2].numero"} {
+		if !contemCampo(got, campo) {
+			t.Errorf("falta %s em:\n%s", campo, formatar(got))
+		}
+	}
+	if contemCampo(got, "telefones[0].numero") {
+		t.Error("telefones[0] é válido")
+	}
+}
+
+func TestStruct_PonteirosEOmitempty(t *testing.T) {
+	c := valido()
+	c.Endereco = nil // sem required: ausente é válido
+	c.Apelido = nil
+	c.Tags = nil
+	if err := Struct(c); err != nil {
+		t.Errorf("Struct = %v", err)
+	}
+
+	type Obrigatorio struct {
+		Endereco *Endereco `json:"endereco" validate:"required"`
+	}
+	got := lista(t, Struct(Obrigatorio{}))
+	if len(got) != 1 || got[0].Campo != "endereco" || got[0].Mensagem != "obrigatório" {
+		t.Errorf("erros = %v", got)
+	}
+
+	// Ponteiro para zero foi enviado: required passa e omitempty não pula
+	type Nota struct {
+		Valor  *int `json:"valor" validate:"required,max=10"`
+		Faltas *int `json:"faltas" validate:"omitempty,min=1"`
+	}
+	zero := 0
+	got = lista(t, Struct(Nota{Valor: &zero, Faltas: &zero}))
+	if len(got) != 1 || got[0].Campo != "faltas" {
+		t.Errorf("erros = %v; esperado só faltas", got)
+	}
+	got = lista(t, Struct(Nota{}))
+	if len(got) != 1 || got[0].Campo != "valor" || got[0].Mensagem != "obrigatório" {
+		t.Errorf("erros = %v", got)
+	}
+}
+
+func TestStruct_EntreCampos(t *testing.T) {
+	type Periodo struct {
+		Inicio int    `validate:"required"`
+		Fim    int    `validate:"gtfield=Inicio"`
+		Antes  string `validate:"ltfield=Depois"`
+		Depois string
+		Novo   string `validate:"nefield=Antigo"`
+		Antigo string
+	}
+	testes := []struct {
+		nome   string
+		p      Periodo
+		campos []string
+	}{
+		{"válido", Periodo{Inicio: 1, Fim: 2, Antes: "a", Depois: "b", Novo: "x", Antigo: "y"}, nil},
+		{"fim igual", Periodo{Inicio: 1, Fim: 1, Antes: "a", Depois: "b", Novo: "x", Antigo: "y"}, []string{"Fim"}},
+		{"tudo errado", Periodo{Inicio: 5, Fim: 1, Antes: "c", Depois: "b", Novo: "x", Antigo: "x"}, []string{"Fim", "Antes", "Novo"}},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			var campos []string
+			for _, e := range lista(t, Struct(tt.p)) {
+				campos = append(campos, e.Campo)
+			}
+			if !reflect.DeepEqual(campos, tt.campos) {
+				t.Errorf("campos = %v; esperado %v", campos, tt.campos)
+			}
+		})
+	}
+}
+
+func TestRegistrar(t *testing.T) {
+	v := Novo()
+	v.Registrar("par", func(c Campo) error {
+		if c.Valor.Int()%2 != 0 {
+			return errors.New("deve ser par")
+		}
+		return nil
+	})
+	type Mesa struct {
+		Lugares int `json:"lugares" validate:"min=2,par"`
+	}
+	if err := v.Struct(Mesa{Lugares: 4}); err != nil {
+		t.Errorf("4 lugares: %v", err)
+	}
+	got := lista(t, v.Struct(Mesa{Lugares: 5}))
+	if len(got) != 1 || got[0].Mensagem != "deve ser par" || got[0].Valor != "5" {
+		t.Errorf("5 lugares: %v", got)
+	}
+
+	// A regra é do validador, não do padrão
+	defer func() {
+		if recover() == nil {
+			t.Error("o validador padrão não conhece \"par\"")
+		}
+	}()
+	Struct(Mesa{Lugares: 4})
+}
+
+func TestPanics(t *testing.T) {
+	type Desconhecida struct {
+		X string `validate:"cpf"`
+	}
+	type NaoNumero struct {
+		X string `validate:"min=tres"`
+	}
+	type SemCampo struct {
+		X string `validate:"eqfield=Y"`
+	}
+	type MinEmBool struct {
+		X bool `validate:"min=1"`
+	}
+	type MaxEmStruct struct {
+		X *Endereco `validate:"max=2"`
+	}
+	type LenNosItens struct {
+		X []bool `validate:"dive,len=1"`
+	}
+	type DiveEmTexto struct {
+		X string `validate:"dive,min=1"`
+	}
+	testes := []struct {
+		nome string
+		f    func()
+	}{
+		{"regra desconhecida", func() { Struct(Desconhecida{}) }},
+		{"parâmetro não numérico", func() { Struct(NaoNumero{}) }},
+		{"campo inexistente", func() { Struct(SemCampo{}) }},
+		{"min em bool", func() { Struct(MinEmBool{}) }},
+		{"max em struct", func() { Struct(MaxEmStruct{}) }},
+		{"len nos itens de []bool", func() { Struct(LenNosItens{}) }},
+		{"dive em string", func() { Struct(DiveEmTexto{}) }},
+		{"não é struct", func() { Struct("texto") }},
+		{"nome reservado", func() { Novo().Registrar("dive", func(Campo) error { return nil }) }},
+		{"nome com vírgula", func() { Novo().Registrar("a,b", func(Campo) error { return nil }) }},
+	}
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("esperado panic")
+				}
+			}()
+			tt.f()
+		})
+	}
+}
+
+func TestConcorrencia(t *testing.T) {
+	v := Novo()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := v.Struct(valido()); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func contemCampo(lista []erros.ErroValidacao, campo string) bool {
+	for _, e := range lista {
+		if e.Campo == campo {
+			return true
+		}
+	}
+	return false
+}
+
+func formatar(lista []erros.ErroValidacao) string {
+	var b strings.Builder
+	for _, e := range lista {
+		fmt.Fprintf(&b, "  %s (%q): %s\n", e.Campo, e.Valor, e.Mensagem)
+	}
+	return b.String()
+}